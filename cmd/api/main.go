@@ -45,39 +45,99 @@ func main() {
 		loggerService.Error("Failed to connect to database", err)
 		return
 	}
+	dockerRepository := repository.NewDockerRepository(db.DBConnection, loggerService)
+	dockerClientManager := config.NewDockerClientManager(loggerService, dockerRepository, cfg.EncryptionKey,
+		30*time.Second, 3, 1*time.Second)
+	defer dockerClientManager.Close()
 	rateLimiter := middleware.NewRateLimiter(5, 1*time.Minute, 5*time.Minute, 10*time.Minute, loggerService)
 	jwt := middleware.NewJWT(cfg.JWTSecret, loggerService, cacheService)
 
 	// User
 	userRepository := repository.NewUserRepository(db.DBConnection, loggerService)
+	permissions := middleware.NewPermissions(userRepository, loggerService)
 	userService := user.NewUserService(loggerService, userRepository, cacheService)
 	userController := controllers.NewUserController(userService, loggerService)
+	appRepository := repository.NewAppRepository(db.DBConnection, loggerService)
+	appNotificationsService := servicesApp.NewAppNotificationsService(appRepository, loggerService)
 	// Route
 	routeRepository := repository.NewRouteRepository(db.DBConnection, loggerService)
-	routeStatusService := servicesApp.NewRouteStatusService(routeRepository, loggerService)
-	routeService := servicesApp.NewRouteService(loggerService, routeRepository)
-	routeController := controllers.NewRouteController(routeService, loggerService)
+	routeStatusService := servicesApp.NewRouteStatusService(routeRepository, appNotificationsService,
+		cfg.EncryptionKey, loggerService)
+	if err := routeStatusService.FailInterruptedRouteFlowRuns(context.Background()); err != nil {
+		loggerService.Warn("Failed to mark interrupted route flow runs as failed", err)
+	}
+	routeService := servicesApp.NewRouteService(loggerService, routeRepository, appRepository, cfg.EncryptionKey)
+	routeController := controllers.NewRouteController(routeService, routeStatusService, loggerService)
 	// App
-	appRepository := repository.NewAppRepository(db.DBConnection, loggerService)
-	appStatusService := servicesApp.NewAppStatusService(appRepository, cacheService, loggerService, cfg.DockerHost)
-	appNotificationsService := servicesApp.NewAppNotificationsService(appRepository, loggerService)
+	pm2Client := config.NewPm2Client("pm2", loggerService)
+	systemdClient := config.NewSystemdClient(loggerService)
+	kubernetesRepository := repository.NewKubernetesRepository(db.DBConnection, loggerService)
+	kubernetesClient := config.NewKubernetesClient(loggerService, kubernetesRepository, cfg.EncryptionKey)
+	appStatusService := servicesApp.NewAppStatusService(appRepository, cacheService, loggerService, cfg.DockerHost,
+		dockerClientManager, pm2Client, systemdClient, kubernetesRepository, kubernetesClient)
 	appService := servicesApp.NewAppService(appRepository, loggerService, appStatusService, appNotificationsService, routeStatusService)
 	appController := controllers.NewAppController(appService, loggerService)
+	logRepository := repository.NewLogRepository(db.DBConnection, loggerService)
+	logsService := servicesApp.NewLogsService(logRepository, loggerService)
+	logsController := controllers.NewLogsController(logsService, loggerService)
+	logAlertRepository := repository.NewLogAlertRepository(db.DBConnection, loggerService)
+	logAlertService := servicesApp.NewLogAlertService(logAlertRepository, appRepository, appNotificationsService,
+		loggerService)
+	logAlertController := controllers.NewLogAlertController(logAlertService, loggerService)
 	// webSocket
-	wsService := servicesApp.NewWsService(loggerService, cfg.DockerHost)
+	wsService := servicesApp.NewWsService(appRepository, loggerService, cfg.DockerHost, dockerClientManager,
+		systemdClient, kubernetesRepository, kubernetesClient)
 	wsController := controllers.NewWsController(wsService, loggerService)
 	// Server
 	serverService := server.NewServerService(loggerService, cacheService)
 	serverController := controllers.NewServerController(loggerService, serverService)
 	// Docker
-	dockerService := thirdPartyServices.NewDockerService(appRepository, loggerService, cfg.DockerHost)
+	stackRepository := repository.NewStackRepository(db.DBConnection, loggerService)
+	dockerService := thirdPartyServices.NewDockerService(appRepository, stackRepository, loggerService,
+		cfg.DockerHost, dockerClientManager)
 	dockerController := controllers.NewDockerController(dockerService, loggerService)
+	dockerHostService := thirdPartyServices.NewDockerHostService(dockerRepository, dockerClientManager,
+		cfg.EncryptionKey, loggerService)
+	dockerHostController := controllers.NewDockerHostController(dockerHostService, loggerService)
+	dockerImageService := thirdPartyServices.NewDockerImageService(loggerService, cfg.DockerHost, dockerClientManager)
+	dockerImageController := controllers.NewDockerImageController(dockerImageService, loggerService)
+	containerStatsRepository := repository.NewContainerStatsRepository(db.DBConnection, loggerService)
+	dockerStatsService := thirdPartyServices.NewDockerStatsService(appRepository, containerStatsRepository,
+		loggerService, cfg.DockerHost, dockerClientManager)
+	dockerStatsController := controllers.NewDockerStatsController(dockerStatsService, loggerService)
+	consoleAuditRepository := repository.NewConsoleAuditRepository(db.DBConnection, loggerService)
+	dockerConsoleService := thirdPartyServices.NewDockerConsoleService(appRepository, consoleAuditRepository,
+		loggerService, cfg.DockerHost, dockerClientManager)
+	dockerConsoleController := controllers.NewDockerConsoleController(dockerConsoleService, loggerService)
+	// PM2
+	pm2Service := thirdPartyServices.NewPm2Service(appRepository, pm2Client, loggerService)
+	pm2Controller := controllers.NewPm2Controller(loggerService, pm2Service)
+	// Systemd
+	systemdService := thirdPartyServices.NewSystemdService(appRepository, systemdClient,
+		cfg.SystemdUnitsPrefixes, loggerService)
+	systemdController := controllers.NewSystemdController(systemdService, loggerService)
+	// Kubernetes
+	kubernetesService := thirdPartyServices.NewKubernetesService(appRepository, kubernetesRepository,
+		kubernetesClient, cfg.EncryptionKey, loggerService)
+	kubernetesController := controllers.NewKubernetesController(kubernetesService, loggerService)
+	// Remediation
+	remediationRepository := repository.NewRemediationRepository(db.DBConnection, loggerService)
+	remediationService := servicesApp.NewRemediationService(remediationRepository, appRepository,
+		appNotificationsService, dockerService, pm2Service, systemdService, kubernetesService, loggerService)
+	remediationController := controllers.NewRemediationController(remediationService, loggerService)
+	// Stack
+	dockerStackService := thirdPartyServices.NewDockerStackService(stackRepository, loggerService, cfg.DockerHost,
+		dockerClientManager)
+	stackController := controllers.NewStackController(dockerStackService, loggerService)
 	// Auth
 	authService := user.NewAuthService(loggerService, userRepository, jwt)
 	authController := controllers.NewAuthController(authService, loggerService)
 
 	dependenciesConfig := api.NewDependencyConfig(cfg.Port, userController, appController, dockerController,
-		authController, jwt, serverController, wsController, rateLimiter, routeController)
+		authController, jwt, serverController, wsController, rateLimiter, routeController, dockerHostController,
+		dockerImageController, stackController, dockerStatsController, dockerConsoleController, permissions,
+		logsController, logAlertController, pm2Controller, systemdController, kubernetesController,
+		remediationController)
 
 	apiCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	httpServer := api.NewServer(dependenciesConfig)
@@ -99,6 +159,9 @@ func main() {
 	if err := httpServer.Shutdown(apiCtx); err != nil {
 		loggerService.Error("Server forced to shutdown:", err)
 	}
+	if err := routeStatusService.Shutdown(apiCtx); err != nil {
+		loggerService.Error("Route flow runs did not finish before shutdown", err)
+	}
 
 	loggerService.Info("Server exited")
 }
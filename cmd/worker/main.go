@@ -3,12 +3,16 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/slodkiadrianek/octopus/internal/config"
 	"github.com/slodkiadrianek/octopus/internal/repository"
 	servicesApp "github.com/slodkiadrianek/octopus/internal/services/app"
 	"github.com/slodkiadrianek/octopus/internal/services/server"
+	"github.com/slodkiadrianek/octopus/internal/services/thirdPartyServices"
 	"github.com/slodkiadrianek/octopus/internal/utils"
 )
 
@@ -38,27 +42,93 @@ func main() {
 		loggerService.Error("Failed to connect to database", err)
 		return
 	}
-	// Route
-	routeRepository := repository.NewRouteRepository(db.DBConnection, loggerService)
-	routeStatusService := servicesApp.NewRouteStatusService(routeRepository, loggerService)
+	dockerRepository := repository.NewDockerRepository(db.DBConnection, loggerService)
+	dockerClientManager := config.NewDockerClientManager(loggerService, dockerRepository, cfg.EncryptionKey,
+		30*time.Second, 3, 1*time.Second)
+	defer dockerClientManager.Close()
 	// App
 	appRepository := repository.NewAppRepository(db.DBConnection, loggerService)
-	appStatusService := servicesApp.NewAppStatusService(appRepository, cacheService, loggerService, cfg.DockerHost)
 	appNotificationsService := servicesApp.NewAppNotificationsService(appRepository, loggerService)
+	// Route
+	routeRepository := repository.NewRouteRepository(db.DBConnection, loggerService)
+	routeStatusService := servicesApp.NewRouteStatusService(routeRepository, appNotificationsService,
+		cfg.EncryptionKey, loggerService)
+	pm2Client := config.NewPm2Client("pm2", loggerService)
+	systemdClient := config.NewSystemdClient(loggerService)
+	kubernetesRepository := repository.NewKubernetesRepository(db.DBConnection, loggerService)
+	kubernetesClient := config.NewKubernetesClient(loggerService, kubernetesRepository, cfg.EncryptionKey)
+	appStatusService := servicesApp.NewAppStatusService(appRepository, cacheService, loggerService, cfg.DockerHost,
+		dockerClientManager, pm2Client, systemdClient, kubernetesRepository, kubernetesClient)
 	appService := servicesApp.NewAppService(appRepository, loggerService, appStatusService, appNotificationsService, routeStatusService)
+	dockerEventsService := servicesApp.NewDockerEventsService(appStatusService, appNotificationsService,
+		loggerService, cfg.DockerHost, dockerClientManager)
 	// Server
 	serverService := server.NewServerService(loggerService, cacheService)
+	// Containers stats
+	containerStatsRepository := repository.NewContainerStatsRepository(db.DBConnection, loggerService)
+	dockerStatsService := thirdPartyServices.NewDockerStatsService(appRepository, containerStatsRepository,
+		loggerService, cfg.DockerHost, dockerClientManager)
+	// Logs
+	logRepository := repository.NewLogRepository(db.DBConnection, loggerService)
+	logCollectorService := servicesApp.NewLogCollectorService(appRepository, logRepository, loggerService,
+		cfg.DockerHost, dockerClientManager)
+	logAlertRepository := repository.NewLogAlertRepository(db.DBConnection, loggerService)
+	logAlertService := servicesApp.NewLogAlertService(logAlertRepository, appRepository, appNotificationsService,
+		loggerService)
+	// Remediation
+	stackRepository := repository.NewStackRepository(db.DBConnection, loggerService)
+	dockerService := thirdPartyServices.NewDockerService(appRepository, stackRepository, loggerService,
+		cfg.DockerHost, dockerClientManager)
+	pm2Service := thirdPartyServices.NewPm2Service(appRepository, pm2Client, loggerService)
+	systemdService := thirdPartyServices.NewSystemdService(appRepository, systemdClient,
+		cfg.SystemdUnitsPrefixes, loggerService)
+	kubernetesService := thirdPartyServices.NewKubernetesService(appRepository, kubernetesRepository,
+		kubernetesClient, cfg.EncryptionKey, loggerService)
+	remediationRepository := repository.NewRemediationRepository(db.DBConnection, loggerService)
+	remediationService := servicesApp.NewRemediationService(remediationRepository, appRepository,
+		appNotificationsService, dockerService, pm2Service, systemdService, kubernetesService, loggerService)
 
-	ctx := context.Background()
-	ticker(ctx, appService, serverService, loggerService)
+	// Stopping the worker cancels the checks which are still running.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go logCollectorService.Run(ctx)
+	go dockerEventsService.Run(ctx)
+	ticker(ctx, appService, serverService, dockerStatsService, logAlertService, remediationService, loggerService)
 }
 
-func ticker(ctx context.Context, appService *servicesApp.AppService, serverService *server.ServerService, logger *utils.Logger) {
+func ticker(ctx context.Context, appService *servicesApp.AppService, serverService *server.ServerService,
+	dockerStatsService *thirdPartyServices.DockerStatsService, logAlertService *servicesApp.LogAlertService,
+	remediationService *servicesApp.RemediationService, logger *utils.Logger,
+) {
 	period := 5 * time.Second
 	ticker := time.NewTicker(period)
 	defer ticker.Stop()
+	// Reading stats of a container takes about two seconds, so they are sampled less often than the statuses.
+	statsTicker := time.NewTicker(time.Minute)
+	defer statsTicker.Stop()
+	logAlertsTicker := time.NewTicker(30 * time.Second)
+	defer logAlertsTicker.Stop()
+	remediationsTicker := time.NewTicker(10 * time.Second)
+	defer remediationsTicker.Stop()
 	for {
 		select {
+		case <-remediationsTicker.C:
+			err := remediationService.EvaluateRemediations(ctx)
+			if err != nil {
+				logger.Warn("Something went wrong during remediating failed apps", err)
+			}
+		case <-logAlertsTicker.C:
+			err := logAlertService.EvaluateLogAlerts(ctx)
+			if err != nil {
+				logger.Warn("Something went wrong during evaluating log alerts", err)
+			}
+		case <-statsTicker.C:
+			err := dockerStatsService.InsertContainersStats(ctx)
+			if err != nil {
+				logger.Warn("Something went wrong during inserting data about containers stats", err)
+				continue
+			}
+			logger.Info("Successfully inserted data about containers stats")
 		case <-ticker.C:
 			appsToSendNotification, err := appService.CheckAppsStatus(ctx)
 			fmt.Println()
@@ -1,23 +1,27 @@
 package DTO
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type App struct {
 	ID          string
 	Name        string
 	Description string
-	IsDocker    bool
+	Runtime     string
 	OwnerID     int
 	IPAddress   string
 	Port        string
+	StackID     int
 }
 
-func NewApp(id, name, description string, isDocker bool, ownerID int, IPAddress, port string) *App {
+func NewApp(id, name, description, runtime string, ownerID int, IPAddress, port string) *App {
 	return &App{
 		ID:          id,
 		Name:        name,
 		Description: description,
-		IsDocker:    isDocker,
+		Runtime:     runtime,
 		OwnerID:     ownerID,
 		IPAddress:   IPAddress,
 		Port:        port,
@@ -44,11 +48,21 @@ type UpdateApp struct {
 type AppID struct {
 	AppID string `json:"appID" example:"nd3289dh23934382"`
 }
+
+// AppStatus of PM2 apps also carries the restart count and usage reported by PM2. Docker apps carry the result of
+// their HEALTHCHECK and the exit code of a stopped container.
 type AppStatus struct {
-	AppID     string        `json:"app_id"`
-	Status    string        `json:"status"`
-	ChangedAt time.Time     `json:"changed_at"`
-	Duration  time.Duration `json:"duration"`
+	AppID        string        `json:"app_id"`
+	Status       string        `json:"status"`
+	ChangedAt    time.Time     `json:"changed_at"`
+	Duration     time.Duration `json:"duration"`
+	RestartCount int           `json:"restart_count,omitempty"`
+	MemoryBytes  uint64        `json:"memory_bytes,omitempty"`
+	CPUPercent   float64       `json:"cpu_percent,omitempty"`
+	Health       string        `json:"health,omitempty"`
+	HealthOutput string        `json:"health_output,omitempty"`
+	ExitCode     int           `json:"exit_code,omitempty"`
+	OOMKilled    bool          `json:"oom_killed,omitempty"`
 }
 
 func NewAppStatus(appID, status string, changedAt time.Time, duration time.Duration) *AppStatus {
@@ -59,3 +73,27 @@ func NewAppStatus(appID, status string, changedAt time.Time, duration time.Durat
 		Duration:  duration,
 	}
 }
+
+// Describe returns the status with the details explaining it, for example "exited (137, OOMKilled) after 3
+// restarts" or "unhealthy: connection refused".
+func (as AppStatus) Describe() string {
+	description := as.Status
+	switch {
+	case as.Status == "exited" || as.Status == "dead":
+		if as.OOMKilled {
+			description += fmt.Sprintf(" (%d, OOMKilled)", as.ExitCode)
+		} else {
+			description += fmt.Sprintf(" (%d)", as.ExitCode)
+		}
+	case as.Status == "unhealthy" && as.HealthOutput != "":
+		description += ": " + as.HealthOutput
+	}
+	switch {
+	case as.RestartCount == 1:
+		description += " after 1 restart"
+	case as.RestartCount > 1:
+		description += fmt.Sprintf(" after %d restarts", as.RestartCount)
+	}
+
+	return description
+}
@@ -0,0 +1,20 @@
+package DTO
+
+import "time"
+
+// ConsoleMessage is sent by the client of the console, "input" carries the keystrokes in Data and "resize"
+// the new size of the terminal.
+type ConsoleMessage struct {
+	Type string `json:"type" example:"input"`
+	Data string `json:"data" example:"ls -la\r"`
+	Cols uint   `json:"cols" example:"120"`
+	Rows uint   `json:"rows" example:"40"`
+}
+
+type ConsoleAuditLog struct {
+	SessionID string    `json:"sessionID" example:"4f0c1d2e3b"`
+	AppID     string    `json:"appID" example:"e9530eae6aa7"`
+	UserID    int       `json:"userID" example:"1"`
+	Command   string    `json:"command" example:"ls -la"`
+	CreatedAt time.Time `json:"createdAt" example:"2025-01-01T00:00:00Z"`
+}
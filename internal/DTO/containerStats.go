@@ -0,0 +1,17 @@
+package DTO
+
+import "time"
+
+type ContainerStats struct {
+	AppID         string    `json:"appID" example:"e9530eae6aa7"`
+	CPUPercent    float64   `json:"cpuPercent" example:"12.5"`
+	MemoryUsage   uint64    `json:"memoryUsage" example:"52428800"`
+	MemoryLimit   uint64    `json:"memoryLimit" example:"2147483648"`
+	MemoryPercent float64   `json:"memoryPercent" example:"2.44"`
+	NetworkRx     uint64    `json:"networkRx" example:"1024"`
+	NetworkTx     uint64    `json:"networkTx" example:"2048"`
+	BlockRead     uint64    `json:"blockRead" example:"4096"`
+	BlockWrite    uint64    `json:"blockWrite" example:"8192"`
+	PIDs          uint64    `json:"pids" example:"12"`
+	CreatedAt     time.Time `json:"createdAt" example:"2025-01-01T00:00:00Z"`
+}
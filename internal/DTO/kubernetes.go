@@ -0,0 +1,38 @@
+package DTO
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// KubernetesAppID is a hash of the owner and the workload location, because its names do not fit into the id of
+// an app.
+func KubernetesAppID(ownerID int, cluster, namespace, kind, name string) string {
+	hash := sha256.Sum256(fmt.Appendf(nil, "%d/%s/%s/%s/%s", ownerID, cluster, namespace, kind, name))
+	return "k8s-" + hex.EncodeToString(hash[:12])
+}
+
+type KubernetesCluster struct {
+	Name       string `json:"name" example:"k3s"`
+	Kubeconfig string `json:"kubeconfig" example:"apiVersion: v1\nkind: Config"`
+}
+
+// ImportKubernetesWorkloads imports the workloads of all namespaces when the namespace is empty.
+type ImportKubernetesWorkloads struct {
+	Cluster   string `json:"cluster" example:"k3s"`
+	Namespace string `json:"namespace" example:"default"`
+}
+
+type KubernetesWorkload struct {
+	Cluster           string    `json:"cluster" example:"k3s"`
+	Namespace         string    `json:"namespace" example:"default"`
+	Kind              string    `json:"kind" example:"Deployment"`
+	Name              string    `json:"name" example:"api"`
+	Replicas          int       `json:"replicas" example:"3"`
+	ReadyReplicas     int       `json:"readyReplicas" example:"3"`
+	UpdatedReplicas   int       `json:"updatedReplicas" example:"3"`
+	AvailableReplicas int       `json:"availableReplicas" example:"3"`
+	ChangedAt         time.Time `json:"changedAt" example:"2025-01-01T00:00:00Z"`
+}
@@ -0,0 +1,40 @@
+package DTO
+
+import "time"
+
+type LogAlertRuleID struct {
+	AppID  string `json:"appID" example:"e9530eae6aa7"`
+	RuleID string `json:"ruleID" example:"1"`
+}
+
+// CreateLogAlertRule alerts when more than Threshold lines matching Pattern were logged in the last
+// WindowSeconds. After an alert the rule is muted for CooldownSeconds.
+type CreateLogAlertRule struct {
+	Name            string `json:"name" example:"Errors"`
+	Pattern         string `json:"pattern" example:"ERROR|panic"`
+	WindowSeconds   int    `json:"windowSeconds" example:"300"`
+	Threshold       int    `json:"threshold" example:"20"`
+	CooldownSeconds int    `json:"cooldownSeconds" example:"900"`
+	Enabled         bool   `json:"enabled" example:"true"`
+}
+
+type LogAlertRule struct {
+	ID              int        `json:"id" example:"1"`
+	AppID           string     `json:"appID" example:"e9530eae6aa7"`
+	Name            string     `json:"name" example:"Errors"`
+	Pattern         string     `json:"pattern" example:"ERROR|panic"`
+	WindowSeconds   int        `json:"windowSeconds" example:"300"`
+	Threshold       int        `json:"threshold" example:"20"`
+	CooldownSeconds int        `json:"cooldownSeconds" example:"900"`
+	Enabled         bool       `json:"enabled" example:"true"`
+	LastTriggeredAt *time.Time `json:"lastTriggeredAt" example:"2025-01-01T00:00:00Z"`
+}
+
+type TriggeredLogAlert struct {
+	RuleID        int    `json:"ruleID" example:"1"`
+	AppID         string `json:"appID" example:"e9530eae6aa7"`
+	Name          string `json:"name" example:"Errors"`
+	Pattern       string `json:"pattern" example:"ERROR|panic"`
+	WindowSeconds int    `json:"windowSeconds" example:"300"`
+	MatchedLines  int    `json:"matchedLines" example:"25"`
+}
@@ -0,0 +1,57 @@
+package DTO
+
+import "time"
+
+type LogsOptions struct {
+	Tail   string `json:"tail" example:"100"`
+	Since  string `json:"since" example:"10m"`
+	Until  string `json:"until" example:"2025-01-01T00:00:00Z"`
+	Stream string `json:"stream" example:"stderr"`
+	Grep   string `json:"grep" example:"ERROR|panic"`
+}
+
+type LogLine struct {
+	Stream    string    `json:"stream" example:"stdout"`
+	Timestamp time.Time `json:"ts" example:"2025-01-01T00:00:00Z"`
+	Line      string    `json:"line" example:"server started on :8080"`
+	Source    string    `json:"source,omitempty" example:"api-7d9c6b5f4-x2x9z/api"`
+}
+
+type AppLog struct {
+	AppID    string    `json:"appID" example:"e9530eae6aa7"`
+	Stream   string    `json:"stream" example:"stderr"`
+	Level    string    `json:"level" example:"error"`
+	Line     string    `json:"line" example:"ERROR failed to connect to the database"`
+	LoggedAt time.Time `json:"loggedAt" example:"2025-01-01T00:00:00Z"`
+}
+
+type LogsSearch struct {
+	Query string    `json:"q" example:"database"`
+	AppID string    `json:"app" example:"e9530eae6aa7"`
+	Level string    `json:"level" example:"error"`
+	From  time.Time `json:"from" example:"2025-01-01T00:00:00Z"`
+	To    time.Time `json:"to" example:"2025-01-02T00:00:00Z"`
+	Page  int       `json:"page" example:"1"`
+	Limit int       `json:"limit" example:"50"`
+}
+
+type LogSearchHit struct {
+	AppID     string    `json:"appID" example:"e9530eae6aa7"`
+	AppName   string    `json:"appName" example:"api"`
+	Stream    string    `json:"stream" example:"stderr"`
+	Level     string    `json:"level" example:"error"`
+	Line      string    `json:"line" example:"ERROR failed to connect to the database"`
+	Highlight string    `json:"highlight" example:"ERROR failed to connect to the <mark>database</mark>"`
+	LoggedAt  time.Time `json:"loggedAt" example:"2025-01-01T00:00:00Z"`
+}
+
+type LogsSearchResult struct {
+	Logs  []LogSearchHit `json:"logs"`
+	Page  int            `json:"page" example:"1"`
+	Limit int            `json:"limit" example:"50"`
+	Total int            `json:"total" example:"120"`
+}
+
+type LogsRetention struct {
+	Days int `json:"days" example:"14"`
+}
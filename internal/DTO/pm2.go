@@ -0,0 +1,33 @@
+package DTO
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pm2AppIDPrefix keeps the ids of PM2 apps apart from the ids of docker containers.
+const pm2AppIDPrefix = "pm2-"
+
+// Pm2AppID contains the owner, so every user can import the same process.
+func Pm2AppID(ownerID int, processName string) string {
+	return pm2AppIDPrefix + strconv.Itoa(ownerID) + "-" + processName
+}
+
+func Pm2ProcessName(appID string) string {
+	_, processName, _ := strings.Cut(strings.TrimPrefix(appID, pm2AppIDPrefix), "-")
+	return processName
+}
+
+type Pm2Process struct {
+	Name             string    `json:"name" example:"api"`
+	PmID             int       `json:"pmID" example:"0"`
+	PID              int       `json:"pid" example:"4021"`
+	Status           string    `json:"status" example:"online"`
+	Port             string    `json:"port" example:"3000"`
+	RestartCount     int       `json:"restartCount" example:"2"`
+	UnstableRestarts int       `json:"unstableRestarts" example:"0"`
+	MemoryBytes      uint64    `json:"memoryBytes" example:"52428800"`
+	CPUPercent       float64   `json:"cpuPercent" example:"1.5"`
+	StartedAt        time.Time `json:"startedAt" example:"2025-01-01T00:00:00Z"`
+}
@@ -0,0 +1,50 @@
+package DTO
+
+import "time"
+
+// UpsertRemediationPolicy makes up to MaxAttempts attempts to bring a failed app back. Every attempt restarts the
+// app when Restart is set and calls the hooks, the delay between the attempts starts at BackoffSeconds and doubles.
+type UpsertRemediationPolicy struct {
+	MaxAttempts    int    `json:"maxAttempts" example:"3"`
+	BackoffSeconds int    `json:"backoffSeconds" example:"10"`
+	Restart        bool   `json:"restart" example:"true"`
+	WebhookURL     string `json:"webhookUrl" example:"https://example.com/hooks/octopus"`
+	Script         string `json:"script" example:"/opt/octopus/hooks/clean-cache.sh"`
+	Enabled        bool   `json:"enabled" example:"true"`
+}
+
+type RemediationPolicy struct {
+	AppID            string     `json:"appID" example:"e9530eae6aa7"`
+	MaxAttempts      int        `json:"maxAttempts" example:"3"`
+	BackoffSeconds   int        `json:"backoffSeconds" example:"10"`
+	Restart          bool       `json:"restart" example:"true"`
+	WebhookURL       string     `json:"webhookUrl" example:"https://example.com/hooks/octopus"`
+	Script           string     `json:"script" example:"/opt/octopus/hooks/clean-cache.sh"`
+	Enabled          bool       `json:"enabled" example:"true"`
+	MaintenanceUntil *time.Time `json:"maintenanceUntil" example:"2025-01-01T00:00:00Z"`
+	Attempts         int        `json:"attempts" example:"1"`
+	LastAttemptAt    *time.Time `json:"lastAttemptAt" example:"2025-01-01T00:00:00Z"`
+	Escalated        bool       `json:"escalated" example:"false"`
+}
+
+// RemediationMaintenance pauses the policy of the app for Minutes.
+type RemediationMaintenance struct {
+	Minutes int `json:"minutes" example:"60"`
+}
+
+// RemediationAttempt is one action of a remediation attempt: the restart or one of the hooks.
+type RemediationAttempt struct {
+	AppID     string    `json:"appID" example:"e9530eae6aa7"`
+	Attempt   int       `json:"attempt" example:"1"`
+	Action    string    `json:"action" example:"restart"`
+	Status    string    `json:"status" example:"exited (137, OOMKilled)"`
+	Success   bool      `json:"success" example:"true"`
+	Error     string    `json:"error" example:""`
+	CreatedAt time.Time `json:"createdAt" example:"2025-01-01T00:00:00Z"`
+}
+
+type FailedRemediation struct {
+	AppID    string `json:"appID" example:"e9530eae6aa7"`
+	Attempts int    `json:"attempts" example:"3"`
+	Status   string `json:"status" example:"exited (137, OOMKilled)"`
+}
@@ -1,28 +1,63 @@
 package DTO
 
-type RouteID struct {
-	RouteID string `json:"routeID"`
+import (
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+type RouteFlowID struct {
+	AppID  string `json:"appID"`
+	FlowID string `json:"flowID"`
+}
+
+type RouteFlowRunID struct {
+	AppID  string `json:"appID"`
+	FlowID string `json:"flowID"`
+	RunID  string `json:"runID"`
 }
+
 type RoutesParentID interface {
 	GetParentID() int
 }
+
+// CreateRouteData is the definition of the route flow. The steps can use the env and the secrets as
+// {{ .env.<name> }} and {{ .secrets.<name> }}. The secrets of an edited flow are kept when they are left out.
+// HTTPOptions tell how the requests of all the steps are sent and Notifications when the failures and recoveries of the
+// flow are sent through the channels of the app.
 type CreateRouteData struct {
-	Name   string `json:"name"`
-	Routes []CreateRoute
+	Name          string                        `json:"name"`
+	Env           map[string]string             `json:"env"`
+	Secrets       map[string]string             `json:"secrets"`
+	HTTPOptions   models.RouteFlowHTTPOptions   `json:"httpOptions"`
+	Notifications models.RouteFlowNotifications `json:"notifications"`
+	Routes        []CreateRoute
 }
+
+// CreateRoute is a step of the route flow. The response has to have the status code and the fields of the
+// ResponseBody, the Assertions, ResponseSchema and MaxLatencyMs are checked on top of them. RequestBody is sent as
+// JSON or as a form depending on BodyType, a raw body is sent from RawBody.
 type CreateRoute struct {
-	Path                         string            `json:"path" example:"/users"`
-	Method                       string            `json:"method" example:"GET"`
-	RequestAuthorization         string            `json:"requestAuthorization" example:"Bearer:fb43fg3487f34g78f3gu"`
-	RequestQuery                 map[string]string `json:"requestQuery" example:"id=1"`
-	RequestParams                map[string]string `json:"requestParams" example:"id=1"`
-	RequestBody                  map[string]any    `json:"requestBody" example:"id=1"`
-	NextRouteBody                []string          `json:"nextRouteBody"`
-	NextRouteQuery               []string          `json:"nextRouteQuery"`
-	NextRouteParams              []string          `json:"nextRouteParams"`
-	NextRouteAuthorizationHeader string            `json:"nextRouteAuthorizationHeader"`
-	ResponseStatusCode           int               `json:"responseStatusCode" example:"200"`
-	ResponseBody                 map[string]any    `json:"responseBody" example:"id=1"`
+	Path                         string                  `json:"path" example:"/users"`
+	Method                       string                  `json:"method" example:"GET"`
+	RequestAuthorization         string                  `json:"requestAuthorization" example:"Bearer:fb43fg3487f34g78f3gu"`
+	RequestQuery                 map[string]string       `json:"requestQuery" example:"id=1"`
+	RequestParams                map[string]string       `json:"requestParams" example:"id=1"`
+	RequestBody                  map[string]any          `json:"requestBody" example:"id=1"`
+	NextRouteBody                []string                `json:"nextRouteBody"`
+	NextRouteQuery               []string                `json:"nextRouteQuery"`
+	NextRouteParams              []string                `json:"nextRouteParams"`
+	NextRouteAuthorizationHeader string                  `json:"nextRouteAuthorizationHeader"`
+	ResponseStatusCode           int                     `json:"responseStatusCode" example:"200"`
+	ResponseBody                 map[string]any          `json:"responseBody" example:"id=1"`
+	Assertions                   []models.RouteAssertion `json:"assertions"`
+	ResponseSchema               map[string]any          `json:"responseSchema"`
+	MaxLatencyMs                 int                     `json:"maxLatencyMs" example:"500"`
+	RequestHeaders               map[string]string       `json:"requestHeaders"`
+	Extract                      []models.RouteVariable  `json:"extract"`
+	Auth                         models.RouteAuth        `json:"auth"`
+	BodyType                     string                  `json:"bodyType" example:"json"`
+	RawBody                      string                  `json:"rawBody"`
 	ParentID                     int
 }
 
@@ -30,13 +65,69 @@ type WorkingRoute struct {
 	Name            string
 	ParentID        int
 	AppID           string
+	FlowID          int
+	Version         int
+	Position        int
 	RouteID         int
 	RequestID       int
 	ResponseID      int
 	NextRouteDataID int
+	Assertions      string
+	ResponseSchema  string
+	MaxLatencyMs    int
+	RequestHeaders  string
+	Extract         string
+	Auth            string
+	BodyType        string
+	RawBody         string
 	Status          string
 }
 
+// RouteFlow is the flow with the steps of a single version. SecretNames lists the secrets of the flow, their values
+// are never returned.
+type RouteFlow struct {
+	ID               int                           `json:"id"`
+	AppID            string                        `json:"appID"`
+	Name             string                        `json:"name"`
+	Version          int                           `json:"version"`
+	Env              map[string]string             `json:"env"`
+	SecretNames      []string                      `json:"secrets"`
+	EncryptedSecrets string                        `json:"-"`
+	HTTPOptions      models.RouteFlowHTTPOptions   `json:"httpOptions"`
+	Notifications    models.RouteFlowNotifications `json:"notifications"`
+	CreatedAt        time.Time                     `json:"createdAt"`
+	UpdatedAt        time.Time                     `json:"updatedAt"`
+	Steps            []RouteFlowStep               `json:"steps"`
+}
+
+// RouteFlowStep is a step of the flow version, the status is the result of its last check.
+type RouteFlowStep struct {
+	ID                           int                      `json:"id"`
+	Position                     int                      `json:"position"`
+	Path                         string                   `json:"path" example:"/users"`
+	Method                       string                   `json:"method" example:"GET"`
+	RequestAuthorization         string                   `json:"requestAuthorization" example:"Bearer:fb43fg3487f34g78f3gu"`
+	RequestQuery                 map[string]string        `json:"requestQuery" example:"id=1"`
+	RequestParams                map[string]string        `json:"requestParams" example:"id=1"`
+	RequestBody                  map[string]any           `json:"requestBody" example:"id=1"`
+	NextRouteBody                []string                 `json:"nextRouteBody"`
+	NextRouteQuery               []string                 `json:"nextRouteQuery"`
+	NextRouteParams              []string                 `json:"nextRouteParams"`
+	NextRouteAuthorizationHeader string                   `json:"nextRouteAuthorizationHeader"`
+	ResponseStatusCode           int                      `json:"responseStatusCode" example:"200"`
+	ResponseBody                 map[string]any           `json:"responseBody" example:"id=1"`
+	Assertions                   []models.RouteAssertion  `json:"assertions"`
+	ResponseSchema               map[string]any           `json:"responseSchema"`
+	MaxLatencyMs                 int                      `json:"maxLatencyMs"`
+	RequestHeaders               map[string]string        `json:"requestHeaders"`
+	Extract                      []models.RouteVariable   `json:"extract"`
+	Auth                         models.RouteAuth         `json:"auth"`
+	BodyType                     string                   `json:"bodyType"`
+	RawBody                      string                   `json:"rawBody"`
+	Status                       string                   `json:"status"`
+	FailedAssertions             []models.FailedAssertion `json:"failedAssertions"`
+}
+
 type RouteInfo struct {
 	Path     string `json:"path" example:"/users"`
 	Method   string `json:"method" example:"GET"`
@@ -116,3 +207,73 @@ func NewRouteResponse(statusCode, parentID int, body string) *RouteResponse {
 func (rr *RouteResponse) GetParentID() int {
 	return rr.ParentID
 }
+
+// RouteFlowRun is a single check of the flow version, it has the steps which were run until the first failure. A dry
+// run only renders the requests and checks the assertions, it is not saved.
+type RouteFlowRun struct {
+	ID         int                `json:"id"`
+	FlowID     int                `json:"flowID"`
+	Version    int                `json:"version"`
+	Status     string             `json:"status" example:"success"`
+	DryRun     bool               `json:"dryRun"`
+	StartedAt  time.Time          `json:"startedAt"`
+	DurationMs int64              `json:"durationMs" example:"350"`
+	Steps      []RouteFlowRunStep `json:"steps"`
+}
+
+// RunRouteFlowOptions tells how a flow run on demand is made. An async run is returned right after it is saved with
+// the running status, its result is read from the history.
+type RunRouteFlowOptions struct {
+	DryRun bool
+	Async  bool
+}
+
+// RouteFlowRunStep is the result of a step in the run. ResponseBody is the beginning of the body and Error tells why
+// the request could not be sent.
+type RouteFlowRunStep struct {
+	WorkingRouteID     int                      `json:"workingRouteID"`
+	Position           int                      `json:"position"`
+	Status             string                   `json:"status" example:"success"`
+	Request            models.RouteRunRequest   `json:"request"`
+	ResponseStatusCode int                      `json:"responseStatusCode" example:"200"`
+	ResponseBody       string                   `json:"responseBody"`
+	LatencyMs          int64                    `json:"latencyMs" example:"120"`
+	FailedAssertions   []models.FailedAssertion `json:"failedAssertions"`
+	Error              string                   `json:"error"`
+}
+
+// ImportRouteFlows is an OpenAPI 3 document (JSON or YAML), a Postman v2.1 collection or an Insomnia export. The
+// format is detected when it is left out. Select has the names of the previewed flows which are imported.
+type ImportRouteFlows struct {
+	Format   string   `json:"format" example:"openapi"`
+	Document string   `json:"document"`
+	Select   []string `json:"select"`
+}
+
+// RouteFlowImport is a flow generated from a single operation of the document. Error tells why the flow would not
+// pass the validation, such a flow cannot be imported.
+type RouteFlowImport struct {
+	Name   string          `json:"name" example:"GET /users/{id}"`
+	Method string          `json:"method" example:"GET"`
+	Path   string          `json:"path" example:"/users/{id}"`
+	Flow   CreateRouteData `json:"flow"`
+	Error  string          `json:"error"`
+}
+
+// ImportedRouteFlow is the result of importing the selected flow, the flow which was not created has the error.
+type ImportedRouteFlow struct {
+	Name   string `json:"name" example:"GET /users/{id}"`
+	FlowID int    `json:"flowID"`
+	Error  string `json:"error"`
+}
+
+// RouteFlowNotification is a failure or a recovery of the flow. FailedStep is the step which failed in the last run,
+// a recovery does not have it.
+type RouteFlowNotification struct {
+	FlowID        int               `json:"flowID"`
+	AppID         string            `json:"appID"`
+	Name          string            `json:"name"`
+	Recovered     bool              `json:"recovered"`
+	FailureStreak int               `json:"failureStreak"`
+	FailedStep    *RouteFlowRunStep `json:"failedStep"`
+}
@@ -0,0 +1,19 @@
+package DTO
+
+type StackID struct {
+	StackID string `json:"stackID" example:"1"`
+}
+
+type StackService struct {
+	AppID    string `json:"appID" example:"e9530eae6aa7"`
+	Service  string `json:"service" example:"db"`
+	State    string `json:"state" example:"running"`
+	Required bool   `json:"required" example:"true"`
+}
+
+type Stack struct {
+	ID       int            `json:"id" example:"1"`
+	Name     string         `json:"name" example:"shop"`
+	Status   string         `json:"status" example:"running"`
+	Services []StackService `json:"services"`
+}
@@ -0,0 +1,36 @@
+package DTO
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// systemdAppIDPrefix keeps the ids of systemd apps apart from the ids of the other runtimes.
+const systemdAppIDPrefix = "systemd-"
+
+// SystemdAppID contains the owner, so every user can import the same unit.
+func SystemdAppID(ownerID int, unitName string) string {
+	return systemdAppIDPrefix + strconv.Itoa(ownerID) + "-" + unitName
+}
+
+func SystemdUnitName(appID string) string {
+	_, unitName, _ := strings.Cut(strings.TrimPrefix(appID, systemdAppIDPrefix), "-")
+	return unitName
+}
+
+type SystemdUnit struct {
+	Name         string    `json:"name" example:"nginx.service"`
+	Description  string    `json:"description" example:"A high performance web server"`
+	LoadState    string    `json:"loadState" example:"loaded"`
+	ActiveState  string    `json:"activeState" example:"active"`
+	SubState     string    `json:"subState" example:"running"`
+	MainPID      int       `json:"mainPID" example:"812"`
+	RestartCount int       `json:"restartCount" example:"0"`
+	MemoryBytes  uint64    `json:"memoryBytes" example:"10485760"`
+	StartedAt    time.Time `json:"startedAt" example:"2025-01-01T00:00:00Z"`
+}
+
+type ImportSystemdUnits struct {
+	Pattern string `json:"pattern" example:"octopus-*.service"`
+}
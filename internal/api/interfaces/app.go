@@ -18,13 +18,46 @@ type DockerController interface {
 	UnpauseContainer(w http.ResponseWriter, r *http.Request)
 	StopContainer(w http.ResponseWriter, r *http.Request)
 	ImportDockerContainers(w http.ResponseWriter, r *http.Request)
+	CreateContainer(w http.ResponseWriter, r *http.Request)
+	RemoveContainer(w http.ResponseWriter, r *http.Request)
+	RenameContainer(w http.ResponseWriter, r *http.Request)
+	RecreateContainer(w http.ResponseWriter, r *http.Request)
 }
 
 type RouteController interface {
-	CheckRouteStatus(w http.ResponseWriter, r *http.Request)
+	GetRouteFlows(w http.ResponseWriter, r *http.Request)
+	GetRouteFlow(w http.ResponseWriter, r *http.Request)
 	AddWorkingRoutes(w http.ResponseWriter, r *http.Request)
+	UpdateRouteFlow(w http.ResponseWriter, r *http.Request)
+	DeleteRouteFlow(w http.ResponseWriter, r *http.Request)
+	GetRouteFlowRuns(w http.ResponseWriter, r *http.Request)
+	GetRouteFlowRun(w http.ResponseWriter, r *http.Request)
+	RunRouteFlow(w http.ResponseWriter, r *http.Request)
+	PreviewRouteFlowsImport(w http.ResponseWriter, r *http.Request)
+	ImportRouteFlows(w http.ResponseWriter, r *http.Request)
 }
 
 type WsController interface {
 	Logs(w http.ResponseWriter, r *http.Request)
 }
+
+type LogsController interface {
+	SearchLogs(w http.ResponseWriter, r *http.Request)
+	UpdateLogsRetention(w http.ResponseWriter, r *http.Request)
+}
+
+type LogAlertController interface {
+	GetLogAlertRules(w http.ResponseWriter, r *http.Request)
+	CreateLogAlertRule(w http.ResponseWriter, r *http.Request)
+	UpdateLogAlertRule(w http.ResponseWriter, r *http.Request)
+	DeleteLogAlertRule(w http.ResponseWriter, r *http.Request)
+}
+
+type RemediationController interface {
+	GetRemediationPolicy(w http.ResponseWriter, r *http.Request)
+	UpsertRemediationPolicy(w http.ResponseWriter, r *http.Request)
+	DeleteRemediationPolicy(w http.ResponseWriter, r *http.Request)
+	StartMaintenance(w http.ResponseWriter, r *http.Request)
+	EndMaintenance(w http.ResponseWriter, r *http.Request)
+	GetRemediationHistory(w http.ResponseWriter, r *http.Request)
+}
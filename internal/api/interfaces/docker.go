@@ -0,0 +1,25 @@
+package interfaces
+
+import "net/http"
+
+type DockerHostController interface {
+	SaveDockerHost(w http.ResponseWriter, r *http.Request)
+}
+
+type DockerImageController interface {
+	ListImages(w http.ResponseWriter, r *http.Request)
+	PullImage(w http.ResponseWriter, r *http.Request)
+	RemoveImage(w http.ResponseWriter, r *http.Request)
+	PruneImages(w http.ResponseWriter, r *http.Request)
+	GetImageHistory(w http.ResponseWriter, r *http.Request)
+}
+
+type DockerStatsController interface {
+	GetContainerStats(w http.ResponseWriter, r *http.Request)
+	GetContainerStatsHistory(w http.ResponseWriter, r *http.Request)
+	StreamContainerStats(w http.ResponseWriter, r *http.Request)
+}
+
+type DockerConsoleController interface {
+	Console(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,10 @@
+package interfaces
+
+import "net/http"
+
+type KubernetesController interface {
+	SaveCluster(w http.ResponseWriter, r *http.Request)
+	ImportWorkloads(w http.ResponseWriter, r *http.Request)
+	RestartWorkload(w http.ResponseWriter, r *http.Request)
+	GetWorkload(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import "net/http"
+
+type Pm2Controller interface {
+	ImportPm2Apps(w http.ResponseWriter, r *http.Request)
+	StartApp(w http.ResponseWriter, r *http.Request)
+	StopApp(w http.ResponseWriter, r *http.Request)
+	RestartApp(w http.ResponseWriter, r *http.Request)
+	ReloadApp(w http.ResponseWriter, r *http.Request)
+	GetProcesses(w http.ResponseWriter, r *http.Request)
+}
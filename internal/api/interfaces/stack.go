@@ -0,0 +1,11 @@
+package interfaces
+
+import "net/http"
+
+type StackController interface {
+	GetStacks(w http.ResponseWriter, r *http.Request)
+	GetStack(w http.ResponseWriter, r *http.Request)
+	StartStack(w http.ResponseWriter, r *http.Request)
+	StopStack(w http.ResponseWriter, r *http.Request)
+	RestartStack(w http.ResponseWriter, r *http.Request)
+}
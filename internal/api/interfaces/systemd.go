@@ -0,0 +1,11 @@
+package interfaces
+
+import "net/http"
+
+type SystemdController interface {
+	ImportSystemdUnits(w http.ResponseWriter, r *http.Request)
+	StartUnit(w http.ResponseWriter, r *http.Request)
+	StopUnit(w http.ResponseWriter, r *http.Request)
+	RestartUnit(w http.ResponseWriter, r *http.Request)
+	GetUnit(w http.ResponseWriter, r *http.Request)
+}
@@ -5,22 +5,27 @@ import (
 	"github.com/slodkiadrianek/octopus/internal/api/interfaces"
 	"github.com/slodkiadrianek/octopus/internal/api/routes"
 	"github.com/slodkiadrianek/octopus/internal/middleware"
+	"github.com/slodkiadrianek/octopus/internal/models"
 	"github.com/slodkiadrianek/octopus/internal/schema"
 )
 
 type AppSettingsHandlers struct {
-	appController    interfaces.AppController
-	dockerController interfaces.DockerController
-	jwt              *middleware.JWT
+	appController         interfaces.AppController
+	dockerController      interfaces.DockerController
+	dockerStatsController interfaces.DockerStatsController
+	jwt                   *middleware.JWT
+	permissions           *middleware.Permissions
 }
 
 func NewAppAppHandler(appController interfaces.AppController, dockerController interfaces.DockerController,
-	jwt *middleware.JWT,
+	dockerStatsController interfaces.DockerStatsController, jwt *middleware.JWT, permissions *middleware.Permissions,
 ) *AppSettingsHandlers {
 	return &AppSettingsHandlers{
-		appController:    appController,
-		dockerController: dockerController,
-		jwt:              jwt,
+		appController:         appController,
+		dockerController:      dockerController,
+		dockerStatsController: dockerStatsController,
+		jwt:                   jwt,
+		permissions:           permissions,
 	}
 }
 
@@ -31,19 +36,39 @@ func (a AppSettingsHandlers) SetupAppHandlers(router routes.Router) {
 	appGroup.GET("/:appID", a.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
 		schema.AppIDSchema), a.appController.GetInfoAboutApp)
 	appGroup.GET("/:appID/status", a.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema), a.appController.GetAppStatus)
+	appGroup.GET("/:appID/docker/stats", a.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), a.dockerStatsController.GetContainerStats)
+	appGroup.GET("/:appID/docker/stats/history", a.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), a.dockerStatsController.GetContainerStatsHistory)
 
 	appGroup.POST("", a.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.CreateApp]("body", schema.CreateAppSchema),
 		a.appController.CreateApp)
-	appGroup.POST("/docker/import", a.jwt.VerifyToken, a.dockerController.ImportDockerContainers)
+	// Volumes of a new container can bind any path of the docker host. A recreated container keeps the binds and the
+	// privileges of an imported one with the image picked by the caller, so both require the operator permission too.
+	appGroup.POST("/docker/import", a.jwt.VerifyToken, a.permissions.RequirePermission(models.PermissionOperator),
+		a.dockerController.ImportDockerContainers)
+	appGroup.POST("/docker/containers", a.jwt.VerifyToken, a.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.CreateContainer]("body", schema.CreateContainerSchema),
+		a.dockerController.CreateContainer)
+	appGroup.POST("/:appID/docker/recreate", a.jwt.VerifyToken,
+		a.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema),
+		middleware.ValidateMiddleware[DTO.RecreateContainer]("body", schema.RecreateContainerSchema),
+		a.dockerController.RecreateContainer)
 
 	appGroup.PUT("/:appID/docker/stop", a.jwt.VerifyToken, a.dockerController.StopContainer)
 	appGroup.PUT("/:appID/docker/start", a.jwt.VerifyToken, a.dockerController.StartContainer)
 	appGroup.PUT("/:appID/docker/restart", a.jwt.VerifyToken, a.dockerController.RestartContainer)
 	appGroup.PUT("/:appID/docker/pause", a.jwt.VerifyToken, a.dockerController.PauseContainer)
 	appGroup.PUT("/:appID/docker/unpause", a.jwt.VerifyToken, a.dockerController.UnpauseContainer)
+	appGroup.PUT("/:appID/docker/rename", a.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), middleware.ValidateMiddleware[DTO.RenameContainer]("body", schema.RenameContainerSchema),
+		a.dockerController.RenameContainer)
 
 	appGroup.PUT("/:appID", a.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema),
 		middleware.ValidateMiddleware[DTO.UpdateApp]("body", schema.UpdateAppSchema), a.appController.UpdateApp)
+	appGroup.DELETE("/:appID/docker", a.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), a.dockerController.RemoveContainer)
 	appGroup.DELETE("/:appID", a.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
 		schema.AppIDSchema), a.appController.DeleteApp)
 }
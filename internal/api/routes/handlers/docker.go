@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/api/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/api/routes"
+	"github.com/slodkiadrianek/octopus/internal/middleware"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/schema"
+)
+
+type DockerHandlers struct {
+	dockerHostController  interfaces.DockerHostController
+	dockerImageController interfaces.DockerImageController
+	jwt                   *middleware.JWT
+	permissions           *middleware.Permissions
+}
+
+func NewDockerHandlers(dockerHostController interfaces.DockerHostController,
+	dockerImageController interfaces.DockerImageController, jwt *middleware.JWT,
+	permissions *middleware.Permissions,
+) *DockerHandlers {
+	return &DockerHandlers{
+		dockerHostController:  dockerHostController,
+		dockerImageController: dockerImageController,
+		jwt:                   jwt,
+		permissions:           permissions,
+	}
+}
+
+func (d *DockerHandlers) SetupDockerHandlers(router routes.Router) {
+	dockerGroup := router.Group("/api/v1/docker")
+
+	// The daemon and its credentials are used by every user, so saving them requires the operator permission.
+	dockerGroup.PUT("/hosts", d.jwt.VerifyToken, d.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.DockerHost]("body", schema.DockerHostSchema),
+		d.dockerHostController.SaveDockerHost)
+
+	// The images are shared by the containers of every user, the list names the containers which use them.
+	dockerGroup.GET("/images", d.jwt.VerifyToken, d.permissions.RequirePermission(models.PermissionOperator),
+		d.dockerImageController.ListImages)
+	dockerGroup.GET("/images/pull", d.jwt.VerifyToken, d.permissions.RequirePermission(models.PermissionOperator),
+		d.dockerImageController.PullImage)
+	dockerGroup.GET("/images/:imageID/history", d.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.DockerImageID](
+		"params", schema.DockerImageIDSchema), d.dockerImageController.GetImageHistory)
+	dockerGroup.POST("/images/prune", d.jwt.VerifyToken, d.permissions.RequirePermission(models.PermissionOperator),
+		d.dockerImageController.PruneImages)
+	dockerGroup.DELETE("/images/:imageID", d.jwt.VerifyToken,
+		d.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.DockerImageID]("params", schema.DockerImageIDSchema),
+		d.dockerImageController.RemoveImage)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/api/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/api/routes"
+	"github.com/slodkiadrianek/octopus/internal/middleware"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/schema"
+)
+
+type KubernetesHandlers struct {
+	kubernetesController interfaces.KubernetesController
+	jwt                  *middleware.JWT
+	permissions          *middleware.Permissions
+}
+
+func NewKubernetesHandlers(kubernetesController interfaces.KubernetesController,
+	jwt *middleware.JWT, permissions *middleware.Permissions,
+) *KubernetesHandlers {
+	return &KubernetesHandlers{
+		kubernetesController: kubernetesController,
+		jwt:                  jwt,
+		permissions:          permissions,
+	}
+}
+
+func (k KubernetesHandlers) SetupKubernetesHandlers(router routes.Router) {
+	kubernetesGroup := router.Group("/api/v1/kubernetes")
+
+	// The credentials of a cluster give access to all of its workloads, so saving them and importing the workloads
+	// requires the operator permission.
+	kubernetesGroup.PUT("/clusters", k.jwt.VerifyToken, k.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.KubernetesCluster]("body", schema.KubernetesClusterSchema),
+		k.kubernetesController.SaveCluster)
+
+	appGroup := router.Group("/api/v1/apps")
+
+	appGroup.GET("/:appID/kubernetes", k.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), k.kubernetesController.GetWorkload)
+
+	appGroup.POST("/kubernetes/import", k.jwt.VerifyToken, k.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.ImportKubernetesWorkloads]("body", schema.ImportKubernetesWorkloadsSchema),
+		k.kubernetesController.ImportWorkloads)
+
+	appGroup.PUT("/:appID/kubernetes/restart", k.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), k.kubernetesController.RestartWorkload)
+}
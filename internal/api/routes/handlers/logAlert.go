@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/api/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/api/routes"
+	"github.com/slodkiadrianek/octopus/internal/middleware"
+	"github.com/slodkiadrianek/octopus/internal/schema"
+)
+
+type LogAlertHandlers struct {
+	logAlertController interfaces.LogAlertController
+	jwt                *middleware.JWT
+}
+
+func NewLogAlertHandlers(logAlertController interfaces.LogAlertController, jwt *middleware.JWT) *LogAlertHandlers {
+	return &LogAlertHandlers{
+		logAlertController: logAlertController,
+		jwt:                jwt,
+	}
+}
+
+func (la LogAlertHandlers) SetupLogAlertHandlers(router routes.Router) {
+	appGroup := router.Group("/api/v1/apps")
+
+	appGroup.GET("/:appID/log-alerts", la.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), la.logAlertController.GetLogAlertRules)
+
+	appGroup.POST("/:appID/log-alerts", la.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), middleware.ValidateMiddleware[DTO.CreateLogAlertRule]("body",
+		schema.CreateLogAlertRuleSchema), la.logAlertController.CreateLogAlertRule)
+
+	appGroup.PUT("/:appID/log-alerts/:ruleID", la.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.LogAlertRuleID]("params", schema.LogAlertRuleIDSchema),
+		middleware.ValidateMiddleware[DTO.CreateLogAlertRule]("body", schema.CreateLogAlertRuleSchema),
+		la.logAlertController.UpdateLogAlertRule)
+
+	appGroup.DELETE("/:appID/log-alerts/:ruleID", la.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.LogAlertRuleID]("params", schema.LogAlertRuleIDSchema),
+		la.logAlertController.DeleteLogAlertRule)
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/api/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/api/routes"
+	"github.com/slodkiadrianek/octopus/internal/middleware"
+	"github.com/slodkiadrianek/octopus/internal/schema"
+)
+
+type LogsHandlers struct {
+	logsController interfaces.LogsController
+	jwt            *middleware.JWT
+}
+
+func NewLogsHandlers(logsController interfaces.LogsController, jwt *middleware.JWT) *LogsHandlers {
+	return &LogsHandlers{
+		logsController: logsController,
+		jwt:            jwt,
+	}
+}
+
+func (l LogsHandlers) SetupLogsHandlers(router routes.Router) {
+	logsGroup := router.Group("/api/v1/logs")
+
+	logsGroup.GET("/search", l.jwt.VerifyToken, l.logsController.SearchLogs)
+
+	appGroup := router.Group("/api/v1/apps")
+
+	appGroup.PUT("/:appID/logs/retention", l.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), middleware.ValidateMiddleware[DTO.LogsRetention]("body", schema.LogsRetentionSchema),
+		l.logsController.UpdateLogsRetention)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/api/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/api/routes"
+	"github.com/slodkiadrianek/octopus/internal/middleware"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/schema"
+)
+
+type Pm2Handlers struct {
+	pm2Controller interfaces.Pm2Controller
+	jwt           *middleware.JWT
+	permissions   *middleware.Permissions
+}
+
+func NewPm2Handlers(pm2Controller interfaces.Pm2Controller, jwt *middleware.JWT,
+	permissions *middleware.Permissions,
+) *Pm2Handlers {
+	return &Pm2Handlers{
+		pm2Controller: pm2Controller,
+		jwt:           jwt,
+		permissions:   permissions,
+	}
+}
+
+func (pm Pm2Handlers) SetupPm2Handlers(router routes.Router) {
+	appGroup := router.Group("/api/v1/apps")
+
+	appGroup.GET("/:appID/pm2", pm.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), pm.pm2Controller.GetProcesses)
+
+	// Processes are managed as the PM2 user, so importing and controlling them requires the operator permission.
+	appGroup.POST("/pm2/import", pm.jwt.VerifyToken, pm.permissions.RequirePermission(models.PermissionOperator),
+		pm.pm2Controller.ImportPm2Apps)
+
+	appGroup.PUT("/:appID/pm2/start", pm.jwt.VerifyToken, pm.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema), pm.pm2Controller.StartApp)
+	appGroup.PUT("/:appID/pm2/stop", pm.jwt.VerifyToken, pm.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema), pm.pm2Controller.StopApp)
+	appGroup.PUT("/:appID/pm2/restart", pm.jwt.VerifyToken, pm.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema), pm.pm2Controller.RestartApp)
+	appGroup.PUT("/:appID/pm2/reload", pm.jwt.VerifyToken, pm.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema), pm.pm2Controller.ReloadApp)
+}
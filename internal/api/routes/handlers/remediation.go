@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/api/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/api/routes"
+	"github.com/slodkiadrianek/octopus/internal/middleware"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/schema"
+)
+
+type RemediationHandlers struct {
+	remediationController interfaces.RemediationController
+	jwt                   *middleware.JWT
+	permissions           *middleware.Permissions
+}
+
+func NewRemediationHandlers(remediationController interfaces.RemediationController, jwt *middleware.JWT,
+	permissions *middleware.Permissions,
+) *RemediationHandlers {
+	return &RemediationHandlers{
+		remediationController: remediationController,
+		jwt:                   jwt,
+		permissions:           permissions,
+	}
+}
+
+func (rh RemediationHandlers) SetupRemediationHandlers(router routes.Router) {
+	appGroup := router.Group("/api/v1/apps")
+
+	appGroup.GET("/:appID/remediation", rh.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), rh.remediationController.GetRemediationPolicy)
+
+	// The script hook runs on the worker machine, so saving a policy requires the operator permission.
+	appGroup.PUT("/:appID/remediation", rh.jwt.VerifyToken,
+		rh.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema),
+		middleware.ValidateMiddleware[DTO.UpsertRemediationPolicy]("body", schema.UpsertRemediationPolicySchema),
+		rh.remediationController.UpsertRemediationPolicy)
+
+	appGroup.DELETE("/:appID/remediation", rh.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), rh.remediationController.DeleteRemediationPolicy)
+
+	appGroup.PUT("/:appID/remediation/maintenance", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema),
+		middleware.ValidateMiddleware[DTO.RemediationMaintenance]("body", schema.RemediationMaintenanceSchema),
+		rh.remediationController.StartMaintenance)
+
+	appGroup.DELETE("/:appID/remediation/maintenance", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema),
+		rh.remediationController.EndMaintenance)
+
+	appGroup.GET("/:appID/remediation/history", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema),
+		rh.remediationController.GetRemediationHistory)
+}
@@ -10,20 +10,58 @@ import (
 
 type RouteHandlers struct {
 	routeController interfaces.RouteController
+	jwt             *middleware.JWT
 }
 
-func NewRouteHandlers(routeController interfaces.RouteController) *RouteHandlers {
+func NewRouteHandlers(routeController interfaces.RouteController, jwt *middleware.JWT) *RouteHandlers {
 	return &RouteHandlers{
 		routeController: routeController,
+		jwt:             jwt,
 	}
 }
 
 func (rh *RouteHandlers) SetupRouteHandler(router routes.Router) {
 	routeGroup := router.Group("/api/v1/apps/:appID/routes")
 
-	routeGroup.GET("/:routeID", middleware.ValidateMiddleware[DTO.RouteID]("params", schema.RouteIDSchema), rh.routeController.CheckRouteStatus)
-	routeGroup.POST("/", middleware.ValidateMiddleware[DTO.CreateRouteData]("body", schema.CreateRouteSchema),
+	routeGroup.GET("/", rh.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema),
+		rh.routeController.GetRouteFlows)
+
+	routeGroup.POST("/", rh.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema),
+		middleware.ValidateMiddleware[DTO.CreateRouteData]("body", schema.CreateRouteSchema),
 		rh.routeController.AddWorkingRoutes)
-	// routeGroup.PUT("/:routeId", rh.routeController.UpdateRoute)
-	// routeGroup.DELETE("/:routeId", rh.routeController.DeleteRoute)
+
+	routeGroup.POST("/import/preview", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema),
+		middleware.ValidateMiddleware[DTO.ImportRouteFlows]("body", schema.ImportRouteFlowsSchema),
+		rh.routeController.PreviewRouteFlowsImport)
+
+	routeGroup.POST("/import", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema),
+		middleware.ValidateMiddleware[DTO.ImportRouteFlows]("body", schema.ImportRouteFlowsSchema),
+		rh.routeController.ImportRouteFlows)
+
+	routeGroup.GET("/:flowID", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.RouteFlowID]("params", schema.RouteFlowIDSchema),
+		rh.routeController.GetRouteFlow)
+
+	routeGroup.PUT("/:flowID", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.RouteFlowID]("params", schema.RouteFlowIDSchema),
+		middleware.ValidateMiddleware[DTO.CreateRouteData]("body", schema.CreateRouteSchema),
+		rh.routeController.UpdateRouteFlow)
+
+	routeGroup.DELETE("/:flowID", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.RouteFlowID]("params", schema.RouteFlowIDSchema),
+		rh.routeController.DeleteRouteFlow)
+
+	routeGroup.GET("/:flowID/runs", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.RouteFlowID]("params", schema.RouteFlowIDSchema),
+		rh.routeController.GetRouteFlowRuns)
+
+	routeGroup.GET("/:flowID/runs/:runID", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.RouteFlowRunID]("params", schema.RouteFlowRunIDSchema),
+		rh.routeController.GetRouteFlowRun)
+
+	routeGroup.POST("/:flowID/run", rh.jwt.VerifyToken,
+		middleware.ValidateMiddleware[DTO.RouteFlowID]("params", schema.RouteFlowIDSchema),
+		rh.routeController.RunRouteFlow)
 }
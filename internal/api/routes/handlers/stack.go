@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/api/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/api/routes"
+	"github.com/slodkiadrianek/octopus/internal/middleware"
+	"github.com/slodkiadrianek/octopus/internal/schema"
+)
+
+type StackHandlers struct {
+	stackController interfaces.StackController
+	jwt             *middleware.JWT
+}
+
+func NewStackHandlers(stackController interfaces.StackController, jwt *middleware.JWT) *StackHandlers {
+	return &StackHandlers{
+		stackController: stackController,
+		jwt:             jwt,
+	}
+}
+
+func (s StackHandlers) SetupStackHandlers(router routes.Router) {
+	stackGroup := router.Group("/api/v1/stacks")
+
+	stackGroup.GET("", s.jwt.VerifyToken, s.stackController.GetStacks)
+	stackGroup.GET("/:stackID", s.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.StackID]("params",
+		schema.StackIDSchema), s.stackController.GetStack)
+
+	stackGroup.PUT("/:stackID/start", s.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.StackID]("params",
+		schema.StackIDSchema), s.stackController.StartStack)
+	stackGroup.PUT("/:stackID/stop", s.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.StackID]("params",
+		schema.StackIDSchema), s.stackController.StopStack)
+	stackGroup.PUT("/:stackID/restart", s.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.StackID]("params",
+		schema.StackIDSchema), s.stackController.RestartStack)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/api/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/api/routes"
+	"github.com/slodkiadrianek/octopus/internal/middleware"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/schema"
+)
+
+type SystemdHandlers struct {
+	systemdController interfaces.SystemdController
+	jwt               *middleware.JWT
+	permissions       *middleware.Permissions
+}
+
+func NewSystemdHandlers(systemdController interfaces.SystemdController, jwt *middleware.JWT,
+	permissions *middleware.Permissions,
+) *SystemdHandlers {
+	return &SystemdHandlers{
+		systemdController: systemdController,
+		jwt:               jwt,
+		permissions:       permissions,
+	}
+}
+
+func (sd SystemdHandlers) SetupSystemdHandlers(router routes.Router) {
+	appGroup := router.Group("/api/v1/apps")
+
+	appGroup.GET("/:appID/systemd", sd.jwt.VerifyToken, middleware.ValidateMiddleware[DTO.AppID]("params",
+		schema.AppIDSchema), sd.systemdController.GetUnit)
+
+	// Units are managed as the user running OCTOPUS, so importing and controlling them requires the operator
+	// permission.
+	appGroup.POST("/systemd/import", sd.jwt.VerifyToken, sd.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.ImportSystemdUnits]("body", schema.ImportSystemdUnitsSchema),
+		sd.systemdController.ImportSystemdUnits)
+
+	appGroup.PUT("/:appID/systemd/start", sd.jwt.VerifyToken, sd.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema), sd.systemdController.StartUnit)
+	appGroup.PUT("/:appID/systemd/stop", sd.jwt.VerifyToken, sd.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema), sd.systemdController.StopUnit)
+	appGroup.PUT("/:appID/systemd/restart", sd.jwt.VerifyToken,
+		sd.permissions.RequirePermission(models.PermissionOperator),
+		middleware.ValidateMiddleware[DTO.AppID]("params", schema.AppIDSchema), sd.systemdController.RestartUnit)
+}
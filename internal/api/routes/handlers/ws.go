@@ -4,23 +4,35 @@ import (
 	"github.com/slodkiadrianek/octopus/internal/api/interfaces"
 	"github.com/slodkiadrianek/octopus/internal/api/routes"
 	"github.com/slodkiadrianek/octopus/internal/middleware"
+	"github.com/slodkiadrianek/octopus/internal/models"
 )
 
 type WebSocketHandlers struct {
-	wsController interfaces.WsController
-	jwt          *middleware.JWT
+	wsController            interfaces.WsController
+	dockerStatsController   interfaces.DockerStatsController
+	dockerConsoleController interfaces.DockerConsoleController
+	jwt                     *middleware.JWT
+	permissions             *middleware.Permissions
 }
 
-func NewWebsocketHandler(wsController interfaces.WsController, jwt *middleware.JWT) *WebSocketHandlers {
+func NewWebsocketHandler(wsController interfaces.WsController, dockerStatsController interfaces.DockerStatsController,
+	dockerConsoleController interfaces.DockerConsoleController, jwt *middleware.JWT,
+	permissions *middleware.Permissions,
+) *WebSocketHandlers {
 	return &WebSocketHandlers{
-		wsController: wsController,
-		jwt:          jwt,
+		wsController:            wsController,
+		dockerStatsController:   dockerStatsController,
+		dockerConsoleController: dockerConsoleController,
+		jwt:                     jwt,
+		permissions:             permissions,
 	}
 }
 
 func (ws *WebSocketHandlers) SetupWebsocketHandlers(router routes.Router) {
 	groupRouter := router.Group("/ws/v1/apps")
 
-	groupRouter.GET("/:appId/logs", ws.wsController.Logs)
-	// groupRouter.GET("/:appId/console", ws.jwt.VerifyToken)
+	groupRouter.GET("/:appID/logs", ws.jwt.VerifyToken, ws.wsController.Logs)
+	groupRouter.GET("/:appID/stats", ws.jwt.VerifyToken, ws.dockerStatsController.StreamContainerStats)
+	groupRouter.GET("/:appID/console", ws.jwt.VerifyToken, ws.permissions.RequirePermission(models.PermissionOperator),
+		ws.dockerConsoleController.Console)
 }
@@ -13,34 +13,64 @@ import (
 )
 
 type DependencyConfig struct {
-	port                string
-	userController      interfaces.UserController
-	appController       interfaces.AppController
-	dockerController    interfaces.DockerController
-	authController      interfaces.AuthController
-	serverController    interfaces.ServerController
-	webSocketController interfaces.WsController
-	routeController     interfaces.RouteController
-	jwt                 *middleware.JWT
-	rateLimiter         *middleware.RateLimiter
+	port                    string
+	userController          interfaces.UserController
+	appController           interfaces.AppController
+	dockerController        interfaces.DockerController
+	authController          interfaces.AuthController
+	serverController        interfaces.ServerController
+	webSocketController     interfaces.WsController
+	routeController         interfaces.RouteController
+	dockerHostController    interfaces.DockerHostController
+	dockerImageController   interfaces.DockerImageController
+	stackController         interfaces.StackController
+	dockerStatsController   interfaces.DockerStatsController
+	dockerConsoleController interfaces.DockerConsoleController
+	logsController          interfaces.LogsController
+	logAlertController      interfaces.LogAlertController
+	pm2Controller           interfaces.Pm2Controller
+	systemdController       interfaces.SystemdController
+	kubernetesController    interfaces.KubernetesController
+	remediationController   interfaces.RemediationController
+	jwt                     *middleware.JWT
+	permissions             *middleware.Permissions
+	rateLimiter             *middleware.RateLimiter
 }
 
 func NewDependencyConfig(port string, userController interfaces.UserController,
 	appController interfaces.AppController, dockerController interfaces.DockerController,
 	authController interfaces.AuthController, jwt *middleware.JWT, serverController interfaces.ServerController,
 	wsController interfaces.WsController, rateLimiter *middleware.RateLimiter, routeController interfaces.RouteController,
+	dockerHostController interfaces.DockerHostController, dockerImageController interfaces.DockerImageController,
+	stackController interfaces.StackController, dockerStatsController interfaces.DockerStatsController,
+	dockerConsoleController interfaces.DockerConsoleController, permissions *middleware.Permissions,
+	logsController interfaces.LogsController, logAlertController interfaces.LogAlertController,
+	pm2Controller interfaces.Pm2Controller, systemdController interfaces.SystemdController,
+	kubernetesController interfaces.KubernetesController, remediationController interfaces.RemediationController,
 ) *DependencyConfig {
 	return &DependencyConfig{
-		port:                port,
-		userController:      userController,
-		appController:       appController,
-		dockerController:    dockerController,
-		authController:      authController,
-		serverController:    serverController,
-		webSocketController: wsController,
-		routeController:     routeController,
-		jwt:                 jwt,
-		rateLimiter:         rateLimiter,
+		port:                    port,
+		userController:          userController,
+		appController:           appController,
+		dockerController:        dockerController,
+		authController:          authController,
+		serverController:        serverController,
+		webSocketController:     wsController,
+		routeController:         routeController,
+		dockerHostController:    dockerHostController,
+		dockerImageController:   dockerImageController,
+		stackController:         stackController,
+		dockerStatsController:   dockerStatsController,
+		dockerConsoleController: dockerConsoleController,
+		logsController:          logsController,
+		logAlertController:      logAlertController,
+		pm2Controller:           pm2Controller,
+		systemdController:       systemdController,
+		kubernetesController:    kubernetesController,
+		remediationController:   remediationController,
+		jwt:                     jwt,
+		permissions:             permissions,
+		rateLimiter:             rateLimiter,
 	}
 }
 
@@ -73,16 +103,39 @@ func (s *Server) Start() error {
 func (s *Server) SetupRoutes() {
 	authHandler := handlers.NewAuthHandler(s.config.userController, s.config.authController, s.config.jwt, s.config.rateLimiter)
 	userHandler := handlers.NewUserHandler(s.config.userController, s.config.jwt)
-	appHandler := handlers.NewAppAppHandler(s.config.appController, s.config.dockerController, s.config.jwt)
+	appHandler := handlers.NewAppAppHandler(s.config.appController, s.config.dockerController,
+		s.config.dockerStatsController, s.config.jwt, s.config.permissions)
 	serverHandler := handlers.NewServerHandlers(s.config.serverController, s.config.jwt)
-	wsHandler := handlers.NewWebsocketHandler(s.config.webSocketController, s.config.jwt)
-	routeHandler := handlers.NewRouteHandlers(s.config.routeController)
+	wsHandler := handlers.NewWebsocketHandler(s.config.webSocketController, s.config.dockerStatsController,
+		s.config.dockerConsoleController, s.config.jwt, s.config.permissions)
+	routeHandler := handlers.NewRouteHandlers(s.config.routeController, s.config.jwt)
+	stackHandler := handlers.NewStackHandlers(s.config.stackController, s.config.jwt)
+	dockerHandler := handlers.NewDockerHandlers(s.config.dockerHostController, s.config.dockerImageController,
+		s.config.jwt, s.config.permissions)
+	logsHandler := handlers.NewLogsHandlers(s.config.logsController, s.config.jwt)
+	logAlertHandler := handlers.NewLogAlertHandlers(s.config.logAlertController, s.config.jwt)
+	pm2Handler := handlers.NewPm2Handlers(s.config.pm2Controller, s.config.jwt,
+		s.config.permissions)
+	systemdHandler := handlers.NewSystemdHandlers(s.config.systemdController, s.config.jwt,
+		s.config.permissions)
+	kubernetesHandler := handlers.NewKubernetesHandlers(s.config.kubernetesController, s.config.jwt,
+		s.config.permissions)
+	remediationHandler := handlers.NewRemediationHandlers(s.config.remediationController, s.config.jwt,
+		s.config.permissions)
 	authHandler.SetupAuthHandlers(*s.router)
 	appHandler.SetupAppHandlers(*s.router)
 	wsHandler.SetupWebsocketHandlers(*s.router)
 	serverHandler.SetupServerHandlers(*s.router)
 	userHandler.SetupUserHandlers(*s.router)
 	routeHandler.SetupRouteHandler(*s.router)
+	stackHandler.SetupStackHandlers(*s.router)
+	dockerHandler.SetupDockerHandlers(*s.router)
+	logsHandler.SetupLogsHandlers(*s.router)
+	logAlertHandler.SetupLogAlertHandlers(*s.router)
+	pm2Handler.SetupPm2Handlers(*s.router)
+	systemdHandler.SetupSystemdHandlers(*s.router)
+	kubernetesHandler.SetupKubernetesHandlers(*s.router)
+	remediationHandler.SetupRemediationHandlers(*s.router)
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type dockerClient struct {
+	mu              sync.Mutex
+	cli             *client.Client
+	lastHealthCheck time.Time
+}
+
+type DockerClientManager struct {
+	mu                  sync.Mutex
+	clients             map[string]*dockerClient
+	loggerService       utils.LoggerService
+	dockerRepository    interfaces.DockerRepository
+	encryptionKey       string
+	healthCheckInterval time.Duration
+	maxRetries          int
+	retryBackoff        time.Duration
+}
+
+func NewDockerClientManager(loggerService utils.LoggerService, dockerRepository interfaces.DockerRepository,
+	encryptionKey string, healthCheckInterval time.Duration, maxRetries int, retryBackoff time.Duration,
+) *DockerClientManager {
+	return &DockerClientManager{
+		clients:             make(map[string]*dockerClient),
+		loggerService:       loggerService,
+		dockerRepository:    dockerRepository,
+		encryptionKey:       encryptionKey,
+		healthCheckInterval: healthCheckInterval,
+		maxRetries:          maxRetries,
+		retryBackoff:        retryBackoff,
+	}
+}
+
+// GetClient returns a shared client for the docker host. The connection is health checked at most once per
+// healthCheckInterval and recreated with backoff when the daemon stops answering.
+func (dm *DockerClientManager) GetClient(ctx context.Context, dockerHost string) (client.APIClient, error) {
+	dm.mu.Lock()
+	entry, exists := dm.clients[dockerHost]
+	if !exists {
+		entry = &dockerClient{}
+		dm.clients[dockerHost] = entry
+	}
+	dm.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.cli != nil {
+		if time.Since(entry.lastHealthCheck) < dm.healthCheckInterval {
+			return entry.cli, nil
+		}
+
+		_, err := entry.cli.Ping(ctx)
+		if err == nil {
+			entry.lastHealthCheck = time.Now()
+			return entry.cli, nil
+		}
+
+		dm.loggerService.Warn("docker daemon health check failed, reconnecting", map[string]any{
+			"dockerHost": dockerHost,
+			"err":        err.Error(),
+		})
+		dm.closeClient(entry)
+	}
+
+	cli, err := dm.connect(ctx, dockerHost)
+	if err != nil {
+		return nil, err
+	}
+	entry.cli = cli
+	entry.lastHealthCheck = time.Now()
+
+	return entry.cli, nil
+}
+
+func (dm *DockerClientManager) connect(ctx context.Context, dockerHost string) (*client.Client, error) {
+	opts, err := dm.clientOptions(ctx, dockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	backoff := dm.retryBackoff
+	for attempt := 0; ; attempt++ {
+		_, err = cli.Ping(ctx)
+		if err == nil {
+			return cli, nil
+		}
+
+		if attempt >= dm.maxRetries {
+			break
+		}
+
+		dm.loggerService.Warn("failed to connect to docker daemon, retrying", map[string]any{
+			"dockerHost": dockerHost,
+			"attempt":    attempt + 1,
+			"backoff":    backoff.String(),
+			"err":        err.Error(),
+		})
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			if closeErr := cli.Close(); closeErr != nil {
+				dm.loggerService.Warn("failed to close docker client", closeErr)
+			}
+			return nil, ctx.Err()
+		}
+	}
+
+	if closeErr := cli.Close(); closeErr != nil {
+		dm.loggerService.Warn("failed to close docker client", closeErr)
+	}
+	dm.loggerService.Error("failed to connect to docker daemon", map[string]any{
+		"dockerHost": dockerHost,
+		"err":        err.Error(),
+	})
+
+	return nil, describeConnectionError(dockerHost, err)
+}
+
+func (dm *DockerClientManager) closeClient(entry *dockerClient) {
+	if entry.cli == nil {
+		return
+	}
+
+	if err := entry.cli.Close(); err != nil {
+		dm.loggerService.Warn("failed to close docker client", err)
+	}
+	entry.cli = nil
+}
+
+// ResetClient drops the client of the docker host, so the next call connects again with fresh credentials.
+func (dm *DockerClientManager) ResetClient(dockerHost string) {
+	dm.mu.Lock()
+	entry, exists := dm.clients[dockerHost]
+	delete(dm.clients, dockerHost)
+	dm.mu.Unlock()
+
+	if !exists {
+		return
+	}
+
+	entry.mu.Lock()
+	dm.closeClient(entry)
+	entry.mu.Unlock()
+}
+
+func (dm *DockerClientManager) Close() {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	for dockerHost, entry := range dm.clients {
+		entry.mu.Lock()
+		dm.closeClient(entry)
+		entry.mu.Unlock()
+		delete(dm.clients, dockerHost)
+	}
+}
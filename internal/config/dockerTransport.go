@@ -0,0 +1,280 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+func (dm *DockerClientManager) readDockerHostConfig(ctx context.Context, dockerHost string) (*models.DockerHost, error) {
+	if dm.dockerRepository == nil {
+		return nil, nil
+	}
+
+	hostConfig, err := dm.dockerRepository.GetDockerHost(ctx, dockerHost)
+	if err != nil || hostConfig == nil {
+		return nil, err
+	}
+
+	decryptedHostConfig := models.DockerHost{
+		Host:            hostConfig.Host,
+		SSHIdentityFile: hostConfig.SSHIdentityFile,
+	}
+	encryptedFields := []struct {
+		encrypted string
+		decrypted *string
+	}{
+		{hostConfig.TLSCACert, &decryptedHostConfig.TLSCACert},
+		{hostConfig.TLSCert, &decryptedHostConfig.TLSCert},
+		{hostConfig.TLSKey, &decryptedHostConfig.TLSKey},
+	}
+	for _, field := range encryptedFields {
+		*field.decrypted, err = utils.Decrypt(field.encrypted, dm.encryptionKey)
+		if err != nil {
+			dm.loggerService.Error("failed to decrypt docker host credentials", map[string]any{
+				"dockerHost": dockerHost,
+				"err":        err.Error(),
+			})
+			return nil, models.NewError(500, "Docker", "failed to decrypt credentials of docker host "+dockerHost)
+		}
+	}
+
+	return &decryptedHostConfig, nil
+}
+
+func (dm *DockerClientManager) clientOptions(ctx context.Context, dockerHost string) ([]client.Opt, error) {
+	hostConfig, err := dm.readDockerHostConfig(ctx, dockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(dockerHost, "ssh://") {
+		sshURL, err := url.Parse(dockerHost)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse docker host `%s`: %w", dockerHost, err)
+		}
+		identityFile := ""
+		if hostConfig != nil {
+			identityFile = hostConfig.SSHIdentityFile
+		}
+
+		return []client.Opt{
+			client.WithHTTPClient(&http.Client{Transport: &http.Transport{}}),
+			client.WithHost("http://docker.example.com"),
+			client.WithDialContext(sshDialContext(sshURL, identityFile)),
+			client.WithAPIVersionNegotiation(),
+		}, nil
+	}
+
+	opts := make([]client.Opt, 0, 3)
+	if hostConfig != nil && (hostConfig.TLSCACert != "" || hostConfig.TLSCert != "") {
+		// The TLS transport has to be set before WithHost, which configures the transport for the host protocol.
+		opts = append(opts, withTLSClientConfigFromPEM(hostConfig.TLSCACert, hostConfig.TLSCert, hostConfig.TLSKey))
+	}
+	opts = append(opts, client.WithHost(dockerHost), client.WithAPIVersionNegotiation())
+
+	return opts, nil
+}
+
+func withTLSClientConfigFromPEM(caCert, cert, key string) client.Opt {
+	return func(c *client.Client) error {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+		if caCert != "" {
+			certPool := x509.NewCertPool()
+			if !certPool.AppendCertsFromPEM([]byte(caCert)) {
+				return errors.New("failed to parse CA bundle of docker host")
+			}
+			tlsConfig.RootCAs = certPool
+		}
+
+		if cert != "" {
+			keyPair, err := tls.X509KeyPair([]byte(cert), []byte(key))
+			if err != nil {
+				return fmt.Errorf("failed to load client certificate of docker host: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{keyPair}
+		}
+
+		return client.WithHTTPClient(&http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		})(c)
+	}
+}
+
+// describeConnectionError turns TLS and SSH failures into messages which say which side refused the connection.
+func describeConnectionError(dockerHost string, err error) error {
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var certificateInvalidError x509.CertificateInvalidError
+	errMessage := err.Error()
+
+	switch {
+	case errors.As(err, &unknownAuthorityError), errors.As(err, &hostnameError),
+		errors.As(err, &certificateInvalidError):
+		return models.NewError(502, "Docker", fmt.Sprintf(
+			"certificate presented by docker daemon at %s is not trusted by the configured CA: %s", dockerHost,
+			errMessage))
+	case strings.Contains(errMessage, "remote error: tls"):
+		return models.NewError(502, "Docker", fmt.Sprintf(
+			"docker daemon at %s rejected the client certificate: %s", dockerHost, errMessage))
+	case strings.Contains(errMessage, "server gave HTTP response to HTTPS client"),
+		strings.Contains(errMessage, "client sent an HTTP request to an HTTPS server"):
+		return models.NewError(502, "Docker", fmt.Sprintf(
+			"TLS settings do not match the docker daemon at %s: %s", dockerHost, errMessage))
+	case strings.HasPrefix(dockerHost, "ssh://"):
+		return models.NewError(502, "Docker", fmt.Sprintf(
+			"failed to connect to docker daemon at %s over ssh: %s", dockerHost, errMessage))
+	}
+
+	return err
+}
+
+// sshDialContext works like the docker connection helper: it starts `docker system dial-stdio` on the remote host
+// and uses the standard streams of the ssh process as the connection to the daemon. The process is killed when the
+// context of the dial is done.
+func sshDialContext(sshURL *url.URL, identityFile string) func(ctx context.Context, network, addr string) (net.Conn,
+	error,
+) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		args := []string{"-o", "ConnectTimeout=30", "-o", "BatchMode=yes"}
+		if sshURL.Port() != "" {
+			args = append(args, "-p", sshURL.Port())
+		}
+		if identityFile != "" {
+			args = append(args, "-i", identityFile)
+		}
+
+		target := sshURL.Hostname()
+		if sshURL.User != nil {
+			target = sshURL.User.Username() + "@" + target
+		}
+		args = append(args, "--", target, "docker", "system", "dial-stdio")
+
+		return newCommandConn(exec.CommandContext(ctx, "ssh", args...))
+	}
+}
+
+// commandStderrWait is how long the end of the connection waits for the rest of the standard error of the command.
+const commandStderrWait = time.Second
+
+// commandStderr keeps the standard error of the command, it is copied by its own goroutine while the connection
+// reads it.
+type commandStderr struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (cs *commandStderr) Write(p []byte) (int, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.buf.Write(p)
+}
+
+func (cs *commandStderr) String() string {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.buf.String()
+}
+
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr commandStderr
+	// stderrDone is closed once the whole standard error was copied.
+	stderrDone chan struct{}
+	closeOnce  sync.Once
+}
+
+func newCommandConn(cmd *exec.Cmd) (net.Conn, error) {
+	conn := &commandConn{cmd: cmd, stderrDone: make(chan struct{})}
+	var err error
+
+	conn.stdin, err = cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	conn.stdout, err = cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		defer close(conn.stderrDone)
+		_, _ = io.Copy(&conn.stderr, stderr)
+	}()
+
+	return conn, nil
+}
+
+func (cc *commandConn) Read(p []byte) (int, error) {
+	n, err := cc.stdout.Read(p)
+	if errors.Is(err, io.EOF) {
+		select {
+		case <-cc.stderrDone:
+		case <-time.After(commandStderrWait):
+		}
+		if stderr := strings.TrimSpace(cc.stderr.String()); stderr != "" {
+			return n, fmt.Errorf("%w: %s", err, stderr)
+		}
+	}
+	return n, err
+}
+
+func (cc *commandConn) Write(p []byte) (int, error) {
+	return cc.stdin.Write(p)
+}
+
+func (cc *commandConn) Close() error {
+	cc.closeOnce.Do(func() {
+		_ = cc.stdin.Close()
+		if cc.cmd.Process != nil {
+			_ = cc.cmd.Process.Kill()
+		}
+		_ = cc.cmd.Wait()
+	})
+	return nil
+}
+
+func (cc *commandConn) LocalAddr() net.Addr {
+	return &net.UnixAddr{Name: "dial-stdio", Net: "unix"}
+}
+
+func (cc *commandConn) RemoteAddr() net.Addr {
+	return &net.UnixAddr{Name: "dial-stdio", Net: "unix"}
+}
+
+func (cc *commandConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (cc *commandConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (cc *commandConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
@@ -0,0 +1,288 @@
+package config
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerClientManager_GetClient(t *testing.T) {
+	loggerService := utils.NewLogger(t.TempDir(), "2006-01-02 15:04:05")
+	loggerService.InitializeLogger()
+	defer loggerService.Close()
+
+	type args struct {
+		name                string
+		dockerHost          func(serverURL string) string
+		healthCheckInterval time.Duration
+		expectedError       error
+		expectedPings       int32
+	}
+	testsScenarios := []args{
+		{
+			name: "Client is reused between calls",
+			dockerHost: func(serverURL string) string {
+				return "tcp://" + strings.TrimPrefix(serverURL, "http://")
+			},
+			healthCheckInterval: time.Minute,
+			expectedError:       nil,
+			expectedPings:       1,
+		},
+		{
+			name: "Client is health checked after interval",
+			dockerHost: func(serverURL string) string {
+				return "tcp://" + strings.TrimPrefix(serverURL, "http://")
+			},
+			healthCheckInterval: 0,
+			expectedError:       nil,
+			expectedPings:       2,
+		},
+		{
+			name: "Invalid docker host",
+			dockerHost: func(serverURL string) string {
+				return ""
+			},
+			healthCheckInterval: time.Minute,
+			expectedError:       errors.New("unable to parse docker host"),
+			expectedPings:       0,
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			var pings int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/_ping") {
+					atomic.AddInt32(&pings, 1)
+					w.Header().Set("API-Version", "1.47")
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+
+			dockerClientManager := NewDockerClientManager(loggerService, nil, "", testScenario.healthCheckInterval, 0,
+				time.Millisecond)
+			defer dockerClientManager.Close()
+
+			ctx := context.Background()
+			dockerHost := testScenario.dockerHost(server.URL)
+			firstClient, err := dockerClientManager.GetClient(ctx, dockerHost)
+			if testScenario.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+				return
+			}
+			assert.NoError(t, err)
+
+			secondClient, err := dockerClientManager.GetClient(ctx, dockerHost)
+			assert.NoError(t, err)
+			assert.Same(t, firstClient, secondClient)
+			assert.Equal(t, testScenario.expectedPings, atomic.LoadInt32(&pings))
+		})
+	}
+}
+
+func TestDockerClientManager_Reconnect(t *testing.T) {
+	loggerService := utils.NewLogger(t.TempDir(), "2006-01-02 15:04:05")
+	loggerService.InitializeLogger()
+	defer loggerService.Close()
+
+	var healthy atomic.Bool
+	healthy.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("API-Version", "1.47")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dockerClientManager := NewDockerClientManager(loggerService, nil, "", 0, 2, time.Millisecond)
+	defer dockerClientManager.Close()
+	dockerHost := "tcp://" + strings.TrimPrefix(server.URL, "http://")
+
+	ctx := context.Background()
+	firstClient, err := dockerClientManager.GetClient(ctx, dockerHost)
+	assert.NoError(t, err)
+
+	healthy.Store(false)
+	_, err = dockerClientManager.GetClient(ctx, dockerHost)
+	assert.Error(t, err)
+
+	healthy.Store(true)
+	secondClient, err := dockerClientManager.GetClient(ctx, dockerHost)
+	assert.NoError(t, err)
+	assert.NotSame(t, firstClient, secondClient)
+}
+
+type fakeDockerRepository struct {
+	dockerHost *models.DockerHost
+}
+
+func (fr *fakeDockerRepository) GetDockerHost(ctx context.Context, host string) (*models.DockerHost, error) {
+	return fr.dockerHost, nil
+}
+
+func (fr *fakeDockerRepository) UpsertDockerHost(ctx context.Context, dockerHost models.DockerHost) error {
+	fr.dockerHost = &dockerHost
+	return nil
+}
+
+func generateClientCertificate(t *testing.T) (string, string) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "octopus"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	assert.NoError(t, err)
+	marshaledKey, err := x509.MarshalECPrivateKey(privateKey)
+	assert.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: marshaledKey}))
+}
+
+func TestDockerClientManager_TLS(t *testing.T) {
+	loggerService := utils.NewLogger(t.TempDir(), "2006-01-02 15:04:05")
+	loggerService.InitializeLogger()
+	defer loggerService.Close()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", "1.47")
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	clientCert, clientKey := generateClientCertificate(t)
+	encryptionKey := "secret"
+
+	type args struct {
+		name          string
+		caCert        string
+		cert          string
+		key           string
+		expectedError error
+	}
+	testsScenarios := []args{
+		{
+			name:          "Proper CA and client certificate",
+			caCert:        serverCA,
+			cert:          clientCert,
+			key:           clientKey,
+			expectedError: nil,
+		},
+		{
+			name:          "Missing client certificate",
+			caCert:        serverCA,
+			expectedError: errors.New("rejected the client certificate"),
+		},
+		{
+			name:          "Missing CA of the daemon",
+			cert:          clientCert,
+			key:           clientKey,
+			expectedError: errors.New("is not trusted by the configured CA"),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			dockerHost := "tcp://" + strings.TrimPrefix(server.URL, "https://")
+			encryptedDockerHost := models.DockerHost{Host: dockerHost}
+			var err error
+			encryptedDockerHost.TLSCACert, err = utils.Encrypt(testScenario.caCert, encryptionKey)
+			assert.NoError(t, err)
+			encryptedDockerHost.TLSCert, err = utils.Encrypt(testScenario.cert, encryptionKey)
+			assert.NoError(t, err)
+			encryptedDockerHost.TLSKey, err = utils.Encrypt(testScenario.key, encryptionKey)
+			assert.NoError(t, err)
+
+			dockerRepository := &fakeDockerRepository{dockerHost: &encryptedDockerHost}
+			dockerClientManager := NewDockerClientManager(loggerService, dockerRepository, encryptionKey, time.Minute,
+				0, time.Millisecond)
+			defer dockerClientManager.Close()
+
+			_, err = dockerClientManager.GetClient(context.Background(), dockerHost)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+		})
+	}
+}
+
+func TestCommandConn(t *testing.T) {
+	type args struct {
+		name          string
+		script        string
+		cancelled     bool
+		expectedData  string
+		expectedError string
+	}
+
+	testsScenarios := []args{
+		{
+			name:          "Standard error is added to the end of the stream",
+			script:        "printf data; echo 'Permission denied (publickey)' >&2; exit 255",
+			expectedData:  "data",
+			expectedError: "EOF: Permission denied (publickey)",
+		},
+		{
+			name:          "Dial which was cancelled",
+			script:        "sleep 5",
+			cancelled:     true,
+			expectedError: "context canceled",
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if testScenario.cancelled {
+				cancel()
+			}
+
+			conn, err := newCommandConn(exec.CommandContext(ctx, "sh", "-c", testScenario.script))
+			if err != nil {
+				assert.ErrorContains(t, err, testScenario.expectedError)
+				return
+			}
+			defer conn.Close()
+
+			data, err := io.ReadAll(conn)
+			assert.Equal(t, testScenario.expectedData, string(data))
+			assert.EqualError(t, err, testScenario.expectedError)
+		})
+	}
+}
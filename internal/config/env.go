@@ -8,12 +8,18 @@ import (
 	"strings"
 )
 
+// defaultSystemdUnitsPrefix is the only prefix of systemd units which can be imported when none is configured.
+const defaultSystemdUnitsPrefix = "octopus-"
+
 type Env struct {
-	Port       string
-	JWTSecret  string
-	DBLink     string
-	CacheLink  string
-	DockerHost string
+	Port          string
+	JWTSecret     string
+	DBLink        string
+	CacheLink     string
+	DockerHost    string
+	EncryptionKey string
+	// SystemdUnitsPrefixes are the prefixes of the systemd units which can be imported.
+	SystemdUnitsPrefixes []string
 }
 
 func readFile(filepath string) (map[string]string, error) {
@@ -71,9 +77,27 @@ func (e *Env) Validate() error {
 		return errors.New("DockerHost is required")
 	}
 
+	if e.EncryptionKey == "" {
+		return errors.New("EncryptionKey is required")
+	}
+
 	return nil
 }
 
+func splitSystemdUnitsPrefixes(value string) []string {
+	prefixes := make([]string, 0)
+	for _, prefix := range strings.Split(value, ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return []string{defaultSystemdUnitsPrefix}
+	}
+
+	return prefixes
+}
+
 func SetConfig(filepath string) (*Env, error) {
 	envVariables, err := readFile(filepath)
 	if err != nil {
@@ -81,10 +105,12 @@ func SetConfig(filepath string) (*Env, error) {
 	}
 
 	return &Env{
-		Port:       envVariables["Port"],
-		JWTSecret:  envVariables["JWTSecret"],
-		DBLink:     envVariables["DBLink"],
-		CacheLink:  envVariables["CacheLink"],
-		DockerHost: envVariables["DockerHost"],
+		Port:                 envVariables["Port"],
+		JWTSecret:            envVariables["JWTSecret"],
+		DBLink:               envVariables["DBLink"],
+		CacheLink:            envVariables["CacheLink"],
+		DockerHost:           envVariables["DockerHost"],
+		EncryptionKey:        envVariables["EncryptionKey"],
+		SystemdUnitsPrefixes: splitSystemdUnitsPrefixes(envVariables["SystemdUnitsPrefixes"]),
 	}, nil
 }
@@ -0,0 +1,460 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// kubernetesRestartedAtAnnotation is the pod template annotation changed by kubectl rollout restart.
+const kubernetesRestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+const defaultKubernetesLogsTail int64 = 100
+
+// kubernetesClusterKey identifies a cluster, the clusters are registered by every owner under their own names.
+type kubernetesClusterKey struct {
+	ownerID int
+	name    string
+}
+
+// KubernetesClient talks to the registered clusters. A clientset is created from the kubeconfig saved in the
+// database on the first use of a cluster and kept until ResetClient is called.
+type KubernetesClient struct {
+	mu                   sync.Mutex
+	clientsets           map[kubernetesClusterKey]kubernetes.Interface
+	kubernetesRepository interfaces.KubernetesRepository
+	encryptionKey        string
+	loggerService        utils.LoggerService
+}
+
+func NewKubernetesClient(loggerService utils.LoggerService, kubernetesRepository interfaces.KubernetesRepository,
+	encryptionKey string,
+) *KubernetesClient {
+	return &KubernetesClient{
+		clientsets:           make(map[kubernetesClusterKey]kubernetes.Interface),
+		kubernetesRepository: kubernetesRepository,
+		encryptionKey:        encryptionKey,
+		loggerService:        loggerService,
+	}
+}
+
+func (k *KubernetesClient) getClientset(ctx context.Context, ownerID int,
+	cluster string,
+) (kubernetes.Interface, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	clusterKey := kubernetesClusterKey{ownerID: ownerID, name: cluster}
+	if clientset, exists := k.clientsets[clusterKey]; exists {
+		return clientset, nil
+	}
+
+	kubernetesCluster, err := k.kubernetesRepository.GetKubernetesCluster(ctx, ownerID, cluster)
+	if err != nil {
+		return nil, err
+	}
+	if kubernetesCluster == nil {
+		return nil, models.NewError(404, "Kubernetes", fmt.Sprintf("kubernetes cluster %s not found", cluster))
+	}
+
+	kubeconfig, err := utils.Decrypt(kubernetesCluster.Kubeconfig, k.encryptionKey)
+	if err != nil {
+		k.loggerService.Error("failed to decrypt kubeconfig", map[string]any{
+			"cluster": cluster,
+			"err":     err.Error(),
+		})
+		return nil, models.NewError(500, "Kubernetes", "failed to decrypt kubeconfig of cluster "+cluster)
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
+	if err != nil {
+		return nil, models.NewError(500, "Kubernetes", fmt.Sprintf("kubeconfig of cluster %s is invalid: %s",
+			cluster, err.Error()))
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, models.NewError(500, "Kubernetes", fmt.Sprintf("failed to create client of cluster %s: %s",
+			cluster, err.Error()))
+	}
+	k.clientsets[clusterKey] = clientset
+
+	return clientset, nil
+}
+
+// ResetClient drops the clientset of the cluster, so the next call reads the saved kubeconfig again.
+func (k *KubernetesClient) ResetClient(ownerID int, cluster string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	delete(k.clientsets, kubernetesClusterKey{ownerID: ownerID, name: cluster})
+}
+
+func (k *KubernetesClient) describeError(action string, workload models.KubernetesWorkload, err error) error {
+	switch {
+	case apierrors.IsNotFound(err):
+		return models.NewError(404, "Kubernetes", fmt.Sprintf("%s %s/%s not found in cluster %s", workload.Kind,
+			workload.Namespace, workload.Name, workload.Cluster))
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return models.NewError(403, "Kubernetes", fmt.Sprintf("kubeconfig of cluster %s is not allowed to %s: %s",
+			workload.Cluster, action, err.Error()))
+	}
+
+	k.loggerService.Error("kubernetes request failed", map[string]any{
+		"action":   action,
+		"workload": workload,
+		"err":      err.Error(),
+	})
+	return models.NewError(502, "Kubernetes", fmt.Sprintf("failed to %s: %s", action, err.Error()))
+}
+
+// workloadChangedAt returns the time of the latest condition change, the creation time is used when the workload
+// has no conditions yet.
+func workloadChangedAt(createdAt metav1.Time, conditionsTimes ...metav1.Time) time.Time {
+	changedAt := createdAt.Time
+	for _, conditionTime := range conditionsTimes {
+		if conditionTime.After(changedAt) {
+			changedAt = conditionTime.Time
+		}
+	}
+
+	return changedAt
+}
+
+// workloadReplicas reads the desired replicas, kubernetes defaults them to 1 when they are not set.
+func workloadReplicas(replicas *int32) int {
+	if replicas == nil {
+		return 1
+	}
+
+	return int(*replicas)
+}
+
+func deploymentWorkload(cluster string, deployment appsv1.Deployment) DTO.KubernetesWorkload {
+	conditionsTimes := make([]metav1.Time, 0, len(deployment.Status.Conditions))
+	for _, condition := range deployment.Status.Conditions {
+		conditionsTimes = append(conditionsTimes, condition.LastTransitionTime)
+	}
+
+	return DTO.KubernetesWorkload{
+		Cluster:           cluster,
+		Namespace:         deployment.Namespace,
+		Kind:              models.KubernetesKindDeployment,
+		Name:              deployment.Name,
+		Replicas:          workloadReplicas(deployment.Spec.Replicas),
+		ReadyReplicas:     int(deployment.Status.ReadyReplicas),
+		UpdatedReplicas:   int(deployment.Status.UpdatedReplicas),
+		AvailableReplicas: int(deployment.Status.AvailableReplicas),
+		ChangedAt:         workloadChangedAt(deployment.CreationTimestamp, conditionsTimes...),
+	}
+}
+
+func statefulSetWorkload(cluster string, statefulSet appsv1.StatefulSet) DTO.KubernetesWorkload {
+	conditionsTimes := make([]metav1.Time, 0, len(statefulSet.Status.Conditions))
+	for _, condition := range statefulSet.Status.Conditions {
+		conditionsTimes = append(conditionsTimes, condition.LastTransitionTime)
+	}
+
+	return DTO.KubernetesWorkload{
+		Cluster:           cluster,
+		Namespace:         statefulSet.Namespace,
+		Kind:              models.KubernetesKindStatefulSet,
+		Name:              statefulSet.Name,
+		Replicas:          workloadReplicas(statefulSet.Spec.Replicas),
+		ReadyReplicas:     int(statefulSet.Status.ReadyReplicas),
+		UpdatedReplicas:   int(statefulSet.Status.UpdatedReplicas),
+		AvailableReplicas: int(statefulSet.Status.AvailableReplicas),
+		ChangedAt:         workloadChangedAt(statefulSet.CreationTimestamp, conditionsTimes...),
+	}
+}
+
+// ListWorkloads lists the deployments and statefulsets of the namespace, an empty namespace lists all of them.
+func (k *KubernetesClient) ListWorkloads(ctx context.Context, ownerID int, cluster,
+	namespace string,
+) ([]DTO.KubernetesWorkload, error) {
+	clientset, err := k.getClientset(ctx, ownerID, cluster)
+	if err != nil {
+		return nil, err
+	}
+	listedWorkloads := models.KubernetesWorkload{Cluster: cluster, Namespace: namespace, Kind: "workloads"}
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, k.describeError("list deployments", listedWorkloads, err)
+	}
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, k.describeError("list statefulsets", listedWorkloads, err)
+	}
+
+	workloads := make([]DTO.KubernetesWorkload, 0, len(deployments.Items)+len(statefulSets.Items))
+	for _, deployment := range deployments.Items {
+		workloads = append(workloads, deploymentWorkload(cluster, deployment))
+	}
+	for _, statefulSet := range statefulSets.Items {
+		workloads = append(workloads, statefulSetWorkload(cluster, statefulSet))
+	}
+
+	return workloads, nil
+}
+
+func (k *KubernetesClient) GetWorkload(ctx context.Context,
+	workload models.KubernetesWorkload,
+) (DTO.KubernetesWorkload, error) {
+	clientset, err := k.getClientset(ctx, workload.OwnerID, workload.Cluster)
+	if err != nil {
+		return DTO.KubernetesWorkload{}, err
+	}
+
+	switch workload.Kind {
+	case models.KubernetesKindDeployment:
+		deployment, err := clientset.AppsV1().Deployments(workload.Namespace).Get(ctx, workload.Name,
+			metav1.GetOptions{})
+		if err != nil {
+			return DTO.KubernetesWorkload{}, k.describeError("get deployment", workload, err)
+		}
+		return deploymentWorkload(workload.Cluster, *deployment), nil
+	case models.KubernetesKindStatefulSet:
+		statefulSet, err := clientset.AppsV1().StatefulSets(workload.Namespace).Get(ctx, workload.Name,
+			metav1.GetOptions{})
+		if err != nil {
+			return DTO.KubernetesWorkload{}, k.describeError("get statefulset", workload, err)
+		}
+		return statefulSetWorkload(workload.Cluster, *statefulSet), nil
+	}
+
+	return DTO.KubernetesWorkload{}, models.NewError(400, "Kubernetes", "unsupported workload kind "+workload.Kind)
+}
+
+// RestartWorkload does the same as kubectl rollout restart, the pod template annotation is changed, so the
+// workload replaces its pods according to its update strategy.
+func (k *KubernetesClient) RestartWorkload(ctx context.Context, workload models.KubernetesWorkload) error {
+	clientset, err := k.getClientset(ctx, workload.OwnerID, workload.Cluster)
+	if err != nil {
+		return err
+	}
+
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		kubernetesRestartedAtAnnotation, time.Now().Format(time.RFC3339))
+	switch workload.Kind {
+	case models.KubernetesKindDeployment:
+		_, err = clientset.AppsV1().Deployments(workload.Namespace).Patch(ctx, workload.Name,
+			types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	case models.KubernetesKindStatefulSet:
+		_, err = clientset.AppsV1().StatefulSets(workload.Namespace).Patch(ctx, workload.Name,
+			types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	default:
+		return models.NewError(400, "Kubernetes", "unsupported workload kind "+workload.Kind)
+	}
+	if err != nil {
+		return k.describeError("restart workload", workload, err)
+	}
+
+	return nil
+}
+
+func (k *KubernetesClient) workloadSelector(ctx context.Context, clientset kubernetes.Interface,
+	workload models.KubernetesWorkload,
+) (string, error) {
+	var labelSelector *metav1.LabelSelector
+	switch workload.Kind {
+	case models.KubernetesKindDeployment:
+		deployment, err := clientset.AppsV1().Deployments(workload.Namespace).Get(ctx, workload.Name,
+			metav1.GetOptions{})
+		if err != nil {
+			return "", k.describeError("get deployment", workload, err)
+		}
+		labelSelector = deployment.Spec.Selector
+	case models.KubernetesKindStatefulSet:
+		statefulSet, err := clientset.AppsV1().StatefulSets(workload.Namespace).Get(ctx, workload.Name,
+			metav1.GetOptions{})
+		if err != nil {
+			return "", k.describeError("get statefulset", workload, err)
+		}
+		labelSelector = statefulSet.Spec.Selector
+	default:
+		return "", models.NewError(400, "Kubernetes", "unsupported workload kind "+workload.Kind)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return "", models.NewError(500, "Kubernetes", "selector of the workload is invalid: "+err.Error())
+	}
+
+	return selector.String(), nil
+}
+
+// parseLogsTime reads a duration before now, a RFC 3339 time or a unix timestamp, like docker does.
+func parseLogsTime(value string, now time.Time) (time.Time, bool) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), true
+	}
+	if parsedTime, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return parsedTime, true
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(int64(seconds), 0), true
+	}
+
+	return time.Time{}, false
+}
+
+// preparePodLogOptions converts the logs options to the pod logs request. Kubernetes cannot stop the logs at a
+// point in time, so the lines after until are dropped while reading.
+func preparePodLogOptions(logsOptions DTO.LogsOptions, now time.Time) (*corev1.PodLogOptions, time.Time, error) {
+	podLogOptions := &corev1.PodLogOptions{
+		Follow:     logsOptions.Until == "",
+		Timestamps: true,
+	}
+
+	tail := defaultKubernetesLogsTail
+	if logsOptions.Tail != "" && logsOptions.Tail != "all" {
+		parsedTail, err := strconv.ParseInt(logsOptions.Tail, 10, 64)
+		if err != nil || parsedTail < 0 {
+			return nil, time.Time{}, models.NewError(400, "Validation", "tail has to be a number or all")
+		}
+		tail = parsedTail
+	}
+	if logsOptions.Tail != "all" {
+		podLogOptions.TailLines = &tail
+	}
+
+	if logsOptions.Since != "" {
+		since, ok := parseLogsTime(logsOptions.Since, now)
+		if !ok {
+			return nil, time.Time{}, models.NewError(400, "Validation", "since is not a valid time")
+		}
+		sinceTime := metav1.NewTime(since)
+		podLogOptions.SinceTime = &sinceTime
+	}
+
+	var until time.Time
+	if logsOptions.Until != "" {
+		var ok bool
+		until, ok = parseLogsTime(logsOptions.Until, now)
+		if !ok {
+			return nil, time.Time{}, models.NewError(400, "Validation", "until is not a valid time")
+		}
+	}
+
+	return podLogOptions, until, nil
+}
+
+// parsePodLogLine splits the timestamp added by kubernetes from the line. Kubernetes does not keep stdout and
+// stderr apart, so every line is reported as stdout.
+func parsePodLogLine(line, source string) DTO.LogLine {
+	line = strings.ToValidUTF8(strings.TrimSuffix(line, "\r"), "")
+	logLine := DTO.LogLine{
+		Stream: "stdout",
+		Source: source,
+		Line:   line,
+	}
+	if timestamp, text, found := strings.Cut(line, " "); found {
+		if parsedTimestamp, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+			logLine.Timestamp = parsedTimestamp
+			logLine.Line = text
+		}
+	}
+
+	return logLine
+}
+
+// StreamPodLogs sends the logs of every container of the workload pods. The pods are listed once, pods created
+// later, e.g. by a rollout, are not followed. A failed stream of a single container does not stop the others.
+func (k *KubernetesClient) StreamPodLogs(ctx context.Context, workload models.KubernetesWorkload,
+	logsOptions DTO.LogsOptions, send func(DTO.LogLine) error,
+) error {
+	podLogOptions, until, err := preparePodLogOptions(logsOptions, time.Now())
+	if err != nil {
+		return err
+	}
+
+	clientset, err := k.getClientset(ctx, workload.OwnerID, workload.Cluster)
+	if err != nil {
+		return err
+	}
+	selector, err := k.workloadSelector(ctx, clientset, workload)
+	if err != nil {
+		return err
+	}
+	pods, err := clientset.CoreV1().Pods(workload.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return k.describeError("list pods", workload, err)
+	}
+	if len(pods.Items) == 0 {
+		return models.NewError(404, "Kubernetes", "workload has no pods")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var sendMu sync.Mutex
+	var sendErr error
+	var wg sync.WaitGroup
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			containerLogOptions := *podLogOptions
+			containerLogOptions.Container = container.Name
+			source := pod.Name + "/" + container.Name
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				stream, err := clientset.CoreV1().Pods(workload.Namespace).GetLogs(pod.Name,
+					&containerLogOptions).Stream(ctx)
+				if err != nil {
+					k.loggerService.Warn("failed to stream pod logs", map[string]any{
+						"source": source,
+						"err":    err.Error(),
+					})
+					return
+				}
+				defer stream.Close()
+
+				scanner := bufio.NewScanner(stream)
+				scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+				for scanner.Scan() {
+					logLine := parsePodLogLine(scanner.Text(), source)
+					if !until.IsZero() && logLine.Timestamp.After(until) {
+						continue
+					}
+
+					sendMu.Lock()
+					if sendErr == nil {
+						sendErr = send(logLine)
+					}
+					failed := sendErr != nil
+					sendMu.Unlock()
+					if failed {
+						cancel()
+						return
+					}
+				}
+				if err := scanner.Err(); err != nil && ctx.Err() == nil {
+					k.loggerService.Info("Pod logs stream stopped", map[string]any{
+						"source": source,
+						"err":    err.Error(),
+					})
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	return sendErr
+}
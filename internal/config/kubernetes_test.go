@@ -0,0 +1,263 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeKubernetesRepository struct {
+	cluster *models.KubernetesCluster
+}
+
+func (fr *fakeKubernetesRepository) GetKubernetesCluster(ctx context.Context, ownerID int,
+	name string,
+) (*models.KubernetesCluster, error) {
+	return fr.cluster, nil
+}
+
+func (fr *fakeKubernetesRepository) UpsertKubernetesCluster(ctx context.Context,
+	cluster models.KubernetesCluster,
+) error {
+	return nil
+}
+
+func (fr *fakeKubernetesRepository) ImportKubernetesWorkloads(ctx context.Context, apps []DTO.App,
+	workloads []models.KubernetesWorkload,
+) error {
+	return nil
+}
+
+func (fr *fakeKubernetesRepository) GetKubernetesWorkload(ctx context.Context,
+	appID string,
+) (*models.KubernetesWorkload, error) {
+	return nil, nil
+}
+
+func (fr *fakeKubernetesRepository) GetKubernetesWorkloads(ctx context.Context) ([]models.KubernetesWorkload, error) {
+	return nil, nil
+}
+
+func int32Pointer(value int32) *int32 {
+	return &value
+}
+
+func newFakeKubernetesClient(t *testing.T, objects ...runtime.Object) (*KubernetesClient, *fake.Clientset) {
+	loggerService := utils.NewLogger(t.TempDir(), "2006-01-02 15:04:05")
+	loggerService.InitializeLogger()
+	t.Cleanup(func() {
+		_ = loggerService.Close()
+	})
+
+	clientset := fake.NewClientset(objects...)
+	kubernetesClient := NewKubernetesClient(loggerService, &fakeKubernetesRepository{}, "test-key")
+	kubernetesClient.clientsets[kubernetesClusterKey{ownerID: 1, name: "k3s"}] = clientset
+
+	return kubernetesClient, clientset
+}
+
+func kubernetesTestObjects(changedAt time.Time) []runtime.Object {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}
+	return []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Pointer(3), Selector: selector},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 2, UpdatedReplicas: 3, AvailableReplicas: 2,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, LastTransitionTime: metav1.NewTime(changedAt)},
+				}},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "data"},
+			Spec:       appsv1.StatefulSetSpec{Selector: &metav1.LabelSelector{}},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "default", Labels: map[string]string{"app": "api"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "api"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default", Labels: map[string]string{"app": "web"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web"}}},
+		},
+	}
+}
+
+func TestKubernetesClient_ListWorkloads(t *testing.T) {
+	changedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	type args struct {
+		name              string
+		namespace         string
+		expectedWorkloads []DTO.KubernetesWorkload
+	}
+	testsScenarios := []args{
+		{
+			name: "All namespaces",
+			expectedWorkloads: []DTO.KubernetesWorkload{
+				{Cluster: "k3s", Namespace: "default", Kind: models.KubernetesKindDeployment, Name: "api", Replicas: 3,
+					ReadyReplicas: 2, UpdatedReplicas: 3, AvailableReplicas: 2, ChangedAt: changedAt},
+				{Cluster: "k3s", Namespace: "data", Kind: models.KubernetesKindStatefulSet, Name: "db", Replicas: 1,
+					ReadyReplicas: 1},
+			},
+		},
+		{
+			name:      "Single namespace",
+			namespace: "data",
+			expectedWorkloads: []DTO.KubernetesWorkload{
+				{Cluster: "k3s", Namespace: "data", Kind: models.KubernetesKindStatefulSet, Name: "db", Replicas: 1,
+					ReadyReplicas: 1},
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			kubernetesClient, _ := newFakeKubernetesClient(t, kubernetesTestObjects(changedAt)...)
+			workloads, err := kubernetesClient.ListWorkloads(context.Background(), 1, "k3s", testScenario.namespace)
+			assert.NoError(t, err)
+			for i := range workloads {
+				workloads[i].ChangedAt = workloads[i].ChangedAt.UTC()
+			}
+			assert.Equal(t, testScenario.expectedWorkloads, workloads)
+		})
+	}
+}
+
+func TestKubernetesClient_GetWorkload(t *testing.T) {
+	type args struct {
+		name                  string
+		workload              models.KubernetesWorkload
+		expectedReadyReplicas int
+		expectedError         error
+	}
+	testsScenarios := []args{
+		{
+			name: "Deployment",
+			workload: models.KubernetesWorkload{OwnerID: 1, Cluster: "k3s", Namespace: "default",
+				Kind: models.KubernetesKindDeployment, Name: "api"},
+			expectedReadyReplicas: 2,
+		},
+		{
+			name: "Removed statefulset",
+			workload: models.KubernetesWorkload{OwnerID: 1, Cluster: "k3s", Namespace: "default",
+				Kind: models.KubernetesKindStatefulSet, Name: "cache"},
+			expectedError: errors.New("StatefulSet default/cache not found in cluster k3s"),
+		},
+		{
+			name: "Cluster not registered",
+			workload: models.KubernetesWorkload{OwnerID: 1, Cluster: "prod", Namespace: "default",
+				Kind: models.KubernetesKindDeployment, Name: "api"},
+			expectedError: errors.New("kubernetes cluster prod not found"),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			kubernetesClient, _ := newFakeKubernetesClient(t, kubernetesTestObjects(time.Now())...)
+			workload, err := kubernetesClient.GetWorkload(context.Background(), testScenario.workload)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, testScenario.expectedReadyReplicas, workload.ReadyReplicas)
+			} else {
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+		})
+	}
+}
+
+func TestKubernetesClient_RestartWorkload(t *testing.T) {
+	kubernetesClient, clientset := newFakeKubernetesClient(t, kubernetesTestObjects(time.Now())...)
+	err := kubernetesClient.RestartWorkload(context.Background(), models.KubernetesWorkload{OwnerID: 1, Cluster: "k3s",
+		Namespace: "default", Kind: models.KubernetesKindDeployment, Name: "api"})
+	assert.NoError(t, err)
+
+	deployment, err := clientset.AppsV1().Deployments("default").Get(context.Background(), "api",
+		metav1.GetOptions{})
+	assert.NoError(t, err)
+	restartedAt, err := time.Parse(time.RFC3339,
+		deployment.Spec.Template.Annotations[kubernetesRestartedAtAnnotation])
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), restartedAt, time.Minute)
+	assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+}
+
+func TestKubernetesClient_StreamPodLogs(t *testing.T) {
+	kubernetesClient, _ := newFakeKubernetesClient(t, kubernetesTestObjects(time.Now())...)
+	logLines := make([]DTO.LogLine, 0)
+	err := kubernetesClient.StreamPodLogs(context.Background(), models.KubernetesWorkload{OwnerID: 1, Cluster: "k3s",
+		Namespace: "default", Kind: models.KubernetesKindDeployment, Name: "api"}, DTO.LogsOptions{},
+		func(logLine DTO.LogLine) error {
+			logLines = append(logLines, logLine)
+			return nil
+		})
+	assert.NoError(t, err)
+	// The fake clientset returns the same logs for every container.
+	assert.Equal(t, []DTO.LogLine{{Stream: "stdout", Line: "fake logs", Source: "api-0/api"}}, logLines)
+}
+
+func TestPreparePodLogOptions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	type args struct {
+		name              string
+		logsOptions       DTO.LogsOptions
+		expectedTail      *int64
+		expectedFollow    bool
+		expectedSinceTime time.Time
+		expectedUntil     time.Time
+		expectedError     error
+	}
+	defaultTail := defaultKubernetesLogsTail
+	tail := int64(20)
+	testsScenarios := []args{
+		{
+			name:           "Default options",
+			expectedTail:   &defaultTail,
+			expectedFollow: true,
+		},
+		{
+			name:              "Relative since",
+			logsOptions:       DTO.LogsOptions{Tail: "20", Since: "10m"},
+			expectedTail:      &tail,
+			expectedFollow:    true,
+			expectedSinceTime: now.Add(-10 * time.Minute),
+		},
+		{
+			name:          "Until stops following",
+			logsOptions:   DTO.LogsOptions{Tail: "all", Until: "2025-01-01T09:00:00Z"},
+			expectedUntil: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Invalid tail",
+			logsOptions:   DTO.LogsOptions{Tail: "many"},
+			expectedError: errors.New("tail has to be a number or all"),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			podLogOptions, until, err := preparePodLogOptions(testScenario.logsOptions, now)
+			if testScenario.expectedError != nil {
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, podLogOptions.Timestamps)
+			assert.Equal(t, testScenario.expectedTail, podLogOptions.TailLines)
+			assert.Equal(t, testScenario.expectedFollow, podLogOptions.Follow)
+			if testScenario.expectedSinceTime.IsZero() {
+				assert.Nil(t, podLogOptions.SinceTime)
+			} else {
+				assert.True(t, testScenario.expectedSinceTime.Equal(podLogOptions.SinceTime.Time))
+			}
+			assert.True(t, testScenario.expectedUntil.Equal(until))
+		})
+	}
+}
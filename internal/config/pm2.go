@@ -0,0 +1,116 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type pm2JlistProcess struct {
+	Name  string `json:"name"`
+	PmID  int    `json:"pm_id"`
+	PID   int    `json:"pid"`
+	Monit struct {
+		Memory uint64  `json:"memory"`
+		CPU    float64 `json:"cpu"`
+	} `json:"monit"`
+	Pm2Env struct {
+		Status           string         `json:"status"`
+		RestartTime      int            `json:"restart_time"`
+		UnstableRestarts int            `json:"unstable_restarts"`
+		PmUptime         int64          `json:"pm_uptime"`
+		Env              map[string]any `json:"env"`
+	} `json:"pm2_env"`
+}
+
+// Pm2Client controls the PM2 daemon of the current user through the pm2 binary.
+type Pm2Client struct {
+	binary        string
+	loggerService utils.LoggerService
+}
+
+func NewPm2Client(binary string, loggerService utils.LoggerService) *Pm2Client {
+	return &Pm2Client{
+		binary:        binary,
+		loggerService: loggerService,
+	}
+}
+
+// parsePm2Processes reads the output of pm2 jlist. PM2 may print warnings, e.g. about an outdated daemon, before
+// the JSON, so everything before the list is skipped.
+func parsePm2Processes(output []byte) ([]DTO.Pm2Process, error) {
+	start := bytes.IndexByte(output, '[')
+	if start == -1 {
+		return nil, fmt.Errorf("pm2 jlist output does not contain a process list")
+	}
+
+	var jlistProcesses []pm2JlistProcess
+	if err := json.Unmarshal(output[start:], &jlistProcesses); err != nil {
+		return nil, err
+	}
+
+	processes := make([]DTO.Pm2Process, 0, len(jlistProcesses))
+	for _, jlistProcess := range jlistProcesses {
+		process := DTO.Pm2Process{
+			Name:             jlistProcess.Name,
+			PmID:             jlistProcess.PmID,
+			PID:              jlistProcess.PID,
+			Status:           jlistProcess.Pm2Env.Status,
+			RestartCount:     jlistProcess.Pm2Env.RestartTime,
+			UnstableRestarts: jlistProcess.Pm2Env.UnstableRestarts,
+			MemoryBytes:      jlistProcess.Monit.Memory,
+			CPUPercent:       jlistProcess.Monit.CPU,
+		}
+		if jlistProcess.Pm2Env.PmUptime > 0 {
+			process.StartedAt = time.UnixMilli(jlistProcess.Pm2Env.PmUptime).UTC()
+		}
+		switch port := jlistProcess.Pm2Env.Env["PORT"].(type) {
+		case string:
+			process.Port = port
+		case float64:
+			process.Port = strconv.Itoa(int(port))
+		}
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+func (p *Pm2Client) ListProcesses(ctx context.Context) ([]DTO.Pm2Process, error) {
+	output, err := exec.CommandContext(ctx, p.binary, "jlist").Output()
+	if err != nil {
+		p.loggerService.Error("failed to list pm2 processes", err)
+		return nil, models.NewError(500, "Pm2", "failed to list pm2 processes")
+	}
+
+	processes, err := parsePm2Processes(output)
+	if err != nil {
+		p.loggerService.Error("failed to parse pm2 processes", err)
+		return nil, models.NewError(500, "Pm2", "failed to list pm2 processes")
+	}
+
+	return processes, nil
+}
+
+func (p *Pm2Client) RunAction(ctx context.Context, action, processName string) error {
+	output, err := exec.CommandContext(ctx, p.binary, action, processName).CombinedOutput()
+	if err != nil {
+		p.loggerService.Error("failed to run pm2 action", map[string]any{
+			"action":  action,
+			"process": processName,
+			"output":  string(output),
+			"err":     err.Error(),
+		})
+		return models.NewError(500, "Pm2", fmt.Sprintf("failed to %s pm2 process", action))
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePm2Processes(t *testing.T) {
+	type args struct {
+		name              string
+		output            string
+		expectedProcesses []DTO.Pm2Process
+		expectedError     bool
+	}
+	testsScenarios := []args{
+		{
+			name: "Processes with a warning before the list",
+			output: `>>>> In-memory PM2 is out-of-date, do:
+[{"name":"api","pm_id":0,"pid":4021,"monit":{"memory":52428800,"cpu":1.5},
+"pm2_env":{"status":"online","restart_time":2,"unstable_restarts":1,"pm_uptime":1735689600000,
+"env":{"PORT":"3000"}}},
+{"name":"worker","pm_id":1,"pid":0,"monit":{"memory":0,"cpu":0},
+"pm2_env":{"status":"stopped","restart_time":0,"unstable_restarts":0,"env":{"PORT":8080}}}]`,
+			expectedProcesses: []DTO.Pm2Process{
+				{
+					Name:             "api",
+					PmID:             0,
+					PID:              4021,
+					Status:           "online",
+					Port:             "3000",
+					RestartCount:     2,
+					UnstableRestarts: 1,
+					MemoryBytes:      52428800,
+					CPUPercent:       1.5,
+					StartedAt:        time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+				{
+					Name:   "worker",
+					PmID:   1,
+					Status: "stopped",
+					Port:   "8080",
+				},
+			},
+		},
+		{
+			name:              "No processes",
+			output:            "[]",
+			expectedProcesses: []DTO.Pm2Process{},
+		},
+		{
+			name:          "Not a process list",
+			output:        "command not found",
+			expectedError: true,
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			processes, err := parsePm2Processes([]byte(testScenario.output))
+			if testScenario.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testScenario.expectedProcesses, processes)
+		})
+	}
+}
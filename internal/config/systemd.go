@@ -0,0 +1,370 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+const (
+	systemdBusName       = "org.freedesktop.systemd1"
+	systemdObjectPath    = "/org/freedesktop/systemd1"
+	systemdManager       = "org.freedesktop.systemd1.Manager"
+	systemdUnitInterface = "org.freedesktop.systemd1.Unit"
+	systemdService       = "org.freedesktop.systemd1.Service"
+)
+
+// systemdShowProperties are read by systemctl show when D-Bus is not available.
+var systemdShowProperties = []string{
+	"Id", "Description", "LoadState", "ActiveState", "SubState", "MainPID", "NRestarts", "MemoryCurrent",
+	"ActiveEnterTimestamp",
+}
+
+var systemdActionsMethods = map[string]string{
+	"start":   "StartUnit",
+	"stop":    "StopUnit",
+	"restart": "RestartUnit",
+}
+
+type dbusUnit struct {
+	Name        string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+	Followed    string
+	Path        dbus.ObjectPath
+	JobID       uint32
+	JobType     string
+	JobPath     dbus.ObjectPath
+}
+
+type systemctlUnit struct {
+	Unit        string `json:"unit"`
+	Load        string `json:"load"`
+	Active      string `json:"active"`
+	Sub         string `json:"sub"`
+	Description string `json:"description"`
+}
+
+// SystemdClient manages the units of the local systemd over the system D-Bus. When the bus is not reachable it
+// falls back to systemctl. The journal is always read with journalctl.
+type SystemdClient struct {
+	loggerService utils.LoggerService
+}
+
+func NewSystemdClient(loggerService utils.LoggerService) *SystemdClient {
+	return &SystemdClient{
+		loggerService: loggerService,
+	}
+}
+
+func (s *SystemdClient) connect(ctx context.Context) (*dbus.Conn, error) {
+	return dbus.ConnectSystemBus(dbus.WithContext(ctx))
+}
+
+func (s *SystemdClient) closeConnection(conn *dbus.Conn) {
+	if err := conn.Close(); err != nil {
+		s.loggerService.Warn("failed to close D-Bus connection", err)
+	}
+}
+
+func (s *SystemdClient) ListUnits(ctx context.Context, pattern string) ([]DTO.SystemdUnit, error) {
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return s.listUnitsWithSystemctl(ctx, pattern)
+	}
+	defer s.closeConnection(conn)
+
+	var dbusUnits []dbusUnit
+	err = conn.Object(systemdBusName, systemdObjectPath).CallWithContext(ctx, systemdManager+".ListUnitsByPatterns", 0,
+		[]string{}, []string{pattern}).Store(&dbusUnits)
+	if err != nil {
+		s.loggerService.Error("failed to list systemd units", err)
+		return nil, models.NewError(500, "Systemd", "failed to list systemd units")
+	}
+
+	units := make([]DTO.SystemdUnit, 0, len(dbusUnits))
+	for _, unit := range dbusUnits {
+		units = append(units, DTO.SystemdUnit{
+			Name:        unit.Name,
+			Description: unit.Description,
+			LoadState:   unit.LoadState,
+			ActiveState: unit.ActiveState,
+			SubState:    unit.SubState,
+		})
+	}
+
+	return units, nil
+}
+
+func (s *SystemdClient) listUnitsWithSystemctl(ctx context.Context, pattern string) ([]DTO.SystemdUnit, error) {
+	output, err := exec.CommandContext(ctx, "systemctl", "list-units", "--all", "--output=json", "--no-pager", "--",
+		pattern).Output()
+	if err != nil {
+		s.loggerService.Error("failed to list systemd units", err)
+		return nil, models.NewError(500, "Systemd", "failed to list systemd units")
+	}
+
+	units, err := parseSystemctlUnits(output)
+	if err != nil {
+		s.loggerService.Error("failed to parse systemd units", err)
+		return nil, models.NewError(500, "Systemd", "failed to list systemd units")
+	}
+
+	return units, nil
+}
+
+func parseSystemctlUnits(output []byte) ([]DTO.SystemdUnit, error) {
+	var systemctlUnits []systemctlUnit
+	if err := json.Unmarshal(output, &systemctlUnits); err != nil {
+		return nil, err
+	}
+
+	units := make([]DTO.SystemdUnit, 0, len(systemctlUnits))
+	for _, unit := range systemctlUnits {
+		units = append(units, DTO.SystemdUnit{
+			Name:        unit.Unit,
+			Description: unit.Description,
+			LoadState:   unit.Load,
+			ActiveState: unit.Active,
+			SubState:    unit.Sub,
+		})
+	}
+
+	return units, nil
+}
+
+func (s *SystemdClient) GetUnit(ctx context.Context, unitName string) (DTO.SystemdUnit, error) {
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return s.getUnitWithSystemctl(ctx, unitName)
+	}
+	defer s.closeConnection(conn)
+
+	var unitPath dbus.ObjectPath
+	err = conn.Object(systemdBusName, systemdObjectPath).CallWithContext(ctx, systemdManager+".LoadUnit", 0,
+		unitName).Store(&unitPath)
+	if err != nil {
+		s.loggerService.Error("failed to load systemd unit", map[string]any{
+			"unit": unitName,
+			"err":  err.Error(),
+		})
+		return DTO.SystemdUnit{}, models.NewError(500, "Systemd", "failed to read systemd unit")
+	}
+
+	unitObject := conn.Object(systemdBusName, unitPath)
+	var unitProperties map[string]dbus.Variant
+	err = unitObject.CallWithContext(ctx, "org.freedesktop.DBus.Properties.GetAll", 0,
+		systemdUnitInterface).Store(&unitProperties)
+	if err != nil {
+		s.loggerService.Error("failed to read systemd unit properties", map[string]any{
+			"unit": unitName,
+			"err":  err.Error(),
+		})
+		return DTO.SystemdUnit{}, models.NewError(500, "Systemd", "failed to read systemd unit")
+	}
+	// Only services have a main process and restarts, other units are read without them.
+	var serviceProperties map[string]dbus.Variant
+	_ = unitObject.CallWithContext(ctx, "org.freedesktop.DBus.Properties.GetAll", 0,
+		systemdService).Store(&serviceProperties)
+
+	unit := DTO.SystemdUnit{Name: unitName}
+	_ = unitProperties["Description"].Store(&unit.Description)
+	_ = unitProperties["LoadState"].Store(&unit.LoadState)
+	_ = unitProperties["ActiveState"].Store(&unit.ActiveState)
+	_ = unitProperties["SubState"].Store(&unit.SubState)
+	var activeEnterTimestamp uint64
+	if unitProperties["ActiveEnterTimestamp"].Store(&activeEnterTimestamp) == nil && activeEnterTimestamp > 0 {
+		unit.StartedAt = time.UnixMicro(int64(activeEnterTimestamp)).UTC()
+	}
+	var mainPID, restartCount uint32
+	if serviceProperties["MainPID"].Store(&mainPID) == nil {
+		unit.MainPID = int(mainPID)
+	}
+	if serviceProperties["NRestarts"].Store(&restartCount) == nil {
+		unit.RestartCount = int(restartCount)
+	}
+	var memoryCurrent uint64
+	// systemd reports the maximum value when the memory accounting is disabled.
+	if serviceProperties["MemoryCurrent"].Store(&memoryCurrent) == nil && memoryCurrent != math.MaxUint64 {
+		unit.MemoryBytes = memoryCurrent
+	}
+
+	return unit, nil
+}
+
+func (s *SystemdClient) getUnitWithSystemctl(ctx context.Context, unitName string) (DTO.SystemdUnit, error) {
+	output, err := exec.CommandContext(ctx, "systemctl", "show", "--no-pager", "--timestamp=unix",
+		"--property="+strings.Join(systemdShowProperties, ","), "--", unitName).Output()
+	if err != nil {
+		s.loggerService.Error("failed to read systemd unit", map[string]any{
+			"unit": unitName,
+			"err":  err.Error(),
+		})
+		return DTO.SystemdUnit{}, models.NewError(500, "Systemd", "failed to read systemd unit")
+	}
+
+	unit := parseSystemctlShow(output)
+	unit.Name = unitName
+
+	return unit, nil
+}
+
+// parseSystemctlShow reads the key=value output of systemctl show. Values which are not set are left empty.
+func parseSystemctlShow(output []byte) DTO.SystemdUnit {
+	var unit DTO.SystemdUnit
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "Id":
+			unit.Name = value
+		case "Description":
+			unit.Description = value
+		case "LoadState":
+			unit.LoadState = value
+		case "ActiveState":
+			unit.ActiveState = value
+		case "SubState":
+			unit.SubState = value
+		case "MainPID":
+			unit.MainPID, _ = strconv.Atoi(value)
+		case "NRestarts":
+			unit.RestartCount, _ = strconv.Atoi(value)
+		case "MemoryCurrent":
+			// Older systemctl prints the maximum value instead of [not set].
+			if memoryCurrent, err := strconv.ParseUint(value, 10, 64); err == nil && memoryCurrent != math.MaxUint64 {
+				unit.MemoryBytes = memoryCurrent
+			}
+		case "ActiveEnterTimestamp":
+			if seconds, err := strconv.ParseInt(strings.TrimPrefix(value, "@"), 10, 64); err == nil && seconds > 0 {
+				unit.StartedAt = time.Unix(seconds, 0).UTC()
+			}
+		}
+	}
+
+	return unit
+}
+
+func (s *SystemdClient) RunAction(ctx context.Context, action, unitName string) error {
+	method, ok := systemdActionsMethods[action]
+	if !ok {
+		return models.NewError(400, "Systemd", fmt.Sprintf("unsupported systemd action %s", action))
+	}
+
+	conn, err := s.connect(ctx)
+	if err != nil {
+		output, err := exec.CommandContext(ctx, "systemctl", action, "--", unitName).CombinedOutput()
+		if err != nil {
+			s.loggerService.Error("failed to run systemd action", map[string]any{
+				"action": action,
+				"unit":   unitName,
+				"output": string(output),
+				"err":    err.Error(),
+			})
+			return models.NewError(500, "Systemd", fmt.Sprintf("failed to %s systemd unit", action))
+		}
+		return nil
+	}
+	defer s.closeConnection(conn)
+
+	var jobPath dbus.ObjectPath
+	err = conn.Object(systemdBusName, systemdObjectPath).CallWithContext(ctx, systemdManager+"."+method, 0,
+		unitName, "replace").Store(&jobPath)
+	if err != nil {
+		s.loggerService.Error("failed to run systemd action", map[string]any{
+			"action": action,
+			"unit":   unitName,
+			"err":    err.Error(),
+		})
+		return models.NewError(500, "Systemd", fmt.Sprintf("failed to %s systemd unit", action))
+	}
+
+	return nil
+}
+
+// journalTime converts the since and until values accepted by the docker logs to the format of journalctl. Go
+// durations are relative to now.
+func journalTime(value string, now time.Time) string {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return fmt.Sprintf("@%d", now.Add(-duration).Unix())
+	}
+	if parsedTime, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return fmt.Sprintf("@%d", parsedTime.Unix())
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return fmt.Sprintf("@%d", int64(seconds))
+	}
+
+	return value
+}
+
+func prepareJournalArgs(unitName string, logsOptions DTO.LogsOptions, now time.Time) []string {
+	args := []string{"--unit=" + unitName, "--output=json", "--no-pager"}
+	tail := logsOptions.Tail
+	if tail == "" {
+		tail = "100"
+	}
+	args = append(args, "--lines="+tail)
+	if logsOptions.Since != "" {
+		args = append(args, "--since="+journalTime(logsOptions.Since, now))
+	}
+	// The journal ends on its own when it is read up to a point in time.
+	if logsOptions.Until != "" {
+		args = append(args, "--until="+journalTime(logsOptions.Until, now))
+	} else {
+		args = append(args, "--follow")
+	}
+
+	return args
+}
+
+type commandReadCloser struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (c *commandReadCloser) Close() error {
+	err := c.ReadCloser.Close()
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	_ = c.cmd.Wait()
+
+	return err
+}
+
+// FollowJournal returns the journal entries of the unit as JSON lines.
+func (s *SystemdClient) FollowJournal(ctx context.Context, unitName string,
+	logsOptions DTO.LogsOptions,
+) (io.ReadCloser, error) {
+	cmd := exec.CommandContext(ctx, "journalctl", prepareJournalArgs(unitName, logsOptions, time.Now())...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		s.loggerService.Error("failed to read systemd journal", err)
+		return nil, models.NewError(500, "Systemd", "failed to read systemd journal")
+	}
+	if err := cmd.Start(); err != nil {
+		s.loggerService.Error("failed to read systemd journal", err)
+		return nil, models.NewError(500, "Systemd", "failed to read systemd journal")
+	}
+
+	return &commandReadCloser{ReadCloser: stdout, cmd: cmd}, nil
+}
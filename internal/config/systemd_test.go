@@ -0,0 +1,101 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSystemctlUnits(t *testing.T) {
+	output := `[{"unit":"octopus-api.service","load":"loaded","active":"active","sub":"running",
+"description":"Octopus API"},{"unit":"octopus-old.service","load":"not-found","active":"inactive","sub":"dead",
+"description":"octopus-old.service"}]`
+	units, err := parseSystemctlUnits([]byte(output))
+	assert.NoError(t, err)
+	assert.Equal(t, []DTO.SystemdUnit{
+		{Name: "octopus-api.service", Description: "Octopus API", LoadState: "loaded", ActiveState: "active",
+			SubState: "running"},
+		{Name: "octopus-old.service", Description: "octopus-old.service", LoadState: "not-found",
+			ActiveState: "inactive", SubState: "dead"},
+	}, units)
+}
+
+func TestParseSystemctlShow(t *testing.T) {
+	type args struct {
+		name         string
+		output       string
+		expectedUnit DTO.SystemdUnit
+	}
+	testsScenarios := []args{
+		{
+			name: "Running service",
+			output: `Id=nginx.service
+Description=A high performance web server
+LoadState=loaded
+ActiveState=active
+SubState=running
+MainPID=812
+NRestarts=2
+MemoryCurrent=10485760
+ActiveEnterTimestamp=@1735725600
+`,
+			expectedUnit: DTO.SystemdUnit{Name: "nginx.service", Description: "A high performance web server",
+				LoadState: "loaded", ActiveState: "active", SubState: "running", MainPID: 812, RestartCount: 2,
+				MemoryBytes: 10485760, StartedAt: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "Stopped service without memory accounting",
+			output: `Id=worker.service
+ActiveState=inactive
+MainPID=0
+MemoryCurrent=[not set]
+ActiveEnterTimestamp=
+`,
+			expectedUnit: DTO.SystemdUnit{Name: "worker.service", ActiveState: "inactive"},
+		},
+		{
+			name: "Memory which is not set printed as the maximum value",
+			output: `Id=worker.service
+ActiveState=inactive
+MemoryCurrent=18446744073709551615
+`,
+			expectedUnit: DTO.SystemdUnit{Name: "worker.service", ActiveState: "inactive"},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			assert.Equal(t, testScenario.expectedUnit, parseSystemctlShow([]byte(testScenario.output)))
+		})
+	}
+}
+
+func TestPrepareJournalArgs(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	type args struct {
+		name         string
+		logsOptions  DTO.LogsOptions
+		expectedArgs []string
+	}
+	testsScenarios := []args{
+		{
+			name:        "Follow with default tail",
+			logsOptions: DTO.LogsOptions{},
+			expectedArgs: []string{"--unit=api.service", "--output=json", "--no-pager", "--lines=100",
+				"--follow"},
+		},
+		{
+			name:        "Relative since and RFC3339 until",
+			logsOptions: DTO.LogsOptions{Tail: "all", Since: "10m", Until: "2025-01-01T10:00:00Z"},
+			expectedArgs: []string{"--unit=api.service", "--output=json", "--no-pager", "--lines=all",
+				"--since=@1735725000", "--until=@1735725600"},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			assert.Equal(t, testScenario.expectedArgs, prepareJournalArgs("api.service", testScenario.logsOptions,
+				now))
+		})
+	}
+}
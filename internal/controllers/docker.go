@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/utils"
 	"github.com/slodkiadrianek/octopus/internal/utils/request"
 	"github.com/slodkiadrianek/octopus/internal/utils/response"
@@ -16,6 +17,10 @@ type dockerService interface {
 	UnpauseContainer(ctx context.Context, appID string) error
 	StopContainer(ctx context.Context, appID string) error
 	ImportContainers(ctx context.Context, ownerID int) error
+	CreateContainer(ctx context.Context, containerToCreate DTO.CreateContainer, ownerID int) (string, error)
+	RemoveContainer(ctx context.Context, appID string, ownerID int, removeVolumes bool) error
+	RenameContainer(ctx context.Context, appID string, ownerID int, name string) error
+	RecreateContainer(ctx context.Context, appID string, ownerID int, image string) (string, error)
 }
 
 type DockerController struct {
@@ -131,3 +136,114 @@ func (dc *DockerController) ImportDockerContainers(w http.ResponseWriter, r *htt
 
 	response.Send(w, 201, map[string]any{})
 }
+
+func (dc *DockerController) CreateContainer(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		dc.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	containerToCreate, err := request.ReadBody[DTO.CreateContainer](r)
+	if err != nil {
+		dc.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := dc.dockerService.CreateContainer(r.Context(), *containerToCreate, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 201, map[string]string{"appID": appID})
+}
+
+func (dc *DockerController) RemoveContainer(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		dc.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		dc.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	removeVolumes := request.ReadQueryParam(r, "volumes") == "true"
+	err = dc.dockerService.RemoveContainer(r.Context(), appID, ownerID, removeVolumes)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (dc *DockerController) RenameContainer(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		dc.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		dc.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	containerToRename, err := request.ReadBody[DTO.RenameContainer](r)
+	if err != nil {
+		dc.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = dc.dockerService.RenameContainer(r.Context(), appID, ownerID, containerToRename.Name)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (dc *DockerController) RecreateContainer(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		dc.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		dc.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	containerToRecreate, err := request.ReadBody[DTO.RecreateContainer](r)
+	if err != nil {
+		dc.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	newAppID, err := dc.dockerService.RecreateContainer(r.Context(), appID, ownerID, containerToRecreate.Image)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, map[string]string{"appID": newAppID})
+}
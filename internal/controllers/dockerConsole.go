@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type dockerConsoleService interface {
+	Console(ctx context.Context, appID string, ownerID int, shell string, conn *websocket.Conn)
+}
+
+type DockerConsoleController struct {
+	dockerConsoleService dockerConsoleService
+	loggerService        utils.LoggerService
+}
+
+func NewDockerConsoleController(dockerConsoleService dockerConsoleService, loggerService utils.LoggerService,
+) *DockerConsoleController {
+	return &DockerConsoleController{
+		dockerConsoleService: dockerConsoleService,
+		loggerService:        loggerService,
+	}
+}
+
+func (dc *DockerConsoleController) Console(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		dc.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		dc.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	shell := request.ReadQueryParam(r, "shell")
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		dc.loggerService.Error("failed to upgrade connection", err)
+		return
+	}
+	defer conn.Close()
+
+	dc.dockerConsoleService.Console(context.Background(), appID, ownerID, shell, conn)
+}
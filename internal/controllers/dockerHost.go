@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type dockerHostService interface {
+	SaveDockerHost(ctx context.Context, dockerHost DTO.DockerHost) error
+}
+
+type DockerHostController struct {
+	dockerHostService dockerHostService
+	loggerService     utils.LoggerService
+}
+
+func NewDockerHostController(dockerHostService dockerHostService, loggerService utils.LoggerService,
+) *DockerHostController {
+	return &DockerHostController{
+		dockerHostService: dockerHostService,
+		loggerService:     loggerService,
+	}
+}
+
+func (dh *DockerHostController) SaveDockerHost(w http.ResponseWriter, r *http.Request) {
+	dockerHost, err := request.ReadBody[DTO.DockerHost](r)
+	if err != nil {
+		dh.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = dh.dockerHostService.SaveDockerHost(r.Context(), *dockerHost)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]string{})
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type dockerImageService interface {
+	ListImages(ctx context.Context) ([]DTO.DockerImage, error)
+	PullImage(ctx context.Context, image string, conn *websocket.Conn)
+	RemoveImage(ctx context.Context, imageID string, force bool) error
+	PruneImages(ctx context.Context) (DTO.ImagesPruneReport, error)
+	GetImageHistory(ctx context.Context, imageID string) (DTO.ImageHistory, error)
+}
+
+type DockerImageController struct {
+	dockerImageService dockerImageService
+	loggerService      utils.LoggerService
+}
+
+func NewDockerImageController(dockerImageService dockerImageService, loggerService utils.LoggerService,
+) *DockerImageController {
+	return &DockerImageController{
+		dockerImageService: dockerImageService,
+		loggerService:      loggerService,
+	}
+}
+
+func (di *DockerImageController) ListImages(w http.ResponseWriter, r *http.Request) {
+	images, err := di.dockerImageService.ListImages(r.Context())
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, images)
+}
+
+func (di *DockerImageController) PullImage(w http.ResponseWriter, r *http.Request) {
+	image := request.ReadQueryParam(r, "image")
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		di.loggerService.Error("failed to upgrade connection", err)
+		return
+	}
+	defer conn.Close()
+
+	di.dockerImageService.PullImage(context.Background(), image, conn)
+}
+
+func (di *DockerImageController) RemoveImage(w http.ResponseWriter, r *http.Request) {
+	imageID, err := request.ReadParam(r, "imageID")
+	if err != nil {
+		di.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	force := request.ReadQueryParam(r, "force") == "true"
+	err = di.dockerImageService.RemoveImage(r.Context(), imageID, force)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (di *DockerImageController) PruneImages(w http.ResponseWriter, r *http.Request) {
+	pruneReport, err := di.dockerImageService.PruneImages(r.Context())
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, pruneReport)
+}
+
+func (di *DockerImageController) GetImageHistory(w http.ResponseWriter, r *http.Request) {
+	imageID, err := request.ReadParam(r, "imageID")
+	if err != nil {
+		di.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	imageHistory, err := di.dockerImageService.GetImageHistory(r.Context(), imageID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, imageHistory)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type dockerStatsService interface {
+	GetContainerStats(ctx context.Context, appID string, ownerID int) (DTO.ContainerStats, error)
+	GetContainerStatsHistory(ctx context.Context, appID string, ownerID int, since time.Time) ([]DTO.ContainerStats,
+		error)
+	StreamContainerStats(ctx context.Context, appID string, ownerID int, conn *websocket.Conn)
+}
+
+type DockerStatsController struct {
+	dockerStatsService dockerStatsService
+	loggerService      utils.LoggerService
+}
+
+func NewDockerStatsController(dockerStatsService dockerStatsService, loggerService utils.LoggerService,
+) *DockerStatsController {
+	return &DockerStatsController{
+		dockerStatsService: dockerStatsService,
+		loggerService:      loggerService,
+	}
+}
+
+func (ds *DockerStatsController) GetContainerStats(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		ds.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		ds.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	containerStats, err := ds.dockerStatsService.GetContainerStats(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, containerStats)
+}
+
+// GetContainerStatsHistory returns the samples collected since the RFC3339 "since" query param, by default
+// from the last hour.
+func (ds *DockerStatsController) GetContainerStatsHistory(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		ds.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		ds.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	since := time.Now().Add(-time.Hour)
+	if sinceParam := request.ReadQueryParam(r, "since"); sinceParam != "" {
+		since, err = time.Parse(time.RFC3339, sinceParam)
+		if err != nil {
+			response.SetError(w, r, models.NewError(400, "Validation", "since must be a RFC3339 date"))
+			return
+		}
+	}
+
+	containerStatsHistory, err := ds.dockerStatsService.GetContainerStatsHistory(r.Context(), appID, ownerID, since)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, containerStatsHistory)
+}
+
+func (ds *DockerStatsController) StreamContainerStats(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		ds.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		ds.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		ds.loggerService.Error("failed to upgrade connection", err)
+		return
+	}
+	defer conn.Close()
+
+	ds.dockerStatsService.StreamContainerStats(context.Background(), appID, ownerID, conn)
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type kubernetesService interface {
+	SaveCluster(ctx context.Context, ownerID int, cluster DTO.KubernetesCluster) error
+	ImportWorkloads(ctx context.Context, ownerID int, cluster, namespace string) error
+	RestartWorkload(ctx context.Context, appID string, ownerID int) error
+	GetWorkload(ctx context.Context, appID string, ownerID int) (DTO.KubernetesWorkload, error)
+}
+
+type KubernetesController struct {
+	kubernetesService kubernetesService
+	loggerService     utils.LoggerService
+}
+
+func NewKubernetesController(kubernetesService kubernetesService,
+	loggerService utils.LoggerService,
+) *KubernetesController {
+	return &KubernetesController{
+		kubernetesService: kubernetesService,
+		loggerService:     loggerService,
+	}
+}
+
+func (kc *KubernetesController) readAppParams(r *http.Request) (string, int, error) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		kc.loggerService.Error(failedToReadDataFromToken)
+		return "", 0, err
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		kc.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		return "", 0, err
+	}
+
+	return appID, ownerID, nil
+}
+
+func (kc *KubernetesController) SaveCluster(w http.ResponseWriter, r *http.Request) {
+	cluster, err := request.ReadBody[DTO.KubernetesCluster](r)
+	if err != nil {
+		kc.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		kc.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = kc.kubernetesService.SaveCluster(r.Context(), ownerID, *cluster)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (kc *KubernetesController) ImportWorkloads(w http.ResponseWriter, r *http.Request) {
+	importWorkloads, err := request.ReadBody[DTO.ImportKubernetesWorkloads](r)
+	if err != nil {
+		kc.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		kc.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = kc.kubernetesService.ImportWorkloads(r.Context(), ownerID, importWorkloads.Cluster,
+		importWorkloads.Namespace)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 201, map[string]any{})
+}
+
+func (kc *KubernetesController) RestartWorkload(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := kc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = kc.kubernetesService.RestartWorkload(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (kc *KubernetesController) GetWorkload(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := kc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	workload, err := kc.kubernetesService.GetWorkload(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, workload)
+}
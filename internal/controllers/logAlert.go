@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type logAlertService interface {
+	GetLogAlertRules(ctx context.Context, appID string, ownerID int) ([]DTO.LogAlertRule, error)
+	CreateLogAlertRule(ctx context.Context, appID string, ownerID int, logAlertRule DTO.CreateLogAlertRule) (int,
+		error)
+	UpdateLogAlertRule(ctx context.Context, appID string, ruleID, ownerID int, logAlertRule DTO.CreateLogAlertRule) error
+	DeleteLogAlertRule(ctx context.Context, appID string, ruleID, ownerID int) error
+}
+
+type LogAlertController struct {
+	logAlertService logAlertService
+	loggerService   utils.LoggerService
+}
+
+func NewLogAlertController(logAlertService logAlertService, loggerService utils.LoggerService) *LogAlertController {
+	return &LogAlertController{
+		logAlertService: logAlertService,
+		loggerService:   loggerService,
+	}
+}
+
+func (la *LogAlertController) readRuleParams(r *http.Request) (string, int, int, error) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		la.loggerService.Error(failedToReadDataFromToken)
+		return "", 0, 0, err
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		la.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		return "", 0, 0, err
+	}
+
+	ruleIDString, err := request.ReadParam(r, "ruleID")
+	if err != nil {
+		la.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		return "", 0, 0, err
+	}
+
+	ruleID, err := strconv.Atoi(ruleIDString)
+	if err != nil {
+		la.loggerService.Error("failed to covnert string to int", ruleIDString)
+		return "", 0, 0, err
+	}
+
+	return appID, ruleID, ownerID, nil
+}
+
+func (la *LogAlertController) GetLogAlertRules(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		la.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		la.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	logAlertRules, err := la.logAlertService.GetLogAlertRules(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, logAlertRules)
+}
+
+func (la *LogAlertController) CreateLogAlertRule(w http.ResponseWriter, r *http.Request) {
+	logAlertRule, err := request.ReadBody[DTO.CreateLogAlertRule](r)
+	if err != nil {
+		la.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		la.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		la.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	ruleID, err := la.logAlertService.CreateLogAlertRule(r.Context(), appID, ownerID, *logAlertRule)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 201, map[string]int{"id": ruleID})
+}
+
+func (la *LogAlertController) UpdateLogAlertRule(w http.ResponseWriter, r *http.Request) {
+	logAlertRule, err := request.ReadBody[DTO.CreateLogAlertRule](r)
+	if err != nil {
+		la.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, ruleID, ownerID, err := la.readRuleParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = la.logAlertService.UpdateLogAlertRule(r.Context(), appID, ruleID, ownerID, *logAlertRule)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (la *LogAlertController) DeleteLogAlertRule(w http.ResponseWriter, r *http.Request) {
+	appID, ruleID, ownerID, err := la.readRuleParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = la.logAlertService.DeleteLogAlertRule(r.Context(), appID, ruleID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type logsService interface {
+	SearchLogs(ctx context.Context, ownerID int, logsSearch DTO.LogsSearch) (DTO.LogsSearchResult, error)
+	UpdateLogsRetention(ctx context.Context, appID string, ownerID, days int) error
+}
+
+type LogsController struct {
+	logsService   logsService
+	loggerService utils.LoggerService
+}
+
+func NewLogsController(logsService logsService, loggerService utils.LoggerService) *LogsController {
+	return &LogsController{
+		logsService:   logsService,
+		loggerService: loggerService,
+	}
+}
+
+func readLogsSearch(r *http.Request) (DTO.LogsSearch, error) {
+	logsSearch := DTO.LogsSearch{
+		Query: request.ReadQueryParam(r, "q"),
+		AppID: request.ReadQueryParam(r, "app"),
+		Level: request.ReadQueryParam(r, "level"),
+	}
+	switch logsSearch.Level {
+	case "", "debug", "info", "warn", "error", "fatal":
+	default:
+		return DTO.LogsSearch{}, models.NewError(400, "Validation",
+			"level must be one of: debug, info, warn, error, fatal")
+	}
+
+	for queryParam, date := range map[string]*time.Time{"from": &logsSearch.From, "to": &logsSearch.To} {
+		value := request.ReadQueryParam(r, queryParam)
+		if value == "" {
+			continue
+		}
+		parsedDate, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return DTO.LogsSearch{}, models.NewError(400, "Validation", queryParam+" must be a RFC3339 date")
+		}
+		*date = parsedDate
+	}
+
+	for queryParam, number := range map[string]*int{"page": &logsSearch.Page, "limit": &logsSearch.Limit} {
+		value := request.ReadQueryParam(r, queryParam)
+		if value == "" {
+			continue
+		}
+		parsedNumber, err := strconv.Atoi(value)
+		if err != nil || parsedNumber < 1 {
+			return DTO.LogsSearch{}, models.NewError(400, "Validation", queryParam+" must be a positive number")
+		}
+		*number = parsedNumber
+	}
+
+	return logsSearch, nil
+}
+
+func (lc *LogsController) SearchLogs(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		lc.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	logsSearch, err := readLogsSearch(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	logsSearchResult, err := lc.logsService.SearchLogs(r.Context(), ownerID, logsSearch)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, logsSearchResult)
+}
+
+func (lc *LogsController) UpdateLogsRetention(w http.ResponseWriter, r *http.Request) {
+	logsRetention, err := request.ReadBody[DTO.LogsRetention](r)
+	if err != nil {
+		lc.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		lc.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		lc.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = lc.logsService.UpdateLogsRetention(r.Context(), appID, ownerID, logsRetention.Days)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
@@ -1,18 +1,115 @@
 package controllers
 
 import (
-	"github.com/slodkiadrianek/octopus/internal/services/thirdPartyServices"
+	"context"
+	"net/http"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
 )
 
+type pm2Service interface {
+	ImportApps(ctx context.Context, ownerID int) error
+	StartApp(ctx context.Context, appID string, ownerID int) error
+	StopApp(ctx context.Context, appID string, ownerID int) error
+	RestartApp(ctx context.Context, appID string, ownerID int) error
+	ReloadApp(ctx context.Context, appID string, ownerID int) error
+	GetProcesses(ctx context.Context, appID string, ownerID int) ([]DTO.Pm2Process, error)
+}
+
 type Pm2Controller struct {
 	loggerService utils.LoggerService
-	pm2Service    *thirdPartyServices.Pm2Service
+	pm2Service    pm2Service
 }
 
-func NewPm2Controller(loggerService utils.LoggerService, pm2Service *thirdPartyServices.Pm2Service) *Pm2Controller {
+func NewPm2Controller(loggerService utils.LoggerService, pm2Service pm2Service) *Pm2Controller {
 	return &Pm2Controller{
 		loggerService: loggerService,
 		pm2Service:    pm2Service,
 	}
 }
+
+func (pm *Pm2Controller) readAppParams(r *http.Request) (string, int, error) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		pm.loggerService.Error(failedToReadDataFromToken)
+		return "", 0, err
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		pm.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		return "", 0, err
+	}
+
+	return appID, ownerID, nil
+}
+
+func (pm *Pm2Controller) runAction(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, appID string, ownerID int) error,
+) {
+	appID, ownerID, err := pm.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = action(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (pm *Pm2Controller) ImportPm2Apps(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		pm.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = pm.pm2Service.ImportApps(r.Context(), ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 201, map[string]any{})
+}
+
+func (pm *Pm2Controller) StartApp(w http.ResponseWriter, r *http.Request) {
+	pm.runAction(w, r, pm.pm2Service.StartApp)
+}
+
+func (pm *Pm2Controller) StopApp(w http.ResponseWriter, r *http.Request) {
+	pm.runAction(w, r, pm.pm2Service.StopApp)
+}
+
+func (pm *Pm2Controller) RestartApp(w http.ResponseWriter, r *http.Request) {
+	pm.runAction(w, r, pm.pm2Service.RestartApp)
+}
+
+func (pm *Pm2Controller) ReloadApp(w http.ResponseWriter, r *http.Request) {
+	pm.runAction(w, r, pm.pm2Service.ReloadApp)
+}
+
+func (pm *Pm2Controller) GetProcesses(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := pm.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	processes, err := pm.pm2Service.GetProcesses(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, processes)
+}
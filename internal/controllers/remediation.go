@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type remediationService interface {
+	GetRemediationPolicy(ctx context.Context, appID string, ownerID int) (*DTO.RemediationPolicy, error)
+	UpsertRemediationPolicy(ctx context.Context, appID string, ownerID int, policy DTO.UpsertRemediationPolicy) error
+	DeleteRemediationPolicy(ctx context.Context, appID string, ownerID int) error
+	StartMaintenance(ctx context.Context, appID string, ownerID, minutes int) error
+	EndMaintenance(ctx context.Context, appID string, ownerID int) error
+	GetRemediationHistory(ctx context.Context, appID string, ownerID int) ([]DTO.RemediationAttempt, error)
+}
+
+type RemediationController struct {
+	remediationService remediationService
+	loggerService      utils.LoggerService
+}
+
+func NewRemediationController(remediationService remediationService,
+	loggerService utils.LoggerService,
+) *RemediationController {
+	return &RemediationController{
+		remediationService: remediationService,
+		loggerService:      loggerService,
+	}
+}
+
+func (rc *RemediationController) readAppParams(r *http.Request) (string, int, error) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		rc.loggerService.Error(failedToReadDataFromToken)
+		return "", 0, err
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		rc.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		return "", 0, err
+	}
+
+	return appID, ownerID, nil
+}
+
+func (rc *RemediationController) GetRemediationPolicy(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := rc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	policy, err := rc.remediationService.GetRemediationPolicy(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, policy)
+}
+
+func (rc *RemediationController) UpsertRemediationPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := request.ReadBody[DTO.UpsertRemediationPolicy](r)
+	if err != nil {
+		rc.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, ownerID, err := rc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = rc.remediationService.UpsertRemediationPolicy(r.Context(), appID, ownerID, *policy)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (rc *RemediationController) DeleteRemediationPolicy(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := rc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = rc.remediationService.DeleteRemediationPolicy(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (rc *RemediationController) StartMaintenance(w http.ResponseWriter, r *http.Request) {
+	maintenance, err := request.ReadBody[DTO.RemediationMaintenance](r)
+	if err != nil {
+		rc.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	appID, ownerID, err := rc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = rc.remediationService.StartMaintenance(r.Context(), appID, ownerID, maintenance.Minutes)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (rc *RemediationController) EndMaintenance(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := rc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = rc.remediationService.EndMaintenance(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (rc *RemediationController) GetRemediationHistory(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := rc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	remediationHistory, err := rc.remediationService.GetRemediationHistory(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, remediationHistory)
+}
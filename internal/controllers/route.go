@@ -2,11 +2,13 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/schema"
 	"github.com/slodkiadrianek/octopus/internal/utils"
 	"github.com/slodkiadrianek/octopus/internal/utils/request"
 	"github.com/slodkiadrianek/octopus/internal/utils/response"
@@ -14,52 +16,85 @@ import (
 )
 
 type routeService interface {
-	CheckRouteStatus(ctx context.Context, routeID int) (string, error)
-	AddWorkingRoutes(ctx context.Context, routes *[]DTO.CreateRoute, appID string, name string) error
+	AddWorkingRoutes(ctx context.Context, routeFlow DTO.CreateRouteData, appID string, ownerID int) (int, error)
+	UpdateRouteFlow(ctx context.Context, routeFlow DTO.CreateRouteData, appID string, ownerID, flowID int) (int,
+		error)
+	GetRouteFlows(ctx context.Context, appID string, ownerID int) ([]DTO.RouteFlow, error)
+	GetRouteFlow(ctx context.Context, appID string, ownerID, flowID, version int) (*DTO.RouteFlow, error)
+	DeleteRouteFlow(ctx context.Context, appID string, ownerID, flowID int) error
+	GetRouteFlowRuns(ctx context.Context, appID string, ownerID, flowID int) ([]DTO.RouteFlowRun, error)
+	GetRouteFlowRun(ctx context.Context, appID string, ownerID, flowID, runID int) (*DTO.RouteFlowRun, error)
+	PreviewRouteFlowsImport(ctx context.Context, appID string, ownerID int,
+		importData DTO.ImportRouteFlows) ([]DTO.RouteFlowImport, error)
 }
+
+type routeStatusService interface {
+	RunRouteFlow(ctx context.Context, appID string, ownerID, flowID int,
+		options DTO.RunRouteFlowOptions) (*DTO.RouteFlowRun, error)
+}
+
 type RouteController struct {
-	routeService  routeService
-	loggerService utils.LoggerService
+	routeService       routeService
+	routeStatusService routeStatusService
+	loggerService      utils.LoggerService
 }
 
-func NewRouteController(routeService routeService, loggerService utils.LoggerService) *RouteController {
+func NewRouteController(routeService routeService, routeStatusService routeStatusService,
+	loggerService utils.LoggerService,
+) *RouteController {
 	return &RouteController{
-		routeService:  routeService,
-		loggerService: loggerService,
+		routeService:       routeService,
+		routeStatusService: routeStatusService,
+		loggerService:      loggerService,
 	}
 }
 
-func (rc *RouteController) CheckRouteStatus(w http.ResponseWriter, r *http.Request) {
-	routeID, err := request.ReadParam(r, "routeID")
+func (rc *RouteController) readAppParams(r *http.Request) (string, int, error) {
+	ownerID, err := request.ReadUserIDFromToken(r)
 	if err != nil {
-		rc.loggerService.Error(failedToReadParamFromRequest, err.Error())
-		response.SetError(w, r, err)
-		return
+		rc.loggerService.Error(failedToReadDataFromToken)
+		return "", 0, err
 	}
-	convertedRouteIDToINT, _ := strconv.Atoi(routeID)
-	routeStatus, err := rc.routeService.CheckRouteStatus(r.Context(), convertedRouteIDToINT)
+
+	appID, err := request.ReadParam(r, "appID")
 	if err != nil {
-		response.SetError(w, r, err)
-		return
+		rc.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		return "", 0, err
 	}
-	response.Send(w, 200, map[string]string{"routeStatus": routeStatus})
+
+	return appID, ownerID, nil
 }
 
-func (rc *RouteController) AddWorkingRoutes(w http.ResponseWriter, r *http.Request) {
-	appID, err := request.ReadParam(r, "appID")
+func (rc *RouteController) readFlowParams(r *http.Request) (string, int, int, error) {
+	appID, ownerID, err := rc.readAppParams(r)
 	if err != nil {
-		rc.loggerService.Error(failedToReadParamFromRequest, err.Error())
-		response.SetError(w, r, err)
-		return
+		return "", 0, 0, err
 	}
 
-	body, err := request.ReadBody[DTO.CreateRouteData](r)
+	flowIDString, err := request.ReadParam(r, "flowID")
 	if err != nil {
-		response.SetError(w, r, err)
-		return
+		rc.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		return "", 0, 0, err
+	}
+
+	flowID, err := strconv.Atoi(flowIDString)
+	if err != nil {
+		rc.loggerService.Info("failed to convert flow id to int", flowIDString)
+		return "", 0, 0, models.NewError(400, "Validation", "flowID has to be a number")
+	}
+
+	return appID, ownerID, flowID, nil
+}
+
+// validateRoutes checks that the data passed between the steps is present in both of them and that the templates
+// use only the known variables, it numbers the steps as well.
+func (rc *RouteController) validateRoutes(routeFlow DTO.CreateRouteData) error {
+	routes := routeFlow.Routes
+	if len(routes) == 0 {
+		return models.NewError(400, "Validation", "route flow has to have at least one route")
 	}
 
-	routes := body.Routes
+	// Only the position of the invalid route is logged, the routes can contain credentials in Auth.
 	for i := 0; i < len(routes); i++ {
 
 		if i < len(routes)-1 {
@@ -68,9 +103,8 @@ func (rc *RouteController) AddWorkingRoutes(w http.ResponseWriter, r *http.Reque
 				resBody := validation.CheckIsNextRouteBodyInTheBodyAndInTheBodyOfTheNextRoute(routes[i], routes[i+1])
 				if !resBody {
 					err := models.NewError(400, "Validation", "provided next route body data is malformed, make sure next route body data are in response and in the next route")
-					rc.loggerService.Info(err.Error(), routes)
-					response.SetError(w, r, err)
-					return
+					rc.loggerService.Info(err.Error(), map[string]any{"flow": routeFlow.Name, "route": i})
+					return err
 				}
 
 			}
@@ -80,9 +114,8 @@ func (rc *RouteController) AddWorkingRoutes(w http.ResponseWriter, r *http.Reque
 				resQuery := validation.CheckIsNextRouteQueryInTheBodyAndInTheQueryOfTheNextRoute(routes[i], routes[i+1])
 				if !resQuery {
 					err := models.NewError(400, "Validation", "provided next route query data is malformed, make sure next route query data are in response and in the next route")
-					rc.loggerService.Info(err.Error(), routes)
-					response.SetError(w, r, err)
-					return
+					rc.loggerService.Info(err.Error(), map[string]any{"flow": routeFlow.Name, "route": i})
+					return err
 				}
 
 			}
@@ -92,9 +125,8 @@ func (rc *RouteController) AddWorkingRoutes(w http.ResponseWriter, r *http.Reque
 				resParams := validation.CheckIsNextRouteParamsInTheBodyAndInTheParamsOfTheNextRoute(routes[i], routes[i+1])
 				if !resParams {
 					err := models.NewError(400, "Validation", "provided next route params data is malformed, make sure next route params data are in response and in the next route")
-					rc.loggerService.Info(err.Error(), routes)
-					response.SetError(w, r, err)
-					return
+					rc.loggerService.Info(err.Error(), map[string]any{"flow": routeFlow.Name, "route": i})
+					return err
 				}
 
 			}
@@ -103,41 +135,346 @@ func (rc *RouteController) AddWorkingRoutes(w http.ResponseWriter, r *http.Reque
 
 			if len(routes[i].NextRouteBody) > 0 {
 				err := models.NewError(400, "Validation", "provided next route body data is malformed, make sure next route body data are in response and in the next route")
-				rc.loggerService.Info(err.Error(), routes)
-				response.SetError(w, r, err)
-				return
+				rc.loggerService.Info(err.Error(), map[string]any{"flow": routeFlow.Name, "route": i})
+				return err
 			}
 
 			if len(routes[i].NextRouteQuery) > 0 {
 				err := models.NewError(400, "Validation", "provided next route query data is malformed, make sure next route query data are in response and in the next route")
-				rc.loggerService.Info(err.Error(), routes)
-				response.SetError(w, r, err)
-				return
+				rc.loggerService.Info(err.Error(), map[string]any{"flow": routeFlow.Name, "route": i})
+				return err
 			}
 
 			if len(routes[i].NextRouteParams) > 0 {
 				err := models.NewError(400, "Validation", "provided next route params data is malformed, make sure next route params data are in response and in the next route")
-				rc.loggerService.Info(err.Error(), routes)
-				response.SetError(w, r, err)
-				return
+				rc.loggerService.Info(err.Error(), map[string]any{"flow": routeFlow.Name, "route": i})
+				return err
 			}
 		}
 		resParams := request.CheckRouteParams(routes[i])
 		if !resParams {
 			err := models.NewError(400, "Validation", "provided next route params data is malformed, make sure next route params data are in response and in the next route")
-			rc.loggerService.Info(err.Error(), routes)
-			response.SetError(w, r, err)
-			return
+			rc.loggerService.Info(err.Error(), map[string]any{"flow": routeFlow.Name, "route": i})
+			return err
+		}
+
+		if err := validation.CheckRouteAssertions(routes[i]); err != nil {
+			rc.loggerService.Info(err.Error(), map[string]any{"flow": routeFlow.Name, "route": i})
+			return models.NewError(400, "Validation", err.Error())
+		}
+
+		if err := validation.CheckRouteRequest(routes[i]); err != nil {
+			rc.loggerService.Info(err.Error(), map[string]any{"flow": routeFlow.Name, "route": i})
+			return models.NewError(400, "Validation", err.Error())
 		}
 
 		routes[i].ParentID = i
 	}
 
-	err = rc.routeService.AddWorkingRoutes(r.Context(), &routes, appID, body.Name)
+	if err := validation.CheckRouteFlowHTTPOptions(routeFlow.HTTPOptions); err != nil {
+		rc.loggerService.Info(err.Error(), routeFlow.HTTPOptions.BaseURL)
+		return models.NewError(400, "Validation", err.Error())
+	}
+
+	if err := validation.CheckRouteTemplates(routeFlow); err != nil {
+		rc.loggerService.Info(err.Error(), map[string]any{"flow": routeFlow.Name})
+		return models.NewError(400, "Validation", err.Error())
+	}
+
+	return nil
+}
+
+func (rc *RouteController) GetRouteFlows(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := rc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	routeFlows, err := rc.routeService.GetRouteFlows(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, routeFlows)
+}
+
+func (rc *RouteController) GetRouteFlow(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, flowID, err := rc.readFlowParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	version := 0
+	if versionString := request.ReadQueryParam(r, "version"); versionString != "" {
+		version, err = strconv.Atoi(versionString)
+		if err != nil || version < 1 {
+			response.SetError(w, r, models.NewError(400, "Validation", "version has to be a positive number"))
+			return
+		}
+	}
+
+	routeFlow, err := rc.routeService.GetRouteFlow(r.Context(), appID, ownerID, flowID, version)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, routeFlow)
+}
+
+func (rc *RouteController) AddWorkingRoutes(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := rc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	body, err := request.ReadBody[DTO.CreateRouteData](r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = rc.validateRoutes(*body)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	flowID, err := rc.routeService.AddWorkingRoutes(r.Context(), *body, appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 201, map[string]int{"id": flowID})
+}
+
+func (rc *RouteController) UpdateRouteFlow(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, flowID, err := rc.readFlowParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	body, err := request.ReadBody[DTO.CreateRouteData](r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = rc.validateRoutes(*body)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	version, err := rc.routeService.UpdateRouteFlow(r.Context(), *body, appID, ownerID, flowID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, map[string]int{"version": version})
+}
+
+func (rc *RouteController) DeleteRouteFlow(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, flowID, err := rc.readFlowParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = rc.routeService.DeleteRouteFlow(r.Context(), appID, ownerID, flowID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (rc *RouteController) GetRouteFlowRuns(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, flowID, err := rc.readFlowParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	routeFlowRuns, err := rc.routeService.GetRouteFlowRuns(r.Context(), appID, ownerID, flowID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, routeFlowRuns)
+}
+
+func (rc *RouteController) GetRouteFlowRun(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, flowID, err := rc.readFlowParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	runIDString, err := request.ReadParam(r, "runID")
+	if err != nil {
+		rc.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return
+	}
+
+	runID, err := strconv.Atoi(runIDString)
+	if err != nil {
+		rc.loggerService.Info("failed to convert run id to int", runIDString)
+		response.SetError(w, r, models.NewError(400, "Validation", "runID has to be a number"))
+		return
+	}
+
+	routeFlowRun, err := rc.routeService.GetRouteFlowRun(r.Context(), appID, ownerID, flowID, runID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, routeFlowRun)
+}
+
+// RunRouteFlow checks the flow on demand. With dryRun=true the requests are only rendered, with async=true the run
+// is started in the background and its id is returned.
+func (rc *RouteController) RunRouteFlow(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, flowID, err := rc.readFlowParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	options := DTO.RunRouteFlowOptions{
+		DryRun: request.ReadQueryParam(r, "dryRun") == "true",
+		Async:  request.ReadQueryParam(r, "async") == "true",
+	}
+
+	routeFlowRun, err := rc.routeStatusService.RunRouteFlow(r.Context(), appID, ownerID, flowID, options)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	if options.Async && !options.DryRun {
+		response.Send(w, 202, map[string]int{"id": routeFlowRun.ID})
+		return
+	}
+
+	response.Send(w, 200, routeFlowRun)
+}
+
+// checkRouteFlowsImport runs the generated flows through the same validation as the flows created by hand, the flow
+// which does not pass it gets the reason as its error.
+func (rc *RouteController) checkRouteFlowsImport(routeFlowsImport []DTO.RouteFlowImport) {
+	for i := range routeFlowsImport {
+		flow := &routeFlowsImport[i].Flow
+		if issues := validation.ValidateInputStruct(schema.CreateRouteSchema, flow); issues != nil {
+			if firstIssues := issues["$first"]; len(firstIssues) > 0 {
+				routeFlowsImport[i].Error = fmt.Sprintf("%s: %s", firstIssues[0].Path, firstIssues[0].Message)
+				continue
+			}
+		}
+		if err := rc.validateRoutes(*flow); err != nil {
+			routeFlowsImport[i].Error = err.Error()
+		}
+	}
+}
+
+// PreviewRouteFlowsImport returns the flows generated from the document, nothing is saved.
+func (rc *RouteController) PreviewRouteFlowsImport(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := rc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	body, err := request.ReadBody[DTO.ImportRouteFlows](r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	routeFlowsImport, err := rc.routeService.PreviewRouteFlowsImport(r.Context(), appID, ownerID, *body)
 	if err != nil {
 		response.SetError(w, r, err)
 		return
 	}
+	rc.checkRouteFlowsImport(routeFlowsImport)
+
+	response.Send(w, 200, routeFlowsImport)
+}
+
+// ImportRouteFlows creates the selected flows of the document. The flows are created one by one, so the result tells
+// which of them failed to be saved.
+func (rc *RouteController) ImportRouteFlows(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := rc.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	body, err := request.ReadBody[DTO.ImportRouteFlows](r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+	if len(body.Select) == 0 {
+		response.SetError(w, r, models.NewError(400, "Validation", "select has to have at least one flow"))
+		return
+	}
+
+	routeFlowsImport, err := rc.routeService.PreviewRouteFlowsImport(r.Context(), appID, ownerID, *body)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+	rc.checkRouteFlowsImport(routeFlowsImport)
+
+	routeFlowsByName := make(map[string]DTO.RouteFlowImport, len(routeFlowsImport))
+	for _, routeFlowImport := range routeFlowsImport {
+		routeFlowsByName[routeFlowImport.Name] = routeFlowImport
+	}
+	selectedNames := make(map[string]bool, len(body.Select))
+	for _, name := range body.Select {
+		if selectedNames[name] {
+			response.SetError(w, r, models.NewError(400, "Validation",
+				fmt.Sprintf("flow %q is selected more than once", name)))
+			return
+		}
+		selectedNames[name] = true
+
+		routeFlowImport, ok := routeFlowsByName[name]
+		if !ok {
+			response.SetError(w, r, models.NewError(400, "Validation",
+				fmt.Sprintf("flow %q is not in the document", name)))
+			return
+		}
+		if routeFlowImport.Error != "" {
+			response.SetError(w, r, models.NewError(400, "Validation",
+				fmt.Sprintf("flow %q cannot be imported: %s", name, routeFlowImport.Error)))
+			return
+		}
+	}
+
+	importedRouteFlows := make([]DTO.ImportedRouteFlow, 0, len(body.Select))
+	for _, name := range body.Select {
+		importedRouteFlow := DTO.ImportedRouteFlow{Name: name}
+		flowID, err := rc.routeService.AddWorkingRoutes(r.Context(), routeFlowsByName[name].Flow, appID, ownerID)
+		if err != nil {
+			rc.loggerService.Warn("failed to import route flow", map[string]any{"name": name, "err": err.Error()})
+			importedRouteFlow.Error = err.Error()
+		}
+		importedRouteFlow.FlowID = flowID
+		importedRouteFlows = append(importedRouteFlows, importedRouteFlow)
+	}
 
-	response.Send(w, 201, map[string]string{})
+	response.Send(w, 201, importedRouteFlows)
 }
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type stackService interface {
+	GetStacks(ctx context.Context, ownerID int) ([]DTO.Stack, error)
+	GetStack(ctx context.Context, stackID, ownerID int) (DTO.Stack, error)
+	StartStack(ctx context.Context, stackID, ownerID int) error
+	StopStack(ctx context.Context, stackID, ownerID int) error
+	RestartStack(ctx context.Context, stackID, ownerID int) error
+}
+
+type StackController struct {
+	stackService  stackService
+	loggerService utils.LoggerService
+}
+
+func NewStackController(stackService stackService, loggerService utils.LoggerService) *StackController {
+	return &StackController{
+		stackService:  stackService,
+		loggerService: loggerService,
+	}
+}
+
+func (s *StackController) readStackRequest(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		s.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return 0, 0, false
+	}
+
+	stackIDString, err := request.ReadParam(r, "stackID")
+	if err != nil {
+		s.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		response.SetError(w, r, err)
+		return 0, 0, false
+	}
+
+	stackID, err := strconv.Atoi(stackIDString)
+	if err != nil {
+		s.loggerService.Error("failed to covnert string to int", err)
+		response.SetError(w, r, err)
+		return 0, 0, false
+	}
+
+	return stackID, ownerID, true
+}
+
+func (s *StackController) GetStacks(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		s.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	stacks, err := s.stackService.GetStacks(r.Context(), ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, stacks)
+}
+
+func (s *StackController) GetStack(w http.ResponseWriter, r *http.Request) {
+	stackID, ownerID, ok := s.readStackRequest(w, r)
+	if !ok {
+		return
+	}
+
+	stack, err := s.stackService.GetStack(r.Context(), stackID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, stack)
+}
+
+func (s *StackController) StartStack(w http.ResponseWriter, r *http.Request) {
+	stackID, ownerID, ok := s.readStackRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.stackService.StartStack(r.Context(), stackID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (s *StackController) StopStack(w http.ResponseWriter, r *http.Request) {
+	stackID, ownerID, ok := s.readStackRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.stackService.StopStack(r.Context(), stackID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (s *StackController) RestartStack(w http.ResponseWriter, r *http.Request) {
+	stackID, ownerID, ok := s.readStackRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.stackService.RestartStack(r.Context(), stackID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type systemdService interface {
+	ImportUnits(ctx context.Context, ownerID int, pattern string) error
+	StartUnit(ctx context.Context, appID string, ownerID int) error
+	StopUnit(ctx context.Context, appID string, ownerID int) error
+	RestartUnit(ctx context.Context, appID string, ownerID int) error
+	GetUnit(ctx context.Context, appID string, ownerID int) (DTO.SystemdUnit, error)
+}
+
+type SystemdController struct {
+	systemdService systemdService
+	loggerService  utils.LoggerService
+}
+
+func NewSystemdController(systemdService systemdService, loggerService utils.LoggerService) *SystemdController {
+	return &SystemdController{
+		systemdService: systemdService,
+		loggerService:  loggerService,
+	}
+}
+
+func (sd *SystemdController) readAppParams(r *http.Request) (string, int, error) {
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		sd.loggerService.Error(failedToReadDataFromToken)
+		return "", 0, err
+	}
+
+	appID, err := request.ReadParam(r, "appID")
+	if err != nil {
+		sd.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
+		return "", 0, err
+	}
+
+	return appID, ownerID, nil
+}
+
+func (sd *SystemdController) runAction(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, appID string, ownerID int) error,
+) {
+	appID, ownerID, err := sd.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = action(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 204, map[string]any{})
+}
+
+func (sd *SystemdController) ImportSystemdUnits(w http.ResponseWriter, r *http.Request) {
+	importSystemdUnits, err := request.ReadBody[DTO.ImportSystemdUnits](r)
+	if err != nil {
+		sd.loggerService.Error(failedToReadBodyFromRequest, err)
+		response.SetError(w, r, err)
+		return
+	}
+
+	ownerID, err := request.ReadUserIDFromToken(r)
+	if err != nil {
+		sd.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
+		return
+	}
+
+	err = sd.systemdService.ImportUnits(r.Context(), ownerID, importSystemdUnits.Pattern)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 201, map[string]any{})
+}
+
+func (sd *SystemdController) StartUnit(w http.ResponseWriter, r *http.Request) {
+	sd.runAction(w, r, sd.systemdService.StartUnit)
+}
+
+func (sd *SystemdController) StopUnit(w http.ResponseWriter, r *http.Request) {
+	sd.runAction(w, r, sd.systemdService.StopUnit)
+}
+
+func (sd *SystemdController) RestartUnit(w http.ResponseWriter, r *http.Request) {
+	sd.runAction(w, r, sd.systemdService.RestartUnit)
+}
+
+func (sd *SystemdController) GetUnit(w http.ResponseWriter, r *http.Request) {
+	appID, ownerID, err := sd.readAppParams(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	unit, err := sd.systemdService.GetUnit(r.Context(), appID, ownerID)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
+
+	response.Send(w, 200, unit)
+}
@@ -3,14 +3,18 @@ package controllers
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
 	"github.com/slodkiadrianek/octopus/internal/utils"
 	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
 )
 
 type wsService interface {
-	Logs(ctx context.Context, appID string, conn *websocket.Conn)
+	Logs(ctx context.Context, appID string, ownerID int, logsOptions DTO.LogsOptions, conn *websocket.Conn)
 }
 
 type WsController struct {
@@ -31,24 +35,59 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+func readLogsOptions(r *http.Request) (DTO.LogsOptions, error) {
+	logsOptions := DTO.LogsOptions{
+		Tail:   request.ReadQueryParam(r, "tail"),
+		Since:  request.ReadQueryParam(r, "since"),
+		Until:  request.ReadQueryParam(r, "until"),
+		Stream: request.ReadQueryParam(r, "stream"),
+		Grep:   request.ReadQueryParam(r, "grep"),
+	}
+	if logsOptions.Tail != "" && logsOptions.Tail != "all" {
+		tail, err := strconv.Atoi(logsOptions.Tail)
+		if err != nil || tail < 0 {
+			return DTO.LogsOptions{}, models.NewError(400, "Validation", "tail must be a positive number or all")
+		}
+	}
+	switch logsOptions.Stream {
+	case "", "all", "stdout", "stderr":
+	default:
+		return DTO.LogsOptions{}, models.NewError(400, "Validation", "stream must be one of: all, stdout, stderr")
+	}
+	if len(logsOptions.Grep) > 256 {
+		return DTO.LogsOptions{}, models.NewError(400, "Validation", "grep can not be longer than 256 characters")
+	}
+
+	return logsOptions, nil
+}
+
 func (ws *WsController) Logs(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	ownerID, err := request.ReadUserIDFromToken(r)
 	if err != nil {
-		ws.loggerService.Error("failed to upgrade connection", err)
+		ws.loggerService.Error(failedToReadDataFromToken)
+		response.SetError(w, r, err)
 		return
 	}
 
 	appID, err := request.ReadParam(r, "appID")
 	if err != nil {
 		ws.loggerService.Error(failedToReadParamFromRequest, r.URL.Path)
-		err := conn.WriteMessage(websocket.TextMessage, []byte("failed to read appID"))
-		if err != nil {
-			return
-		}
+		response.SetError(w, r, err)
+		return
+	}
+
+	logsOptions, err := readLogsOptions(r)
+	if err != nil {
+		response.SetError(w, r, err)
+		return
+	}
 
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		ws.loggerService.Error("failed to upgrade connection", err)
 		return
 	}
+	defer conn.Close()
 
-	ctx := context.Background()
-	ws.wsService.Logs(ctx, appID, conn)
+	ws.wsService.Logs(context.Background(), appID, ownerID, logsOptions, conn)
 }
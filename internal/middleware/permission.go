@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/response"
+)
+
+type Permissions struct {
+	userRepository interfaces.UserRepository
+	loggerService  utils.LoggerService
+}
+
+func NewPermissions(userRepository interfaces.UserRepository, loggerService utils.LoggerService) *Permissions {
+	return &Permissions{
+		userRepository: userRepository,
+		loggerService:  loggerService,
+	}
+}
+
+// RequirePermission must be used after VerifyToken, the permissions are read from the database on every request,
+// so revoking a permission does not wait for the token to expire.
+func (p Permissions) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := request.ReadUserIDFromToken(r)
+			if err != nil {
+				response.SetError(w, r, err)
+				return
+			}
+
+			hasPermission, err := p.userRepository.HasPermission(r.Context(), userID, permission)
+			if err != nil {
+				response.SetError(w, r, err)
+				return
+			}
+
+			if !hasPermission {
+				p.loggerService.Info("user does not have required permission", map[string]any{
+					"userID":     userID,
+					"permission": permission,
+				})
+				err := models.NewError(403, "Authorization", "You do not have permission to perform this action")
+				response.SetError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,10 +1,19 @@
 package models
 
+// Runtimes of the apps. Apps added by hand are checked by connecting to their address.
+const (
+	RuntimeTCP        = "tcp"
+	RuntimeDocker     = "docker"
+	RuntimePm2        = "pm2"
+	RuntimeSystemd    = "systemd"
+	RuntimeKubernetes = "kubernetes"
+)
+
 type App struct {
 	ID                string `json:"id" example:"1"`
 	Name              string `json:"name" example:"My App"`
 	Description       string `json:"description" example:"This is my app."`
-	IsDocker          bool   `json:"is_docker" example:"false"`
+	Runtime           string `json:"runtime" example:"docker"`
 	OwnerID           int    `json:"owner_id" example:"1"`
 	IPAddress         string `json:"ip_address" example:"192.168.1.1"`
 	Port              string `json:"port" example:"8080"`
@@ -16,7 +25,7 @@ type AppToCheck struct {
 	ID        string `json:"id" example:"1"`
 	Name      string `json:"name" example:"My App"`
 	OwnerID   int    `json:"owner_id" example:"1"`
-	IsDocker  bool   `json:"is_docker" example:"false"`
+	Runtime   string `json:"runtime" example:"docker"`
 	IPAddress string `json:"ip_address" example:"192.168.1.1"`
 	Port      string `json:"port" example:"8080"`
 	Status    string `json:"status" example:"running"`
@@ -0,0 +1,9 @@
+package models
+
+type DockerHost struct {
+	Host            string `json:"host" example:"tcp://192.168.0.100:2376"`
+	TLSCACert       string `json:"tls_ca_cert"`
+	TLSCert         string `json:"tls_cert"`
+	TLSKey          string `json:"tls_key"`
+	SSHIdentityFile string `json:"ssh_identity_file" example:"/home/octopus/.ssh/id_ed25519"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Kinds of the kubernetes workloads which can be imported as apps.
+const (
+	KubernetesKindDeployment  = "Deployment"
+	KubernetesKindStatefulSet = "StatefulSet"
+)
+
+// KubernetesCluster is registered by its owner, the names of the clusters of different owners do not collide.
+type KubernetesCluster struct {
+	OwnerID    int    `json:"owner_id" example:"1"`
+	Name       string `json:"name" example:"k3s"`
+	Kubeconfig string `json:"kubeconfig"`
+}
+
+// KubernetesWorkload points an imported app to its workload in the cluster.
+type KubernetesWorkload struct {
+	AppID     string `json:"app_id" example:"k8s-5f1c0e6d2b7a9c3e4d8f1a2b"`
+	OwnerID   int    `json:"owner_id" example:"1"`
+	Cluster   string `json:"cluster" example:"k3s"`
+	Namespace string `json:"namespace" example:"default"`
+	Kind      string `json:"kind" example:"Deployment"`
+	Name      string `json:"name" example:"api"`
+}
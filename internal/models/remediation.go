@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Actions recorded in the remediation history.
+const (
+	RemediationActionRestart = "restart"
+	RemediationActionWebhook = "webhook"
+	RemediationActionScript  = "script"
+)
+
+// AppToRemediate is an app with an enabled remediation policy, which is not in maintenance, and its last status.
+type AppToRemediate struct {
+	ID             string
+	Name           string
+	Runtime        string
+	OwnerID        int
+	Status         string
+	ExitCode       int
+	OOMKilled      bool
+	StoppedByUser  bool
+	MaxAttempts    int
+	BackoffSeconds int
+	Restart        bool
+	WebhookURL     string
+	Script         string
+	Attempts       int
+	LastAttemptAt  *time.Time
+	Escalated      bool
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 type JSONMapStringString map[string]string
@@ -64,7 +65,241 @@ type RouteToTest struct {
 	NextAuthorizationHeader string              `json:"next_authorization_header"`
 	ResponseStatusCode      int                 `json:"responseStatusCode" example:"200"`
 	ResponseBody            JSONMapStringAny    `json:"responseBody" example:"id=1"`
+	Assertions              RouteAssertions     `json:"assertions"`
+	ResponseSchema          JSONMapStringAny    `json:"responseSchema"`
+	MaxLatencyMs            int                 `json:"maxLatencyMs"`
+	RequestHeaders          JSONMapStringString `json:"requestHeaders"`
+	Extract                 RouteVariables      `json:"extract"`
+	Auth                    RouteAuth           `json:"auth"`
+	BodyType                string              `json:"bodyType"`
+	RawBody                 string              `json:"rawBody"`
 	ParentID                int
 	Status                  string
 	AppID                   string
+	FlowID                  int
+	Version                 int
+	Position                int
+	// Env, Secrets and HTTPOptions are shared by the steps of the flow, the secrets are encrypted.
+	Env         JSONMapStringString
+	Secrets     string
+	HTTPOptions RouteFlowHTTPOptions
+}
+
+const (
+	RouteAssertionSourceBody   = "body"
+	RouteAssertionSourceHeader = "header"
+)
+
+const (
+	RouteAssertionOperatorEq        = "eq"
+	RouteAssertionOperatorRegex     = "regex"
+	RouteAssertionOperatorType      = "type"
+	RouteAssertionOperatorRange     = "range"
+	RouteAssertionOperatorExists    = "exists"
+	RouteAssertionOperatorNotExists = "notExists"
+)
+
+// RouteAssertion checks a value of the response. The body values are chosen with a gjson-style selector like
+// "data.items.0.id" or a JSONPath like "$.data.items[0].id", the header values with the name of the header.
+type RouteAssertion struct {
+	Source   string   `json:"source" example:"body"`
+	Selector string   `json:"selector" example:"data.items.0.id"`
+	Operator string   `json:"operator" example:"eq"`
+	Value    any      `json:"value,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+}
+
+type RouteAssertions []RouteAssertion
+
+func (ra *RouteAssertions) Scan(value any) error {
+	if value == nil {
+		*ra = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("type assertion failed: %T", value)
+	}
+	return json.Unmarshal(b, ra)
+}
+
+// FailedAssertion describes a single check of the step which failed. The source is "status", "body", "header",
+// "schema" or "latency".
+type FailedAssertion struct {
+	Source   string `json:"source"`
+	Selector string `json:"selector,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Expected any    `json:"expected,omitempty"`
+	Actual   any    `json:"actual,omitempty"`
+	Message  string `json:"message"`
+}
+
+type FailedAssertions []FailedAssertion
+
+func (fa *FailedAssertions) Scan(value any) error {
+	if value == nil {
+		*fa = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("type assertion failed: %T", value)
+	}
+	return json.Unmarshal(b, fa)
+}
+
+// RouteStepResult is the result of the last check of the step.
+type RouteStepResult struct {
+	Status           string
+	FailedAssertions []FailedAssertion
+}
+
+// RouteVariable is extracted from the response of a step, so the later steps can use it as {{ .vars.<name> }}. The
+// selector works like the one of RouteAssertion.
+type RouteVariable struct {
+	Name     string `json:"name" example:"userID"`
+	Source   string `json:"source" example:"body"`
+	Selector string `json:"selector" example:"data.user.id"`
+}
+
+type RouteVariables []RouteVariable
+
+func (rv *RouteVariables) Scan(value any) error {
+	if value == nil {
+		*rv = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("type assertion failed: %T", value)
+	}
+	return json.Unmarshal(b, rv)
+}
+
+// RouteRunRequest is the request sent by a step during a run, the secrets and the authorization are redacted.
+type RouteRunRequest struct {
+	Method  string            `json:"method" example:"GET"`
+	URL     string            `json:"url" example:"http://192.168.0.100:3040/api/v1/users/17?"`
+	Headers map[string]string `json:"headers"`
+	Body    any               `json:"body"`
+}
+
+func (rr *RouteRunRequest) Scan(value any) error {
+	if value == nil {
+		*rr = RouteRunRequest{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("type assertion failed: %T", value)
+	}
+	return json.Unmarshal(b, rr)
+}
+
+const (
+	RouteAuthTypeBearer = "bearer"
+	RouteAuthTypeBasic  = "basic"
+	RouteAuthTypeAPIKey = "apiKey"
+	RouteAuthTypeCookie = "cookie"
+)
+
+// RouteAuth holds the credentials sent by the step. Value is the token, the API key or the cookie and Name is the
+// header of the API key or the name of the cookie. A step without the type sends RequestAuthorization as a Bearer
+// token.
+type RouteAuth struct {
+	Type     string `json:"type" example:"basic"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Name     string `json:"name" example:"X-Api-Key"`
+	Value    string `json:"value"`
+}
+
+func (ra *RouteAuth) Scan(value any) error {
+	if value == nil {
+		*ra = RouteAuth{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("type assertion failed: %T", value)
+	}
+	return json.Unmarshal(b, ra)
+}
+
+const (
+	RouteBodyTypeJSON = "json"
+	RouteBodyTypeForm = "form"
+	RouteBodyTypeRaw  = "raw"
+)
+
+const (
+	RouteImportFormatOpenAPI  = "openapi"
+	RouteImportFormatPostman  = "postman"
+	RouteImportFormatInsomnia = "insomnia"
+)
+
+const (
+	RouteRedirectPolicyFollow = "follow"
+	RouteRedirectPolicyNone   = "none"
+)
+
+// RouteFlowHTTPOptions tells how the requests of the flow are sent. BaseURL replaces the address of the app, e.g.
+// "https://api.example.com", and CACertificate is a PEM encoded certificate trusted on top of the system ones.
+type RouteFlowHTTPOptions struct {
+	BaseURL            string `json:"baseURL" example:"https://api.example.com"`
+	TimeoutMs          int    `json:"timeoutMs" example:"10000"`
+	RedirectPolicy     string `json:"redirectPolicy" example:"follow"`
+	MaxRedirects       int    `json:"maxRedirects" example:"10"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"`
+	CACertificate      string `json:"caCertificate"`
+}
+
+func (ro *RouteFlowHTTPOptions) Scan(value any) error {
+	if value == nil {
+		*ro = RouteFlowHTTPOptions{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("type assertion failed: %T", value)
+	}
+	return json.Unmarshal(b, ro)
+}
+
+const (
+	RouteFlowNotifiedStatusPassing = "passing"
+	RouteFlowNotifiedStatusFailing = "failing"
+)
+
+// RouteFlowNotifications tells when the flow notifies about its failure and recovery. The failure is sent after
+// FailureThreshold failed runs in a row and the notifications of the flow are at least CooldownSeconds apart.
+type RouteFlowNotifications struct {
+	Muted            bool `json:"muted"`
+	FailureThreshold int  `json:"failureThreshold" example:"3"`
+	CooldownSeconds  int  `json:"cooldownSeconds" example:"600"`
+}
+
+func (rn *RouteFlowNotifications) Scan(value any) error {
+	if value == nil {
+		*rn = RouteFlowNotifications{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("type assertion failed: %T", value)
+	}
+	return json.Unmarshal(b, rn)
+}
+
+// RouteFlowNotificationState is the flow after its run was counted. NotifiedStatus is the status of the last
+// notification, so a flow which keeps failing is reported only once.
+type RouteFlowNotificationState struct {
+	FlowID         int
+	AppID          string
+	Name           string
+	Notifications  RouteFlowNotifications
+	FailureStreak  int
+	NotifiedStatus string
+	NotifiedAt     *time.Time
 }
@@ -0,0 +1,7 @@
+package models
+
+type Stack struct {
+	ID      int    `json:"id" example:"1"`
+	Name    string `json:"name" example:"shop"`
+	OwnerID int    `json:"owner_id" example:"1"`
+}
@@ -14,3 +14,7 @@ type User struct {
 	CreatedAt                    time.Time `json:"created_at" sql:"created_at" example:"2023-01-01T00:00:00Z"`
 	UpdatedAt                    time.Time `json:"updated_at" sql:"updated_at" example:"2023-01-01T00:00:00Z"`
 }
+
+// PermissionOperator allows to open a console in the containers of the user and to save remediation policies,
+// which can run scripts on the worker machine.
+const PermissionOperator = "operator"
@@ -24,26 +24,41 @@ func NewAppRepository(db *sql.DB, loggerService utils.LoggerService) *AppReposit
 }
 
 func (a *AppRepository) InsertApp(ctx context.Context, app []DTO.App) error {
+	return insertApps(ctx, a.db, a.loggerService, app)
+}
+
+// statementPreparer is implemented by the database and by a transaction, so the same query can run in both.
+type statementPreparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// insertApps inserts the apps with the database or with a transaction which also inserts the data of their
+// runtime.
+func insertApps(ctx context.Context, db statementPreparer, loggerService utils.LoggerService, app []DTO.App) error {
 	placeholders := make([]string, 0, len(app))
 	args := make([]any, 0, len(app))
 	for i := range app {
-		preparedValues := fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d)", i*6+1, i*6+2, i*6+3, i*6+4, i*6+5, i*6+6)
-		args = append(args, app[i].ID, app[i].Name, app[i].IsDocker, app[i].OwnerID, app[i].IPAddress, app[i].Port)
+		preparedValues := fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,NULLIF($%d, 0))", i*8+1, i*8+2, i*8+3, i*8+4,
+			i*8+5, i*8+6, i*8+7, i*8+8)
+		args = append(args, app[i].ID, app[i].Name, app[i].Description, app[i].Runtime, app[i].OwnerID,
+			app[i].IPAddress, app[i].Port, app[i].StackID)
 		placeholders = append(placeholders, preparedValues)
 	}
 
 	query := fmt.Sprintf(`INSERT INTO apps (
 		id,
 		name,
-		is_docker,
+		description,
+		runtime,
 		owner_id,
 		ip_address,
-		port
+		port,
+		stack_id
 	)  VALUES %s`, strings.Join(placeholders, ","))
 
-	stmt, err := a.db.PrepareContext(ctx, query)
+	stmt, err := db.PrepareContext(ctx, query)
 	if err != nil {
-		a.loggerService.Error(failedToPrepareQuery, map[string]any{
+		loggerService.Error(failedToPrepareQuery, map[string]any{
 			"query": query,
 			"args":  app,
 			"err":   err.Error(),
@@ -52,13 +67,13 @@ func (a *AppRepository) InsertApp(ctx context.Context, app []DTO.App) error {
 	}
 	defer func() {
 		if closeErr := stmt.Close(); closeErr != nil {
-			a.loggerService.Error(failedToCloseStatement, closeErr)
+			loggerService.Error(failedToCloseStatement, closeErr)
 		}
 	}()
 
 	_, err = stmt.ExecContext(ctx, args...)
 	if err != nil {
-		a.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+		loggerService.Error(failedToExecuteInsertQuery, map[string]any{
 			"query": query,
 			"args":  app,
 			"err":   err.Error(),
@@ -74,7 +89,7 @@ func (a *AppRepository) GetApp(ctx context.Context, appID string, ownerID int) (
 		id,
 		name,
 		COALESCE(description, ''),
-		is_docker,
+		runtime,
 		owner_id,
 		COALESCE(slack_webhook_url, ''),
 		COALESCE(discord_webhook_url, ''),
@@ -102,8 +117,8 @@ func (a *AppRepository) GetApp(ctx context.Context, appID string, ownerID int) (
 
 	var app models.App
 	row := stmt.QueryRowContext(ctx, appID, ownerID)
-	err = row.Scan(&app.ID, &app.Name, &app.Description, &app.IsDocker, &app.OwnerID, &app.SlackWebhookURL,
-		&app.DiscordWebhookURL, &app.IPAddress, &app.Port)
+	err = row.Scan(&app.ID, &app.Name, &app.Description, &app.Runtime, &app.OwnerID,
+		&app.SlackWebhookURL, &app.DiscordWebhookURL, &app.IPAddress, &app.Port)
 	if err != nil {
 		a.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
 			"query": query,
@@ -121,7 +136,7 @@ func (a *AppRepository) GetApps(ctx context.Context, ownerID int) ([]models.App,
 		id, 
 		name, 
 		COALESCE(description, ''), 
-		is_docker,
+		runtime,
 		owner_id,
 		COALESCE(slack_webhook_url, ''),
 		COALESCE(discord_webhook_url, ''),
@@ -167,7 +182,7 @@ func (a *AppRepository) GetApps(ctx context.Context, ownerID int) ([]models.App,
 			&app.ID,
 			&app.Name,
 			&app.Description,
-			&app.IsDocker,
+			&app.Runtime,
 			&app.OwnerID,
 			&app.SlackWebhookURL,
 			&app.DiscordWebhookURL,
@@ -213,7 +228,19 @@ func (a *AppRepository) DeleteApp(ctx context.Context, appID string, ownerID int
 }
 
 func (a *AppRepository) GetAppStatus(ctx context.Context, appID string, ownerID int) (DTO.AppStatus, error) {
-	query := "SELECT * FROM apps_statuses WHERE apps_statuses.app_id = $1 AND owner_id = $2	"
+	query := `SELECT
+		aps.app_id,
+		aps.status,
+		aps.changed_at,
+		aps.duration,
+		aps.restart_count,
+		aps.health,
+		aps.health_output,
+		aps.exit_code,
+		aps.oom_killed
+	FROM apps_statuses aps
+	INNER JOIN apps a ON a.id = aps.app_id
+	WHERE aps.app_id = $1 AND a.owner_id = $2`
 	stmt, err := a.db.PrepareContext(ctx, query)
 	if err != nil {
 		a.loggerService.Error(failedToPrepareQuery, map[string]any{
@@ -230,7 +257,8 @@ func (a *AppRepository) GetAppStatus(ctx context.Context, appID string, ownerID
 
 	var appStatus DTO.AppStatus
 	err = stmt.QueryRowContext(ctx, appID, ownerID).Scan(&appStatus.AppID, &appStatus.Status, &appStatus.ChangedAt,
-		&appStatus.Duration)
+		&appStatus.Duration, &appStatus.RestartCount, &appStatus.Health, &appStatus.HealthOutput, &appStatus.ExitCode,
+		&appStatus.OOMKilled)
 	if err != nil {
 		a.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
 			"query": query,
@@ -249,7 +277,7 @@ func (a *AppRepository) GetAppsToCheck(ctx context.Context) ([]*models.AppToChec
 	    a.id,
 	    a.name,
 		a.owner_id,
-	    a.is_docker,
+	    a.runtime,
 	    a.ip_address,
 	    a.port,
 		COALESCE(aps.status, 'stopped')
@@ -286,7 +314,7 @@ func (a *AppRepository) GetAppsToCheck(ctx context.Context) ([]*models.AppToChec
 	apps := make([]*models.AppToCheck, 0)
 	for rows.Next() {
 		app := &models.AppToCheck{}
-		err := rows.Scan(&app.ID, &app.Name, &app.OwnerID, &app.IsDocker, &app.IPAddress, &app.Port, &app.Status)
+		err := rows.Scan(&app.ID, &app.Name, &app.OwnerID, &app.Runtime, &app.IPAddress, &app.Port, &app.Status)
 		if err != nil {
 			a.loggerService.Error(failedToScanRows, map[string]any{
 				"query": query,
@@ -351,13 +379,78 @@ func (a *AppRepository) UpdateApp(ctx context.Context, appID string, app DTO.Upd
 	return nil
 }
 
+func (a *AppRepository) UpdateAppID(ctx context.Context, appID, newAppID string, ownerID int) error {
+	query := `UPDATE apps SET id = $1 WHERE id = $2 AND owner_id = $3`
+	stmt, err := a.db.PrepareContext(ctx, query)
+	if err != nil {
+		a.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update app id")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			a.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, newAppID, appID, ownerID)
+	if err != nil {
+		a.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args": map[string]any{
+				"appID":    appID,
+				"newAppID": newAppID,
+			},
+			"err": err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update app id")
+	}
+
+	return nil
+}
+
+// UpdateAppStoppedByUser is called with the container id of a docker event, which does not tell the owner of the
+// app.
+func (a *AppRepository) UpdateAppStoppedByUser(ctx context.Context, appID string, stoppedByUser bool) error {
+	query := `UPDATE apps SET stopped_by_user = $1 WHERE id = $2`
+	stmt, err := a.db.PrepareContext(ctx, query)
+	if err != nil {
+		a.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			a.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, stoppedByUser, appID)
+	if err != nil {
+		a.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args":  []any{stoppedByUser, appID},
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+
+	return nil
+}
+
 func (a *AppRepository) InsertAppStatuses(ctx context.Context, appsStatuses []DTO.AppStatus) error {
 	placeholders := make([]string, 0, len(appsStatuses))
 	args := make([]any, 0, len(appsStatuses))
 	for i := range appsStatuses {
-		preparedValues := fmt.Sprintf("($%d,$%d,$%d,$%d)", i*4+1, i*4+2, i*4+3, i*4+4)
+		preparedValues := fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)", i*9+1, i*9+2, i*9+3, i*9+4, i*9+5,
+			i*9+6, i*9+7, i*9+8, i*9+9)
 		args = append(args, appsStatuses[i].AppID, appsStatuses[i].Status, appsStatuses[i].ChangedAt,
-			appsStatuses[i].Duration)
+			appsStatuses[i].Duration, appsStatuses[i].RestartCount, appsStatuses[i].Health,
+			appsStatuses[i].HealthOutput, appsStatuses[i].ExitCode, appsStatuses[i].OOMKilled)
 		placeholders = append(placeholders, preparedValues)
 	}
 
@@ -366,13 +459,23 @@ func (a *AppRepository) InsertAppStatuses(ctx context.Context, appsStatuses []DT
         app_id,
         status,
         changed_at,
-        duration
+        duration,
+        restart_count,
+        health,
+        health_output,
+        exit_code,
+        oom_killed
     ) VALUES %s
     ON CONFLICT (app_id) 
     DO UPDATE SET
         status = EXCLUDED.status,
         changed_at = EXCLUDED.changed_at,
-        duration = EXCLUDED.duration
+        duration = EXCLUDED.duration,
+        restart_count = EXCLUDED.restart_count,
+        health = EXCLUDED.health,
+        health_output = EXCLUDED.health_output,
+        exit_code = EXCLUDED.exit_code,
+        oom_killed = EXCLUDED.oom_killed
 `, strings.Join(placeholders, ","))
 
 	stmt, err := a.db.PrepareContext(ctx, query)
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type ConsoleAuditRepository struct {
+	db            *sql.DB
+	loggerService utils.LoggerService
+}
+
+func NewConsoleAuditRepository(db *sql.DB, loggerService utils.LoggerService) *ConsoleAuditRepository {
+	return &ConsoleAuditRepository{
+		db:            db,
+		loggerService: loggerService,
+	}
+}
+
+func (ca *ConsoleAuditRepository) InsertConsoleAuditLog(ctx context.Context,
+	consoleAuditLog DTO.ConsoleAuditLog,
+) error {
+	query := `INSERT INTO console_audit_logs (
+		session_id,
+		app_id,
+		user_id,
+		command,
+		created_at
+	) VALUES ($1, $2, $3, $4, $5)`
+	stmt, err := ca.db.PrepareContext(ctx, query)
+	if err != nil {
+		ca.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to save console audit log")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			ca.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, consoleAuditLog.SessionID, consoleAuditLog.AppID, consoleAuditLog.UserID,
+		consoleAuditLog.Command, consoleAuditLog.CreatedAt)
+	if err != nil {
+		ca.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"args":  consoleAuditLog,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to save console audit log")
+	}
+
+	return nil
+}
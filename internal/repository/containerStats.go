@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type ContainerStatsRepository struct {
+	db            *sql.DB
+	loggerService utils.LoggerService
+}
+
+func NewContainerStatsRepository(db *sql.DB, loggerService utils.LoggerService) *ContainerStatsRepository {
+	return &ContainerStatsRepository{
+		db:            db,
+		loggerService: loggerService,
+	}
+}
+
+func (cs *ContainerStatsRepository) InsertContainersStats(ctx context.Context,
+	containersStats []DTO.ContainerStats,
+) error {
+	const columnsCount = 11
+	placeholders := make([]string, 0, len(containersStats))
+	args := make([]any, 0, len(containersStats)*columnsCount)
+	for i := range containersStats {
+		rowPlaceholders := make([]string, 0, columnsCount)
+		for column := 1; column <= columnsCount; column++ {
+			rowPlaceholders = append(rowPlaceholders, fmt.Sprintf("$%d", i*columnsCount+column))
+		}
+		placeholders = append(placeholders, "("+strings.Join(rowPlaceholders, ",")+")")
+		args = append(args, containersStats[i].AppID, containersStats[i].CPUPercent, containersStats[i].MemoryUsage,
+			containersStats[i].MemoryLimit, containersStats[i].MemoryPercent, containersStats[i].NetworkRx,
+			containersStats[i].NetworkTx, containersStats[i].BlockRead, containersStats[i].BlockWrite,
+			containersStats[i].PIDs, containersStats[i].CreatedAt)
+	}
+
+	query := fmt.Sprintf(`
+	INSERT INTO containers_stats(
+		app_id,
+		cpu_percent,
+		memory_usage,
+		memory_limit,
+		memory_percent,
+		network_rx,
+		network_tx,
+		block_read,
+		block_write,
+		pids,
+		created_at
+	) VALUES %s`, strings.Join(placeholders, ","))
+
+	stmt, err := cs.db.PrepareContext(ctx, query)
+	if err != nil {
+		cs.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to add containers stats to the database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			cs.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, args...)
+	if err != nil {
+		cs.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"args":  containersStats,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to add containers stats to the database")
+	}
+
+	return nil
+}
+
+func (cs *ContainerStatsRepository) GetContainerStatsHistory(ctx context.Context, appID string, ownerID int,
+	since time.Time,
+) ([]DTO.ContainerStats, error) {
+	query := `
+	SELECT
+		cs.app_id,
+		cs.cpu_percent,
+		cs.memory_usage,
+		cs.memory_limit,
+		cs.memory_percent,
+		cs.network_rx,
+		cs.network_tx,
+		cs.block_read,
+		cs.block_write,
+		cs.pids,
+		cs.created_at
+	FROM containers_stats cs
+		JOIN apps a ON a.id = cs.app_id
+	WHERE cs.app_id = $1 AND a.owner_id = $2 AND cs.created_at >= $3
+	ORDER BY cs.created_at`
+	stmt, err := cs.db.PrepareContext(ctx, query)
+	if err != nil {
+		cs.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			cs.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	rows, err := stmt.QueryContext(ctx, appID, ownerID, since)
+	if err != nil {
+		cs.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  appID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			cs.loggerService.Error(failedToCloseRows, closeErr)
+		}
+	}()
+
+	containerStatsHistory := make([]DTO.ContainerStats, 0)
+	for rows.Next() {
+		var containerStats DTO.ContainerStats
+		err := rows.Scan(&containerStats.AppID, &containerStats.CPUPercent, &containerStats.MemoryUsage,
+			&containerStats.MemoryLimit, &containerStats.MemoryPercent, &containerStats.NetworkRx,
+			&containerStats.NetworkTx, &containerStats.BlockRead, &containerStats.BlockWrite, &containerStats.PIDs,
+			&containerStats.CreatedAt)
+		if err != nil {
+			cs.loggerService.Error(failedToScanRows, map[string]any{
+				"query": query,
+				"err":   err.Error(),
+			})
+			return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+		}
+		containerStatsHistory = append(containerStatsHistory, containerStats)
+	}
+
+	if err := rows.Err(); err != nil {
+		cs.loggerService.Error(failedToIterateOverRows, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return containerStatsHistory, nil
+}
+
+func (cs *ContainerStatsRepository) DeleteContainersStatsOlderThan(ctx context.Context, olderThan time.Time) error {
+	query := `DELETE FROM containers_stats WHERE created_at < $1`
+	stmt, err := cs.db.PrepareContext(ctx, query)
+	if err != nil {
+		cs.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to delete old containers stats")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			cs.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, olderThan)
+	if err != nil {
+		cs.loggerService.Error(failedToExecuteDeleteQuery, map[string]any{
+			"query": query,
+			"args":  olderThan,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to delete old containers stats")
+	}
+
+	return nil
+}
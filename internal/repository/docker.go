@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 
+	"github.com/slodkiadrianek/octopus/internal/models"
 	"github.com/slodkiadrianek/octopus/internal/utils"
 )
 
@@ -18,7 +21,88 @@ func NewDockerRepository(db *sql.DB, loggerService utils.LoggerService) *DockerR
 	}
 }
 
-func (dr *DockerRepository) ImportContainers(ownerID int) error {
-	// Implementation of importing docker containers
+func (dr *DockerRepository) GetDockerHost(ctx context.Context, host string) (*models.DockerHost, error) {
+	query := `SELECT
+		host,
+		COALESCE(tls_ca_cert, ''),
+		COALESCE(tls_cert, ''),
+		COALESCE(tls_key, ''),
+		COALESCE(ssh_identity_file, '')
+	FROM docker_hosts
+	WHERE host = $1`
+	stmt, err := dr.db.PrepareContext(ctx, query)
+	if err != nil {
+		dr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"args":  host,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			dr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var dockerHost models.DockerHost
+	err = stmt.QueryRowContext(ctx, host).Scan(&dockerHost.Host, &dockerHost.TLSCACert, &dockerHost.TLSCert,
+		&dockerHost.TLSKey, &dockerHost.SSHIdentityFile)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		dr.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  host,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return &dockerHost, nil
+}
+
+func (dr *DockerRepository) UpsertDockerHost(ctx context.Context, dockerHost models.DockerHost) error {
+	query := `INSERT INTO docker_hosts (
+		host,
+		tls_ca_cert,
+		tls_cert,
+		tls_key,
+		ssh_identity_file
+	) VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (host)
+	DO UPDATE SET
+		tls_ca_cert = EXCLUDED.tls_ca_cert,
+		tls_cert = EXCLUDED.tls_cert,
+		tls_key = EXCLUDED.tls_key,
+		ssh_identity_file = EXCLUDED.ssh_identity_file,
+		updated_at = CURRENT_TIMESTAMP`
+	stmt, err := dr.db.PrepareContext(ctx, query)
+	if err != nil {
+		dr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"args":  dockerHost.Host,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to save docker host configuration")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			dr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, dockerHost.Host, dockerHost.TLSCACert, dockerHost.TLSCert, dockerHost.TLSKey,
+		dockerHost.SSHIdentityFile)
+	if err != nil {
+		dr.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"args":  dockerHost.Host,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to save docker host configuration")
+	}
+
 	return nil
 }
@@ -1,5 +1,11 @@
 package repository
 
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
 const failedToPrepareQuery = "failed to prepared statement for execution"
 
 const (
@@ -21,3 +27,24 @@ const (
 	failedToCloseStatement = "failed to close statement"
 	failedToCloseRows      = "failed to close rows"
 )
+
+const (
+	failedToBeginTransaction    = "failed to begin transaction"
+	failedToCommitTransaction   = "failed to commit transaction"
+	failedToRollbackTransaction = "failed to rollback transaction"
+)
+
+const (
+	uniqueViolationCode          = "23505"
+	invalidRegularExpressionCode = "2201B"
+)
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode
+}
+
+func isInvalidRegularExpression(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == invalidRegularExpressionCode
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type KubernetesRepository struct {
+	db            *sql.DB
+	loggerService utils.LoggerService
+}
+
+func NewKubernetesRepository(db *sql.DB, loggerService utils.LoggerService) *KubernetesRepository {
+	return &KubernetesRepository{
+		db:            db,
+		loggerService: loggerService,
+	}
+}
+
+func (kr *KubernetesRepository) GetKubernetesCluster(ctx context.Context, ownerID int,
+	name string,
+) (*models.KubernetesCluster, error) {
+	query := `SELECT
+		owner_id,
+		name,
+		kubeconfig
+	FROM kubernetes_clusters
+	WHERE owner_id = $1 AND name = $2`
+	stmt, err := kr.db.PrepareContext(ctx, query)
+	if err != nil {
+		kr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"args":  name,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			kr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var cluster models.KubernetesCluster
+	err = stmt.QueryRowContext(ctx, ownerID, name).Scan(&cluster.OwnerID, &cluster.Name, &cluster.Kubeconfig)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		kr.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  name,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return &cluster, nil
+}
+
+func (kr *KubernetesRepository) UpsertKubernetesCluster(ctx context.Context, cluster models.KubernetesCluster) error {
+	query := `INSERT INTO kubernetes_clusters (
+		owner_id,
+		name,
+		kubeconfig
+	) VALUES ($1, $2, $3)
+	ON CONFLICT (owner_id, name)
+	DO UPDATE SET
+		kubeconfig = EXCLUDED.kubeconfig,
+		updated_at = CURRENT_TIMESTAMP`
+	stmt, err := kr.db.PrepareContext(ctx, query)
+	if err != nil {
+		kr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"args":  cluster.Name,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to save kubernetes cluster configuration")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			kr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, cluster.OwnerID, cluster.Name, cluster.Kubeconfig)
+	if err != nil {
+		kr.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"args":  cluster.Name,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to save kubernetes cluster configuration")
+	}
+
+	return nil
+}
+
+// ImportKubernetesWorkloads inserts the apps and their workloads in one transaction, so an app is never left
+// without its workload.
+func (kr *KubernetesRepository) ImportKubernetesWorkloads(ctx context.Context, apps []DTO.App,
+	workloads []models.KubernetesWorkload,
+) error {
+	tx, err := kr.db.BeginTx(ctx, nil)
+	if err != nil {
+		kr.loggerService.Error(failedToBeginTransaction, err)
+		return models.NewError(500, "Database", "failed to add kubernetes workloads to the database")
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			kr.loggerService.Error(failedToRollbackTransaction, rollbackErr)
+		}
+	}()
+
+	err = insertApps(ctx, tx, kr.loggerService, apps)
+	if err != nil {
+		return err
+	}
+	err = kr.insertKubernetesWorkloads(ctx, tx, workloads)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		kr.loggerService.Error(failedToCommitTransaction, err)
+		return models.NewError(500, "Database", "failed to add kubernetes workloads to the database")
+	}
+
+	return nil
+}
+
+func (kr *KubernetesRepository) insertKubernetesWorkloads(ctx context.Context, tx statementPreparer,
+	workloads []models.KubernetesWorkload,
+) error {
+	placeholders := make([]string, 0, len(workloads))
+	args := make([]any, 0, len(workloads)*6)
+	for i := range workloads {
+		preparedValues := fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d)", i*6+1, i*6+2, i*6+3, i*6+4, i*6+5, i*6+6)
+		args = append(args, workloads[i].AppID, workloads[i].OwnerID, workloads[i].Cluster, workloads[i].Namespace,
+			workloads[i].Kind, workloads[i].Name)
+		placeholders = append(placeholders, preparedValues)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO kubernetes_workloads (
+		app_id,
+		owner_id,
+		cluster,
+		namespace,
+		kind,
+		name
+	) VALUES %s`, strings.Join(placeholders, ","))
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		kr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"args":  workloads,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to add kubernetes workloads to the database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			kr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, args...)
+	if err != nil {
+		kr.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"args":  workloads,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to add kubernetes workloads to the database")
+	}
+
+	return nil
+}
+
+func (kr *KubernetesRepository) GetKubernetesWorkload(ctx context.Context,
+	appID string,
+) (*models.KubernetesWorkload, error) {
+	query := `SELECT
+		app_id,
+		owner_id,
+		cluster,
+		namespace,
+		kind,
+		name
+	FROM kubernetes_workloads
+	WHERE app_id = $1`
+	stmt, err := kr.db.PrepareContext(ctx, query)
+	if err != nil {
+		kr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"args":  appID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			kr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var workload models.KubernetesWorkload
+	err = stmt.QueryRowContext(ctx, appID).Scan(&workload.AppID, &workload.OwnerID, &workload.Cluster,
+		&workload.Namespace, &workload.Kind, &workload.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.NewError(404, "Kubernetes", "kubernetes workload of the app not found")
+	}
+	if err != nil {
+		kr.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  appID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return &workload, nil
+}
+
+func (kr *KubernetesRepository) GetKubernetesWorkloads(ctx context.Context) ([]models.KubernetesWorkload, error) {
+	query := `SELECT
+		app_id,
+		owner_id,
+		cluster,
+		namespace,
+		kind,
+		name
+	FROM kubernetes_workloads`
+	stmt, err := kr.db.PrepareContext(ctx, query)
+	if err != nil {
+		kr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			kr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		kr.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			kr.loggerService.Error(failedToCloseRows, closeErr)
+		}
+	}()
+
+	workloads := make([]models.KubernetesWorkload, 0)
+	for rows.Next() {
+		var workload models.KubernetesWorkload
+		err := rows.Scan(&workload.AppID, &workload.OwnerID, &workload.Cluster, &workload.Namespace, &workload.Kind,
+			&workload.Name)
+		if err != nil {
+			kr.loggerService.Error(failedToScanRows, err)
+			return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+		}
+		workloads = append(workloads, workload)
+	}
+
+	if err := rows.Err(); err != nil {
+		kr.loggerService.Error(failedToIterateOverRows, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return workloads, nil
+}
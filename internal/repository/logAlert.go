@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type LogAlertRepository struct {
+	db            *sql.DB
+	loggerService utils.LoggerService
+}
+
+func NewLogAlertRepository(db *sql.DB, loggerService utils.LoggerService) *LogAlertRepository {
+	return &LogAlertRepository{
+		db:            db,
+		loggerService: loggerService,
+	}
+}
+
+func (la *LogAlertRepository) GetLogAlertRules(ctx context.Context, appID string,
+	ownerID int,
+) ([]DTO.LogAlertRule, error) {
+	query := `
+	SELECT
+		r.id,
+		r.app_id,
+		r.name,
+		r.pattern,
+		r.window_seconds,
+		r.threshold,
+		r.cooldown_seconds,
+		r.enabled,
+		r.last_triggered_at
+	FROM log_alert_rules r
+		JOIN apps a ON a.id = r.app_id
+	WHERE r.app_id = $1 AND a.owner_id = $2
+	ORDER BY r.id`
+	stmt, err := la.db.PrepareContext(ctx, query)
+	if err != nil {
+		la.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			la.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	rows, err := stmt.QueryContext(ctx, appID, ownerID)
+	if err != nil {
+		la.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  appID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			la.loggerService.Error(failedToCloseRows, closeErr)
+		}
+	}()
+
+	logAlertRules := make([]DTO.LogAlertRule, 0)
+	for rows.Next() {
+		var logAlertRule DTO.LogAlertRule
+		var lastTriggeredAt sql.NullTime
+		err := rows.Scan(&logAlertRule.ID, &logAlertRule.AppID, &logAlertRule.Name, &logAlertRule.Pattern,
+			&logAlertRule.WindowSeconds, &logAlertRule.Threshold, &logAlertRule.CooldownSeconds, &logAlertRule.Enabled,
+			&lastTriggeredAt)
+		if err != nil {
+			la.loggerService.Error(failedToScanRows, map[string]any{
+				"query": query,
+				"err":   err.Error(),
+			})
+			return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+		}
+		if lastTriggeredAt.Valid {
+			logAlertRule.LastTriggeredAt = &lastTriggeredAt.Time
+		}
+		logAlertRules = append(logAlertRules, logAlertRule)
+	}
+
+	if err := rows.Err(); err != nil {
+		la.loggerService.Error(failedToIterateOverRows, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return logAlertRules, nil
+}
+
+func (la *LogAlertRepository) InsertLogAlertRule(ctx context.Context, appID string,
+	logAlertRule DTO.CreateLogAlertRule,
+) (int, error) {
+	query := `
+	INSERT INTO log_alert_rules (
+		app_id,
+		name,
+		pattern,
+		window_seconds,
+		threshold,
+		cooldown_seconds,
+		enabled
+	) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id`
+	stmt, err := la.db.PrepareContext(ctx, query)
+	if err != nil {
+		la.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return 0, models.NewError(500, "Database", "failed to save log alert rule")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			la.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var ruleID int
+	err = stmt.QueryRowContext(ctx, appID, logAlertRule.Name, logAlertRule.Pattern, logAlertRule.WindowSeconds,
+		logAlertRule.Threshold, logAlertRule.CooldownSeconds, logAlertRule.Enabled).Scan(&ruleID)
+	if err != nil {
+		la.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"args":  logAlertRule,
+			"err":   err.Error(),
+		})
+		return 0, models.NewError(500, "Database", "failed to save log alert rule")
+	}
+
+	return ruleID, nil
+}
+
+func (la *LogAlertRepository) UpdateLogAlertRule(ctx context.Context, appID string, ruleID, ownerID int,
+	logAlertRule DTO.CreateLogAlertRule,
+) error {
+	query := `
+	UPDATE log_alert_rules r SET
+		name = $1,
+		pattern = $2,
+		window_seconds = $3,
+		threshold = $4,
+		cooldown_seconds = $5,
+		enabled = $6
+	FROM apps a
+	WHERE a.id = r.app_id AND r.id = $7 AND r.app_id = $8 AND a.owner_id = $9`
+	stmt, err := la.db.PrepareContext(ctx, query)
+	if err != nil {
+		la.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			la.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	result, err := stmt.ExecContext(ctx, logAlertRule.Name, logAlertRule.Pattern, logAlertRule.WindowSeconds,
+		logAlertRule.Threshold, logAlertRule.CooldownSeconds, logAlertRule.Enabled, ruleID, appID, ownerID)
+	if err != nil {
+		la.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args":  logAlertRule,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+
+	return la.checkLogAlertRuleFound(result)
+}
+
+func (la *LogAlertRepository) DeleteLogAlertRule(ctx context.Context, appID string, ruleID, ownerID int) error {
+	query := `
+	DELETE FROM log_alert_rules r
+	USING apps a
+	WHERE a.id = r.app_id AND r.id = $1 AND r.app_id = $2 AND a.owner_id = $3`
+	stmt, err := la.db.PrepareContext(ctx, query)
+	if err != nil {
+		la.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to delete log alert rule")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			la.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	result, err := stmt.ExecContext(ctx, ruleID, appID, ownerID)
+	if err != nil {
+		la.loggerService.Error(failedToExecuteDeleteQuery, map[string]any{
+			"query": query,
+			"args":  []any{ruleID, appID, ownerID},
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to delete log alert rule")
+	}
+
+	return la.checkLogAlertRuleFound(result)
+}
+
+func (la *LogAlertRepository) checkLogAlertRuleFound(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		la.loggerService.Error("failed to read affected rows", err)
+		return models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	if rowsAffected == 0 {
+		return models.NewError(404, "LogAlert", "log alert rule not found")
+	}
+
+	return nil
+}
+
+// ValidateLogAlertPattern checks the pattern with the Postgres regular expressions, which match the lines.
+func (la *LogAlertRepository) ValidateLogAlertPattern(ctx context.Context, pattern string) error {
+	query := `SELECT '' ~ $1`
+	stmt, err := la.db.PrepareContext(ctx, query)
+	if err != nil {
+		la.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			la.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var matched bool
+	err = stmt.QueryRowContext(ctx, pattern).Scan(&matched)
+	if err != nil {
+		if isInvalidRegularExpression(err) {
+			return models.NewError(400, "Validation", "pattern is not a valid regular expression")
+		}
+		la.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return nil
+}
+
+// GetDueLogAlertRules returns the enabled rules which are not in the cooldown.
+func (la *LogAlertRepository) GetDueLogAlertRules(ctx context.Context, now time.Time) ([]DTO.LogAlertRule, error) {
+	query := `
+	SELECT
+		id,
+		app_id,
+		name,
+		pattern,
+		window_seconds,
+		threshold,
+		cooldown_seconds,
+		enabled,
+		last_triggered_at
+	FROM log_alert_rules
+	WHERE enabled
+		AND (last_triggered_at IS NULL
+			OR last_triggered_at <= $1::timestamp - make_interval(secs => cooldown_seconds))
+	ORDER BY id`
+	stmt, err := la.db.PrepareContext(ctx, query)
+	if err != nil {
+		la.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			la.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	rows, err := stmt.QueryContext(ctx, now)
+	if err != nil {
+		la.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			la.loggerService.Error(failedToCloseRows, closeErr)
+		}
+	}()
+
+	logAlertRules := make([]DTO.LogAlertRule, 0)
+	for rows.Next() {
+		var logAlertRule DTO.LogAlertRule
+		var lastTriggeredAt sql.NullTime
+		err := rows.Scan(&logAlertRule.ID, &logAlertRule.AppID, &logAlertRule.Name, &logAlertRule.Pattern,
+			&logAlertRule.WindowSeconds, &logAlertRule.Threshold, &logAlertRule.CooldownSeconds, &logAlertRule.Enabled,
+			&lastTriggeredAt)
+		if err != nil {
+			la.loggerService.Error(failedToScanRows, map[string]any{
+				"query": query,
+				"err":   err.Error(),
+			})
+			return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+		}
+		if lastTriggeredAt.Valid {
+			logAlertRule.LastTriggeredAt = &lastTriggeredAt.Time
+		}
+		logAlertRules = append(logAlertRules, logAlertRule)
+	}
+
+	if err := rows.Err(); err != nil {
+		la.loggerService.Error(failedToIterateOverRows, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return logAlertRules, nil
+}
+
+// CountMatchingLogLines counts the lines of the app logged since the given time which match the pattern.
+func (la *LogAlertRepository) CountMatchingLogLines(ctx context.Context, appID, pattern string,
+	since time.Time,
+) (int, error) {
+	query := `SELECT COUNT(*) FROM apps_logs WHERE app_id = $1 AND logged_at >= $2 AND line ~ $3`
+	stmt, err := la.db.PrepareContext(ctx, query)
+	if err != nil {
+		la.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return 0, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			la.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var matchedLines int
+	err = stmt.QueryRowContext(ctx, appID, since, pattern).Scan(&matchedLines)
+	if err != nil {
+		la.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"appID": appID,
+			"err":   err.Error(),
+		})
+		return 0, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return matchedLines, nil
+}
+
+func (la *LogAlertRepository) UpdateLogAlertsLastTriggered(ctx context.Context, rulesIDs []int,
+	triggeredAt time.Time,
+) error {
+	placeholders := make([]string, 0, len(rulesIDs))
+	args := make([]any, 0, len(rulesIDs)+1)
+	args = append(args, triggeredAt)
+	for i, ruleID := range rulesIDs {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+2))
+		args = append(args, ruleID)
+	}
+
+	query := fmt.Sprintf(`UPDATE log_alert_rules SET last_triggered_at = $1::timestamp WHERE id IN (%s)`,
+		strings.Join(placeholders, ","))
+	stmt, err := la.db.PrepareContext(ctx, query)
+	if err != nil {
+		la.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			la.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, args...)
+	if err != nil {
+		la.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args":  args,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+
+	return nil
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type LogRepository struct {
+	db            *sql.DB
+	loggerService utils.LoggerService
+}
+
+func NewLogRepository(db *sql.DB, loggerService utils.LoggerService) *LogRepository {
+	return &LogRepository{
+		db:            db,
+		loggerService: loggerService,
+	}
+}
+
+func (l *LogRepository) InsertLogs(ctx context.Context, logs []DTO.AppLog) error {
+	const columnsCount = 5
+	placeholders := make([]string, 0, len(logs))
+	args := make([]any, 0, len(logs)*columnsCount)
+	for i, log := range logs {
+		placeholders = append(placeholders, fmt.Sprintf("($%d,$%d,$%d,$%d,$%d)", i*columnsCount+1,
+			i*columnsCount+2, i*columnsCount+3, i*columnsCount+4, i*columnsCount+5))
+		args = append(args, log.AppID, log.Stream, log.Level, log.Line, log.LoggedAt)
+	}
+
+	query := fmt.Sprintf(`
+	INSERT INTO apps_logs(
+		app_id,
+		stream,
+		level,
+		line,
+		logged_at
+	) VALUES %s`, strings.Join(placeholders, ","))
+	stmt, err := l.db.PrepareContext(ctx, query)
+	if err != nil {
+		l.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to add logs to the database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			l.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, args...)
+	if err != nil {
+		l.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to add logs to the database")
+	}
+
+	return nil
+}
+
+// GetLastLogTime returns a zero time when no logs of the app were collected yet.
+func (l *LogRepository) GetLastLogTime(ctx context.Context, appID string) (time.Time, error) {
+	query := `SELECT MAX(logged_at) FROM apps_logs WHERE app_id = $1`
+	stmt, err := l.db.PrepareContext(ctx, query)
+	if err != nil {
+		l.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return time.Time{}, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			l.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var lastLogTime sql.NullTime
+	err = stmt.QueryRowContext(ctx, appID).Scan(&lastLogTime)
+	if err != nil {
+		l.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  appID,
+			"err":   err.Error(),
+		})
+		return time.Time{}, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return lastLogTime.Time, nil
+}
+
+func (l *LogRepository) DeleteExpiredLogs(ctx context.Context) error {
+	query := `
+	DELETE FROM apps_logs l
+	USING apps a
+	WHERE l.app_id = a.id AND l.logged_at < NOW() - make_interval(days => a.logs_retention_days)`
+	stmt, err := l.db.PrepareContext(ctx, query)
+	if err != nil {
+		l.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to delete expired logs")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			l.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		l.loggerService.Error(failedToExecuteDeleteQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to delete expired logs")
+	}
+
+	return nil
+}
+
+// escapedLogLine is the log line escaped for HTML. The escaped characters are parsed as entities, so the words of
+// the line are still matched by the search.
+const escapedLogLine = `replace(replace(replace(replace(replace(l.line, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+	'"', '&quot;'), '''', '&#39;')`
+
+// SearchLogs searches the logs of all apps of the owner, newest first. The highlight is the line escaped for HTML
+// with the matched words wrapped in <mark> tags.
+func (l *LogRepository) SearchLogs(ctx context.Context, ownerID int,
+	logsSearch DTO.LogsSearch,
+) (DTO.LogsSearchResult, error) {
+	args := []any{ownerID}
+	conditions := []string{"a.owner_id = $1"}
+	highlight := escapedLogLine
+	if logsSearch.Query != "" {
+		args = append(args, logsSearch.Query)
+		conditions = append(conditions, fmt.Sprintf("l.search @@ websearch_to_tsquery('simple', $%d)", len(args)))
+		highlight = fmt.Sprintf(`ts_headline('simple', %s, websearch_to_tsquery('simple', $%d),
+			'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')`, escapedLogLine, len(args))
+	}
+	if logsSearch.AppID != "" {
+		args = append(args, logsSearch.AppID)
+		conditions = append(conditions, fmt.Sprintf("l.app_id = $%d", len(args)))
+	}
+	if logsSearch.Level != "" {
+		args = append(args, logsSearch.Level)
+		conditions = append(conditions, fmt.Sprintf("l.level = $%d", len(args)))
+	}
+	if !logsSearch.From.IsZero() {
+		args = append(args, logsSearch.From)
+		conditions = append(conditions, fmt.Sprintf("l.logged_at >= $%d", len(args)))
+	}
+	if !logsSearch.To.IsZero() {
+		args = append(args, logsSearch.To)
+		conditions = append(conditions, fmt.Sprintf("l.logged_at <= $%d", len(args)))
+	}
+	args = append(args, logsSearch.Limit, (logsSearch.Page-1)*logsSearch.Limit)
+
+	query := fmt.Sprintf(`
+	SELECT
+		l.app_id,
+		a.name,
+		l.stream,
+		l.level,
+		l.line,
+		%s,
+		l.logged_at,
+		COUNT(*) OVER()
+	FROM apps_logs l
+		JOIN apps a ON a.id = l.app_id
+	WHERE %s
+	ORDER BY l.logged_at DESC, l.id DESC
+	LIMIT $%d OFFSET $%d`, highlight, strings.Join(conditions, " AND "), len(args)-1, len(args))
+	stmt, err := l.db.PrepareContext(ctx, query)
+	if err != nil {
+		l.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return DTO.LogsSearchResult{}, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			l.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		l.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  args,
+			"err":   err.Error(),
+		})
+		return DTO.LogsSearchResult{}, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			l.loggerService.Error(failedToCloseRows, closeErr)
+		}
+	}()
+
+	logsSearchResult := DTO.LogsSearchResult{
+		Logs:  make([]DTO.LogSearchHit, 0, logsSearch.Limit),
+		Page:  logsSearch.Page,
+		Limit: logsSearch.Limit,
+	}
+	for rows.Next() {
+		var logSearchHit DTO.LogSearchHit
+		err := rows.Scan(&logSearchHit.AppID, &logSearchHit.AppName, &logSearchHit.Stream, &logSearchHit.Level,
+			&logSearchHit.Line, &logSearchHit.Highlight, &logSearchHit.LoggedAt, &logsSearchResult.Total)
+		if err != nil {
+			l.loggerService.Error(failedToScanRows, map[string]any{
+				"query": query,
+				"err":   err.Error(),
+			})
+			return DTO.LogsSearchResult{}, models.NewError(500, "Database", failedToGetDataFromDatabase)
+		}
+		logsSearchResult.Logs = append(logsSearchResult.Logs, logSearchHit)
+	}
+
+	if err := rows.Err(); err != nil {
+		l.loggerService.Error(failedToIterateOverRows, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return DTO.LogsSearchResult{}, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return logsSearchResult, nil
+}
+
+func (l *LogRepository) UpdateLogsRetention(ctx context.Context, appID string, ownerID, days int) error {
+	query := `UPDATE apps SET logs_retention_days = $1 WHERE id = $2 AND owner_id = $3`
+	stmt, err := l.db.PrepareContext(ctx, query)
+	if err != nil {
+		l.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			l.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	result, err := stmt.ExecContext(ctx, days, appID, ownerID)
+	if err != nil {
+		l.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args":  []any{days, appID, ownerID},
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		l.loggerService.Error("failed to read affected rows", err)
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+	if rowsAffected == 0 {
+		return models.NewError(404, "App", "app not found")
+	}
+
+	return nil
+}
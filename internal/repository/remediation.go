@@ -0,0 +1,471 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+// remediationHistoryLimit is the number of the latest history entries returned for an app.
+const remediationHistoryLimit = 100
+
+type RemediationRepository struct {
+	db            *sql.DB
+	loggerService utils.LoggerService
+}
+
+func NewRemediationRepository(db *sql.DB, loggerService utils.LoggerService) *RemediationRepository {
+	return &RemediationRepository{
+		db:            db,
+		loggerService: loggerService,
+	}
+}
+
+func (rr *RemediationRepository) GetRemediationPolicy(ctx context.Context, appID string,
+	ownerID int,
+) (*DTO.RemediationPolicy, error) {
+	query := `
+	SELECT
+		p.app_id,
+		p.max_attempts,
+		p.backoff_seconds,
+		p.restart,
+		p.webhook_url,
+		p.script,
+		p.enabled,
+		p.maintenance_until,
+		p.attempts,
+		p.last_attempt_at,
+		p.escalated
+	FROM remediation_policies p
+		JOIN apps a ON a.id = p.app_id
+	WHERE p.app_id = $1 AND a.owner_id = $2`
+	stmt, err := rr.db.PrepareContext(ctx, query)
+	if err != nil {
+		rr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			rr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var policy DTO.RemediationPolicy
+	var maintenanceUntil, lastAttemptAt sql.NullTime
+	err = stmt.QueryRowContext(ctx, appID, ownerID).Scan(&policy.AppID, &policy.MaxAttempts, &policy.BackoffSeconds,
+		&policy.Restart, &policy.WebhookURL, &policy.Script, &policy.Enabled, &maintenanceUntil, &policy.Attempts,
+		&lastAttemptAt, &policy.Escalated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.NewError(404, "Remediation", "remediation policy not found")
+	}
+	if err != nil {
+		rr.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  appID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	if maintenanceUntil.Valid {
+		policy.MaintenanceUntil = &maintenanceUntil.Time
+	}
+	if lastAttemptAt.Valid {
+		policy.LastAttemptAt = &lastAttemptAt.Time
+	}
+
+	return &policy, nil
+}
+
+// UpsertRemediationPolicy saves the policy of the app. A changed policy starts counting the attempts again.
+func (rr *RemediationRepository) UpsertRemediationPolicy(ctx context.Context, appID string,
+	policy DTO.UpsertRemediationPolicy,
+) error {
+	query := `
+	INSERT INTO remediation_policies (
+		app_id,
+		max_attempts,
+		backoff_seconds,
+		restart,
+		webhook_url,
+		script,
+		enabled
+	) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (app_id)
+	DO UPDATE SET
+		max_attempts = EXCLUDED.max_attempts,
+		backoff_seconds = EXCLUDED.backoff_seconds,
+		restart = EXCLUDED.restart,
+		webhook_url = EXCLUDED.webhook_url,
+		script = EXCLUDED.script,
+		enabled = EXCLUDED.enabled,
+		attempts = 0,
+		last_attempt_at = NULL,
+		escalated = FALSE`
+	stmt, err := rr.db.PrepareContext(ctx, query)
+	if err != nil {
+		rr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to save remediation policy")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			rr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, appID, policy.MaxAttempts, policy.BackoffSeconds, policy.Restart,
+		policy.WebhookURL, policy.Script, policy.Enabled)
+	if err != nil {
+		rr.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"args":  policy,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to save remediation policy")
+	}
+
+	return nil
+}
+
+func (rr *RemediationRepository) DeleteRemediationPolicy(ctx context.Context, appID string, ownerID int) error {
+	query := `
+	DELETE FROM remediation_policies p
+	USING apps a
+	WHERE a.id = p.app_id AND p.app_id = $1 AND a.owner_id = $2`
+	stmt, err := rr.db.PrepareContext(ctx, query)
+	if err != nil {
+		rr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to delete remediation policy")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			rr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	result, err := stmt.ExecContext(ctx, appID, ownerID)
+	if err != nil {
+		rr.loggerService.Error(failedToExecuteDeleteQuery, map[string]any{
+			"query": query,
+			"args":  []any{appID, ownerID},
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to delete remediation policy")
+	}
+
+	return rr.checkRemediationPolicyFound(result)
+}
+
+// UpdateRemediationMaintenance pauses the policy until maintenanceUntil, nil ends the maintenance.
+func (rr *RemediationRepository) UpdateRemediationMaintenance(ctx context.Context, appID string, ownerID int,
+	maintenanceUntil *time.Time,
+) error {
+	query := `
+	UPDATE remediation_policies p SET
+		maintenance_until = $1
+	FROM apps a
+	WHERE a.id = p.app_id AND p.app_id = $2 AND a.owner_id = $3`
+	stmt, err := rr.db.PrepareContext(ctx, query)
+	if err != nil {
+		rr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			rr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	result, err := stmt.ExecContext(ctx, maintenanceUntil, appID, ownerID)
+	if err != nil {
+		rr.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args":  []any{maintenanceUntil, appID, ownerID},
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+
+	return rr.checkRemediationPolicyFound(result)
+}
+
+func (rr *RemediationRepository) checkRemediationPolicyFound(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		rr.loggerService.Error("failed to read affected rows", err)
+		return models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	if rowsAffected == 0 {
+		return models.NewError(404, "Remediation", "remediation policy not found")
+	}
+
+	return nil
+}
+
+// GetAppsToRemediate returns the apps with an enabled policy which is not in maintenance, together with their last
+// status. Apps without a status yet are skipped.
+func (rr *RemediationRepository) GetAppsToRemediate(ctx context.Context,
+	now time.Time,
+) ([]models.AppToRemediate, error) {
+	query := `
+	SELECT
+		a.id,
+		a.name,
+		a.runtime,
+		a.owner_id,
+		aps.status,
+		aps.exit_code,
+		aps.oom_killed,
+		a.stopped_by_user,
+		p.max_attempts,
+		p.backoff_seconds,
+		p.restart,
+		p.webhook_url,
+		p.script,
+		p.attempts,
+		p.last_attempt_at,
+		p.escalated
+	FROM remediation_policies p
+		JOIN apps a ON a.id = p.app_id
+		JOIN apps_statuses aps ON aps.app_id = p.app_id
+	WHERE p.enabled AND (p.maintenance_until IS NULL OR p.maintenance_until <= $1::timestamp)`
+	stmt, err := rr.db.PrepareContext(ctx, query)
+	if err != nil {
+		rr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			rr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	rows, err := stmt.QueryContext(ctx, now)
+	if err != nil {
+		rr.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			rr.loggerService.Error(failedToCloseRows, closeErr)
+		}
+	}()
+
+	appsToRemediate := make([]models.AppToRemediate, 0)
+	for rows.Next() {
+		var appToRemediate models.AppToRemediate
+		var lastAttemptAt sql.NullTime
+		err := rows.Scan(&appToRemediate.ID, &appToRemediate.Name, &appToRemediate.Runtime, &appToRemediate.OwnerID,
+			&appToRemediate.Status, &appToRemediate.ExitCode, &appToRemediate.OOMKilled, &appToRemediate.StoppedByUser,
+			&appToRemediate.MaxAttempts, &appToRemediate.BackoffSeconds, &appToRemediate.Restart,
+			&appToRemediate.WebhookURL, &appToRemediate.Script, &appToRemediate.Attempts, &lastAttemptAt,
+			&appToRemediate.Escalated)
+		if err != nil {
+			rr.loggerService.Error(failedToScanRows, map[string]any{
+				"query": query,
+				"err":   err.Error(),
+			})
+			return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+		}
+		if lastAttemptAt.Valid {
+			appToRemediate.LastAttemptAt = &lastAttemptAt.Time
+		}
+		appsToRemediate = append(appsToRemediate, appToRemediate)
+	}
+
+	if err := rows.Err(); err != nil {
+		rr.loggerService.Error(failedToIterateOverRows, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return appsToRemediate, nil
+}
+
+func (rr *RemediationRepository) UpdateRemediationState(ctx context.Context, appID string, attempts int,
+	lastAttemptAt *time.Time, escalated bool,
+) error {
+	query := `
+	UPDATE remediation_policies SET
+		attempts = $1,
+		last_attempt_at = $2,
+		escalated = $3
+	WHERE app_id = $4`
+	stmt, err := rr.db.PrepareContext(ctx, query)
+	if err != nil {
+		rr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			rr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, attempts, lastAttemptAt, escalated, appID)
+	if err != nil {
+		rr.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args":  []any{attempts, lastAttemptAt, escalated, appID},
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update data in database")
+	}
+
+	return nil
+}
+
+func (rr *RemediationRepository) InsertRemediationAttempts(ctx context.Context,
+	remediationAttempts []DTO.RemediationAttempt,
+) error {
+	placeholders := make([]string, 0, len(remediationAttempts))
+	args := make([]any, 0, len(remediationAttempts)*7)
+	for i := range remediationAttempts {
+		preparedValues := fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d)", i*7+1, i*7+2, i*7+3, i*7+4, i*7+5, i*7+6,
+			i*7+7)
+		args = append(args, remediationAttempts[i].AppID, remediationAttempts[i].Attempt,
+			remediationAttempts[i].Action, remediationAttempts[i].Status, remediationAttempts[i].Success,
+			remediationAttempts[i].Error, remediationAttempts[i].CreatedAt)
+		placeholders = append(placeholders, preparedValues)
+	}
+
+	query := fmt.Sprintf(`
+	INSERT INTO remediation_history (
+		app_id,
+		attempt,
+		action,
+		status,
+		success,
+		error,
+		created_at
+	) VALUES %s`, strings.Join(placeholders, ","))
+	stmt, err := rr.db.PrepareContext(ctx, query)
+	if err != nil {
+		rr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"args":  remediationAttempts,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to add remediation history to the database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			rr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, args...)
+	if err != nil {
+		rr.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"args":  remediationAttempts,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to add remediation history to the database")
+	}
+
+	return nil
+}
+
+func (rr *RemediationRepository) GetRemediationHistory(ctx context.Context, appID string,
+	ownerID int,
+) ([]DTO.RemediationAttempt, error) {
+	query := `
+	SELECT
+		h.app_id,
+		h.attempt,
+		h.action,
+		h.status,
+		h.success,
+		h.error,
+		h.created_at
+	FROM remediation_history h
+		JOIN apps a ON a.id = h.app_id
+	WHERE h.app_id = $1 AND a.owner_id = $2
+	ORDER BY h.created_at DESC, h.id DESC
+	LIMIT $3`
+	stmt, err := rr.db.PrepareContext(ctx, query)
+	if err != nil {
+		rr.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			rr.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	rows, err := stmt.QueryContext(ctx, appID, ownerID, remediationHistoryLimit)
+	if err != nil {
+		rr.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  appID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			rr.loggerService.Error(failedToCloseRows, closeErr)
+		}
+	}()
+
+	remediationHistory := make([]DTO.RemediationAttempt, 0)
+	for rows.Next() {
+		var remediationAttempt DTO.RemediationAttempt
+		err := rows.Scan(&remediationAttempt.AppID, &remediationAttempt.Attempt, &remediationAttempt.Action,
+			&remediationAttempt.Status, &remediationAttempt.Success, &remediationAttempt.Error,
+			&remediationAttempt.CreatedAt)
+		if err != nil {
+			rr.loggerService.Error(failedToScanRows, map[string]any{
+				"query": query,
+				"err":   err.Error(),
+			})
+			return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+		}
+		remediationHistory = append(remediationHistory, remediationAttempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		rr.loggerService.Error(failedToIterateOverRows, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return remediationHistory, nil
+}
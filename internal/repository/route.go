@@ -3,14 +3,20 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/models"
 	"github.com/slodkiadrianek/octopus/internal/utils"
 )
 
+// routeFlowRunsLimit is the number of the latest runs kept for every flow.
+const routeFlowRunsLimit = 100
+
 type RouteRepository struct {
 	db            *sql.DB
 	loggerService utils.LoggerService
@@ -23,53 +29,396 @@ func NewRouteRepository(db *sql.DB, loggerService utils.LoggerService) *RouteRep
 	}
 }
 
-func (r *RouteRepository) CheckRouteStatus(ctx context.Context, routeID int) (string, error) {
-	query := "SELECT status FROM working_routes WHERE id = $1"
+func (r *RouteRepository) InsertRouteFlow(ctx context.Context, appID, name, env, secrets, httpOptions,
+	notifications string,
+) (int, error) {
+	insertQuery := `INSERT INTO route_flows (app_id, name, env, secrets, http_options, notifications)
+	VALUES ($1, $2, $3::jsonb, $4, $5::jsonb, $6::jsonb) RETURNING id`
+	stmt, err := r.db.PrepareContext(ctx, insertQuery)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": insertQuery,
+			"err":   err.Error(),
+		})
+		return 0, models.NewError(500, "Database", "failed to insert route flow")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var flowID int
+	err = stmt.QueryRowContext(ctx, appID, name, env, secrets, httpOptions, notifications).Scan(&flowID)
+	if isUniqueViolation(err) {
+		return 0, models.NewError(409, "RouteFlow", "route flow with this name already exists")
+	}
+	if err != nil {
+		r.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": insertQuery,
+			"args":  appID,
+			"err":   err.Error(),
+		})
+		return 0, models.NewError(500, "Database", "failed to insert route flow")
+	}
+
+	return flowID, nil
+}
+
+// UpdateRouteFlow switches the flow to the new version and saves its steps in one transaction. The update is made
+// only from the previous version, so two concurrent edits do not end up with the same version, the row stays locked
+// until the steps are saved. The secrets are kept when they are nil.
+func (r *RouteRepository) UpdateRouteFlow(ctx context.Context, flowID int, appID string, ownerID int, name string,
+	version int, env string, secrets *string, httpOptions, notifications string, workingRoutes []DTO.WorkingRoute,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.loggerService.Error(failedToBeginTransaction, err)
+		return models.NewError(500, "Database", "failed to update route flow")
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			r.loggerService.Error(failedToRollbackTransaction, rollbackErr)
+		}
+	}()
+
+	query := `
+	UPDATE route_flows f
+	SET
+		name = $1,
+		version = $2,
+		env = $6::jsonb,
+		secrets = COALESCE($7, f.secrets),
+		http_options = $8::jsonb,
+		notifications = $9::jsonb,
+		updated_at = NOW()
+	FROM apps a
+	WHERE a.id = f.app_id AND f.id = $3 AND f.app_id = $4 AND a.owner_id = $5 AND f.version = $2 - 1`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update route flow")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	res, err := stmt.ExecContext(ctx, name, version, flowID, appID, ownerID, env, secrets, httpOptions,
+		notifications)
+	if isUniqueViolation(err) {
+		return models.NewError(409, "RouteFlow", "route flow with this name already exists")
+	}
+	if err != nil {
+		r.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args":  flowID,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update route flow")
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		r.loggerService.Error("failed to read affected rows", err)
+		return models.NewError(500, "Database", "failed to update route flow")
+	}
+	if rowsAffected == 0 {
+		return models.NewError(409, "RouteFlow", "route flow was changed in the meantime")
+	}
+
+	err = r.deleteWorkingRoutes(ctx, tx, flowID, version)
+	if err != nil {
+		return err
+	}
+	parentID := 0
+	for i := range workingRoutes {
+		workingRoutes[i].ParentID = parentID
+		parentID, err = insertWorkingRoute(ctx, tx, r.loggerService, workingRoutes[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.loggerService.Error(failedToCommitTransaction, err)
+		return models.NewError(500, "Database", "failed to update route flow")
+	}
+
+	return nil
+}
+
+// deleteWorkingRoutes removes the steps of the version which were left by an earlier save, so the steps over the new
+// count are not checked.
+func (r *RouteRepository) deleteWorkingRoutes(ctx context.Context, db statementPreparer, flowID, version int) error {
+	query := `DELETE FROM working_routes WHERE flow_id = $1 AND version = $2`
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update route flow")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, flowID, version)
+	if err != nil {
+		r.loggerService.Error(failedToExecuteDeleteQuery, map[string]any{
+			"query": query,
+			"args":  []any{flowID, version},
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update route flow")
+	}
+
+	return nil
+}
+
+func (r *RouteRepository) DeleteRouteFlow(ctx context.Context, flowID int, appID string, ownerID int) error {
+	query := `
+	DELETE FROM route_flows f
+	USING apps a
+	WHERE a.id = f.app_id AND f.id = $1 AND f.app_id = $2 AND a.owner_id = $3`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to delete route flow")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	res, err := stmt.ExecContext(ctx, flowID, appID, ownerID)
+	if err != nil {
+		r.loggerService.Error(failedToExecuteDeleteQuery, map[string]any{
+			"query": query,
+			"args":  flowID,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to delete route flow")
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		r.loggerService.Error("failed to read affected rows", err)
+		return models.NewError(500, "Database", "failed to delete route flow")
+	}
+	if rowsAffected == 0 {
+		return models.NewError(404, "RouteFlow", "route flow not found")
+	}
+
+	return nil
+}
+
+const routeFlowsQuery = `
+	SELECT
+		f.id,
+		f.app_id,
+		f.name,
+		wr.version,
+		f.env,
+		f.secrets,
+		f.http_options,
+		f.notifications,
+		f.created_at,
+		f.updated_at,
+		wr.id,
+		wr.position,
+		ri.path,
+		ri.method,
+		rr.authorization_header,
+		rr.query,
+		rr.params,
+		rr.body,
+		nrd.body,
+		nrd.query,
+		nrd.params,
+		nrd.authorization_header,
+		re.status_code,
+		re.body,
+		wr.assertions,
+		wr.response_schema,
+		wr.max_latency_ms,
+		wr.request_headers,
+		wr.extract,
+		wr.auth,
+		wr.body_type,
+		wr.raw_body,
+		wr.status,
+		wr.failed_assertions
+	FROM route_flows f
+		JOIN apps a ON a.id = f.app_id
+		JOIN working_routes wr ON wr.flow_id = f.id
+		JOIN routes_info ri ON ri.id = wr.route_id
+		JOIN routes_requests rr ON rr.id = wr.request_id
+		JOIN next_route_data nrd ON nrd.id = wr.next_route_data_id
+		JOIN routes_responses re ON re.id = wr.response_id
+	`
+
+// GetRouteFlows returns the flows of the app with the steps of their current version.
+func (r *RouteRepository) GetRouteFlows(ctx context.Context, appID string, ownerID int) ([]DTO.RouteFlow, error) {
+	query := routeFlowsQuery + `
+	WHERE f.app_id = $1 AND a.owner_id = $2 AND wr.version = f.version
+	ORDER BY f.id, wr.position`
+
+	return r.getRouteFlows(ctx, query, appID, ownerID)
+}
+
+// GetRouteFlow returns the flow with the steps of the given version, 0 stands for the current one.
+func (r *RouteRepository) GetRouteFlow(ctx context.Context, flowID int, appID string, ownerID,
+	version int,
+) (*DTO.RouteFlow, error) {
+	query := routeFlowsQuery + `
+	WHERE f.id = $1 AND f.app_id = $2 AND a.owner_id = $3 AND wr.version = COALESCE(NULLIF($4, 0), f.version)
+	ORDER BY wr.position`
+
+	routeFlows, err := r.getRouteFlows(ctx, query, flowID, appID, ownerID, version)
+	if err != nil {
+		return nil, err
+	}
+	if len(routeFlows) == 0 {
+		return nil, models.NewError(404, "RouteFlow", "route flow not found")
+	}
+
+	return &routeFlows[0], nil
+}
+
+func (r *RouteRepository) getRouteFlows(ctx context.Context, query string, args ...any) ([]DTO.RouteFlow, error) {
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		r.loggerService.Error(failedToPrepareQuery, map[string]any{
 			"query": query,
-			"args":  routeID,
 			"err":   err.Error(),
 		})
-		return "", models.NewError(500, "Database", "failed to check route status")
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
 	}
 	defer func() {
 		if closeErr := stmt.Close(); closeErr != nil {
 			r.loggerService.Error(failedToCloseStatement, closeErr)
 		}
 	}()
-	var routeStatus string
-	err = stmt.QueryRowContext(ctx, routeID).Scan(&routeStatus)
+
+	rows, err := stmt.QueryContext(ctx, args...)
 	if err != nil {
 		r.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
 			"query": query,
-			"args":  routeID,
+			"args":  args,
 			"err":   err.Error(),
 		})
-		return "", models.NewError(500, "Database", "failed to check route status")
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
 	}
-	return routeStatus, nil
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseRows, closeErr)
+		}
+	}()
+
+	routeFlows := make([]DTO.RouteFlow, 0)
+	for rows.Next() {
+		var routeFlow DTO.RouteFlow
+		var step DTO.RouteFlowStep
+		var requestQuery, requestParams models.JSONMapStringString
+		var requestBody, responseBody models.JSONMapStringAny
+		var nextRouteBody, nextRouteQuery, nextRouteParams models.JSONStringSlice
+		var assertions models.RouteAssertions
+		var responseSchema models.JSONMapStringAny
+		var failedAssertions models.FailedAssertions
+		var env, requestHeaders models.JSONMapStringString
+		var extract models.RouteVariables
+		var httpOptions models.RouteFlowHTTPOptions
+		var notifications models.RouteFlowNotifications
+		var auth models.RouteAuth
+		err := rows.Scan(&routeFlow.ID, &routeFlow.AppID, &routeFlow.Name, &routeFlow.Version, &env,
+			&routeFlow.EncryptedSecrets, &httpOptions, &notifications, &routeFlow.CreatedAt, &routeFlow.UpdatedAt, &step.ID, &step.Position, &step.Path, &step.Method, &step.RequestAuthorization,
+			&requestQuery, &requestParams, &requestBody, &nextRouteBody, &nextRouteQuery, &nextRouteParams,
+			&step.NextRouteAuthorizationHeader, &step.ResponseStatusCode, &responseBody, &assertions, &responseSchema,
+			&step.MaxLatencyMs, &requestHeaders, &extract, &auth, &step.BodyType, &step.RawBody, &step.Status,
+			&failedAssertions)
+		if err != nil {
+			r.loggerService.Error(failedToScanRows, map[string]any{
+				"query": query,
+				"err":   err.Error(),
+			})
+			return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+		}
+		step.RequestQuery = requestQuery
+		step.RequestParams = requestParams
+		step.RequestBody = requestBody
+		step.NextRouteBody = nextRouteBody
+		step.NextRouteQuery = nextRouteQuery
+		step.NextRouteParams = nextRouteParams
+		step.ResponseBody = responseBody
+		step.Assertions = assertions
+		step.ResponseSchema = responseSchema
+		step.FailedAssertions = failedAssertions
+		step.RequestHeaders = requestHeaders
+		step.Extract = extract
+		step.Auth = auth
+		routeFlow.Env = env
+		routeFlow.HTTPOptions = httpOptions
+		routeFlow.Notifications = notifications
+
+		// The rows are ordered by the flow, so the step belongs to the last flow unless it starts a new one.
+		lastFlow := len(routeFlows) - 1
+		if lastFlow < 0 || routeFlows[lastFlow].ID != routeFlow.ID {
+			routeFlows = append(routeFlows, routeFlow)
+			lastFlow++
+		}
+		routeFlows[lastFlow].Steps = append(routeFlows[lastFlow].Steps, step)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.loggerService.Error(failedToIterateOverRows, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return routeFlows, nil
 }
 
-func (r *RouteRepository) UpdateWorkingRoutesStatuses(ctx context.Context, routesStatuses map[int]string) error {
+func (r *RouteRepository) UpdateWorkingRoutesStatuses(ctx context.Context,
+	routesStatuses map[int]models.RouteStepResult,
+) error {
 	placeholders := make([]string, 0, len(routesStatuses))
 	argPos := 1
-	args := make([]any, 0, len(routesStatuses))
+	args := make([]any, 0, len(routesStatuses)*3)
 	for i, val := range routesStatuses {
-		preparedValues := fmt.Sprintf("($%d,$%d)", argPos, argPos+1)
-		args = append(args, int(i), val)
+		failedAssertions, err := utils.MarshalData(val.FailedAssertions)
+		if err != nil {
+			r.loggerService.Error("failed to marshal failed assertions", err)
+			return models.NewError(500, "Database", "failed to update routes statuses")
+		}
+		preparedValues := fmt.Sprintf("($%d,$%d,$%d::jsonb)", argPos, argPos+1, argPos+2)
+		args = append(args, int(i), val.Status, string(failedAssertions))
 		placeholders = append(placeholders, preparedValues)
-		argPos += 2
+		argPos += 3
 	}
 
 	query := fmt.Sprintf(`
 	UPDATE working_routes AS t
 	SET 
-    status = v.status
+    status = v.status,
+    failed_assertions = v.failed_assertions
 	FROM (VALUES
 	%s
-	) AS v(id, status)
+	) AS v(id, status, failed_assertions)
 		WHERE t.id = v.id::integer;
 	`, strings.Join(placeholders, ","))
 	stmt, err := r.db.PrepareContext(ctx, query)
@@ -102,8 +451,8 @@ func (r *RouteRepository) UpdateWorkingRoutesStatuses(ctx context.Context, route
 	return nil
 }
 
-func (r *RouteRepository) GetWorkingRoutesToTest(ctx context.Context) ([]models.RouteToTest, error) {
-	query := `
+// routesToTestQuery selects the steps of the current versions of the flows together with the address of the app.
+const routesToTestQuery = `
 SELECT
 	wr.id,
     a.ip_address,
@@ -123,17 +472,54 @@ SELECT
     nrd.query,
 	nrd.authorization_header,
     re.status_code,
-    re.body
+    re.body,
+    wr.assertions,
+    wr.response_schema,
+    wr.max_latency_ms,
+    wr.request_headers,
+    wr.extract,
+    wr.auth,
+    wr.body_type,
+    wr.raw_body,
+    f.id,
+    f.env,
+    f.secrets,
+    f.http_options,
+    f.version,
+    wr.position
 FROM working_routes wr
     INNER JOIN public.routes_info rf on wr.route_id = rf.id
     INNER JOIN public.routes_requests rr on wr.request_id = rr.id
     INNER JOIN public.next_route_data nrd on wr.next_route_data_id = nrd.id
     INNER JOIN public.routes_responses re on re.id = wr.response_id
+    INNER JOIN route_flows f on f.id = wr.flow_id AND f.version = wr.version
     inner join public.apps a on a.id = wr.app_id
+`
+
+func (r *RouteRepository) GetWorkingRoutesToTest(ctx context.Context) ([]models.RouteToTest, error) {
+	query := routesToTestQuery + `
     INNER JOIN apps_statuses aps on aps.app_id = wr.app_id
 WHERE aps.status = 'running'
-	`
+ORDER BY wr.flow_id, wr.position`
+
+	return r.getRoutesToTest(ctx, query)
+}
 
+// GetRouteFlowRoutesToTest returns the steps of the current version of a single flow, whatever the status of the app
+// is.
+func (r *RouteRepository) GetRouteFlowRoutesToTest(ctx context.Context, flowID int, appID string,
+	ownerID int,
+) ([]models.RouteToTest, error) {
+	query := routesToTestQuery + `
+WHERE f.id = $1 AND f.app_id = $2 AND a.owner_id = $3
+ORDER BY wr.position`
+
+	return r.getRoutesToTest(ctx, query, flowID, appID, ownerID)
+}
+
+func (r *RouteRepository) getRoutesToTest(ctx context.Context, query string,
+	args ...any,
+) ([]models.RouteToTest, error) {
 	stmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		r.loggerService.Error(failedToPrepareQuery, map[string]any{
@@ -148,10 +534,11 @@ WHERE aps.status = 'running'
 		}
 	}()
 
-	rows, err := stmt.QueryContext(ctx)
+	rows, err := stmt.QueryContext(ctx, args...)
 	if err != nil {
 		r.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
 			"query": query,
+			"args":  args,
 			"err":   err.Error(),
 		})
 		return []models.RouteToTest{}, models.NewError(500, "Database", failedToGetDataFromDatabase)
@@ -169,7 +556,11 @@ WHERE aps.status = 'running'
 			&routeToTest.AppID,
 			&routeToTest.ParentID, &routeToTest.Status,
 			&routeToTest.Path,
-			&routeToTest.Method, &routeToTest.RequestAuthorization, &routeToTest.RequestQuery, &routeToTest.RequestParams, &routeToTest.RequestBody, &routeToTest.NextRouteBody, &routeToTest.NextRouteParams, &routeToTest.NextRouteQuery, &routeToTest.NextAuthorizationHeader, &routeToTest.ResponseStatusCode, &routeToTest.ResponseBody)
+			&routeToTest.Method, &routeToTest.RequestAuthorization, &routeToTest.RequestQuery, &routeToTest.RequestParams, &routeToTest.RequestBody, &routeToTest.NextRouteBody, &routeToTest.NextRouteParams, &routeToTest.NextRouteQuery, &routeToTest.NextAuthorizationHeader, &routeToTest.ResponseStatusCode, &routeToTest.ResponseBody,
+			&routeToTest.Assertions, &routeToTest.ResponseSchema, &routeToTest.MaxLatencyMs,
+			&routeToTest.RequestHeaders, &routeToTest.Extract, &routeToTest.Auth, &routeToTest.BodyType,
+			&routeToTest.RawBody, &routeToTest.FlowID, &routeToTest.Env, &routeToTest.Secrets,
+			&routeToTest.HTTPOptions, &routeToTest.Version, &routeToTest.Position)
 		if err != nil {
 			r.loggerService.Error(failedToScanRow, map[string]any{
 				"query": query,
@@ -460,49 +851,84 @@ JOIN upserted u USING (body,params,query,authorization_header);
 	return nextRoutesDataIDs, nil
 }
 
+// InsertWorkingRoute saves the step of a flow version. The steps left behind by an edit which failed halfway are
+// overwritten by the next edit of the flow.
 func (r *RouteRepository) InsertWorkingRoute(ctx context.Context, workingRoute DTO.WorkingRoute) (int,
 	error,
 ) {
+	return insertWorkingRoute(ctx, r.db, r.loggerService, workingRoute)
+}
+
+// insertWorkingRoute inserts the step with the database or with the transaction which switches the flow to its
+// version.
+func insertWorkingRoute(ctx context.Context, db statementPreparer, loggerService utils.LoggerService,
+	workingRoute DTO.WorkingRoute,
+) (int, error) {
 	insertQuery := `INSERT INTO working_routes (
 	name,
     app_id,
+    flow_id,
+    version,
+    position,
     parent_id,
     route_id,
     request_id,
     response_id,
     next_route_data_id,
+    assertions,
+    response_schema,
+    max_latency_ms,
+    request_headers,
+    extract,
+    auth,
+    body_type,
+    raw_body,
     status
-) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11::jsonb,$12::jsonb,$13,$14::jsonb,$15::jsonb,$16::jsonb,$17,$18,$19)
 ON CONFLICT (
-	name,
-    app_id,
-    parent_id,
-    route_id,
-    request_id,
-    response_id,
-    next_route_data_id
+    flow_id,
+    version,
+    position
 )
 DO UPDATE SET 
+    name = EXCLUDED.name,
+    parent_id = EXCLUDED.parent_id,
+    route_id = EXCLUDED.route_id,
+    request_id = EXCLUDED.request_id,
+    response_id = EXCLUDED.response_id,
+    next_route_data_id = EXCLUDED.next_route_data_id,
+    assertions = EXCLUDED.assertions,
+    response_schema = EXCLUDED.response_schema,
+    max_latency_ms = EXCLUDED.max_latency_ms,
+    request_headers = EXCLUDED.request_headers,
+    extract = EXCLUDED.extract,
+    auth = EXCLUDED.auth,
+    body_type = EXCLUDED.body_type,
+    raw_body = EXCLUDED.raw_body,
     status = EXCLUDED.status
 RETURNING id`
-	stmt, err := r.db.PrepareContext(ctx, insertQuery)
+	stmt, err := db.PrepareContext(ctx, insertQuery)
 	if err != nil {
-		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+		loggerService.Error(failedToPrepareQuery, map[string]any{
 			"query": insertQuery,
 			"err":   err.Error(),
 		})
+		return 0, models.NewError(500, "Database", failedToGetDataFromDatabase)
 	}
 	defer func() {
 		if closeErr := stmt.Close(); closeErr != nil {
-			r.loggerService.Error(failedToCloseStatement, closeErr)
+			loggerService.Error(failedToCloseStatement, closeErr)
 		}
 	}()
 
 	var id int
-	err = stmt.QueryRowContext(ctx, workingRoute.Name, workingRoute.AppID, workingRoute.ParentID, workingRoute.RouteID,
-		workingRoute.RequestID, workingRoute.ResponseID, workingRoute.NextRouteDataID, workingRoute.Status).Scan(&id)
+	err = stmt.QueryRowContext(ctx, workingRoute.Name, workingRoute.AppID, workingRoute.FlowID, workingRoute.Version,
+		workingRoute.Position, workingRoute.ParentID, workingRoute.RouteID, workingRoute.RequestID,
+		workingRoute.ResponseID, workingRoute.NextRouteDataID, workingRoute.Assertions, workingRoute.ResponseSchema,
+		workingRoute.MaxLatencyMs, workingRoute.RequestHeaders, workingRoute.Extract, workingRoute.Auth,
+		workingRoute.BodyType, workingRoute.RawBody, workingRoute.Status).Scan(&id)
 	if err != nil {
-		r.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+		loggerService.Error(failedToExecuteInsertQuery, map[string]any{
 			"query": insertQuery,
 			"err":   err.Error(),
 			"data":  workingRoute,
@@ -511,3 +937,436 @@ RETURNING id`
 	}
 	return id, nil
 }
+
+// InsertRouteFlowRuns saves the runs with their steps and removes the runs of the flows over routeFlowRunsLimit. The
+// ids of the runs are returned in the same order.
+func (r *RouteRepository) InsertRouteFlowRuns(ctx context.Context, routeFlowRuns []DTO.RouteFlowRun) ([]int, error) {
+	insertRunQuery := `
+	INSERT INTO route_flow_runs (
+		flow_id,
+		version,
+		status,
+		started_at,
+		duration_ms
+	) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	stmt, err := r.db.PrepareContext(ctx, insertRunQuery)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": insertRunQuery,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", "failed to add route flow runs to the database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	runIDs := make([]int, 0, len(routeFlowRuns))
+	for _, routeFlowRun := range routeFlowRuns {
+		var runID int
+		err := stmt.QueryRowContext(ctx, routeFlowRun.FlowID, routeFlowRun.Version, routeFlowRun.Status,
+			routeFlowRun.StartedAt, routeFlowRun.DurationMs).Scan(&runID)
+		if err != nil {
+			r.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+				"query": insertRunQuery,
+				"args":  routeFlowRun.FlowID,
+				"err":   err.Error(),
+			})
+			return nil, models.NewError(500, "Database", "failed to add route flow runs to the database")
+		}
+
+		if err := r.insertRouteFlowRunSteps(ctx, runID, routeFlowRun.Steps); err != nil {
+			return nil, err
+		}
+		runIDs = append(runIDs, runID)
+	}
+
+	if err := r.deleteOldRouteFlowRuns(ctx, routeFlowRuns); err != nil {
+		return nil, err
+	}
+
+	return runIDs, nil
+}
+
+// FinishRouteFlowRun saves the result of a run which was added before its steps were run.
+func (r *RouteRepository) FinishRouteFlowRun(ctx context.Context, routeFlowRun DTO.RouteFlowRun) error {
+	query := `UPDATE route_flow_runs SET status = $1, duration_ms = $2 WHERE id = $3`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update route flow run")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, routeFlowRun.Status, routeFlowRun.DurationMs, routeFlowRun.ID)
+	if err != nil {
+		r.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args":  routeFlowRun.ID,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update route flow run")
+	}
+
+	return r.insertRouteFlowRunSteps(ctx, routeFlowRun.ID, routeFlowRun.Steps)
+}
+
+// FailRunningRouteFlowRuns sets the failed status on the runs which are still running.
+func (r *RouteRepository) FailRunningRouteFlowRuns(ctx context.Context, runningStatus, failedStatus string) error {
+	query := `UPDATE route_flow_runs SET status = $1 WHERE status = $2`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update route flow run")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, failedStatus, runningStatus)
+	if err != nil {
+		r.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update route flow run")
+	}
+
+	return nil
+}
+
+// UpdateRouteFlowFailureStreak counts the failed runs of the flow in a row, a passed run resets them. The flow is
+// returned with the last notification sent about it.
+func (r *RouteRepository) UpdateRouteFlowFailureStreak(ctx context.Context, flowID int,
+	failed bool,
+) (*models.RouteFlowNotificationState, error) {
+	query := `
+	UPDATE route_flows
+	SET failure_streak = CASE WHEN $2 THEN failure_streak + 1 ELSE 0 END
+	WHERE id = $1
+	RETURNING id, app_id, name, notifications, failure_streak, notified_status, notified_at`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", "failed to update route flow failure streak")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var state models.RouteFlowNotificationState
+	var notifiedAt sql.NullTime
+	err = stmt.QueryRowContext(ctx, flowID, failed).Scan(&state.FlowID, &state.AppID, &state.Name,
+		&state.Notifications, &state.FailureStreak, &state.NotifiedStatus, &notifiedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.NewError(404, "RouteFlow", "route flow not found")
+	}
+	if err != nil {
+		r.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args":  flowID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", "failed to update route flow failure streak")
+	}
+	if notifiedAt.Valid {
+		state.NotifiedAt = &notifiedAt.Time
+	}
+
+	return &state, nil
+}
+
+// UpdateRouteFlowNotifiedStatus saves the status of the notification sent about the flow, the cooldown of the flow
+// starts at notifiedAt.
+func (r *RouteRepository) UpdateRouteFlowNotifiedStatus(ctx context.Context, flowID int, notifiedStatus string,
+	notifiedAt time.Time,
+) error {
+	query := `UPDATE route_flows SET notified_status = $1, notified_at = $2 WHERE id = $3`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update route flow notified status")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, notifiedStatus, notifiedAt, flowID)
+	if err != nil {
+		r.loggerService.Error(failedToExecuteUpdateQuery, map[string]any{
+			"query": query,
+			"args":  flowID,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to update route flow notified status")
+	}
+
+	return nil
+}
+
+func (r *RouteRepository) insertRouteFlowRunSteps(ctx context.Context, runID int,
+	steps []DTO.RouteFlowRunStep,
+) error {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(steps))
+	args := make([]any, 0, len(steps)*10)
+	for i, step := range steps {
+		request, err := utils.MarshalData(step.Request)
+		if err != nil {
+			r.loggerService.Error("failed to marshal route flow run request", err)
+			return models.NewError(500, "Database", "failed to add route flow runs to the database")
+		}
+		failedAssertions, err := utils.MarshalData(step.FailedAssertions)
+		if err != nil {
+			r.loggerService.Error("failed to marshal failed assertions", err)
+			return models.NewError(500, "Database", "failed to add route flow runs to the database")
+		}
+		preparedValues := fmt.Sprintf("($%d,$%d,$%d,$%d,$%d::jsonb,$%d,$%d,$%d,$%d::jsonb,$%d)", i*10+1, i*10+2,
+			i*10+3, i*10+4, i*10+5, i*10+6, i*10+7, i*10+8, i*10+9, i*10+10)
+		args = append(args, runID, step.WorkingRouteID, step.Position, step.Status, string(request),
+			step.ResponseStatusCode, step.ResponseBody, step.LatencyMs, string(failedAssertions), step.Error)
+		placeholders = append(placeholders, preparedValues)
+	}
+
+	query := fmt.Sprintf(`
+	INSERT INTO route_flow_run_steps (
+		run_id,
+		working_route_id,
+		position,
+		status,
+		request,
+		response_status_code,
+		response_body,
+		latency_ms,
+		failed_assertions,
+		error
+	) VALUES %s`, strings.Join(placeholders, ","))
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to add route flow runs to the database")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, args...)
+	if err != nil {
+		r.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"args":  runID,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to add route flow runs to the database")
+	}
+
+	return nil
+}
+
+func (r *RouteRepository) deleteOldRouteFlowRuns(ctx context.Context, routeFlowRuns []DTO.RouteFlowRun) error {
+	flowIDs := make([]int64, 0, len(routeFlowRuns))
+	for _, routeFlowRun := range routeFlowRuns {
+		flowIDs = append(flowIDs, int64(routeFlowRun.FlowID))
+	}
+
+	query := `
+	DELETE FROM route_flow_runs
+	WHERE id IN (
+		SELECT id FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY flow_id ORDER BY started_at DESC, id DESC) AS position
+			FROM route_flow_runs
+			WHERE flow_id = ANY($1)
+		) AS runs
+		WHERE runs.position > $2
+	)`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to remove old route flow runs")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	_, err = stmt.ExecContext(ctx, pq.Array(flowIDs), routeFlowRunsLimit)
+	if err != nil {
+		r.loggerService.Error(failedToExecuteDeleteQuery, map[string]any{
+			"query": query,
+			"args":  flowIDs,
+			"err":   err.Error(),
+		})
+		return models.NewError(500, "Database", "failed to remove old route flow runs")
+	}
+
+	return nil
+}
+
+// GetRouteFlowRuns returns the latest runs of the flow, the newest first.
+func (r *RouteRepository) GetRouteFlowRuns(ctx context.Context, flowID int, appID string,
+	ownerID int,
+) ([]DTO.RouteFlowRun, error) {
+	return r.getRouteFlowRuns(ctx, flowID, appID, ownerID, 0)
+}
+
+func (r *RouteRepository) GetRouteFlowRun(ctx context.Context, runID, flowID int, appID string,
+	ownerID int,
+) (*DTO.RouteFlowRun, error) {
+	routeFlowRuns, err := r.getRouteFlowRuns(ctx, flowID, appID, ownerID, runID)
+	if err != nil {
+		return nil, err
+	}
+	if len(routeFlowRuns) == 0 {
+		return nil, models.NewError(404, "RouteFlowRun", "route flow run not found")
+	}
+
+	return &routeFlowRuns[0], nil
+}
+
+// getRouteFlowRuns returns the latest runs of the flow or only the one with runID when it is not 0.
+func (r *RouteRepository) getRouteFlowRuns(ctx context.Context, flowID int, appID string, ownerID,
+	runID int,
+) ([]DTO.RouteFlowRun, error) {
+	query := `
+	SELECT
+		runs.id,
+		runs.flow_id,
+		runs.version,
+		runs.status,
+		runs.started_at,
+		runs.duration_ms,
+		s.working_route_id,
+		s.position,
+		s.status,
+		s.request,
+		s.response_status_code,
+		s.response_body,
+		s.latency_ms,
+		s.failed_assertions,
+		s.error
+	FROM (
+		SELECT fr.*
+		FROM route_flow_runs fr
+			JOIN route_flows f ON f.id = fr.flow_id
+			JOIN apps a ON a.id = f.app_id
+		WHERE fr.flow_id = $1 AND f.app_id = $2 AND a.owner_id = $3 AND ($5 = 0 OR fr.id = $5)
+		ORDER BY fr.started_at DESC, fr.id DESC
+		LIMIT $4
+	) AS runs
+		LEFT JOIN route_flow_run_steps s ON s.run_id = runs.id
+	ORDER BY runs.started_at DESC, runs.id DESC, s.position`
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		r.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	rows, err := stmt.QueryContext(ctx, flowID, appID, ownerID, routeFlowRunsLimit, runID)
+	if err != nil {
+		r.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  flowID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			r.loggerService.Error(failedToCloseRows, closeErr)
+		}
+	}()
+
+	routeFlowRuns := make([]DTO.RouteFlowRun, 0)
+	for rows.Next() {
+		var routeFlowRun DTO.RouteFlowRun
+		var workingRouteID, position, responseStatusCode sql.NullInt64
+		var latencyMs sql.NullInt64
+		var status, responseBody, stepError sql.NullString
+		var request models.RouteRunRequest
+		var failedAssertions models.FailedAssertions
+		err := rows.Scan(&routeFlowRun.ID, &routeFlowRun.FlowID, &routeFlowRun.Version, &routeFlowRun.Status,
+			&routeFlowRun.StartedAt, &routeFlowRun.DurationMs, &workingRouteID, &position, &status, &request,
+			&responseStatusCode, &responseBody, &latencyMs, &failedAssertions, &stepError)
+		if err != nil {
+			r.loggerService.Error(failedToScanRows, map[string]any{
+				"query": query,
+				"err":   err.Error(),
+			})
+			return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+		}
+
+		if len(routeFlowRuns) == 0 || routeFlowRuns[len(routeFlowRuns)-1].ID != routeFlowRun.ID {
+			routeFlowRun.Steps = make([]DTO.RouteFlowRunStep, 0)
+			routeFlowRuns = append(routeFlowRuns, routeFlowRun)
+		}
+		if !workingRouteID.Valid {
+			continue
+		}
+		lastRun := &routeFlowRuns[len(routeFlowRuns)-1]
+		lastRun.Steps = append(lastRun.Steps, DTO.RouteFlowRunStep{
+			WorkingRouteID:     int(workingRouteID.Int64),
+			Position:           int(position.Int64),
+			Status:             status.String,
+			Request:            request,
+			ResponseStatusCode: int(responseStatusCode.Int64),
+			ResponseBody:       responseBody.String,
+			LatencyMs:          latencyMs.Int64,
+			FailedAssertions:   failedAssertions,
+			Error:              stepError.String,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		r.loggerService.Error(failedToIterateOverRows, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return routeFlowRuns, nil
+}
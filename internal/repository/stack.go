@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type StackRepository struct {
+	db            *sql.DB
+	loggerService utils.LoggerService
+}
+
+func NewStackRepository(db *sql.DB, loggerService utils.LoggerService) *StackRepository {
+	return &StackRepository{
+		db:            db,
+		loggerService: loggerService,
+	}
+}
+
+func (s *StackRepository) UpsertStack(ctx context.Context, name string, ownerID int) (int, error) {
+	query := `INSERT INTO stacks (
+		name,
+		owner_id
+	) VALUES ($1, $2)
+	ON CONFLICT (name, owner_id)
+	DO UPDATE SET name = EXCLUDED.name
+	RETURNING id`
+	stmt, err := s.db.PrepareContext(ctx, query)
+	if err != nil {
+		s.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"args":  name,
+			"err":   err.Error(),
+		})
+		return 0, models.NewError(500, "Database", "failed to save stack")
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			s.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var stackID int
+	err = stmt.QueryRowContext(ctx, name, ownerID).Scan(&stackID)
+	if err != nil {
+		s.loggerService.Error(failedToExecuteInsertQuery, map[string]any{
+			"query": query,
+			"args":  name,
+			"err":   err.Error(),
+		})
+		return 0, models.NewError(500, "Database", "failed to save stack")
+	}
+
+	return stackID, nil
+}
+
+func (s *StackRepository) GetStack(ctx context.Context, stackID, ownerID int) (*models.Stack, error) {
+	query := `SELECT
+		id,
+		name,
+		owner_id
+	FROM stacks
+	WHERE id = $1 AND owner_id = $2`
+	stmt, err := s.db.PrepareContext(ctx, query)
+	if err != nil {
+		s.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"args":  stackID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			s.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var stack models.Stack
+	err = stmt.QueryRowContext(ctx, stackID, ownerID).Scan(&stack.ID, &stack.Name, &stack.OwnerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.NewError(404, "Stack", "stack not found")
+	}
+	if err != nil {
+		s.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  stackID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return &stack, nil
+}
+
+func (s *StackRepository) GetStacks(ctx context.Context, ownerID int) ([]models.Stack, error) {
+	query := `SELECT
+		id,
+		name,
+		owner_id
+	FROM stacks
+	WHERE owner_id = $1
+	ORDER BY name`
+	stmt, err := s.db.PrepareContext(ctx, query)
+	if err != nil {
+		s.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"args":  ownerID,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			s.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	rows, err := stmt.QueryContext(ctx, ownerID)
+	if err != nil {
+		s.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			s.loggerService.Error(failedToCloseRows, closeErr)
+		}
+	}()
+
+	stacks := make([]models.Stack, 0)
+	for rows.Next() {
+		var stack models.Stack
+		err := rows.Scan(&stack.ID, &stack.Name, &stack.OwnerID)
+		if err != nil {
+			s.loggerService.Error(failedToScanRows, err)
+			return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+		}
+		stacks = append(stacks, stack)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.loggerService.Error(failedToIterateOverRows, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return nil, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return stacks, nil
+}
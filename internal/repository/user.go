@@ -229,3 +229,33 @@ func (u *UserRepository) ChangeUserPassword(ctx context.Context, userID int, new
 	}
 	return nil
 }
+
+func (u *UserRepository) HasPermission(ctx context.Context, userID int, permission string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users_permissions WHERE user_id = $1 AND permission = $2)`
+	stmt, err := u.db.PrepareContext(ctx, query)
+	if err != nil {
+		u.loggerService.Error(failedToPrepareQuery, map[string]any{
+			"query": query,
+			"err":   err.Error(),
+		})
+		return false, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+	defer func() {
+		if closeErr := stmt.Close(); closeErr != nil {
+			u.loggerService.Error(failedToCloseStatement, closeErr)
+		}
+	}()
+
+	var hasPermission bool
+	err = stmt.QueryRowContext(ctx, userID, permission).Scan(&hasPermission)
+	if err != nil {
+		u.loggerService.Error(failedToExecuteSelectQuery, map[string]any{
+			"query": query,
+			"args":  []any{userID, permission},
+			"err":   err.Error(),
+		})
+		return false, models.NewError(500, "Database", failedToGetDataFromDatabase)
+	}
+
+	return hasPermission, nil
+}
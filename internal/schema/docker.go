@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"strings"
+
+	z "github.com/Oudwins/zog"
+)
+
+// DockerHostSchema uses the names of the fields of DTO.DockerHost as its keys, zog panics on the keys which do not
+// match them.
+var DockerHostSchema = z.Struct(z.Shape{
+	"host": z.String().Required().Max(256).TestFunc(func(val *string, ctx z.Ctx) bool {
+		return strings.HasPrefix(*val, "tcp://") || strings.HasPrefix(*val, "ssh://") ||
+			strings.HasPrefix(*val, "unix://")
+	}, z.Message("host must start with tcp://, ssh:// or unix://")),
+	"TLSCACert":       z.String().Optional(),
+	"TLSCert":         z.String().Optional(),
+	"TLSKey":          z.String().Optional(),
+	"SSHIdentityFile": z.String().Optional().Max(256),
+})
+
+var restartPolicies = []string{"no", "always", "on-failure", "unless-stopped"}
+
+var CreateContainerSchema = z.Struct(z.Shape{
+	"name":    z.String().Required().Max(64),
+	"image":   z.String().Required().Max(256),
+	"env":     z.Slice(z.String().Required().Max(1024)).Optional(),
+	"volumes": z.Slice(z.String().Required().Max(512)).Optional(),
+	"ports": z.Slice(z.Struct(z.Shape{
+		"hostPort":      z.String().Required().Max(16),
+		"containerPort": z.String().Required().Max(16),
+		"protocol":      z.String().Optional().OneOf([]string{"tcp", "udp", "sctp"}),
+	})).Optional(),
+	"restartPolicy": z.String().Optional().OneOf(restartPolicies),
+})
+
+var RecreateContainerSchema = z.Struct(z.Shape{
+	"image": z.String().Optional().Max(256),
+})
+
+var RenameContainerSchema = z.Struct(z.Shape{
+	"name": z.String().Required().Max(64),
+})
+
+var DockerImageIDSchema = z.Struct(z.Shape{
+	"imageID": z.String().Required().Max(256),
+})
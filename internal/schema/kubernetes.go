@@ -0,0 +1,22 @@
+package schema
+
+import (
+	"regexp"
+
+	z "github.com/Oudwins/zog"
+)
+
+// kubernetesNameRegex matches the DNS labels used as namespaces, cluster names follow the same rule.
+var kubernetesNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+var KubernetesClusterSchema = z.Struct(z.Shape{
+	"name": z.String().Required().Max(63).Match(kubernetesNameRegex,
+		z.Message("name may contain only lowercase letters, digits and dashes")),
+	"kubeconfig": z.String().Required().Max(65536),
+})
+
+var ImportKubernetesWorkloadsSchema = z.Struct(z.Shape{
+	"cluster": z.String().Required().Max(63),
+	"namespace": z.String().Optional().Max(63).Match(kubernetesNameRegex,
+		z.Message("namespace may contain only lowercase letters, digits and dashes")),
+})
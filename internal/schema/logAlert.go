@@ -0,0 +1,17 @@
+package schema
+
+import z "github.com/Oudwins/zog"
+
+var LogAlertRuleIDSchema = z.Struct(z.Shape{
+	"appID":  z.String().Required().Max(64),
+	"ruleID": z.String().Required().Max(16),
+})
+
+var CreateLogAlertRuleSchema = z.Struct(z.Shape{
+	"name":            z.String().Required().Max(64),
+	"pattern":         z.String().Required().Max(256),
+	"windowSeconds":   z.Int().Required().GTE(10).LTE(86400),
+	"threshold":       z.Int().Required().GTE(0),
+	"cooldownSeconds": z.Int().Required().GTE(0).LTE(604800),
+	"enabled":         z.Bool(),
+})
@@ -0,0 +1,7 @@
+package schema
+
+import z "github.com/Oudwins/zog"
+
+var LogsRetentionSchema = z.Struct(z.Shape{
+	"days": z.Int().Required().GTE(1).LTE(365),
+})
@@ -0,0 +1,17 @@
+package schema
+
+import z "github.com/Oudwins/zog"
+
+// The backoff starts at 10 seconds at least, so the status of a restarted app is checked before the next attempt.
+var UpsertRemediationPolicySchema = z.Struct(z.Shape{
+	"maxAttempts":    z.Int().Required().GTE(1).LTE(20),
+	"backoffSeconds": z.Int().Required().GTE(10).LTE(3600),
+	"restart":        z.Bool(),
+	"webhookURL":     z.String().Optional().Max(512),
+	"script":         z.String().Optional().Max(256),
+	"enabled":        z.Bool(),
+})
+
+var RemediationMaintenanceSchema = z.Struct(z.Shape{
+	"minutes": z.Int().Required().GTE(1).LTE(10080),
+})
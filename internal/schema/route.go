@@ -2,14 +2,43 @@ package schema
 
 import (
 	z "github.com/Oudwins/zog"
+	"github.com/slodkiadrianek/octopus/internal/models"
 )
 
-var RouteIDSchema = z.Struct(z.Shape{
-	"routeID": z.String().Required(),
+var RouteFlowIDSchema = z.Struct(z.Shape{
+	"appID":  z.String().Required().Max(64),
+	"flowID": z.String().Required().Max(16),
+})
+
+var RouteFlowRunIDSchema = z.Struct(z.Shape{
+	"appID":  z.String().Required().Max(64),
+	"flowID": z.String().Required().Max(16),
+	"runID":  z.String().Required().Max(16),
 })
 
 var CreateRouteSchema = z.Struct(z.Shape{
-	"name": z.String().Required(),
+	"name": z.String().Required().Max(64),
+	"env": z.CustomFunc[map[string]string](func(val *map[string]string, ctx z.Ctx) bool {
+		return true
+	}),
+	"secrets": z.CustomFunc[map[string]string](func(val *map[string]string, ctx z.Ctx) bool {
+		return true
+	}),
+	// The keys are matched with the names of the fields, so the acronyms are written the way the fields are.
+	"HTTPOptions": z.Struct(z.Shape{
+		"baseURL":   z.String().Optional().Max(256),
+		"timeoutMs": z.Int().Optional().GTE(0).LTE(120000),
+		"redirectPolicy": z.String().Optional().OneOf([]string{models.RouteRedirectPolicyFollow,
+			models.RouteRedirectPolicyNone}),
+		"maxRedirects":       z.Int().Optional().GTE(0).LTE(20),
+		"insecureSkipVerify": z.Bool().Optional(),
+		"CACertificate":      z.String().Optional().Max(16384),
+	}),
+	"notifications": z.Struct(z.Shape{
+		"muted":            z.Bool().Optional(),
+		"failureThreshold": z.Int().Optional().GTE(0).LTE(100),
+		"cooldownSeconds":  z.Int().Optional().GTE(0).LTE(86400),
+	}),
 	"routes": z.Slice(z.Struct(z.Shape{
 		"path":                 z.String().Required().Max(256),
 		"method":               z.String().OneOf([]string{"POST", "GET", "PUT", "PATCH", "DELETE"}),
@@ -31,5 +60,51 @@ var CreateRouteSchema = z.Struct(z.Shape{
 		"responseBody": z.CustomFunc[map[string]any](func(val *map[string]any, ctx z.Ctx) bool {
 			return true
 		}),
+		"assertions": z.Slice(z.Struct(z.Shape{
+			"source": z.String().Optional().OneOf([]string{models.RouteAssertionSourceBody,
+				models.RouteAssertionSourceHeader}),
+			"selector": z.String().Optional().Max(256),
+			"operator": z.String().Required().OneOf([]string{
+				models.RouteAssertionOperatorEq, models.RouteAssertionOperatorRegex,
+				models.RouteAssertionOperatorType, models.RouteAssertionOperatorRange,
+				models.RouteAssertionOperatorExists, models.RouteAssertionOperatorNotExists,
+			}),
+			"value": z.CustomFunc[any](func(val *any, ctx z.Ctx) bool {
+				return true
+			}),
+			"min": z.Ptr(z.Float64()),
+			"max": z.Ptr(z.Float64()),
+		})).Optional().Max(50),
+		"responseSchema": z.CustomFunc[map[string]any](func(val *map[string]any, ctx z.Ctx) bool {
+			return true
+		}),
+		"maxLatencyMs": z.Int().Optional().GTE(0).LTE(600000),
+		"requestHeaders": z.CustomFunc[map[string]string](func(val *map[string]string, ctx z.Ctx) bool {
+			return true
+		}),
+		"auth": z.Struct(z.Shape{
+			"type": z.String().Optional().OneOf([]string{models.RouteAuthTypeBearer, models.RouteAuthTypeBasic,
+				models.RouteAuthTypeAPIKey, models.RouteAuthTypeCookie}),
+			"username": z.String().Optional().Max(256),
+			"password": z.String().Optional().Max(1024),
+			"name":     z.String().Optional().Max(128),
+			"value":    z.String().Optional().Max(4096),
+		}),
+		"bodyType": z.String().Optional().OneOf([]string{models.RouteBodyTypeJSON, models.RouteBodyTypeForm,
+			models.RouteBodyTypeRaw}),
+		"rawBody": z.String().Optional().Max(65536),
+		"extract": z.Slice(z.Struct(z.Shape{
+			"name": z.String().Required().Max(64),
+			"source": z.String().Optional().OneOf([]string{models.RouteAssertionSourceBody,
+				models.RouteAssertionSourceHeader}),
+			"selector": z.String().Optional().Max(256),
+		})).Optional().Max(50),
 	})),
 })
+
+var ImportRouteFlowsSchema = z.Struct(z.Shape{
+	"format": z.String().Optional().OneOf([]string{models.RouteImportFormatOpenAPI, models.RouteImportFormatPostman,
+		models.RouteImportFormatInsomnia}),
+	"document": z.String().Required().Max(5 << 20),
+	"select":   z.Slice(z.String().Max(64)).Optional().Max(500),
+})
@@ -0,0 +1,7 @@
+package schema
+
+import z "github.com/Oudwins/zog"
+
+var StackIDSchema = z.Struct(z.Shape{
+	"stackID": z.String().Required().Max(16),
+})
@@ -0,0 +1,7 @@
+package schema
+
+import z "github.com/Oudwins/zog"
+
+var ImportSystemdUnitsSchema = z.Struct(z.Shape{
+	"pattern": z.String().Required().Max(128),
+})
@@ -36,7 +36,7 @@ func (a *AppService) CreateApp(ctx context.Context, app DTO.CreateApp, ownerID i
 		return err
 	}
 
-	appDto := DTO.NewApp(generatedID, app.Name, app.Description, false, ownerID, app.IPAddress, app.Port)
+	appDto := DTO.NewApp(generatedID, app.Name, app.Description, models.RuntimeTCP, ownerID, app.IPAddress, app.Port)
 	err = a.appRepository.InsertApp(ctx, []DTO.App{*appDto})
 	if err != nil {
 		return err
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/models"
@@ -15,22 +18,220 @@ import (
 	"github.com/slodkiadrianek/octopus/internal/utils"
 )
 
+// dockerReconciliationPeriod is how often docker apps are inspected while their statuses are updated from the
+// docker events stream.
+const dockerReconciliationPeriod = time.Minute
+
 type AppStatusService struct {
-	appRepository interfaces.AppRepository
-	cacheService  interfaces.CacheService
-	loggerService utils.LoggerService
-	dockerHost    string
+	appRepository        interfaces.AppRepository
+	cacheService         interfaces.CacheService
+	loggerService        utils.LoggerService
+	dockerClientManager  interfaces.DockerClientManager
+	dockerHost           string
+	pm2Client            interfaces.Pm2Client
+	systemdClient        interfaces.SystemdClient
+	kubernetesRepository interfaces.KubernetesRepository
+	kubernetesClient     interfaces.KubernetesClient
+	// dockerEventsSubscribed is set while the docker events stream updates the statuses of docker apps.
+	dockerEventsSubscribed   atomic.Bool
+	lastDockerReconciliation time.Time
 }
 
 func NewAppStatusService(appRepository interfaces.AppRepository, cacheService interfaces.CacheService,
-	loggerService utils.LoggerService, dockerHost string,
+	loggerService utils.LoggerService, dockerHost string, dockerClientManager interfaces.DockerClientManager,
+	pm2Client interfaces.Pm2Client, systemdClient interfaces.SystemdClient,
+	kubernetesRepository interfaces.KubernetesRepository, kubernetesClient interfaces.KubernetesClient,
 ) *AppStatusService {
 	return &AppStatusService{
-		appRepository: appRepository,
-		cacheService:  cacheService,
-		loggerService: loggerService,
-		dockerHost:    dockerHost,
+		appRepository:        appRepository,
+		cacheService:         cacheService,
+		loggerService:        loggerService,
+		dockerClientManager:  dockerClientManager,
+		dockerHost:           dockerHost,
+		pm2Client:            pm2Client,
+		systemdClient:        systemdClient,
+		kubernetesRepository: kubernetesRepository,
+		kubernetesClient:     kubernetesClient,
+	}
+}
+
+// dockerHealthOutputMaxLength limits the output of the last health check kept in the status, the output of a
+// failing check is often a whole error page.
+const dockerHealthOutputMaxLength = 256
+
+// dockerAppStatus reports a running container whose HEALTHCHECK fails as unhealthy. The exit code and the OOM kill
+// of a stopped container tell a crash from a clean stop.
+func dockerAppStatus(appID string, containerInfo container.InspectResponse) (DTO.AppStatus, error) {
+	state := containerInfo.State
+	startedTime, err := time.Parse(time.RFC3339, state.StartedAt)
+	if err != nil {
+		return DTO.AppStatus{}, err
+	}
+
+	appStatus := DTO.NewAppStatus(appID, state.Status, startedTime, time.Since(startedTime))
+	appStatus.RestartCount = containerInfo.RestartCount
+	if state.Health != nil {
+		appStatus.Health = state.Health.Status
+		if len(state.Health.Log) > 0 {
+			output := strings.TrimSpace(state.Health.Log[len(state.Health.Log)-1].Output)
+			if len(output) > dockerHealthOutputMaxLength {
+				output = strings.ToValidUTF8(output[:dockerHealthOutputMaxLength], "")
+			}
+			appStatus.HealthOutput = output
+		}
+		if state.Status == container.StateRunning && state.Health.Status == container.Unhealthy {
+			appStatus.Status = container.Unhealthy
+		}
+	}
+	if state.Status == container.StateExited || state.Status == container.StateDead {
+		appStatus.ExitCode = state.ExitCode
+		appStatus.OOMKilled = state.OOMKilled
+	}
+
+	return *appStatus, nil
+}
+
+// kubernetesAppStatus translates the ready replicas of the workload to the statuses used by the other apps. A
+// workload with only a part of its replicas ready is degraded, a workload scaled to zero is stopped.
+func kubernetesAppStatus(appID string, workload DTO.KubernetesWorkload) DTO.AppStatus {
+	var status string
+	switch {
+	case workload.Replicas == 0:
+		status = "stopped"
+	case workload.ReadyReplicas >= workload.Replicas:
+		status = "running"
+	case workload.ReadyReplicas == 0:
+		status = "unavailable"
+	default:
+		status = "degraded"
+	}
+
+	changedAt := workload.ChangedAt
+	var duration time.Duration
+	if changedAt.IsZero() {
+		changedAt = time.Now()
+	} else {
+		duration = time.Since(changedAt)
 	}
+
+	return *DTO.NewAppStatus(appID, status, changedAt, duration)
+}
+
+// systemdAppStatus translates the active state of the unit to the statuses used by the other apps.
+func systemdAppStatus(appID string, unit DTO.SystemdUnit) DTO.AppStatus {
+	var status string
+	switch unit.ActiveState {
+	case "active", "reloading":
+		status = "running"
+	case "inactive":
+		status = "stopped"
+	case "activating":
+		status = "starting"
+	case "deactivating":
+		status = "stopping"
+	default:
+		status = unit.ActiveState
+	}
+
+	startedAt := unit.StartedAt
+	var duration time.Duration
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	} else {
+		duration = time.Since(startedAt)
+	}
+	appStatus := DTO.NewAppStatus(appID, status, startedAt, duration)
+	appStatus.RestartCount = unit.RestartCount
+	appStatus.MemoryBytes = unit.MemoryBytes
+
+	return *appStatus
+}
+
+// pm2AppStatus translates the PM2 process status to the statuses used by the other apps. A process removed from
+// PM2 is reported as stopped.
+func pm2AppStatus(appID string, process DTO.Pm2Process, found bool) DTO.AppStatus {
+	if !found {
+		return *DTO.NewAppStatus(appID, "stopped", time.Now(), 0)
+	}
+
+	var status string
+	switch process.Status {
+	case "online":
+		status = "running"
+	case "stopping", "stopped":
+		status = "stopped"
+	case "launching":
+		status = "starting"
+	default:
+		status = process.Status
+	}
+
+	startedAt := process.StartedAt
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+	appStatus := DTO.NewAppStatus(appID, status, startedAt, time.Since(startedAt))
+	appStatus.RestartCount = process.RestartCount
+	appStatus.MemoryBytes = process.MemoryBytes
+	appStatus.CPUPercent = process.CPUPercent
+
+	return *appStatus
+}
+
+// listPm2Processes returns nil when there are no PM2 apps, so the pm2 binary is not required by docker only setups.
+func (as *AppStatusService) listPm2Processes(ctx context.Context,
+	appsToCheck []*models.AppToCheck,
+) (map[string]DTO.Pm2Process, error) {
+	hasPm2Apps := false
+	for _, appToCheck := range appsToCheck {
+		if appToCheck.Runtime == models.RuntimePm2 {
+			hasPm2Apps = true
+			break
+		}
+	}
+	if !hasPm2Apps {
+		return nil, nil
+	}
+
+	processes, err := as.pm2Client.ListProcesses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	processesByName := make(map[string]DTO.Pm2Process, len(processes))
+	for _, process := range processes {
+		processesByName[process.Name] = process
+	}
+
+	return processesByName, nil
+}
+
+// listKubernetesWorkloads returns nil when there are no kubernetes apps.
+func (as *AppStatusService) listKubernetesWorkloads(ctx context.Context,
+	appsToCheck []*models.AppToCheck,
+) (map[string]models.KubernetesWorkload, error) {
+	hasKubernetesApps := false
+	for _, appToCheck := range appsToCheck {
+		if appToCheck.Runtime == models.RuntimeKubernetes {
+			hasKubernetesApps = true
+			break
+		}
+	}
+	if !hasKubernetesApps {
+		return nil, nil
+	}
+
+	workloads, err := as.kubernetesRepository.GetKubernetesWorkloads(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workloadsByAppID := make(map[string]models.KubernetesWorkload, len(workloads))
+	for _, workload := range workloads {
+		workloadsByAppID[workload.AppID] = workload
+	}
+
+	return workloadsByAppID, nil
 }
 
 func (as *AppStatusService) readAppStatusFromCache(ctx context.Context, cacheKey string) (DTO.AppStatus, error) {
@@ -49,8 +250,9 @@ func (as *AppStatusService) readAppStatusFromCache(ctx context.Context, cacheKey
 	return *appStatus, nil
 }
 
-func (as *AppStatusService) checkAndCompareAppStatuses(ctx context.Context, cli *client.Client,
-	appsToCheck []*models.AppToCheck,
+func (as *AppStatusService) checkAndCompareAppStatuses(ctx context.Context, cli client.APIClient,
+	appsToCheck []*models.AppToCheck, pm2Processes map[string]DTO.Pm2Process,
+	kubernetesWorkloads map[string]models.KubernetesWorkload,
 ) ([]DTO.AppStatus, []DTO.AppStatus) {
 	appsStatusesChan := make(chan DTO.AppStatus, len(appsToCheck))
 	appsToSendNotificationChan := make(chan DTO.AppStatus, len(appsToCheck))
@@ -67,24 +269,45 @@ func (as *AppStatusService) checkAndCompareAppStatuses(ctx context.Context, cli
 			for job := range jobs {
 				var appStatus DTO.AppStatus
 
-				if job.IsDocker {
-					container, err := cli.ContainerInspect(ctx, job.ID)
+				switch job.Runtime {
+				case models.RuntimePm2:
+					if pm2Processes == nil {
+						continue
+					}
+					process, found := pm2Processes[DTO.Pm2ProcessName(job.ID)]
+					appStatus = pm2AppStatus(job.ID, process, found)
+				case models.RuntimeSystemd:
+					unit, err := as.systemdClient.GetUnit(ctx, DTO.SystemdUnitName(job.ID))
+					if err != nil {
+						as.loggerService.Error("Failed to read systemd unit", err)
+						continue
+					}
+					appStatus = systemdAppStatus(job.ID, unit)
+				case models.RuntimeKubernetes:
+					workloadLocation, found := kubernetesWorkloads[job.ID]
+					if !found {
+						continue
+					}
+					workload, err := as.kubernetesClient.GetWorkload(ctx, workloadLocation)
+					if err != nil {
+						as.loggerService.Error("Failed to read kubernetes workload", err)
+						continue
+					}
+					appStatus = kubernetesAppStatus(job.ID, workload)
+				case models.RuntimeDocker:
+					containerInfo, err := cli.ContainerInspect(ctx, job.ID)
 					if err != nil {
 						as.loggerService.Error("Failed to inspect container", err)
 						continue
 					}
 
-					status := container.State.Status
-					startedTime, err := time.Parse(time.RFC3339, container.State.StartedAt)
+					appStatus, err = dockerAppStatus(job.ID, containerInfo)
 					if err != nil {
 						as.loggerService.Error("Failed to parse container start time", err)
 						continue
 					}
-
-					duration := time.Since(startedTime)
-					appStatus = *DTO.NewAppStatus(job.ID, status, startedTime, duration)
-				} else {
-					address := fmt.Sprintf("%s:%s", job.IPAddress, job.Port)
+				default:
+					address := net.JoinHostPort(job.IPAddress, job.Port)
 					conn, err := net.DialTimeout("tcp", address, 3*time.Second)
 					status := "running"
 					startedTime := time.Now()
@@ -139,19 +362,96 @@ func (as *AppStatusService) checkAndCompareAppStatuses(ctx context.Context, cli
 	return appsStatuses, appsToSendNotification
 }
 
+// SetDockerEventsSubscribed is called by the docker events stream when it connects and disconnects.
+func (as *AppStatusService) SetDockerEventsSubscribed(subscribed bool) {
+	as.dockerEventsSubscribed.Store(subscribed)
+}
+
+// SetDockerAppStoppedByUser is called by the docker events stream when a container is stopped or started, so the
+// remediation tells a stopped container from a crashed one.
+func (as *AppStatusService) SetDockerAppStoppedByUser(ctx context.Context, containerID string,
+	stoppedByUser bool,
+) error {
+	return as.appRepository.UpdateAppStoppedByUser(ctx, containerID, stoppedByUser)
+}
+
+// skipDockerApps leaves out docker apps between reconciliations when their statuses come from the events stream.
+func (as *AppStatusService) skipDockerApps(appsToCheck []*models.AppToCheck) []*models.AppToCheck {
+	if !as.dockerEventsSubscribed.Load() || time.Since(as.lastDockerReconciliation) >= dockerReconciliationPeriod {
+		as.lastDockerReconciliation = time.Now()
+		return appsToCheck
+	}
+
+	notDockerApps := make([]*models.AppToCheck, 0, len(appsToCheck))
+	for _, appToCheck := range appsToCheck {
+		if appToCheck.Runtime != models.RuntimeDocker {
+			notDockerApps = append(notDockerApps, appToCheck)
+		}
+	}
+
+	return notDockerApps
+}
+
 func (as *AppStatusService) CheckAppsStatus(ctx context.Context) ([]DTO.AppStatus, error) {
 	appsToCheck, err := as.appRepository.GetAppsToCheck(ctx)
 	if err != nil {
 		return nil, err
 	}
+	appsToCheck = as.skipDockerApps(appsToCheck)
+
+	cli, err := as.dockerClientManager.GetClient(ctx, as.dockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	pm2Processes, err := as.listPm2Processes(ctx, appsToCheck)
+	if err != nil {
+		as.loggerService.Warn("failed to check statuses of pm2 apps", err)
+	}
+
+	kubernetesWorkloads, err := as.listKubernetesWorkloads(ctx, appsToCheck)
+	if err != nil {
+		as.loggerService.Warn("failed to check statuses of kubernetes apps", err)
+	}
+
+	appsStatuses, appsToSendNotification := as.checkAndCompareAppStatuses(ctx, cli, appsToCheck, pm2Processes,
+		kubernetesWorkloads)
+	if len(appsStatuses) > 0 {
+		if err := as.appRepository.InsertAppStatuses(ctx, appsStatuses); err != nil {
+			as.loggerService.Error("failed to insert app statuses", err)
+			return appsToSendNotification, err
+		}
+	}
+
+	return appsToSendNotification, nil
+}
+
+// CheckDockerAppStatus checks the status of a single docker app after an event of its container. Containers which
+// are not imported as apps are ignored.
+func (as *AppStatusService) CheckDockerAppStatus(ctx context.Context, containerID string) ([]DTO.AppStatus, error) {
+	appsToCheck, err := as.appRepository.GetAppsToCheck(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var dockerApp *models.AppToCheck
+	for _, appToCheck := range appsToCheck {
+		if appToCheck.Runtime == models.RuntimeDocker && appToCheck.ID == containerID {
+			dockerApp = appToCheck
+			break
+		}
+	}
+	if dockerApp == nil {
+		return nil, nil
+	}
 
-	cli, err := client.NewClientWithOpts(client.WithHost(as.dockerHost), client.WithAPIVersionNegotiation())
+	cli, err := as.dockerClientManager.GetClient(ctx, as.dockerHost)
 	if err != nil {
 		return nil, err
 	}
-	defer cli.Close()
 
-	appsStatuses, appsToSendNotification := as.checkAndCompareAppStatuses(ctx, cli, appsToCheck)
+	appsStatuses, appsToSendNotification := as.checkAndCompareAppStatuses(ctx, cli,
+		[]*models.AppToCheck{dockerApp}, nil, nil)
 	if len(appsStatuses) > 0 {
 		if err := as.appRepository.InsertAppStatuses(ctx, appsStatuses); err != nil {
 			as.loggerService.Error("failed to insert app statuses", err)
@@ -99,9 +99,11 @@ func TestAppService_GetAppStatus(t *testing.T) {
 			loggerService := tests.CreateLogger()
 			appRepository, cacheService := testScenario.setupMock()
 			routeRepository := repository.NewRouteRepository(&sql.DB{}, loggerService)
-			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost)
+			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost,
+				tests.CreateDockerClientManager(loggerService), new(mocks.MockPm2Client),
+				new(mocks.MockSystemdClient), new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient))
 			appNotificationsService := NewAppNotificationsService(appRepository, loggerService)
-			routeStatusService := NewRouteStatusService(routeRepository, loggerService)
+			routeStatusService := NewRouteStatusService(routeRepository, &fakeAppNotificationsService{}, "test-key", loggerService)
 			appService := NewAppService(appRepository, loggerService, appStatusService, appNotificationsService, routeStatusService)
 			app, err := appService.GetAppStatus(ctx,
 				"123e23e23", 543)
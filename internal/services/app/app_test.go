@@ -55,9 +55,11 @@ func TestAppService_CreateApp(t *testing.T) {
 			loggerService := tests.CreateLogger()
 			appRepository, cacheService := testScenario.setupMock()
 			routeRepository := repository.NewRouteRepository(&sql.DB{}, loggerService)
-			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost)
+			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost,
+				tests.CreateDockerClientManager(loggerService), new(mocks.MockPm2Client),
+				new(mocks.MockSystemdClient), new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient))
 			appNotificationsService := NewAppNotificationsService(appRepository, loggerService)
-			routeStatusService := NewRouteStatusService(routeRepository, loggerService)
+			routeStatusService := NewRouteStatusService(routeRepository, &fakeAppNotificationsService{}, "test-key", loggerService)
 			appService := NewAppService(appRepository, loggerService, appStatusService, appNotificationsService, routeStatusService)
 			app := DTO.CreateApp{
 				Name:        "test",
@@ -116,9 +118,11 @@ func TestAppService_GetApp(t *testing.T) {
 			loggerService := tests.CreateLogger()
 			appRepository, cacheService := testScenario.setupMock()
 			routeRepository := repository.NewRouteRepository(&sql.DB{}, loggerService)
-			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost)
+			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost,
+				tests.CreateDockerClientManager(loggerService), new(mocks.MockPm2Client),
+				new(mocks.MockSystemdClient), new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient))
 			appNotificationsService := NewAppNotificationsService(appRepository, loggerService)
-			routeStatusService := NewRouteStatusService(routeRepository, loggerService)
+			routeStatusService := NewRouteStatusService(routeRepository, &fakeAppNotificationsService{}, "test-key", loggerService)
 			appService := NewAppService(appRepository, loggerService, appStatusService, appNotificationsService, routeStatusService)
 			app, err := appService.GetApp(ctx, "hf9hrepuihfefui", 32)
 			if testScenario.expectedError == nil {
@@ -173,9 +177,11 @@ func TestAppService_GetApps(t *testing.T) {
 			loggerService := tests.CreateLogger()
 			appRepository, cacheService := testScenario.setupMock()
 			routeRepository := repository.NewRouteRepository(&sql.DB{}, loggerService)
-			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost)
+			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost,
+				tests.CreateDockerClientManager(loggerService), new(mocks.MockPm2Client),
+				new(mocks.MockSystemdClient), new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient))
 			appNotificationsService := NewAppNotificationsService(appRepository, loggerService)
-			routeStatusService := NewRouteStatusService(routeRepository, loggerService)
+			routeStatusService := NewRouteStatusService(routeRepository, &fakeAppNotificationsService{}, "test-key", loggerService)
 			appService := NewAppService(appRepository, loggerService, appStatusService, appNotificationsService, routeStatusService)
 			app, err := appService.GetApps(ctx,
 				32)
@@ -229,9 +235,11 @@ func TestAppService_DeleteApp(t *testing.T) {
 			loggerService := tests.CreateLogger()
 			appRepository, cacheService := testScenario.setupMock()
 			routeRepository := repository.NewRouteRepository(&sql.DB{}, loggerService)
-			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost)
+			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost,
+				tests.CreateDockerClientManager(loggerService), new(mocks.MockPm2Client),
+				new(mocks.MockSystemdClient), new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient))
 			appNotificationsService := NewAppNotificationsService(appRepository, loggerService)
-			routeStatusService := NewRouteStatusService(routeRepository, loggerService)
+			routeStatusService := NewRouteStatusService(routeRepository, &fakeAppNotificationsService{}, "test-key", loggerService)
 			appService := NewAppService(appRepository, loggerService, appStatusService, appNotificationsService, routeStatusService)
 			err := appService.DeleteApp(ctx,
 				"delete", 21)
@@ -283,9 +291,11 @@ func TestAppService_UpdateApp(t *testing.T) {
 			loggerService := tests.CreateLogger()
 			appRepository, cacheService := testScenario.setupMock()
 			routeRepository := repository.NewRouteRepository(&sql.DB{}, loggerService)
-			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost)
+			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, env.DockerHost,
+				tests.CreateDockerClientManager(loggerService), new(mocks.MockPm2Client),
+				new(mocks.MockSystemdClient), new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient))
 			appNotificationsService := NewAppNotificationsService(appRepository, loggerService)
-			routeStatusService := NewRouteStatusService(routeRepository, loggerService)
+			routeStatusService := NewRouteStatusService(routeRepository, &fakeAppNotificationsService{}, "test-key", loggerService)
 			appService := NewAppService(appRepository, loggerService, appStatusService, appNotificationsService, routeStatusService)
 			app := DTO.UpdateApp{Name: "Test", Description: "test", Port: "3020", IPAddress: "192.168.20.10"}
 			err := appService.UpdateApp(ctx,
@@ -345,8 +355,8 @@ func TestAppService_CheckAppsStatus(t *testing.T) {
 				mApp := new(mocks.MockAppRepository)
 				mApp.On("GetAppsToCheck", mock.Anything).Return([]*models.AppToCheck{
 					{
-						ID:       appId,
-						IsDocker: true,
+						ID:      appId,
+						Runtime: models.RuntimeDocker,
 					},
 				},
 					nil)
@@ -365,8 +375,8 @@ func TestAppService_CheckAppsStatus(t *testing.T) {
 				mApp := new(mocks.MockAppRepository)
 				mApp.On("GetAppsToCheck", mock.Anything).Return([]*models.AppToCheck{
 					{
-						ID:       "r32r23r",
-						IsDocker: true,
+						ID:      "r32r23r",
+						Runtime: models.RuntimeDocker,
 					},
 				},
 					nil)
@@ -385,7 +395,7 @@ func TestAppService_CheckAppsStatus(t *testing.T) {
 				mApp.On("GetAppsToCheck", mock.Anything).Return([]*models.AppToCheck{
 					{
 						ID:        "r32r23r",
-						IsDocker:  false,
+						Runtime:   models.RuntimeTCP,
 						IPAddress: "192.168.0.100",
 						Port:      env.Port,
 					},
@@ -408,7 +418,7 @@ func TestAppService_CheckAppsStatus(t *testing.T) {
 				mApp.On("GetAppsToCheck", mock.Anything).Return([]*models.AppToCheck{
 					{
 						ID:        "r32r23r",
-						IsDocker:  false,
+						Runtime:   models.RuntimeTCP,
 						IPAddress: "192.168.0.100",
 						Port:      env.Port,
 					},
@@ -431,7 +441,7 @@ func TestAppService_CheckAppsStatus(t *testing.T) {
 				mApp.On("GetAppsToCheck", mock.Anything).Return([]*models.AppToCheck{
 					{
 						ID:        "r32r23r",
-						IsDocker:  false,
+						Runtime:   models.RuntimeTCP,
 						IPAddress: "192.168.0.100",
 						Port:      "9999",
 					},
@@ -454,7 +464,7 @@ func TestAppService_CheckAppsStatus(t *testing.T) {
 				mApp.On("GetAppsToCheck", mock.Anything).Return([]*models.AppToCheck{
 					{
 						ID:        "r32r23r",
-						IsDocker:  false,
+						Runtime:   models.RuntimeTCP,
 						IPAddress: "192.168.0.100",
 						Port:      env.Port,
 					},
@@ -478,9 +488,11 @@ func TestAppService_CheckAppsStatus(t *testing.T) {
 			appId := containerId
 			appRepository, cacheService := testScenario.setupMock(appId)
 			routeRepository := repository.NewRouteRepository(&sql.DB{}, loggerService)
-			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, testScenario.dockerHost)
+			appStatusService := NewAppStatusService(appRepository, cacheService, loggerService, testScenario.dockerHost,
+				tests.CreateDockerClientManager(loggerService), new(mocks.MockPm2Client),
+				new(mocks.MockSystemdClient), new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient))
 			appNotificationsService := NewAppNotificationsService(appRepository, loggerService)
-			routeStatusService := NewRouteStatusService(routeRepository, loggerService)
+			routeStatusService := NewRouteStatusService(routeRepository, &fakeAppNotificationsService{}, "test-key", loggerService)
 			appService := NewAppService(appRepository, loggerService, appStatusService, appNotificationsService, routeStatusService)
 			_, err := appService.CheckAppsStatus(ctx)
 			if testScenario.expectedError == nil {
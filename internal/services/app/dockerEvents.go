@@ -0,0 +1,132 @@
+package servicesApp
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+// dockerStatusEvents change the status of a container. The daemon matches health_status to the
+// "health_status: healthy" and "health_status: unhealthy" events. A container which crashed reports only die, kill
+// and stop are sent when it is stopped through the API.
+var dockerStatusEvents = []events.Action{
+	events.ActionDie, events.ActionStart, events.ActionStop, events.ActionKill, events.ActionPause,
+	events.ActionUnPause, events.ActionOOM, events.ActionHealthStatus,
+}
+
+// DockerEventsService updates the statuses of docker apps as soon as the docker daemon reports a change, so
+// notifications do not wait for the next status check.
+type DockerEventsService struct {
+	appStatusService        interfaces.AppStatusService
+	appNotificationsService interfaces.AppNotificationsService
+	dockerClientManager     interfaces.DockerClientManager
+	dockerHost              string
+	logger                  utils.LoggerService
+	retryBackoff            time.Duration
+	maxRetryBackoff         time.Duration
+}
+
+func NewDockerEventsService(appStatusService interfaces.AppStatusService,
+	appNotificationsService interfaces.AppNotificationsService, logger utils.LoggerService, dockerHost string,
+	dockerClientManager interfaces.DockerClientManager,
+) *DockerEventsService {
+	return &DockerEventsService{
+		appStatusService:        appStatusService,
+		appNotificationsService: appNotificationsService,
+		dockerClientManager:     dockerClientManager,
+		dockerHost:              dockerHost,
+		logger:                  logger,
+		retryBackoff:            time.Second,
+		maxRetryBackoff:         time.Minute,
+	}
+}
+
+// Run blocks until the context is canceled. The stream is subscribed again with backoff when the daemon restarts,
+// the status checks of the worker inspect all docker apps while it is not subscribed.
+func (de *DockerEventsService) Run(ctx context.Context) {
+	backoff := de.retryBackoff
+	for {
+		subscribedAt := time.Now()
+		err := de.subscribe(ctx)
+		de.appStatusService.SetDockerEventsSubscribed(false)
+		if ctx.Err() != nil {
+			de.logger.Info("Docker events subscription stopped")
+			return
+		}
+
+		if time.Since(subscribedAt) > de.maxRetryBackoff {
+			backoff = de.retryBackoff
+		}
+		de.logger.Warn("docker events stream disconnected, subscribing again", map[string]any{
+			"backoff": backoff.String(),
+			"err":     err.Error(),
+		})
+
+		select {
+		case <-time.After(backoff):
+			backoff = min(backoff*2, de.maxRetryBackoff)
+		case <-ctx.Done():
+			de.logger.Info("Docker events subscription stopped")
+			return
+		}
+	}
+}
+
+func (de *DockerEventsService) subscribe(ctx context.Context) error {
+	cli, err := de.dockerClientManager.GetClient(ctx, de.dockerHost)
+	if err != nil {
+		return err
+	}
+
+	eventsFilters := filters.NewArgs(filters.Arg("type", string(events.ContainerEventType)))
+	for _, action := range dockerStatusEvents {
+		eventsFilters.Add("event", string(action))
+	}
+	messages, errs := cli.Events(ctx, events.ListOptions{Filters: eventsFilters})
+	de.appStatusService.SetDockerEventsSubscribed(true)
+	de.logger.Info("Subscribed to docker events")
+
+	for {
+		select {
+		case message := <-messages:
+			de.handleEvent(ctx, message)
+		case err := <-errs:
+			return err
+		}
+	}
+}
+
+// handleEvent records a deliberate stop before the status is checked. docker stop and docker kill send kill before
+// die, so the exit code they leave is never remediated, start clears the stop.
+func (de *DockerEventsService) handleEvent(ctx context.Context, message events.Message) {
+	switch message.Action {
+	case events.ActionKill, events.ActionStop, events.ActionStart:
+		stoppedByUser := message.Action != events.ActionStart
+		err := de.appStatusService.SetDockerAppStoppedByUser(ctx, message.Actor.ID, stoppedByUser)
+		if err != nil {
+			de.logger.Warn("failed to record deliberate stop of docker app", map[string]any{
+				"containerID": message.Actor.ID,
+				"event":       string(message.Action),
+				"err":         err.Error(),
+			})
+		}
+	}
+
+	appsToSendNotification, err := de.appStatusService.CheckDockerAppStatus(ctx, message.Actor.ID)
+	if err != nil {
+		de.logger.Warn("failed to check status of docker app after event", map[string]any{
+			"containerID": message.Actor.ID,
+			"event":       string(message.Action),
+			"err":         err.Error(),
+		})
+		return
+	}
+
+	if err := de.appNotificationsService.SendNotifications(ctx, appsToSendNotification); err != nil {
+		de.logger.Warn("failed to send notifications after docker event", err)
+	}
+}
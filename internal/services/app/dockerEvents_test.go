@@ -0,0 +1,201 @@
+package servicesApp
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeAppNotificationsService struct {
+	notifications      chan DTO.AppStatus
+	failedRemediations []DTO.FailedRemediation
+}
+
+func (fn *fakeAppNotificationsService) SendNotifications(ctx context.Context, appsStatuses []DTO.AppStatus) error {
+	for _, appStatus := range appsStatuses {
+		select {
+		case fn.notifications <- appStatus:
+		default:
+		}
+	}
+	return nil
+}
+
+func (fn *fakeAppNotificationsService) SendLogAlertsNotifications(ctx context.Context,
+	triggeredLogAlerts []DTO.TriggeredLogAlert,
+) error {
+	return nil
+}
+
+func (fn *fakeAppNotificationsService) SendRemediationsFailedNotifications(ctx context.Context,
+	failedRemediations []DTO.FailedRemediation,
+) error {
+	fn.failedRemediations = append(fn.failedRemediations, failedRemediations...)
+	return nil
+}
+
+func (fn *fakeAppNotificationsService) SendRouteFlowsNotifications(ctx context.Context,
+	routeFlowNotifications []DTO.RouteFlowNotification,
+) error {
+	return nil
+}
+
+func TestDockerEventsService_Run(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	var subscriptions atomic.Int32
+	dockerServer := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+		"GET /events": func(w http.ResponseWriter, r *http.Request) {
+			subscriptions.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"Type":"container","Action":"die","Actor":{"ID":"abc"}}` + "\n"))
+		},
+		"GET /containers/abc/json": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"Id":"abc","State":{"Status":"exited","StartedAt":"2025-01-01T10:00:00Z"}}`))
+		},
+	})
+	defer dockerServer.Close()
+	dockerClientManager := tests.CreateDockerClientManager(loggerService)
+	defer dockerClientManager.Close()
+
+	appRepository := new(mocks.MockAppRepository)
+	appRepository.On("GetAppsToCheck", mock.Anything).Return([]*models.AppToCheck{
+		{ID: "abc", Runtime: models.RuntimeDocker, Status: "running"},
+		{ID: "tcp-app", Runtime: models.RuntimeTCP, Status: "running"},
+	}, nil)
+	appRepository.On("InsertAppStatuses", mock.Anything, mock.Anything).Return(nil)
+	cacheService := new(mocks.MockCacheService)
+	cacheService.On("SetData", mock.Anything, "status-abc", mock.Anything, mock.Anything).Return(nil)
+	appStatusService := NewAppStatusService(appRepository, cacheService, loggerService,
+		tests.FakeDockerHost(dockerServer), dockerClientManager, new(mocks.MockPm2Client),
+		new(mocks.MockSystemdClient), new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient))
+	notificationsService := &fakeAppNotificationsService{notifications: make(chan DTO.AppStatus, 1)}
+	dockerEventsService := NewDockerEventsService(appStatusService, notificationsService, loggerService,
+		tests.FakeDockerHost(dockerServer), dockerClientManager)
+	dockerEventsService.retryBackoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dockerEventsService.Run(ctx)
+
+	select {
+	case appStatus := <-notificationsService.notifications:
+		assert.Equal(t, "abc", appStatus.AppID)
+		assert.Equal(t, "exited", appStatus.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification was not sent after the docker event")
+	}
+	// The stream of the fake daemon ends after every event, so it has to be subscribed again.
+	assert.Eventually(t, func() bool {
+		return subscriptions.Load() >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestAppStatusService_SkipDockerApps(t *testing.T) {
+	appsToCheck := []*models.AppToCheck{
+		{ID: "abc", Runtime: models.RuntimeDocker},
+		{ID: "pm2-api", Runtime: models.RuntimePm2},
+	}
+	type args struct {
+		name                     string
+		subscribed               bool
+		lastDockerReconciliation time.Time
+		expectedAppsIDs          []string
+	}
+	testsScenarios := []args{
+		{
+			name:            "Events stream is not subscribed",
+			expectedAppsIDs: []string{"abc", "pm2-api"},
+		},
+		{
+			name:                     "Between reconciliations",
+			subscribed:               true,
+			lastDockerReconciliation: time.Now(),
+			expectedAppsIDs:          []string{"pm2-api"},
+		},
+		{
+			name:                     "Reconciliation",
+			subscribed:               true,
+			lastDockerReconciliation: time.Now().Add(-2 * dockerReconciliationPeriod),
+			expectedAppsIDs:          []string{"abc", "pm2-api"},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appStatusService := NewAppStatusService(nil, nil, tests.CreateLogger(), "", nil, nil, nil, nil, nil)
+			appStatusService.SetDockerEventsSubscribed(testScenario.subscribed)
+			appStatusService.lastDockerReconciliation = testScenario.lastDockerReconciliation
+
+			appsIDs := make([]string, 0)
+			for _, appToCheck := range appStatusService.skipDockerApps(appsToCheck) {
+				appsIDs = append(appsIDs, appToCheck.ID)
+			}
+			assert.Equal(t, testScenario.expectedAppsIDs, appsIDs)
+		})
+	}
+}
+
+func TestDockerEventsService_handleEvent(t *testing.T) {
+	type args struct {
+		name                  string
+		action                events.Action
+		recordsStop           bool
+		expectedStoppedByUser bool
+	}
+	testsScenarios := []args{
+		{
+			name:                  "Container killed",
+			action:                events.ActionKill,
+			recordsStop:           true,
+			expectedStoppedByUser: true,
+		},
+		{
+			name:                  "Container stopped",
+			action:                events.ActionStop,
+			recordsStop:           true,
+			expectedStoppedByUser: true,
+		},
+		{
+			name:                  "Container started",
+			action:                events.ActionStart,
+			recordsStop:           true,
+			expectedStoppedByUser: false,
+		},
+		{
+			name:   "Container crashed",
+			action: events.ActionDie,
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			loggerService := tests.CreateLogger()
+			appRepository := new(mocks.MockAppRepository)
+			if testScenario.recordsStop {
+				appRepository.On("UpdateAppStoppedByUser", mock.Anything, "abc",
+					testScenario.expectedStoppedByUser).Return(nil)
+			}
+			appRepository.On("GetAppsToCheck", mock.Anything).Return([]*models.AppToCheck{}, nil)
+			appStatusService := NewAppStatusService(appRepository, nil, loggerService, "", nil, nil, nil, nil, nil)
+			dockerEventsService := NewDockerEventsService(appStatusService,
+				&fakeAppNotificationsService{notifications: make(chan DTO.AppStatus, 1)}, loggerService, "", nil)
+
+			dockerEventsService.handleEvent(context.Background(), events.Message{
+				Type:   events.ContainerEventType,
+				Action: testScenario.action,
+				Actor:  events.Actor{ID: "abc"},
+			})
+
+			appRepository.AssertExpectations(t)
+		})
+	}
+}
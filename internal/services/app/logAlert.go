@@ -0,0 +1,115 @@
+package servicesApp
+
+import (
+	"context"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type LogAlertService struct {
+	logAlertRepository      interfaces.LogAlertRepository
+	appRepository           interfaces.AppRepository
+	appNotificationsService interfaces.AppNotificationsService
+	loggerService           utils.LoggerService
+}
+
+func NewLogAlertService(logAlertRepository interfaces.LogAlertRepository, appRepository interfaces.AppRepository,
+	appNotificationsService interfaces.AppNotificationsService, loggerService utils.LoggerService,
+) *LogAlertService {
+	return &LogAlertService{
+		logAlertRepository:      logAlertRepository,
+		appRepository:           appRepository,
+		appNotificationsService: appNotificationsService,
+		loggerService:           loggerService,
+	}
+}
+
+func (la *LogAlertService) GetLogAlertRules(ctx context.Context, appID string,
+	ownerID int,
+) ([]DTO.LogAlertRule, error) {
+	return la.logAlertRepository.GetLogAlertRules(ctx, appID, ownerID)
+}
+
+func (la *LogAlertService) CreateLogAlertRule(ctx context.Context, appID string, ownerID int,
+	logAlertRule DTO.CreateLogAlertRule,
+) (int, error) {
+	// Patterns are validated by Postgres, which matches them against the lines.
+	err := la.logAlertRepository.ValidateLogAlertPattern(ctx, logAlertRule.Pattern)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = la.appRepository.GetApp(ctx, appID, ownerID)
+	if err != nil {
+		return 0, err
+	}
+
+	return la.logAlertRepository.InsertLogAlertRule(ctx, appID, logAlertRule)
+}
+
+func (la *LogAlertService) UpdateLogAlertRule(ctx context.Context, appID string, ruleID, ownerID int,
+	logAlertRule DTO.CreateLogAlertRule,
+) error {
+	if err := la.logAlertRepository.ValidateLogAlertPattern(ctx, logAlertRule.Pattern); err != nil {
+		return err
+	}
+
+	return la.logAlertRepository.UpdateLogAlertRule(ctx, appID, ruleID, ownerID, logAlertRule)
+}
+
+func (la *LogAlertService) DeleteLogAlertRule(ctx context.Context, appID string, ruleID, ownerID int) error {
+	return la.logAlertRepository.DeleteLogAlertRule(ctx, appID, ruleID, ownerID)
+}
+
+// EvaluateLogAlerts notifies about the rules which crossed their threshold and mutes them for the cooldown. Every
+// rule is counted on its own, so a rule which can not be evaluated does not stop the others.
+func (la *LogAlertService) EvaluateLogAlerts(ctx context.Context) error {
+	now := time.Now().UTC()
+	logAlertRules, err := la.logAlertRepository.GetDueLogAlertRules(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	triggeredLogAlerts := make([]DTO.TriggeredLogAlert, 0)
+	for _, logAlertRule := range logAlertRules {
+		since := now.Add(-time.Duration(logAlertRule.WindowSeconds) * time.Second)
+		matchedLines, err := la.logAlertRepository.CountMatchingLogLines(ctx, logAlertRule.AppID,
+			logAlertRule.Pattern, since)
+		if err != nil {
+			la.loggerService.Warn("failed to evaluate log alert rule", map[string]any{
+				"ruleID": logAlertRule.ID,
+				"err":    err.Error(),
+			})
+			continue
+		}
+		if matchedLines <= logAlertRule.Threshold {
+			continue
+		}
+
+		triggeredLogAlerts = append(triggeredLogAlerts, DTO.TriggeredLogAlert{
+			RuleID:        logAlertRule.ID,
+			AppID:         logAlertRule.AppID,
+			Name:          logAlertRule.Name,
+			Pattern:       logAlertRule.Pattern,
+			WindowSeconds: logAlertRule.WindowSeconds,
+			MatchedLines:  matchedLines,
+		})
+	}
+	if len(triggeredLogAlerts) == 0 {
+		return nil
+	}
+
+	rulesIDs := make([]int, 0, len(triggeredLogAlerts))
+	for _, triggeredLogAlert := range triggeredLogAlerts {
+		rulesIDs = append(rulesIDs, triggeredLogAlert.RuleID)
+	}
+	err = la.logAlertRepository.UpdateLogAlertsLastTriggered(ctx, rulesIDs, now)
+	if err != nil {
+		return err
+	}
+
+	return la.appNotificationsService.SendLogAlertsNotifications(ctx, triggeredLogAlerts)
+}
@@ -0,0 +1,163 @@
+package servicesApp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLogAlertService_CreateLogAlertRule(t *testing.T) {
+	type args struct {
+		name          string
+		logAlertRule  DTO.CreateLogAlertRule
+		expectedID    int
+		expectedError error
+		setupMock     func() (*mocks.MockLogAlertRepository, *mocks.MockAppRepository)
+	}
+	logAlertRule := DTO.CreateLogAlertRule{Name: "Errors", Pattern: "ERROR|panic", WindowSeconds: 300, Threshold: 5}
+	testsScenarios := []args{
+		{
+			name:          "Invalid pattern",
+			logAlertRule:  DTO.CreateLogAlertRule{Name: "Errors", Pattern: "(", WindowSeconds: 300},
+			expectedError: errors.New("pattern is not a valid regular expression"),
+			setupMock: func() (*mocks.MockLogAlertRepository, *mocks.MockAppRepository) {
+				mLogAlert := new(mocks.MockLogAlertRepository)
+				mLogAlert.On("ValidateLogAlertPattern", mock.Anything, "(").
+					Return(models.NewError(400, "Validation", "pattern is not a valid regular expression"))
+				return mLogAlert, new(mocks.MockAppRepository)
+			},
+		},
+		{
+			name:          "App of another user",
+			logAlertRule:  logAlertRule,
+			expectedError: errors.New("app not found"),
+			setupMock: func() (*mocks.MockLogAlertRepository, *mocks.MockAppRepository) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApp", mock.Anything, "app", 1).Return((*models.App)(nil),
+					models.NewError(404, "App", "app not found"))
+				mLogAlert := new(mocks.MockLogAlertRepository)
+				mLogAlert.On("ValidateLogAlertPattern", mock.Anything, "ERROR|panic").Return(nil)
+				return mLogAlert, mApp
+			},
+		},
+		{
+			name:         "Rule created",
+			logAlertRule: logAlertRule,
+			expectedID:   3,
+			setupMock: func() (*mocks.MockLogAlertRepository, *mocks.MockAppRepository) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApp", mock.Anything, "app", 1).Return(&models.App{ID: "app"}, nil)
+				mLogAlert := new(mocks.MockLogAlertRepository)
+				mLogAlert.On("ValidateLogAlertPattern", mock.Anything, "ERROR|panic").Return(nil)
+				mLogAlert.On("InsertLogAlertRule", mock.Anything, "app", logAlertRule).Return(3, nil)
+				return mLogAlert, mApp
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			loggerService := tests.CreateLogger()
+			logAlertRepository, appRepository := testScenario.setupMock()
+			logAlertService := NewLogAlertService(logAlertRepository, appRepository,
+				NewAppNotificationsService(appRepository, loggerService), loggerService)
+			ruleID, err := logAlertService.CreateLogAlertRule(context.Background(), "app", 1,
+				testScenario.logAlertRule)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, testScenario.expectedID, ruleID)
+			} else {
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+		})
+	}
+}
+
+func TestLogAlertService_EvaluateLogAlerts(t *testing.T) {
+	type args struct {
+		name          string
+		expectedError error
+		setupMock     func() (*mocks.MockLogAlertRepository, *mocks.MockAppRepository)
+	}
+	logAlertRules := []DTO.LogAlertRule{
+		{ID: 1, AppID: "app", Name: "Errors", Pattern: "ERROR", WindowSeconds: 60, Threshold: 10},
+		{ID: 2, AppID: "app", Name: "Warnings", Pattern: "WARN", WindowSeconds: 60, Threshold: 10},
+		{ID: 4, AppID: "app", Name: "Panics", Pattern: "panic", WindowSeconds: 60, Threshold: 0},
+	}
+	testsScenarios := []args{
+		{
+			name: "No alerts triggered",
+			setupMock: func() (*mocks.MockLogAlertRepository, *mocks.MockAppRepository) {
+				mLogAlert := new(mocks.MockLogAlertRepository)
+				mLogAlert.On("GetDueLogAlertRules", mock.Anything, mock.Anything).Return(logAlertRules[:1], nil)
+				mLogAlert.On("CountMatchingLogLines", mock.Anything, "app", "ERROR", mock.Anything).Return(10, nil)
+				return mLogAlert, new(mocks.MockAppRepository)
+			},
+		},
+		{
+			name: "Triggered alerts are muted and sent",
+			setupMock: func() (*mocks.MockLogAlertRepository, *mocks.MockAppRepository) {
+				mLogAlert := new(mocks.MockLogAlertRepository)
+				mLogAlert.On("GetDueLogAlertRules", mock.Anything, mock.Anything).Return(logAlertRules, nil)
+				mLogAlert.On("CountMatchingLogLines", mock.Anything, "app", "ERROR", mock.Anything).Return(12, nil)
+				mLogAlert.On("CountMatchingLogLines", mock.Anything, "app", "WARN", mock.Anything).Return(3, nil)
+				mLogAlert.On("CountMatchingLogLines", mock.Anything, "app", "panic", mock.Anything).Return(2, nil)
+				mLogAlert.On("UpdateLogAlertsLastTriggered", mock.Anything, []int{1, 4}, mock.Anything).Return(nil)
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetUsersToSendNotifications", mock.Anything, []DTO.AppStatus{{AppID: "app"}}).
+					Return([]models.NotificationInfo{}, nil)
+				return mLogAlert, mApp
+			},
+		},
+		{
+			name: "Rule which can not be evaluated does not stop the others",
+			setupMock: func() (*mocks.MockLogAlertRepository, *mocks.MockAppRepository) {
+				mLogAlert := new(mocks.MockLogAlertRepository)
+				mLogAlert.On("GetDueLogAlertRules", mock.Anything, mock.Anything).Return(logAlertRules, nil)
+				mLogAlert.On("CountMatchingLogLines", mock.Anything, "app", "ERROR", mock.Anything).
+					Return(0, models.NewError(500, "Database", "failed to get data from database"))
+				mLogAlert.On("CountMatchingLogLines", mock.Anything, "app", "WARN", mock.Anything).Return(3, nil)
+				mLogAlert.On("CountMatchingLogLines", mock.Anything, "app", "panic", mock.Anything).Return(2, nil)
+				mLogAlert.On("UpdateLogAlertsLastTriggered", mock.Anything, []int{4}, mock.Anything).Return(nil)
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetUsersToSendNotifications", mock.Anything, []DTO.AppStatus{{AppID: "app"}}).
+					Return([]models.NotificationInfo{}, nil)
+				return mLogAlert, mApp
+			},
+		},
+		{
+			name:          "Failed to mute alerts",
+			expectedError: errors.New("failed to update data in database"),
+			setupMock: func() (*mocks.MockLogAlertRepository, *mocks.MockAppRepository) {
+				mLogAlert := new(mocks.MockLogAlertRepository)
+				mLogAlert.On("GetDueLogAlertRules", mock.Anything, mock.Anything).Return(logAlertRules[2:], nil)
+				mLogAlert.On("CountMatchingLogLines", mock.Anything, "app", "panic", mock.Anything).Return(2, nil)
+				mLogAlert.On("UpdateLogAlertsLastTriggered", mock.Anything, []int{4}, mock.Anything).
+					Return(models.NewError(500, "Database", "failed to update data in database"))
+				return mLogAlert, new(mocks.MockAppRepository)
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			loggerService := tests.CreateLogger()
+			logAlertRepository, appRepository := testScenario.setupMock()
+			logAlertService := NewLogAlertService(logAlertRepository, appRepository,
+				NewAppNotificationsService(appRepository, loggerService), loggerService)
+			err := logAlertService.EvaluateLogAlerts(context.Background())
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			logAlertRepository.AssertExpectations(t)
+			appRepository.AssertExpectations(t)
+		})
+	}
+}
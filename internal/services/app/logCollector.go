@@ -0,0 +1,258 @@
+package servicesApp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+const (
+	logsFollowRefreshPeriod = 30 * time.Second
+	logsFlushPeriod         = 2 * time.Second
+	logsRetentionPeriod     = time.Hour
+	logsBatchSize           = 500
+)
+
+var logLevelRegexp = regexp.MustCompile(`(?i)\b(debug|info|warn|warning|err|error|fatal|panic|critical)\b`)
+
+type followedApp struct {
+	cancel context.CancelFunc
+}
+
+// LogCollectorService follows the logs of all docker apps and saves them in the database, so they can be
+// searched later.
+type LogCollectorService struct {
+	appRepository       interfaces.AppRepository
+	logRepository       interfaces.LogRepository
+	dockerClientManager interfaces.DockerClientManager
+	dockerHost          string
+	logger              utils.LoggerService
+	mu                  sync.Mutex
+	followedApps        map[string]*followedApp
+	logs                chan DTO.AppLog
+}
+
+func NewLogCollectorService(appRepository interfaces.AppRepository, logRepository interfaces.LogRepository,
+	logger utils.LoggerService, dockerHost string, dockerClientManager interfaces.DockerClientManager,
+) *LogCollectorService {
+	return &LogCollectorService{
+		appRepository:       appRepository,
+		logRepository:       logRepository,
+		dockerClientManager: dockerClientManager,
+		dockerHost:          dockerHost,
+		logger:              logger,
+		followedApps:        make(map[string]*followedApp),
+		logs:                make(chan DTO.AppLog, logsBatchSize),
+	}
+}
+
+func detectLogLevel(line string) string {
+	match := logLevelRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+
+	switch level := strings.ToLower(match[1]); level {
+	case "warning":
+		return "warn"
+	case "err":
+		return "error"
+	case "panic", "critical":
+		return "fatal"
+	default:
+		return level
+	}
+}
+
+// Run blocks until the context is canceled.
+func (lc *LogCollectorService) Run(ctx context.Context) {
+	go lc.saveLogs(ctx)
+
+	lc.followApps(ctx)
+	followTicker := time.NewTicker(logsFollowRefreshPeriod)
+	defer followTicker.Stop()
+	retentionTicker := time.NewTicker(logsRetentionPeriod)
+	defer retentionTicker.Stop()
+	for {
+		select {
+		case <-followTicker.C:
+			lc.followApps(ctx)
+		case <-retentionTicker.C:
+			if err := lc.logRepository.DeleteExpiredLogs(ctx); err != nil {
+				lc.logger.Warn("Something went wrong during deleting expired logs", err)
+			}
+		case <-ctx.Done():
+			lc.logger.Info("Logs collection stopped")
+			return
+		}
+	}
+}
+
+// followApps starts following the logs of new docker apps and stops following the removed ones. Apps whose
+// containers stopped are followed again after they start.
+func (lc *LogCollectorService) followApps(ctx context.Context) {
+	apps, err := lc.appRepository.GetAppsToCheck(ctx)
+	if err != nil {
+		lc.logger.Warn("Something went wrong during reading apps to collect logs from", err)
+		return
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	dockerApps := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		if app.Runtime != models.RuntimeDocker {
+			continue
+		}
+		dockerApps[app.ID] = true
+		if _, ok := lc.followedApps[app.ID]; ok {
+			continue
+		}
+
+		appCtx, cancel := context.WithCancel(ctx)
+		followed := &followedApp{cancel: cancel}
+		lc.followedApps[app.ID] = followed
+		go func(appID string) {
+			defer func() {
+				lc.mu.Lock()
+				if lc.followedApps[appID] == followed {
+					delete(lc.followedApps, appID)
+				}
+				lc.mu.Unlock()
+				cancel()
+			}()
+			lc.followApp(appCtx, appID)
+		}(app.ID)
+	}
+
+	for appID, followed := range lc.followedApps {
+		if !dockerApps[appID] {
+			followed.cancel()
+			delete(lc.followedApps, appID)
+		}
+	}
+}
+
+// followApp reads the logs of the container from the last collected line until the container stops.
+func (lc *LogCollectorService) followApp(ctx context.Context, appID string) {
+	cli, err := lc.dockerClientManager.GetClient(ctx, lc.dockerHost)
+	if err != nil {
+		return
+	}
+
+	containerInfo, err := cli.ContainerInspect(ctx, appID)
+	if err != nil {
+		lc.logger.Warn("failed to inspect container to collect logs from", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+		return
+	}
+
+	lastLogTime, err := lc.logRepository.GetLastLogTime(ctx, appID)
+	if err != nil {
+		return
+	}
+	options := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+	}
+	if !lastLogTime.IsZero() {
+		since := lastLogTime.Add(time.Nanosecond)
+		options.Since = fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond())
+	}
+
+	reader, err := cli.ContainerLogs(ctx, appID, options)
+	if err != nil {
+		lc.logger.Warn("failed to follow container logs", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+		return
+	}
+	defer reader.Close()
+
+	send := func(stream string) func(DTO.LogLine) error {
+		return func(logLine DTO.LogLine) error {
+			if logLine.Timestamp.IsZero() {
+				logLine.Timestamp = time.Now().UTC()
+			}
+			select {
+			case lc.logs <- DTO.AppLog{
+				AppID:    appID,
+				Stream:   stream,
+				Level:    detectLogLevel(logLine.Line),
+				Line:     logLine.Line,
+				LoggedAt: logLine.Timestamp,
+			}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	stdoutWriter := &logLineWriter{stream: logsStreamStdout}
+	stdoutWriter.send = send(stdoutWriter.stream)
+	stderrWriter := &logLineWriter{stream: logsStreamStderr}
+	stderrWriter.send = send(stderrWriter.stream)
+	if containerInfo.Config != nil && containerInfo.Config.Tty {
+		_, err = io.Copy(stdoutWriter, reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdoutWriter, stderrWriter, reader)
+	}
+	if err == nil {
+		err = stdoutWriter.Flush()
+	}
+	if err == nil {
+		err = stderrWriter.Flush()
+	}
+	if err != nil && ctx.Err() == nil {
+		lc.logger.Warn("logs collection of the container stopped", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+	}
+}
+
+// saveLogs inserts the collected logs in batches.
+func (lc *LogCollectorService) saveLogs(ctx context.Context) {
+	flushTicker := time.NewTicker(logsFlushPeriod)
+	defer flushTicker.Stop()
+	batch := make([]DTO.AppLog, 0, logsBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := lc.logRepository.InsertLogs(context.Background(), batch); err != nil {
+			lc.logger.Warn("Something went wrong during inserting logs", err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case log := <-lc.logs:
+			batch = append(batch, log)
+			if len(batch) >= logsBatchSize {
+				flush()
+			}
+		case <-flushTicker.C:
+			flush()
+		case <-ctx.Done():
+			flush()
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package servicesApp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDetectLogLevel(t *testing.T) {
+	type args struct {
+		name          string
+		line          string
+		expectedLevel string
+	}
+	testsScenarios := []args{
+		{name: "Plain level", line: "INFO server started", expectedLevel: "info"},
+		{name: "Warning", line: "[Warning] disk is almost full", expectedLevel: "warn"},
+		{name: "JSON log", line: `{"level":"error","msg":"failed"}`, expectedLevel: "error"},
+		{name: "Panic", line: "panic: runtime error", expectedLevel: "fatal"},
+		{name: "Level inside a word", line: "reinforcement learning", expectedLevel: ""},
+		{name: "No level", line: "GET /health 200", expectedLevel: ""},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			assert.Equal(t, testScenario.expectedLevel, detectLogLevel(testScenario.line))
+		})
+	}
+}
+
+func TestLogCollectorService_followApp(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	lastLogTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	dockerServer := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+		"GET /containers/app/json": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"Id":"app","Config":{"Tty":false}}`))
+		},
+		"GET /containers/app/logs": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("since") != "1735725600.000000001" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = stdcopy.NewStdWriter(w, stdcopy.Stdout).Write([]byte("2025-01-01T10:00:01Z INFO started\n"))
+			_, _ = stdcopy.NewStdWriter(w, stdcopy.Stderr).Write([]byte("2025-01-01T10:00:02Z ERROR failed\n"))
+		},
+	})
+	defer dockerServer.Close()
+	dockerClientManager := tests.CreateDockerClientManager(loggerService)
+	defer dockerClientManager.Close()
+
+	logRepository := new(mocks.MockLogRepository)
+	logRepository.On("GetLastLogTime", mock.Anything, "app").Return(lastLogTime, nil)
+	logCollectorService := NewLogCollectorService(new(mocks.MockAppRepository), logRepository, loggerService,
+		tests.FakeDockerHost(dockerServer), dockerClientManager)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	logCollectorService.followApp(ctx, "app")
+	close(logCollectorService.logs)
+	logs := make([]DTO.AppLog, 0)
+	for log := range logCollectorService.logs {
+		logs = append(logs, log)
+	}
+	assert.Equal(t, []DTO.AppLog{
+		{AppID: "app", Stream: "stdout", Level: "info", Line: "INFO started", LoggedAt: lastLogTime.Add(time.Second)},
+		{AppID: "app", Stream: "stderr", Level: "error", Line: "ERROR failed",
+			LoggedAt: lastLogTime.Add(2 * time.Second)},
+	}, logs)
+}
+
+func TestLogsService_SearchLogs(t *testing.T) {
+	type args struct {
+		name          string
+		logsSearch    DTO.LogsSearch
+		expectedPage  int
+		expectedLimit int
+	}
+	testsScenarios := []args{
+		{name: "Default pagination", logsSearch: DTO.LogsSearch{}, expectedPage: 1, expectedLimit: 50},
+		{name: "Limit is capped", logsSearch: DTO.LogsSearch{Page: 3, Limit: 1000}, expectedPage: 3, expectedLimit: 200},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			logRepository := new(mocks.MockLogRepository)
+			logRepository.On("SearchLogs", mock.Anything, 1, mock.MatchedBy(func(logsSearch DTO.LogsSearch) bool {
+				return logsSearch.Page == testScenario.expectedPage && logsSearch.Limit == testScenario.expectedLimit
+			})).Return(DTO.LogsSearchResult{}, nil)
+			logsService := NewLogsService(logRepository, tests.CreateLogger())
+			_, err := logsService.SearchLogs(context.Background(), 1, testScenario.logsSearch)
+			assert.NoError(t, err)
+			logRepository.AssertExpectations(t)
+		})
+	}
+}
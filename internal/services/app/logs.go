@@ -0,0 +1,46 @@
+package servicesApp
+
+import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+const (
+	defaultLogsSearchLimit = 50
+	maxLogsSearchLimit     = 200
+)
+
+type LogsService struct {
+	logRepository interfaces.LogRepository
+	logger        utils.LoggerService
+}
+
+func NewLogsService(logRepository interfaces.LogRepository, logger utils.LoggerService) *LogsService {
+	return &LogsService{
+		logRepository: logRepository,
+		logger:        logger,
+	}
+}
+
+func (ls *LogsService) SearchLogs(ctx context.Context, ownerID int,
+	logsSearch DTO.LogsSearch,
+) (DTO.LogsSearchResult, error) {
+	if logsSearch.Page < 1 {
+		logsSearch.Page = 1
+	}
+	if logsSearch.Limit < 1 {
+		logsSearch.Limit = defaultLogsSearchLimit
+	}
+	if logsSearch.Limit > maxLogsSearchLimit {
+		logsSearch.Limit = maxLogsSearchLimit
+	}
+
+	return ls.logRepository.SearchLogs(ctx, ownerID, logsSearch)
+}
+
+func (ls *LogsService) UpdateLogsRetention(ctx context.Context, appID string, ownerID, days int) error {
+	return ls.logRepository.UpdateLogsRetention(ctx, appID, ownerID, days)
+}
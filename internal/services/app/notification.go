@@ -3,9 +3,13 @@ package servicesApp
 import (
 	"context"
 	"fmt"
+	"maps"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/models"
@@ -14,6 +18,9 @@ import (
 	"github.com/slodkiadrianek/octopus/internal/utils/request"
 )
 
+// routeFlowNotificationSnippetLength is the part of the response of the failed step sent in the notification.
+const routeFlowNotificationSnippetLength = 200
+
 type AppNotificationsService struct {
 	appRepository interfaces.AppRepository
 	loggerService utils.LoggerService
@@ -128,6 +135,20 @@ func (an *AppNotificationsService) sendWebhook(ctx context.Context, notification
 	return nil
 }
 
+// describeAppsStatuses replaces the statuses of the apps with their description, so a notification tells why the
+// app is down.
+func describeAppsStatuses(notificationsInfo []models.NotificationInfo, appsStatuses []DTO.AppStatus) {
+	descriptions := make(map[string]string, len(appsStatuses))
+	for _, appStatus := range appsStatuses {
+		descriptions[appStatus.AppID] = appStatus.Describe()
+	}
+	for i := range notificationsInfo {
+		if description, found := descriptions[notificationsInfo[i].ID]; found {
+			notificationsInfo[i].Status = description
+		}
+	}
+}
+
 func (an *AppNotificationsService) SendNotifications(ctx context.Context, appsStatuses []DTO.AppStatus) error {
 	if len(appsStatuses) == 0 {
 		return nil
@@ -139,7 +160,20 @@ func (an *AppNotificationsService) SendNotifications(ctx context.Context, appsSt
 	if err != nil {
 		return err
 	}
+	describeAppsStatuses(notificationsInfo, appsStatuses)
+
+	err = an.sendNotificationsInfo(ctx, notificationsInfo)
+	if err != nil {
+		return err
+	}
+	an.loggerService.Info("successfully sent notifications to user")
+
+	return nil
+}
 
+func (an *AppNotificationsService) sendNotificationsInfo(ctx context.Context,
+	notificationsInfo []models.NotificationInfo,
+) error {
 	sortedNotificationsToSend := an.assignNotificationToProperSendService(notificationsInfo)
 	discordNotifications, slackNotifications := an.sortNotificationsBySendToInformation(sortedNotificationsToSend)
 	var discordWebhookError, slackWebhookError error
@@ -162,7 +196,127 @@ func (an *AppNotificationsService) SendNotifications(ctx context.Context, appsSt
 	if slackWebhookError != nil {
 		return slackWebhookError
 	}
-	an.loggerService.Info("successfully sent notifications to user")
 
 	return nil
 }
+
+// sendAppsDescriptions sends every description in place of the status of its app, to the users notified about
+// that app. The callers save that the notification was sent before calling it, so a failing webhook does not repeat
+// the notification on every check.
+func (an *AppNotificationsService) sendAppsDescriptions(ctx context.Context,
+	appsDescriptions map[string][]string,
+) error {
+	if len(appsDescriptions) == 0 {
+		return nil
+	}
+
+	appsStatuses := make([]DTO.AppStatus, 0, len(appsDescriptions))
+	for _, appID := range slices.Sorted(maps.Keys(appsDescriptions)) {
+		appsStatuses = append(appsStatuses, DTO.AppStatus{AppID: appID})
+	}
+	appsNotificationsInfo, err := an.appRepository.GetUsersToSendNotifications(ctx, appsStatuses)
+	if err != nil {
+		return err
+	}
+
+	notificationsInfo := make([]models.NotificationInfo, 0, len(appsNotificationsInfo))
+	for _, appNotificationInfo := range appsNotificationsInfo {
+		for _, description := range appsDescriptions[appNotificationInfo.ID] {
+			notificationInfo := appNotificationInfo
+			notificationInfo.Status = description
+			notificationsInfo = append(notificationsInfo, notificationInfo)
+		}
+	}
+
+	return an.sendNotificationsInfo(ctx, notificationsInfo)
+}
+
+// SendLogAlertsNotifications sends the triggered log alerts through the same channels as the status changes, the
+// alert is described in place of the status of the app.
+func (an *AppNotificationsService) SendLogAlertsNotifications(ctx context.Context,
+	triggeredLogAlerts []DTO.TriggeredLogAlert,
+) error {
+	appsDescriptions := make(map[string][]string, len(triggeredLogAlerts))
+	for _, triggeredLogAlert := range triggeredLogAlerts {
+		appsDescriptions[triggeredLogAlert.AppID] = append(appsDescriptions[triggeredLogAlert.AppID], fmt.Sprintf(
+			"log alert %s: %d lines matching %s in %s", triggeredLogAlert.Name, triggeredLogAlert.MatchedLines,
+			triggeredLogAlert.Pattern, time.Duration(triggeredLogAlert.WindowSeconds)*time.Second))
+	}
+
+	return an.sendAppsDescriptions(ctx, appsDescriptions)
+}
+
+// SendRemediationsFailedNotifications escalates the apps which are still down after the last attempt of their
+// remediation policy.
+func (an *AppNotificationsService) SendRemediationsFailedNotifications(ctx context.Context,
+	failedRemediations []DTO.FailedRemediation,
+) error {
+	appsDescriptions := make(map[string][]string, len(failedRemediations))
+	for _, failedRemediation := range failedRemediations {
+		appsDescriptions[failedRemediation.AppID] = append(appsDescriptions[failedRemediation.AppID], fmt.Sprintf(
+			"remediation failed after %d attempts: %s", failedRemediation.Attempts, failedRemediation.Status))
+	}
+
+	return an.sendAppsDescriptions(ctx, appsDescriptions)
+}
+
+// describeRouteFlowNotification tells which step of the flow failed and why, the response is shortened to its
+// beginning.
+func describeRouteFlowNotification(routeFlowNotification DTO.RouteFlowNotification) string {
+	if routeFlowNotification.Recovered {
+		return fmt.Sprintf("route flow %q recovered", routeFlowNotification.Name)
+	}
+
+	description := fmt.Sprintf("route flow %q failed %d times in a row", routeFlowNotification.Name,
+		routeFlowNotification.FailureStreak)
+	failedStep := routeFlowNotification.FailedStep
+	if failedStep == nil {
+		return description
+	}
+
+	description += fmt.Sprintf(" at step %d (%s %s): %s", failedStep.Position+1, failedStep.Request.Method,
+		failedStep.Request.URL, failedStep.Status)
+	if len(failedStep.FailedAssertions) > 0 {
+		failedAssertion := failedStep.FailedAssertions[0]
+		description += fmt.Sprintf("; assertion %s", failedAssertion.Source)
+		if failedAssertion.Selector != "" {
+			description += " " + failedAssertion.Selector
+		}
+		if failedAssertion.Operator != "" {
+			description += " " + failedAssertion.Operator
+		}
+		description += fmt.Sprintf(" %v, got %v (%s)", failedAssertion.Expected, failedAssertion.Actual,
+			failedAssertion.Message)
+	}
+	if failedStep.Error != "" {
+		description += "; error: " + failedStep.Error
+	}
+	if failedStep.ResponseStatusCode != 0 {
+		description += fmt.Sprintf("; response %d: %s", failedStep.ResponseStatusCode,
+			truncateNotificationSnippet(failedStep.ResponseBody))
+	}
+
+	return description
+}
+
+func truncateNotificationSnippet(snippet string) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+	if utf8.RuneCountInString(snippet) <= routeFlowNotificationSnippetLength {
+		return snippet
+	}
+	return string([]rune(snippet)[:routeFlowNotificationSnippetLength]) + "..."
+}
+
+// SendRouteFlowsNotifications sends the failures and the recoveries of the route flows through the channels of
+// their apps.
+func (an *AppNotificationsService) SendRouteFlowsNotifications(ctx context.Context,
+	routeFlowNotifications []DTO.RouteFlowNotification,
+) error {
+	appsDescriptions := make(map[string][]string, len(routeFlowNotifications))
+	for _, routeFlowNotification := range routeFlowNotifications {
+		appsDescriptions[routeFlowNotification.AppID] = append(appsDescriptions[routeFlowNotification.AppID],
+			describeRouteFlowNotification(routeFlowNotification))
+	}
+
+	return an.sendAppsDescriptions(ctx, appsDescriptions)
+}
@@ -240,3 +240,47 @@ func TestAppNotificationsService_SendNotifications(t *testing.T) {
 		})
 	}
 }
+
+func TestDescribeAppsStatuses(t *testing.T) {
+	type args struct {
+		name           string
+		appStatus      DTO.AppStatus
+		expectedStatus string
+	}
+	testsScenarios := []args{
+		{
+			name:           "Running app",
+			appStatus:      DTO.AppStatus{AppID: "32", Status: "running"},
+			expectedStatus: "running",
+		},
+		{
+			name: "Container killed by OOM",
+			appStatus: DTO.AppStatus{AppID: "32", Status: "exited", ExitCode: 137, OOMKilled: true,
+				RestartCount: 3},
+			expectedStatus: "exited (137, OOMKilled) after 3 restarts",
+		},
+		{
+			name:           "Container stopped cleanly",
+			appStatus:      DTO.AppStatus{AppID: "32", Status: "exited"},
+			expectedStatus: "exited (0)",
+		},
+		{
+			name: "Unhealthy container",
+			appStatus: DTO.AppStatus{AppID: "32", Status: "unhealthy", Health: "unhealthy",
+				HealthOutput: "curl: (7) Failed to connect", RestartCount: 1},
+			expectedStatus: "unhealthy: curl: (7) Failed to connect after 1 restart",
+		},
+		{
+			name:           "Status of another app",
+			appStatus:      DTO.AppStatus{AppID: "33", Status: "exited", ExitCode: 1},
+			expectedStatus: "running",
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			notificationsInfo := []models.NotificationInfo{{ID: "32", Status: "running"}}
+			describeAppsStatuses(notificationsInfo, []DTO.AppStatus{testScenario.appStatus})
+			assert.Equal(t, testScenario.expectedStatus, notificationsInfo[0].Status)
+		})
+	}
+}
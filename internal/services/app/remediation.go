@@ -0,0 +1,321 @@
+package servicesApp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+)
+
+const (
+	remediationHookTimeout = 30 * time.Second
+	// remediationMaxBackoffShift stops doubling the backoff at 1024 times the initial one.
+	remediationMaxBackoffShift = 10
+	// remediationScriptOutputMaxLength limits the output of a failed script kept in the history.
+	remediationScriptOutputMaxLength = 512
+)
+
+type RemediationService struct {
+	remediationRepository   interfaces.RemediationRepository
+	appRepository           interfaces.AppRepository
+	appNotificationsService interfaces.AppNotificationsService
+	dockerRestarter         interfaces.DockerRestarter
+	pm2Restarter            interfaces.Pm2Restarter
+	systemdRestarter        interfaces.SystemdRestarter
+	kubernetesRestarter     interfaces.KubernetesRestarter
+	loggerService           utils.LoggerService
+}
+
+func NewRemediationService(remediationRepository interfaces.RemediationRepository,
+	appRepository interfaces.AppRepository, appNotificationsService interfaces.AppNotificationsService,
+	dockerRestarter interfaces.DockerRestarter, pm2Restarter interfaces.Pm2Restarter,
+	systemdRestarter interfaces.SystemdRestarter, kubernetesRestarter interfaces.KubernetesRestarter,
+	loggerService utils.LoggerService,
+) *RemediationService {
+	return &RemediationService{
+		remediationRepository:   remediationRepository,
+		appRepository:           appRepository,
+		appNotificationsService: appNotificationsService,
+		dockerRestarter:         dockerRestarter,
+		pm2Restarter:            pm2Restarter,
+		systemdRestarter:        systemdRestarter,
+		kubernetesRestarter:     kubernetesRestarter,
+		loggerService:           loggerService,
+	}
+}
+
+func validateRemediationPolicy(app *models.App, policy DTO.UpsertRemediationPolicy) error {
+	if !policy.Restart && policy.WebhookURL == "" && policy.Script == "" {
+		return models.NewError(400, "Validation", "policy has to restart the app or call a hook")
+	}
+	if policy.Restart && app.Runtime == models.RuntimeTCP {
+		return models.NewError(400, "Validation", "apps added by hand cannot be restarted")
+	}
+	if policy.WebhookURL != "" {
+		webhookURL, err := url.ParseRequestURI(policy.WebhookURL)
+		if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+			return models.NewError(400, "Validation", "webhookUrl has to be a http or https url")
+		}
+	}
+	if policy.Script != "" && !filepath.IsAbs(policy.Script) {
+		return models.NewError(400, "Validation", "script has to be an absolute path")
+	}
+
+	return nil
+}
+
+func (rs *RemediationService) GetRemediationPolicy(ctx context.Context, appID string,
+	ownerID int,
+) (*DTO.RemediationPolicy, error) {
+	return rs.remediationRepository.GetRemediationPolicy(ctx, appID, ownerID)
+}
+
+func (rs *RemediationService) UpsertRemediationPolicy(ctx context.Context, appID string, ownerID int,
+	policy DTO.UpsertRemediationPolicy,
+) error {
+	app, err := rs.appRepository.GetApp(ctx, appID, ownerID)
+	if err != nil {
+		return err
+	}
+	if err := validateRemediationPolicy(app, policy); err != nil {
+		return err
+	}
+
+	return rs.remediationRepository.UpsertRemediationPolicy(ctx, app.ID, policy)
+}
+
+func (rs *RemediationService) DeleteRemediationPolicy(ctx context.Context, appID string, ownerID int) error {
+	return rs.remediationRepository.DeleteRemediationPolicy(ctx, appID, ownerID)
+}
+
+// StartMaintenance pauses the policy, so the app can be stopped without being restarted.
+func (rs *RemediationService) StartMaintenance(ctx context.Context, appID string, ownerID, minutes int) error {
+	maintenanceUntil := time.Now().UTC().Add(time.Duration(minutes) * time.Minute)
+	return rs.remediationRepository.UpdateRemediationMaintenance(ctx, appID, ownerID, &maintenanceUntil)
+}
+
+func (rs *RemediationService) EndMaintenance(ctx context.Context, appID string, ownerID int) error {
+	return rs.remediationRepository.UpdateRemediationMaintenance(ctx, appID, ownerID, nil)
+}
+
+func (rs *RemediationService) GetRemediationHistory(ctx context.Context, appID string,
+	ownerID int,
+) ([]DTO.RemediationAttempt, error) {
+	return rs.remediationRepository.GetRemediationHistory(ctx, appID, ownerID)
+}
+
+// needsRemediation tells a failure from a deliberate stop. A container stopped or killed through docker exits with
+// 143 or 137, so it is recognized by the stop recorded from the docker events, a container which exited with 0 was
+// stopped as well. Apps added by hand report only whether their port is open, so they are remediated when it is
+// closed.
+func needsRemediation(app models.AppToRemediate) bool {
+	switch app.Status {
+	case "dead", "unhealthy", "errored", "failed", "unavailable":
+		return true
+	case "exited":
+		return !app.StoppedByUser && (app.ExitCode != 0 || app.OOMKilled)
+	case "stopped":
+		return app.Runtime == models.RuntimeTCP
+	default:
+		return false
+	}
+}
+
+// nextRemediationAttemptAt doubles the backoff after every attempt, the first attempt is made right away.
+func nextRemediationAttemptAt(app models.AppToRemediate) time.Time {
+	if app.LastAttemptAt == nil || app.Attempts == 0 {
+		return time.Time{}
+	}
+
+	shift := min(app.Attempts-1, remediationMaxBackoffShift)
+	backoff := time.Duration(app.BackoffSeconds) * time.Second << shift
+
+	return app.LastAttemptAt.Add(backoff)
+}
+
+// EvaluateRemediations makes the next attempt for the failed apps whose backoff elapsed, resets the attempts of the
+// apps which recovered and escalates the apps which are still down after the last attempt.
+func (rs *RemediationService) EvaluateRemediations(ctx context.Context) error {
+	now := time.Now().UTC()
+	appsToRemediate, err := rs.remediationRepository.GetAppsToRemediate(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	failedRemediations := make([]DTO.FailedRemediation, 0)
+	for _, appToRemediate := range appsToRemediate {
+		if !needsRemediation(appToRemediate) {
+			if appToRemediate.Attempts == 0 && !appToRemediate.Escalated {
+				continue
+			}
+			err := rs.remediationRepository.UpdateRemediationState(ctx, appToRemediate.ID, 0, nil, false)
+			if err != nil {
+				rs.loggerService.Warn("failed to reset remediation attempts", err)
+			}
+			continue
+		}
+
+		if appToRemediate.Attempts >= appToRemediate.MaxAttempts {
+			if appToRemediate.Escalated {
+				continue
+			}
+			err := rs.remediationRepository.UpdateRemediationState(ctx, appToRemediate.ID, appToRemediate.Attempts,
+				appToRemediate.LastAttemptAt, true)
+			if err != nil {
+				rs.loggerService.Warn("failed to save remediation escalation", err)
+				continue
+			}
+			failedRemediations = append(failedRemediations, DTO.FailedRemediation{
+				AppID:    appToRemediate.ID,
+				Attempts: appToRemediate.Attempts,
+				Status:   describeRemediatedStatus(appToRemediate),
+			})
+			continue
+		}
+
+		if now.Before(nextRemediationAttemptAt(appToRemediate)) {
+			continue
+		}
+		if err := rs.remediate(ctx, appToRemediate, now); err != nil {
+			rs.loggerService.Warn("failed to save remediation attempt", map[string]any{
+				"appID": appToRemediate.ID,
+				"err":   err.Error(),
+			})
+		}
+	}
+
+	return rs.appNotificationsService.SendRemediationsFailedNotifications(ctx, failedRemediations)
+}
+
+func describeRemediatedStatus(app models.AppToRemediate) string {
+	appStatus := DTO.AppStatus{Status: app.Status, ExitCode: app.ExitCode, OOMKilled: app.OOMKilled}
+	return appStatus.Describe()
+}
+
+func (rs *RemediationService) remediate(ctx context.Context, app models.AppToRemediate, now time.Time) error {
+	attempt := app.Attempts + 1
+	status := describeRemediatedStatus(app)
+	// The attempt is counted before running the actions, so a hanging restart does not start another attempt.
+	err := rs.remediationRepository.UpdateRemediationState(ctx, app.ID, attempt, &now, false)
+	if err != nil {
+		return err
+	}
+
+	remediationAttempts := make([]DTO.RemediationAttempt, 0, 3)
+	record := func(action string, actionErr error) {
+		remediationAttempt := DTO.RemediationAttempt{
+			AppID:     app.ID,
+			Attempt:   attempt,
+			Action:    action,
+			Status:    status,
+			Success:   actionErr == nil,
+			CreatedAt: time.Now().UTC(),
+		}
+		if actionErr != nil {
+			remediationAttempt.Error = actionErr.Error()
+		}
+		remediationAttempts = append(remediationAttempts, remediationAttempt)
+	}
+
+	if app.Restart {
+		record(models.RemediationActionRestart, rs.restartApp(ctx, app))
+	}
+	if app.WebhookURL != "" {
+		record(models.RemediationActionWebhook, rs.callRemediationWebhook(ctx, app, attempt, status))
+	}
+	if app.Script != "" {
+		record(models.RemediationActionScript, runRemediationScript(ctx, app, attempt, status))
+	}
+	rs.loggerService.Info("remediation attempt made", map[string]any{
+		"appID":   app.ID,
+		"attempt": attempt,
+		"status":  status,
+	})
+
+	return rs.remediationRepository.InsertRemediationAttempts(ctx, remediationAttempts)
+}
+
+func (rs *RemediationService) restartApp(ctx context.Context, app models.AppToRemediate) error {
+	switch app.Runtime {
+	case models.RuntimeDocker:
+		return rs.dockerRestarter.RestartContainer(ctx, app.ID)
+	case models.RuntimePm2:
+		return rs.pm2Restarter.RestartApp(ctx, app.ID, app.OwnerID)
+	case models.RuntimeSystemd:
+		return rs.systemdRestarter.RestartUnit(ctx, app.ID, app.OwnerID)
+	case models.RuntimeKubernetes:
+		return rs.kubernetesRestarter.RestartWorkload(ctx, app.ID, app.OwnerID)
+	default:
+		return errors.New("apps added by hand cannot be restarted")
+	}
+}
+
+func (rs *RemediationService) callRemediationWebhook(ctx context.Context, app models.AppToRemediate, attempt int,
+	status string,
+) error {
+	body, err := utils.MarshalData(map[string]any{
+		"appID":   app.ID,
+		"name":    app.Name,
+		"status":  status,
+		"attempt": attempt,
+	})
+	if err != nil {
+		return err
+	}
+
+	httpResponse, err := request.SendHTTPRequest(ctx, request.HTTPRequest{
+		Method:  "POST",
+		URL:     app.WebhookURL,
+		Headers: map[string]string{"Content-Type": "application/json; charset=UTF-8"},
+		Body:    body,
+		Timeout: remediationHookTimeout,
+	})
+	if err != nil {
+		return err
+	}
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", httpResponse.StatusCode)
+	}
+
+	return nil
+}
+
+// runRemediationScript runs the script without a shell. The app is described in the OCTOPUS_* environment
+// variables.
+func runRemediationScript(ctx context.Context, app models.AppToRemediate, attempt int, status string) error {
+	hookCtx, cancel := context.WithTimeout(ctx, remediationHookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(hookCtx, app.Script)
+	cmd.Env = append(os.Environ(),
+		"OCTOPUS_APP_ID="+app.ID,
+		"OCTOPUS_APP_NAME="+app.Name,
+		"OCTOPUS_APP_STATUS="+status,
+		"OCTOPUS_REMEDIATION_ATTEMPT="+strconv.Itoa(attempt),
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		trimmedOutput := strings.TrimSpace(string(output))
+		if len(trimmedOutput) > remediationScriptOutputMaxLength {
+			trimmedOutput = strings.ToValidUTF8(trimmedOutput[:remediationScriptOutputMaxLength], "")
+		}
+		if trimmedOutput == "" {
+			return err
+		}
+		return fmt.Errorf("%w: %s", err, trimmedOutput)
+	}
+
+	return nil
+}
@@ -0,0 +1,350 @@
+package servicesApp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeRestarter struct {
+	restartedApps []string
+	err           error
+}
+
+func (fr *fakeRestarter) RestartContainer(ctx context.Context, appID string) error {
+	fr.restartedApps = append(fr.restartedApps, appID)
+	return fr.err
+}
+
+func (fr *fakeRestarter) RestartApp(ctx context.Context, appID string, ownerID int) error {
+	return fr.RestartContainer(ctx, appID)
+}
+
+func (fr *fakeRestarter) RestartUnit(ctx context.Context, appID string, ownerID int) error {
+	return fr.RestartContainer(ctx, appID)
+}
+
+func (fr *fakeRestarter) RestartWorkload(ctx context.Context, appID string, ownerID int) error {
+	return fr.RestartContainer(ctx, appID)
+}
+
+func newTestRemediationService(remediationRepository *mocks.MockRemediationRepository,
+	appRepository *mocks.MockAppRepository, notificationsService *fakeAppNotificationsService,
+	restarter *fakeRestarter,
+) *RemediationService {
+	return NewRemediationService(remediationRepository, appRepository, notificationsService, restarter, restarter,
+		restarter, restarter, tests.CreateLogger())
+}
+
+func TestNeedsRemediation(t *testing.T) {
+	type args struct {
+		name     string
+		app      models.AppToRemediate
+		expected bool
+	}
+	testsScenarios := []args{
+		{
+			name:     "Running container",
+			app:      models.AppToRemediate{Runtime: models.RuntimeDocker, Status: "running"},
+			expected: false,
+		},
+		{
+			name:     "Container stopped cleanly",
+			app:      models.AppToRemediate{Runtime: models.RuntimeDocker, Status: "exited"},
+			expected: false,
+		},
+		{
+			name:     "Crashed container",
+			app:      models.AppToRemediate{Runtime: models.RuntimeDocker, Status: "exited", ExitCode: 1},
+			expected: true,
+		},
+		{
+			name: "Container stopped through docker",
+			app: models.AppToRemediate{Runtime: models.RuntimeDocker, Status: "exited", ExitCode: 143,
+				StoppedByUser: true},
+			expected: false,
+		},
+		{
+			name:     "Container killed by OOM",
+			app:      models.AppToRemediate{Runtime: models.RuntimeDocker, Status: "exited", OOMKilled: true},
+			expected: true,
+		},
+		{
+			name:     "Unhealthy container",
+			app:      models.AppToRemediate{Runtime: models.RuntimeDocker, Status: "unhealthy"},
+			expected: true,
+		},
+		{
+			name:     "Stopped pm2 process",
+			app:      models.AppToRemediate{Runtime: models.RuntimePm2, Status: "stopped"},
+			expected: false,
+		},
+		{
+			name:     "Errored pm2 process",
+			app:      models.AppToRemediate{Runtime: models.RuntimePm2, Status: "errored"},
+			expected: true,
+		},
+		{
+			name:     "App added by hand with closed port",
+			app:      models.AppToRemediate{Runtime: models.RuntimeTCP, Status: "stopped"},
+			expected: true,
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			assert.Equal(t, testScenario.expected, needsRemediation(testScenario.app))
+		})
+	}
+}
+
+func TestNextRemediationAttemptAt(t *testing.T) {
+	lastAttemptAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	type args struct {
+		name     string
+		app      models.AppToRemediate
+		expected time.Time
+	}
+	testsScenarios := []args{
+		{
+			name:     "First attempt",
+			app:      models.AppToRemediate{BackoffSeconds: 10},
+			expected: time.Time{},
+		},
+		{
+			name:     "Second attempt",
+			app:      models.AppToRemediate{BackoffSeconds: 10, Attempts: 1, LastAttemptAt: &lastAttemptAt},
+			expected: lastAttemptAt.Add(10 * time.Second),
+		},
+		{
+			name:     "Fourth attempt",
+			app:      models.AppToRemediate{BackoffSeconds: 10, Attempts: 3, LastAttemptAt: &lastAttemptAt},
+			expected: lastAttemptAt.Add(40 * time.Second),
+		},
+		{
+			name:     "Backoff stops doubling",
+			app:      models.AppToRemediate{BackoffSeconds: 10, Attempts: 15, LastAttemptAt: &lastAttemptAt},
+			expected: lastAttemptAt.Add(10240 * time.Second),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			assert.Equal(t, testScenario.expected, nextRemediationAttemptAt(testScenario.app))
+		})
+	}
+}
+
+func TestRemediationService_UpsertRemediationPolicy(t *testing.T) {
+	type args struct {
+		name          string
+		runtime       string
+		policy        DTO.UpsertRemediationPolicy
+		expectedError error
+	}
+	testsScenarios := []args{
+		{
+			name:          "Policy without actions",
+			runtime:       models.RuntimeDocker,
+			policy:        DTO.UpsertRemediationPolicy{MaxAttempts: 3, BackoffSeconds: 10},
+			expectedError: errors.New("policy has to restart the app or call a hook"),
+		},
+		{
+			name:          "Restart of an app added by hand",
+			runtime:       models.RuntimeTCP,
+			policy:        DTO.UpsertRemediationPolicy{MaxAttempts: 3, BackoffSeconds: 10, Restart: true},
+			expectedError: errors.New("apps added by hand cannot be restarted"),
+		},
+		{
+			name:    "Invalid webhook url",
+			runtime: models.RuntimeTCP,
+			policy: DTO.UpsertRemediationPolicy{MaxAttempts: 3, BackoffSeconds: 10,
+				WebhookURL: "ftp://example.com/hook"},
+			expectedError: errors.New("webhookUrl has to be a http or https url"),
+		},
+		{
+			name:    "Relative script path",
+			runtime: models.RuntimeDocker,
+			policy: DTO.UpsertRemediationPolicy{MaxAttempts: 3, BackoffSeconds: 10,
+				Script: "hooks/clean.sh"},
+			expectedError: errors.New("script has to be an absolute path"),
+		},
+		{
+			name:    "Policy saved",
+			runtime: models.RuntimeDocker,
+			policy: DTO.UpsertRemediationPolicy{MaxAttempts: 3, BackoffSeconds: 10, Restart: true,
+				WebhookURL: "https://example.com/hook", Script: "/opt/hooks/clean.sh", Enabled: true},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			mApp := new(mocks.MockAppRepository)
+			mApp.On("GetApp", mock.Anything, "app", 1).Return(&models.App{ID: "app", Runtime: testScenario.runtime},
+				nil)
+			mRemediation := new(mocks.MockRemediationRepository)
+			mRemediation.On("UpsertRemediationPolicy", mock.Anything, "app", testScenario.policy).Return(nil)
+			remediationService := newTestRemediationService(mRemediation, mApp, &fakeAppNotificationsService{},
+				&fakeRestarter{})
+
+			err := remediationService.UpsertRemediationPolicy(context.Background(), "app", 1, testScenario.policy)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+				mRemediation.AssertCalled(t, "UpsertRemediationPolicy", mock.Anything, "app", testScenario.policy)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+				mRemediation.AssertNotCalled(t, "UpsertRemediationPolicy", mock.Anything, mock.Anything,
+					mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRemediationService_EvaluateRemediations(t *testing.T) {
+	justNow := time.Now().UTC()
+	type args struct {
+		name                       string
+		app                        models.AppToRemediate
+		restartErr                 error
+		expectedRestarts           []string
+		expectedState              []any
+		expectedActions            map[string]bool
+		expectedFailedRemediations []DTO.FailedRemediation
+	}
+	testsScenarios := []args{
+		{
+			name: "Restart of a crashed container",
+			app: models.AppToRemediate{ID: "app", Runtime: models.RuntimeDocker, Status: "exited", ExitCode: 137,
+				OOMKilled: true, MaxAttempts: 3, BackoffSeconds: 10, Restart: true},
+			expectedRestarts: []string{"app"},
+			expectedState:    []any{1, false},
+			expectedActions:  map[string]bool{models.RemediationActionRestart: true},
+		},
+		{
+			name: "Failed restart is recorded",
+			app: models.AppToRemediate{ID: "app", Runtime: models.RuntimePm2, Status: "errored", MaxAttempts: 3,
+				BackoffSeconds: 10, Restart: true, Attempts: 1,
+				LastAttemptAt: func() *time.Time { lastAttemptAt := justNow.Add(-time.Minute); return &lastAttemptAt }()},
+			restartErr:       errors.New("pm2 is not running"),
+			expectedRestarts: []string{"app"},
+			expectedState:    []any{2, false},
+			expectedActions:  map[string]bool{models.RemediationActionRestart: false},
+		},
+		{
+			name: "Backoff not elapsed",
+			app: models.AppToRemediate{ID: "app", Runtime: models.RuntimeDocker, Status: "dead", MaxAttempts: 3,
+				BackoffSeconds: 10, Restart: true, Attempts: 1, LastAttemptAt: &justNow},
+		},
+		{
+			name: "Attempts used up",
+			app: models.AppToRemediate{ID: "app", Runtime: models.RuntimeDocker, Status: "exited", ExitCode: 1,
+				MaxAttempts: 3, BackoffSeconds: 10, Restart: true, Attempts: 3, LastAttemptAt: &justNow},
+			expectedState: []any{3, true},
+			expectedFailedRemediations: []DTO.FailedRemediation{
+				{AppID: "app", Attempts: 3, Status: "exited (1)"},
+			},
+		},
+		{
+			name: "Already escalated",
+			app: models.AppToRemediate{ID: "app", Runtime: models.RuntimeDocker, Status: "exited", ExitCode: 1,
+				MaxAttempts: 3, BackoffSeconds: 10, Restart: true, Attempts: 3, LastAttemptAt: &justNow,
+				Escalated: true},
+		},
+		{
+			name: "Recovered app",
+			app: models.AppToRemediate{ID: "app", Runtime: models.RuntimeDocker, Status: "running", MaxAttempts: 3,
+				BackoffSeconds: 10, Restart: true, Attempts: 2, LastAttemptAt: &justNow},
+			expectedState: []any{0, false},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			mRemediation := new(mocks.MockRemediationRepository)
+			mRemediation.On("GetAppsToRemediate", mock.Anything, mock.Anything).Return(
+				[]models.AppToRemediate{testScenario.app}, nil)
+			mRemediation.On("UpdateRemediationState", mock.Anything, "app", mock.Anything, mock.Anything,
+				mock.Anything).Return(nil)
+			mRemediation.On("InsertRemediationAttempts", mock.Anything, mock.Anything).Return(nil)
+			restarter := &fakeRestarter{err: testScenario.restartErr}
+			notificationsService := &fakeAppNotificationsService{}
+			remediationService := newTestRemediationService(mRemediation, new(mocks.MockAppRepository),
+				notificationsService, restarter)
+
+			err := remediationService.EvaluateRemediations(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, testScenario.expectedRestarts, restarter.restartedApps)
+			assert.Equal(t, testScenario.expectedFailedRemediations, notificationsService.failedRemediations)
+
+			if testScenario.expectedState == nil {
+				mRemediation.AssertNotCalled(t, "UpdateRemediationState", mock.Anything, mock.Anything,
+					mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				mRemediation.AssertCalled(t, "UpdateRemediationState", mock.Anything, "app",
+					testScenario.expectedState[0], mock.Anything, testScenario.expectedState[1])
+			}
+
+			if testScenario.expectedActions == nil {
+				mRemediation.AssertNotCalled(t, "InsertRemediationAttempts", mock.Anything, mock.Anything)
+				return
+			}
+			remediationAttempts := mRemediation.Calls[len(mRemediation.Calls)-1].Arguments.Get(1).([]DTO.RemediationAttempt)
+			assert.Len(t, remediationAttempts, len(testScenario.expectedActions))
+			for _, remediationAttempt := range remediationAttempts {
+				assert.Equal(t, testScenario.expectedActions[remediationAttempt.Action], remediationAttempt.Success)
+				assert.Equal(t, testScenario.app.Attempts+1, remediationAttempt.Attempt)
+			}
+		})
+	}
+}
+
+func TestRemediationService_EvaluateRemediationsHooks(t *testing.T) {
+	webhookCalls := make(chan map[string]any, 1)
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		webhookCalls <- payload
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhookServer.Close()
+
+	outputPath := filepath.Join(t.TempDir(), "hook-output")
+	scriptPath := filepath.Join(t.TempDir(), "hook.sh")
+	script := "#!/bin/sh\necho \"$OCTOPUS_APP_ID $OCTOPUS_APP_STATUS $OCTOPUS_REMEDIATION_ATTEMPT\" > " + outputPath + "\n"
+	assert.NoError(t, os.WriteFile(scriptPath, []byte(script), 0o700))
+
+	app := models.AppToRemediate{ID: "app", Name: "api", Runtime: models.RuntimeTCP, Status: "stopped",
+		MaxAttempts: 3, BackoffSeconds: 10, WebhookURL: webhookServer.URL, Script: scriptPath}
+	mRemediation := new(mocks.MockRemediationRepository)
+	mRemediation.On("GetAppsToRemediate", mock.Anything, mock.Anything).Return([]models.AppToRemediate{app}, nil)
+	mRemediation.On("UpdateRemediationState", mock.Anything, "app", 1, mock.Anything, false).Return(nil)
+	mRemediation.On("InsertRemediationAttempts", mock.Anything, mock.MatchedBy(
+		func(remediationAttempts []DTO.RemediationAttempt) bool {
+			return len(remediationAttempts) == 2 && remediationAttempts[0].Success && remediationAttempts[1].Success
+		})).Return(nil)
+	remediationService := newTestRemediationService(mRemediation, new(mocks.MockAppRepository),
+		&fakeAppNotificationsService{}, &fakeRestarter{})
+
+	err := remediationService.EvaluateRemediations(context.Background())
+	assert.NoError(t, err)
+	mRemediation.AssertExpectations(t)
+
+	payload := <-webhookCalls
+	assert.Equal(t, "app", payload["appID"])
+	assert.Equal(t, "stopped", payload["status"])
+	assert.Equal(t, float64(1), payload["attempt"])
+
+	output, err := os.ReadFile(outputPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "app stopped 1\n", string(output))
+}
@@ -3,34 +3,34 @@ package servicesApp
 import (
 	"context"
 	"runtime"
+	"slices"
 	"sync"
 
 	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
 
 	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
 	"github.com/slodkiadrianek/octopus/internal/utils"
 )
 
 type RouteService struct {
 	logger          utils.LoggerService
 	routeRepository interfaces.RouteRepository
+	appRepository   interfaces.AppRepository
+	encryptionKey   string
 }
 
-func NewRouteService(logger utils.LoggerService, routeRepository interfaces.RouteRepository) *RouteService {
+func NewRouteService(logger utils.LoggerService, routeRepository interfaces.RouteRepository,
+	appRepository interfaces.AppRepository, encryptionKey string,
+) *RouteService {
 	return &RouteService{
 		logger:          logger,
 		routeRepository: routeRepository,
+		appRepository:   appRepository,
+		encryptionKey:   encryptionKey,
 	}
 }
 
-func (rs *RouteService) CheckRouteStatus(ctx context.Context, routeID int) (string, error) {
-	routeStatus, err := rs.routeRepository.CheckRouteStatus(ctx, routeID)
-	if err != nil {
-		return "", err
-	}
-	return routeStatus, nil
-}
-
 func (rs *RouteService) prepareDataAboutRouteToInsertToDb(routes *[]DTO.CreateRoute) ([]*DTO.NextRoute, []*DTO.RouteRequest, []*DTO.RouteResponse, []*DTO.RouteInfo, error) {
 	nextRoutesChan := make(chan DTO.NextRoute, len(*routes))
 	requestRoutesChan := make(chan DTO.RouteRequest, len(*routes))
@@ -187,39 +187,85 @@ func (rs *RouteService) saveRouteComponents(ctx context.Context, nextRoutes []*D
 	return routesInfoIDs, routesRequestsIDs, routesResponsesIDs, nextRoutesDataIDs, nil
 }
 
-func (rs *RouteService) saveWorkingRoutes(ctx context.Context, routes *[]DTO.CreateRoute, appID, name string,
-	nextRoutesDataIDs,
-	routesRequestsIDs, routesResponsesIDs, routesInfoIDs []int,
-) error {
+// prepareWorkingRoutes links the steps with their saved components, the parents are set when the steps are saved.
+func (rs *RouteService) prepareWorkingRoutes(routes *[]DTO.CreateRoute, appID, name string, flowID, version int,
+	nextRoutesDataIDs, routesRequestsIDs, routesResponsesIDs, routesInfoIDs []int,
+) ([]DTO.WorkingRoute, error) {
 	workingRoutes := make([]DTO.WorkingRoute, len(*routes))
 	for i, val := range *routes {
-		workingRoutes[i] = DTO.WorkingRoute{ParentID: val.ParentID, AppID: appID, Name: name}
+		assertions := val.Assertions
+		if assertions == nil {
+			assertions = []models.RouteAssertion{}
+		}
+		assertionsBytes, err := utils.MarshalData(assertions)
+		if err != nil {
+			return nil, err
+		}
+		responseSchemaBytes, err := utils.MarshalData(val.ResponseSchema)
+		if err != nil {
+			return nil, err
+		}
+		requestHeaders := val.RequestHeaders
+		if requestHeaders == nil {
+			requestHeaders = map[string]string{}
+		}
+		requestHeadersBytes, err := utils.MarshalData(requestHeaders)
+		if err != nil {
+			return nil, err
+		}
+		extract := val.Extract
+		if extract == nil {
+			extract = []models.RouteVariable{}
+		}
+		extractBytes, err := utils.MarshalData(extract)
+		if err != nil {
+			return nil, err
+		}
+		authBytes, err := utils.MarshalData(val.Auth)
+		if err != nil {
+			return nil, err
+		}
+		bodyType := val.BodyType
+		if bodyType == "" {
+			bodyType = models.RouteBodyTypeJSON
+		}
+
+		workingRoutes[i] = DTO.WorkingRoute{
+			ParentID:       val.ParentID,
+			AppID:          appID,
+			Name:           name,
+			FlowID:         flowID,
+			Version:        version,
+			Position:       i,
+			Assertions:     string(assertionsBytes),
+			ResponseSchema: string(responseSchemaBytes),
+			MaxLatencyMs:   val.MaxLatencyMs,
+			RequestHeaders: string(requestHeadersBytes),
+			Extract:        string(extractBytes),
+			Auth:           string(authBytes),
+			BodyType:       bodyType,
+			RawBody:        val.RawBody,
+		}
 	}
-	parentID := 0
 
 	for i := 0; i < len(workingRoutes); i++ {
 		workingRoutes[i].NextRouteDataID = nextRoutesDataIDs[i]
 		workingRoutes[i].RequestID = routesRequestsIDs[i]
 		workingRoutes[i].ResponseID = routesResponsesIDs[i]
 		workingRoutes[i].RouteID = routesInfoIDs[i]
-		workingRoutes[i].ParentID = parentID
 		workingRoutes[i].Status = "unknown"
-		res, err := rs.routeRepository.InsertWorkingRoute(ctx, workingRoutes[i])
-		if err != nil {
-			return err
-		}
-		parentID = res
 	}
 
-	return nil
+	return workingRoutes, nil
 }
 
-func (rs *RouteService) AddWorkingRoutes(ctx context.Context, routes *[]DTO.CreateRoute, appID,
-	name string,
-) error {
+// prepareRouteFlowVersion saves the components of the steps and returns the steps of the given version.
+func (rs *RouteService) prepareRouteFlowVersion(ctx context.Context, routes *[]DTO.CreateRoute, appID, name string,
+	flowID, version int,
+) ([]DTO.WorkingRoute, error) {
 	nextRoutes, requestRoutes, responseRoutes, routesInfo, err := rs.prepareDataAboutRouteToInsertToDb(routes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	nextRoutes = utils.InsertionSortForRoutes(nextRoutes)
@@ -230,14 +276,251 @@ func (rs *RouteService) AddWorkingRoutes(ctx context.Context, routes *[]DTO.Crea
 	routesInfoIDs, routesRequestsIDs, routesResponsesIDs, nextRoutesDataIDs, err := rs.saveRouteComponents(ctx, nextRoutes,
 		requestRoutes, responseRoutes, routesInfo)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = rs.saveWorkingRoutes(ctx, routes, appID, name, nextRoutesDataIDs, routesRequestsIDs,
+	return rs.prepareWorkingRoutes(routes, appID, name, flowID, version, nextRoutesDataIDs, routesRequestsIDs,
 		routesResponsesIDs, routesInfoIDs)
+}
+
+// saveRouteFlowVersion saves the steps of the flow under the given version.
+func (rs *RouteService) saveRouteFlowVersion(ctx context.Context, routes *[]DTO.CreateRoute, appID, name string,
+	flowID, version int,
+) error {
+	workingRoutes, err := rs.prepareRouteFlowVersion(ctx, routes, appID, name, flowID, version)
 	if err != nil {
 		return err
 	}
 
+	parentID := 0
+	for i := range workingRoutes {
+		workingRoutes[i].ParentID = parentID
+		parentID, err = rs.routeRepository.InsertWorkingRoute(ctx, workingRoutes[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rs *RouteService) encodeRouteFlowEnv(env map[string]string) (string, error) {
+	if env == nil {
+		env = map[string]string{}
+	}
+	envBytes, err := utils.MarshalData(env)
+	if err != nil {
+		return "", err
+	}
+	return string(envBytes), nil
+}
+
+func (rs *RouteService) encodeRouteFlowHTTPOptions(httpOptions models.RouteFlowHTTPOptions) (string, error) {
+	httpOptionsBytes, err := utils.MarshalData(httpOptions)
+	if err != nil {
+		return "", err
+	}
+	return string(httpOptionsBytes), nil
+}
+
+func (rs *RouteService) encodeRouteFlowNotifications(notifications models.RouteFlowNotifications) (string, error) {
+	notificationsBytes, err := utils.MarshalData(notifications)
+	if err != nil {
+		return "", err
+	}
+	return string(notificationsBytes), nil
+}
+
+// encryptRouteFlowSecrets returns nil for the secrets which were left out, so the saved ones are kept.
+func (rs *RouteService) encryptRouteFlowSecrets(secrets map[string]string) (*string, error) {
+	if secrets == nil {
+		return nil, nil
+	}
+	encryptedSecrets := ""
+	if len(secrets) > 0 {
+		secretsBytes, err := utils.MarshalData(secrets)
+		if err != nil {
+			return nil, err
+		}
+		encryptedSecrets, err = utils.Encrypt(string(secretsBytes), rs.encryptionKey)
+		if err != nil {
+			rs.logger.Error("failed to encrypt route flow secrets", err)
+			return nil, models.NewError(500, "Encryption", "failed to encrypt route flow secrets")
+		}
+	}
+	return &encryptedSecrets, nil
+}
+
+// describeRouteFlowSecrets replaces the encrypted secrets of the flow with their names.
+func (rs *RouteService) describeRouteFlowSecrets(routeFlow *DTO.RouteFlow) error {
+	secrets, err := decryptRouteFlowSecrets(routeFlow.EncryptedSecrets, rs.encryptionKey)
+	if err != nil {
+		rs.logger.Error("failed to decrypt route flow secrets", err)
+		return models.NewError(500, "Encryption", "failed to decrypt route flow secrets")
+	}
+
+	routeFlow.SecretNames = make([]string, 0, len(secrets))
+	for name := range secrets {
+		routeFlow.SecretNames = append(routeFlow.SecretNames, name)
+	}
+	slices.Sort(routeFlow.SecretNames)
+	routeFlow.EncryptedSecrets = ""
+
 	return nil
 }
+
+// AddWorkingRoutes creates the flow with its steps saved as the first version.
+func (rs *RouteService) AddWorkingRoutes(ctx context.Context, routeFlow DTO.CreateRouteData, appID string,
+	ownerID int,
+) (int, error) {
+	app, err := rs.appRepository.GetApp(ctx, appID, ownerID)
+	if err != nil {
+		return 0, err
+	}
+
+	env, err := rs.encodeRouteFlowEnv(routeFlow.Env)
+	if err != nil {
+		return 0, err
+	}
+	secrets, err := rs.encryptRouteFlowSecrets(routeFlow.Secrets)
+	if err != nil {
+		return 0, err
+	}
+	encryptedSecrets := ""
+	if secrets != nil {
+		encryptedSecrets = *secrets
+	}
+	httpOptions, err := rs.encodeRouteFlowHTTPOptions(routeFlow.HTTPOptions)
+	if err != nil {
+		return 0, err
+	}
+	notifications, err := rs.encodeRouteFlowNotifications(routeFlow.Notifications)
+	if err != nil {
+		return 0, err
+	}
+
+	flowID, err := rs.routeRepository.InsertRouteFlow(ctx, app.ID, routeFlow.Name, env, encryptedSecrets,
+		httpOptions, notifications)
+	if err != nil {
+		return 0, err
+	}
+
+	err = rs.saveRouteFlowVersion(ctx, &routeFlow.Routes, app.ID, routeFlow.Name, flowID, 1)
+	if err != nil {
+		// A flow without steps cannot be checked, so it is removed to let the user create it again.
+		if deleteErr := rs.routeRepository.DeleteRouteFlow(ctx, flowID, app.ID, ownerID); deleteErr != nil {
+			rs.logger.Warn("failed to remove route flow without steps", deleteErr)
+		}
+		return 0, err
+	}
+
+	return flowID, nil
+}
+
+// UpdateRouteFlow saves the steps as the next version of the flow, together with the flow in one transaction. The
+// steps of the previous versions are kept, so the results of the earlier checks stay tied to the definition they ran
+// against.
+func (rs *RouteService) UpdateRouteFlow(ctx context.Context, routeFlow DTO.CreateRouteData, appID string, ownerID,
+	flowID int,
+) (int, error) {
+	savedRouteFlow, err := rs.routeRepository.GetRouteFlow(ctx, flowID, appID, ownerID, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	env, err := rs.encodeRouteFlowEnv(routeFlow.Env)
+	if err != nil {
+		return 0, err
+	}
+	secrets, err := rs.encryptRouteFlowSecrets(routeFlow.Secrets)
+	if err != nil {
+		return 0, err
+	}
+	httpOptions, err := rs.encodeRouteFlowHTTPOptions(routeFlow.HTTPOptions)
+	if err != nil {
+		return 0, err
+	}
+	notifications, err := rs.encodeRouteFlowNotifications(routeFlow.Notifications)
+	if err != nil {
+		return 0, err
+	}
+
+	version := savedRouteFlow.Version + 1
+	workingRoutes, err := rs.prepareRouteFlowVersion(ctx, &routeFlow.Routes, savedRouteFlow.AppID, routeFlow.Name,
+		flowID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	err = rs.routeRepository.UpdateRouteFlow(ctx, flowID, savedRouteFlow.AppID, ownerID, routeFlow.Name, version,
+		env, secrets, httpOptions, notifications, workingRoutes)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (rs *RouteService) GetRouteFlows(ctx context.Context, appID string, ownerID int) ([]DTO.RouteFlow, error) {
+	routeFlows, err := rs.routeRepository.GetRouteFlows(ctx, appID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range routeFlows {
+		if err := rs.describeRouteFlowSecrets(&routeFlows[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return routeFlows, nil
+}
+
+func (rs *RouteService) GetRouteFlow(ctx context.Context, appID string, ownerID, flowID,
+	version int,
+) (*DTO.RouteFlow, error) {
+	routeFlow, err := rs.routeRepository.GetRouteFlow(ctx, flowID, appID, ownerID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rs.describeRouteFlowSecrets(routeFlow); err != nil {
+		return nil, err
+	}
+
+	return routeFlow, nil
+}
+
+func (rs *RouteService) DeleteRouteFlow(ctx context.Context, appID string, ownerID, flowID int) error {
+	return rs.routeRepository.DeleteRouteFlow(ctx, flowID, appID, ownerID)
+}
+
+// GetRouteFlowRuns returns the latest runs of the flow, a flow which does not belong to the user is not found.
+func (rs *RouteService) GetRouteFlowRuns(ctx context.Context, appID string, ownerID,
+	flowID int,
+) ([]DTO.RouteFlowRun, error) {
+	if _, err := rs.routeRepository.GetRouteFlow(ctx, flowID, appID, ownerID, 0); err != nil {
+		return nil, err
+	}
+
+	return rs.routeRepository.GetRouteFlowRuns(ctx, flowID, appID, ownerID)
+}
+
+// GetRouteFlowRun returns a single run of the flow, it is used to read the result of an async run.
+func (rs *RouteService) GetRouteFlowRun(ctx context.Context, appID string, ownerID, flowID,
+	runID int,
+) (*DTO.RouteFlowRun, error) {
+	return rs.routeRepository.GetRouteFlowRun(ctx, runID, flowID, appID, ownerID)
+}
+
+// PreviewRouteFlowsImport generates the flows from the OpenAPI document or the collection. Nothing is saved, the
+// selected flows are added one by one like the flows created by hand.
+func (rs *RouteService) PreviewRouteFlowsImport(ctx context.Context, appID string, ownerID int,
+	importData DTO.ImportRouteFlows,
+) ([]DTO.RouteFlowImport, error) {
+	if _, err := rs.appRepository.GetApp(ctx, appID, ownerID); err != nil {
+		return nil, err
+	}
+
+	return parseRouteFlowsImport(importData.Format, importData.Document)
+}
@@ -0,0 +1,338 @@
+package servicesApp
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/internal/utils/validation"
+)
+
+// routeAssertionValueMaxLength limits the actual values kept in the failed assertions.
+const routeAssertionValueMaxLength = 256
+
+// routeResponse is the response of the step which the assertions are checked against.
+type routeResponse struct {
+	statusCode int
+	header     http.Header
+	body       any
+	latency    time.Duration
+}
+
+// parseSelector splits a gjson-style selector like "data.items.0.id" or a JSONPath like "$.data.items[0].id" into
+// the keys. A dot which is a part of the key is escaped with a backslash.
+func parseSelector(selector string) []string {
+	selector = strings.TrimPrefix(selector, "$")
+	selector = strings.TrimPrefix(selector, ".")
+	if selector == "" {
+		return nil
+	}
+
+	keys := make([]string, 0)
+	var key strings.Builder
+	for i := 0; i < len(selector); i++ {
+		switch selector[i] {
+		case '\\':
+			if i+1 < len(selector) {
+				i++
+				key.WriteByte(selector[i])
+			}
+		case '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		case '[':
+			end := strings.IndexByte(selector[i:], ']')
+			if end == -1 {
+				key.WriteString(selector[i:])
+				i = len(selector)
+				continue
+			}
+			if key.Len() > 0 {
+				keys = append(keys, key.String())
+				key.Reset()
+			}
+			keys = append(keys, strings.Trim(selector[i+1:i+end], `'"`))
+			i += end
+			if i+1 < len(selector) && selector[i+1] == '.' {
+				i++
+			}
+		default:
+			key.WriteByte(selector[i])
+		}
+	}
+	if key.Len() > 0 {
+		keys = append(keys, key.String())
+	}
+
+	return keys
+}
+
+// selectJSONValue returns the value chosen by the selector, "#" returns the length of an array.
+func selectJSONValue(value any, selector string) (any, bool) {
+	for _, key := range parseSelector(selector) {
+		switch current := value.(type) {
+		case map[string]any:
+			next, ok := current[key]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			if key == "#" {
+				value = float64(len(current))
+				continue
+			}
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, false
+			}
+			value = current[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// hasJSONType tells whether the value is of the JSON type, an integer is a number as well.
+func hasJSONType(value any, expectedType string) bool {
+	actualType := validation.JSONType(value)
+	return actualType == expectedType || (expectedType == "number" && actualType == "integer")
+}
+
+// matchesJSONValue compares the values, an expected object matches when the actual one has all of its fields, so
+// only the fields worth checking have to be written down.
+func matchesJSONValue(expected, actual any) bool {
+	switch expectedValue := expected.(type) {
+	case map[string]any:
+		actualValue, ok := actual.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, val := range expectedValue {
+			actualField, ok := actualValue[key]
+			if !ok || !matchesJSONValue(val, actualField) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		actualValue, ok := actual.([]any)
+		if !ok || len(actualValue) != len(expectedValue) {
+			return false
+		}
+		for i := range expectedValue {
+			if !matchesJSONValue(expectedValue[i], actualValue[i]) {
+				return false
+			}
+		}
+		return true
+	case int:
+		return matchesJSONValue(float64(expectedValue), actual)
+	default:
+		return reflect.DeepEqual(expected, actual)
+	}
+}
+
+func stringifyJSONValue(value any) string {
+	if stringValue, ok := value.(string); ok {
+		return stringValue
+	}
+	valueBytes, err := utils.MarshalData(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(valueBytes)
+}
+
+// reportedJSONValue shortens the long actual values, so the failed assertions fit into the status of the step.
+func reportedJSONValue(value any) any {
+	stringValue := stringifyJSONValue(value)
+	if len(stringValue) <= routeAssertionValueMaxLength {
+		return value
+	}
+	return strings.ToValidUTF8(stringValue[:routeAssertionValueMaxLength], "") + "..."
+}
+
+func toFloat(value any) (float64, bool) {
+	switch typedValue := value.(type) {
+	case float64:
+		return typedValue, true
+	case string:
+		floatValue, err := strconv.ParseFloat(strings.TrimSpace(typedValue), 64)
+		return floatValue, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// checkRouteAssertion returns the reason of the failure, an empty one means the assertion passed.
+func checkRouteAssertion(assertion models.RouteAssertion, actual any, found bool) string {
+	switch assertion.Operator {
+	case models.RouteAssertionOperatorExists:
+		if !found {
+			return "value does not exist"
+		}
+	case models.RouteAssertionOperatorNotExists:
+		if found {
+			return "value exists"
+		}
+	case models.RouteAssertionOperatorEq:
+		if !found {
+			return "value does not exist"
+		}
+		if !matchesJSONValue(assertion.Value, actual) {
+			return "value is not equal to the expected one"
+		}
+	case models.RouteAssertionOperatorRegex:
+		if !found {
+			return "value does not exist"
+		}
+		pattern, _ := assertion.Value.(string)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "pattern is not a valid regular expression"
+		}
+		if !re.MatchString(stringifyJSONValue(actual)) {
+			return "value does not match the pattern"
+		}
+	case models.RouteAssertionOperatorType:
+		if !found {
+			return "value does not exist"
+		}
+		expectedType, _ := assertion.Value.(string)
+		if !hasJSONType(actual, expectedType) {
+			return fmt.Sprintf("value is %s", validation.JSONType(actual))
+		}
+	case models.RouteAssertionOperatorRange:
+		if !found {
+			return "value does not exist"
+		}
+		number, ok := toFloat(actual)
+		if !ok {
+			return "value is not a number"
+		}
+		if assertion.Min != nil && number < *assertion.Min {
+			return "value is lower than the minimum"
+		}
+		if assertion.Max != nil && number > *assertion.Max {
+			return "value is greater than the maximum"
+		}
+	default:
+		return "unknown operator"
+	}
+
+	return ""
+}
+
+func expectedAssertionValue(assertion models.RouteAssertion) any {
+	if assertion.Operator != models.RouteAssertionOperatorRange {
+		return assertion.Value
+	}
+
+	expectedRange := make(map[string]float64, 2)
+	if assertion.Min != nil {
+		expectedRange["min"] = *assertion.Min
+	}
+	if assertion.Max != nil {
+		expectedRange["max"] = *assertion.Max
+	}
+	return expectedRange
+}
+
+// evaluateRouteAssertions checks the response against every assertion of the step, each failure is reported on its
+// own.
+func evaluateRouteAssertions(route models.RouteToTest, response routeResponse) []models.FailedAssertion {
+	failedAssertions := make([]models.FailedAssertion, 0)
+
+	if response.statusCode != route.ResponseStatusCode {
+		failedAssertions = append(failedAssertions, models.FailedAssertion{
+			Source:   "status",
+			Operator: models.RouteAssertionOperatorEq,
+			Expected: route.ResponseStatusCode,
+			Actual:   response.statusCode,
+			Message:  "status code is different",
+		})
+	}
+
+	if len(route.ResponseBody) > 0 {
+		bodyObject, _ := response.body.(map[string]any)
+		keys := make([]string, 0, len(route.ResponseBody))
+		for key := range route.ResponseBody {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			if _, ok := bodyObject[key]; !ok {
+				failedAssertions = append(failedAssertions, models.FailedAssertion{
+					Source:   models.RouteAssertionSourceBody,
+					Selector: key,
+					Operator: models.RouteAssertionOperatorExists,
+					Message:  "field of the response body is missing",
+				})
+			}
+		}
+	}
+
+	for _, assertion := range route.Assertions {
+		var actual any
+		var found bool
+		if assertion.Source == models.RouteAssertionSourceHeader {
+			headerValues := response.header.Values(assertion.Selector)
+			actual, found = strings.Join(headerValues, ", "), len(headerValues) > 0
+		} else {
+			actual, found = selectJSONValue(response.body, assertion.Selector)
+		}
+
+		message := checkRouteAssertion(assertion, actual, found)
+		if message == "" {
+			continue
+		}
+		source := assertion.Source
+		if source == "" {
+			source = models.RouteAssertionSourceBody
+		}
+		failedAssertion := models.FailedAssertion{
+			Source:   source,
+			Selector: assertion.Selector,
+			Operator: assertion.Operator,
+			Expected: expectedAssertionValue(assertion),
+			Message:  message,
+		}
+		if found {
+			failedAssertion.Actual = reportedJSONValue(actual)
+		}
+		failedAssertions = append(failedAssertions, failedAssertion)
+	}
+
+	if len(route.ResponseSchema) > 0 {
+		for _, schemaError := range validation.ValidateJSONSchema(route.ResponseSchema, response.body, "$") {
+			failedAssertions = append(failedAssertions, models.FailedAssertion{
+				Source:   "schema",
+				Selector: schemaError.Path,
+				Message:  schemaError.Message,
+			})
+		}
+	}
+
+	latencyMs := response.latency.Milliseconds()
+	if route.MaxLatencyMs > 0 && latencyMs > int64(route.MaxLatencyMs) {
+		failedAssertions = append(failedAssertions, models.FailedAssertion{
+			Source:   "latency",
+			Operator: models.RouteAssertionOperatorRange,
+			Expected: route.MaxLatencyMs,
+			Actual:   latencyMs,
+			Message:  "response took longer than the maximum latency",
+		})
+	}
+
+	return failedAssertions
+}
@@ -0,0 +1,124 @@
+package servicesApp
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectJSONValue(t *testing.T) {
+	type args struct {
+		name          string
+		selector      string
+		expectedValue any
+		expectedFound bool
+	}
+
+	var body any
+	err := json.Unmarshal([]byte(`{"data": {"items": [{"id": 7}, {"id": 8}], "a.b": "dotted"}}`), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testsScenarios := []args{
+		{name: "gjson-style path", selector: "data.items.1.id", expectedValue: float64(8), expectedFound: true},
+		{name: "JSONPath", selector: "$.data.items[0].id", expectedValue: float64(7), expectedFound: true},
+		{name: "Length of an array", selector: "data.items.#", expectedValue: float64(2), expectedFound: true},
+		{name: "Escaped dot", selector: `data.a\.b`, expectedValue: "dotted", expectedFound: true},
+		{name: "Bracket key", selector: `$.data['a.b']`, expectedValue: "dotted", expectedFound: true},
+		{name: "Whole body", selector: "$", expectedValue: body, expectedFound: true},
+		{name: "Missing field", selector: "data.missing", expectedValue: nil, expectedFound: false},
+		{name: "Index out of range", selector: "data.items.2", expectedValue: nil, expectedFound: false},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			value, found := selectJSONValue(body, testScenario.selector)
+			assert.Equal(t, testScenario.expectedValue, value)
+			assert.Equal(t, testScenario.expectedFound, found)
+		})
+	}
+}
+
+func TestEvaluateRouteAssertions(t *testing.T) {
+	type args struct {
+		name                     string
+		route                    models.RouteToTest
+		expectedFailedAssertions []models.FailedAssertion
+	}
+
+	var body any
+	err := json.Unmarshal([]byte(`{"id": 3, "user": {"name": "adrian", "role": "admin"}, "tags": ["a", "b"]}`), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := routeResponse{
+		statusCode: 200,
+		header:     http.Header{"Content-Type": []string{"application/json"}},
+		body:       body,
+		latency:    120 * time.Millisecond,
+	}
+
+	testsScenarios := []args{
+		{
+			name: "Every assertion passes",
+			route: models.RouteToTest{
+				ResponseStatusCode: 200,
+				ResponseBody:       models.JSONMapStringAny{"id": 1, "user": map[string]any{}},
+				Assertions: models.RouteAssertions{
+					{Selector: "user", Operator: models.RouteAssertionOperatorEq, Value: map[string]any{"name": "adrian"}},
+					{Selector: "user.name", Operator: models.RouteAssertionOperatorRegex, Value: "^adr"},
+					{Selector: "id", Operator: models.RouteAssertionOperatorType, Value: "number"},
+					{Selector: "tags.#", Operator: models.RouteAssertionOperatorRange, Min: tests.Ptr(1.0),
+						Max: tests.Ptr(2.0)},
+					{Selector: "deletedAt", Operator: models.RouteAssertionOperatorNotExists},
+					{Source: models.RouteAssertionSourceHeader, Selector: "content-type",
+						Operator: models.RouteAssertionOperatorEq, Value: "application/json"},
+				},
+				ResponseSchema: models.JSONMapStringAny{"type": "object", "required": []any{"id"}},
+				MaxLatencyMs:   500,
+			},
+			expectedFailedAssertions: []models.FailedAssertion{},
+		},
+		{
+			name: "Every failed assertion is reported",
+			route: models.RouteToTest{
+				ResponseStatusCode: 201,
+				ResponseBody:       models.JSONMapStringAny{"id": 1, "createdAt": "now"},
+				Assertions: models.RouteAssertions{
+					{Selector: "user.role", Operator: models.RouteAssertionOperatorEq, Value: "user"},
+					{Selector: "id", Operator: models.RouteAssertionOperatorRange, Max: tests.Ptr(2.0)},
+					{Source: models.RouteAssertionSourceHeader, Selector: "X-Request-ID",
+						Operator: models.RouteAssertionOperatorExists},
+				},
+				ResponseSchema: models.JSONMapStringAny{
+					"properties": map[string]any{"tags": map[string]any{"maxItems": float64(1)}},
+				},
+				MaxLatencyMs: 100,
+			},
+			expectedFailedAssertions: []models.FailedAssertion{
+				{Source: "status", Operator: "eq", Expected: 201, Actual: 200, Message: "status code is different"},
+				{Source: "body", Selector: "createdAt", Operator: "exists",
+					Message: "field of the response body is missing"},
+				{Source: "body", Selector: "user.role", Operator: "eq", Expected: "user", Actual: "admin",
+					Message: "value is not equal to the expected one"},
+				{Source: "body", Selector: "id", Operator: "range", Expected: map[string]float64{"max": 2},
+					Actual: float64(3), Message: "value is greater than the maximum"},
+				{Source: "header", Selector: "X-Request-ID", Operator: "exists", Message: "value does not exist"},
+				{Source: "schema", Selector: "$.tags", Message: "expected at most 1 items, got 2"},
+				{Source: "latency", Operator: "range", Expected: 100, Actual: int64(120),
+					Message: "response took longer than the maximum latency"},
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			failedAssertions := evaluateRouteAssertions(testScenario.route, response)
+			assert.Equal(t, testScenario.expectedFailedAssertions, failedAssertions)
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package servicesApp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+const (
+	// routeFlowNameMaxLength is the longest name of the flow which can be saved.
+	routeFlowNameMaxLength = 64
+	// routeImportDefaultParam is sent for the path params without an example.
+	routeImportDefaultParam = "1"
+)
+
+// routeImportVariableRegex matches the variables of Postman ({{baseUrl}}) and Insomnia ({{ _.baseUrl }} or
+// {{ _['base-url'] }}), they are turned into the env of the flow.
+var routeImportVariableRegex = regexp.MustCompile(`\{\{\s*(?:_\.|_\[['"])?([^{}\s'"\]]+)(?:['"]\])?\s*\}\}`)
+
+var routeImportNonWordRegex = regexp.MustCompile(`\W+`)
+
+// routeFlowImporter collects the flows generated from the document, every operation gets its own flow with a single
+// smoke step.
+type routeFlowImporter struct {
+	imports []DTO.RouteFlowImport
+	names   map[string]int
+}
+
+func newRouteFlowImporter() *routeFlowImporter {
+	return &routeFlowImporter{
+		imports: make([]DTO.RouteFlowImport, 0),
+		names:   make(map[string]int),
+	}
+}
+
+// add saves the flow of the operation, the name is shortened to fit into the flow and numbered when it repeats.
+func (ri *routeFlowImporter) add(name string, env map[string]string, baseURL string, route DTO.CreateRoute) {
+	name = truncateRouteImportName(strings.TrimSpace(name), routeFlowNameMaxLength)
+	ri.names[name]++
+	if count := ri.names[name]; count > 1 {
+		suffix := fmt.Sprintf(" (%d)", count)
+		name = truncateRouteImportName(name, routeFlowNameMaxLength-len(suffix)) + suffix
+	}
+
+	ri.imports = append(ri.imports, DTO.RouteFlowImport{
+		Name:   name,
+		Method: route.Method,
+		Path:   route.Path,
+		Flow: DTO.CreateRouteData{
+			Name:        name,
+			Env:         env,
+			HTTPOptions: models.RouteFlowHTTPOptions{BaseURL: baseURL},
+			Routes:      []DTO.CreateRoute{route},
+		},
+	})
+}
+
+func truncateRouteImportName(name string, maxLength int) string {
+	if utf8.RuneCountInString(name) <= maxLength {
+		return name
+	}
+	return string([]rune(name)[:maxLength])
+}
+
+// routeImportDocumentJSON returns the document as JSON, the YAML documents are converted.
+func routeImportDocumentJSON(document string) ([]byte, error) {
+	trimmedDocument := bytes.TrimSpace([]byte(document))
+	if bytes.HasPrefix(trimmedDocument, []byte("{")) {
+		return trimmedDocument, nil
+	}
+	return yaml.YAMLToJSON(trimmedDocument)
+}
+
+func detectRouteImportFormat(document map[string]any) (string, error) {
+	if _, ok := document["swagger"]; ok {
+		return "", models.NewError(400, "RouteImport", "Swagger 2.0 documents are not supported, convert them to OpenAPI 3")
+	}
+	if _, ok := document["openapi"]; ok {
+		return models.RouteImportFormatOpenAPI, nil
+	}
+	if documentType, _ := document["_type"].(string); documentType == "export" {
+		return models.RouteImportFormatInsomnia, nil
+	}
+	if info, ok := document["info"].(map[string]any); ok {
+		if schema, _ := info["schema"].(string); strings.Contains(schema, "getpostman.com") {
+			return models.RouteImportFormatPostman, nil
+		}
+	}
+
+	return "", models.NewError(400, "RouteImport", "document is not an OpenAPI 3 document, a Postman v2.1 collection or an Insomnia export")
+}
+
+// parseRouteFlowsImport generates the flows from the document. The flows are not validated, so the preview can tell
+// which of them cannot be imported.
+func parseRouteFlowsImport(format, document string) ([]DTO.RouteFlowImport, error) {
+	documentJSON, err := routeImportDocumentJSON(document)
+	if err != nil {
+		return nil, models.NewError(400, "RouteImport", "document has to be JSON or YAML")
+	}
+	var rawDocument map[string]any
+	if err := json.Unmarshal(documentJSON, &rawDocument); err != nil {
+		return nil, models.NewError(400, "RouteImport", "document has to be an object")
+	}
+
+	if format == "" {
+		format, err = detectRouteImportFormat(rawDocument)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var imports []DTO.RouteFlowImport
+	switch format {
+	case models.RouteImportFormatOpenAPI:
+		imports, err = importOpenAPIRouteFlows(rawDocument)
+	case models.RouteImportFormatPostman:
+		imports, err = importPostmanRouteFlows(documentJSON)
+	case models.RouteImportFormatInsomnia:
+		imports, err = importInsomniaRouteFlows(documentJSON)
+	default:
+		return nil, models.NewError(400, "RouteImport", fmt.Sprintf("format %q is not supported", format))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(imports) == 0 {
+		return nil, models.NewError(400, "RouteImport", "document does not have any requests to import")
+	}
+
+	return imports, nil
+}
+
+func sanitizeRouteImportVariable(name string) string {
+	name = routeImportNonWordRegex.ReplaceAllString(name, "_")
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		return "var" + name
+	}
+	return name
+}
+
+// convertRouteImportTemplates turns the variables of the document into the env of the flow, the env gets every
+// variable which is used.
+func convertRouteImportTemplates(text string, env map[string]string) string {
+	return routeImportVariableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		name := sanitizeRouteImportVariable(routeImportVariableRegex.FindStringSubmatch(match)[1])
+		if _, ok := env[name]; !ok {
+			env[name] = ""
+		}
+		return "{{ .env." + name + " }}"
+	})
+}
+
+func convertRouteImportBody(body any, env map[string]string) any {
+	switch typedBody := body.(type) {
+	case string:
+		return convertRouteImportTemplates(typedBody, env)
+	case map[string]any:
+		convertedBody := make(map[string]any, len(typedBody))
+		for key, val := range typedBody {
+			convertedBody[key] = convertRouteImportBody(val, env)
+		}
+		return convertedBody
+	case []any:
+		convertedBody := make([]any, len(typedBody))
+		for i, val := range typedBody {
+			convertedBody[i] = convertRouteImportBody(val, env)
+		}
+		return convertedBody
+	default:
+		return body
+	}
+}
+
+// fillRouteImportEnv sets the values of the variables used by the flow.
+func fillRouteImportEnv(env map[string]string, variables map[string]string) {
+	for name := range env {
+		if val, ok := variables[name]; ok {
+			env[name] = val
+		}
+	}
+}
+
+// importRoutePath writes the path params as {name} and keeps only the params which are in the path. A param which is
+// a part of the segment is written into the path, because the params have to fill the whole segment.
+func importRoutePath(path string, params map[string]string) (string, map[string]string) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	routeParams := make(map[string]string)
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.Contains(segment, "{{") {
+			continue
+		}
+		if strings.HasPrefix(segment, ":") && len(segment) > 1 {
+			segment = "{" + segment[1:] + "}"
+		}
+
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") &&
+			!strings.ContainsAny(segment[1:len(segment)-1], "{}") {
+			name := segment[1 : len(segment)-1]
+			routeParams[name] = routeImportDefaultParam
+			if val, ok := params[name]; ok && val != "" {
+				routeParams[name] = val
+			}
+			segments[i] = segment
+			continue
+		}
+
+		for strings.Contains(segment, "{") && strings.Contains(segment, "}") {
+			start := strings.Index(segment, "{")
+			end := strings.Index(segment[start:], "}") + start
+			if end <= start {
+				break
+			}
+			val, ok := params[segment[start+1:end]]
+			if !ok || val == "" {
+				val = routeImportDefaultParam
+			}
+			segment = segment[:start] + url.PathEscape(val) + segment[end+1:]
+		}
+		segments[i] = segment
+	}
+
+	return strings.Join(segments, "/"), routeParams
+}
+
+// splitRouteImportURL splits the URL of the request into the base URL, the path and the query. A URL which starts
+// with a variable uses the variable as its base URL.
+func splitRouteImportURL(rawURL string) (baseURL, path, query string) {
+	rawURL, _, _ = strings.Cut(rawURL, "#")
+	rawURL, query, _ = strings.Cut(rawURL, "?")
+
+	switch {
+	case strings.HasPrefix(rawURL, "{{"):
+		end := strings.Index(rawURL, "}}") + len("}}")
+		baseURL, path = rawURL[:end], rawURL[end:]
+	case strings.Contains(rawURL, "://"):
+		scheme, rest, _ := strings.Cut(rawURL, "://")
+		host, rest, found := strings.Cut(rest, "/")
+		baseURL = scheme + "://" + host
+		if found {
+			path = "/" + rest
+		}
+	case strings.HasPrefix(rawURL, "/"):
+		path = rawURL
+	default:
+		host, rest, found := strings.Cut(rawURL, "/")
+		baseURL = "http://" + host
+		if found {
+			path = "/" + rest
+		}
+	}
+
+	if path == "" {
+		path = "/"
+	}
+	return baseURL, path, query
+}
+
+func parseRouteImportQuery(query string) map[string]string {
+	params := make(map[string]string)
+	for pair := range strings.SplitSeq(query, "&") {
+		if pair == "" {
+			continue
+		}
+		key, val, _ := strings.Cut(pair, "=")
+		if unescapedVal, err := url.QueryUnescape(val); err == nil {
+			val = unescapedVal
+		}
+		params[key] = val
+	}
+	return params
+}
+
+// setRouteImportBody sets the body of the step, a JSON object is sent as the request body and any other text as the
+// raw body.
+func setRouteImportBody(route *DTO.CreateRoute, text string, env map[string]string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	var body any
+	if err := json.Unmarshal([]byte(text), &body); err == nil {
+		if objectBody, ok := body.(map[string]any); ok {
+			route.BodyType = models.RouteBodyTypeJSON
+			route.RequestBody = convertRouteImportBody(objectBody, env).(map[string]any)
+			return
+		}
+	}
+
+	route.BodyType = models.RouteBodyTypeRaw
+	route.RawBody = convertRouteImportTemplates(text, env)
+}
+
+func newRouteImportStep(method string) DTO.CreateRoute {
+	return DTO.CreateRoute{
+		Method:             strings.ToUpper(method),
+		RequestQuery:       map[string]string{},
+		RequestParams:      map[string]string{},
+		RequestHeaders:     map[string]string{},
+		ResponseStatusCode: 200,
+	}
+}
+
+// stringifyRouteImportValue writes the example or the variable of the document as the text sent in the request.
+func stringifyRouteImportValue(value any) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typedValue
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(typedValue)
+	default:
+		encodedValue, err := json.Marshal(typedValue)
+		if err != nil {
+			return ""
+		}
+		return string(encodedValue)
+	}
+}
+
+func sortedRouteImportKeys[T any](values map[string]T) []string {
+	return slices.Sorted(maps.Keys(values))
+}
@@ -0,0 +1,194 @@
+package servicesApp
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+type insomniaExport struct {
+	Type      string             `json:"_type"`
+	Resources []insomniaResource `json:"resources"`
+}
+
+// insomniaResource is any of the exported resources, only the requests, their folders and the environments are
+// read.
+type insomniaResource struct {
+	ID             string             `json:"_id"`
+	Type           string             `json:"_type"`
+	ParentID       string             `json:"parentId"`
+	Name           string             `json:"name"`
+	Method         string             `json:"method"`
+	URL            string             `json:"url"`
+	Body           insomniaBody       `json:"body"`
+	Headers        []insomniaKeyValue `json:"headers"`
+	Parameters     []insomniaKeyValue `json:"parameters"`
+	Authentication insomniaAuth       `json:"authentication"`
+	Data           map[string]any     `json:"data"`
+}
+
+type insomniaBody struct {
+	MimeType string             `json:"mimeType"`
+	Text     string             `json:"text"`
+	Params   []insomniaKeyValue `json:"params"`
+}
+
+type insomniaKeyValue struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+}
+
+type insomniaAuth struct {
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+	Token    string `json:"token"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	AddTo    string `json:"addTo"`
+}
+
+func importInsomniaRouteFlows(documentJSON []byte) ([]DTO.RouteFlowImport, error) {
+	var export insomniaExport
+	if err := json.Unmarshal(documentJSON, &export); err != nil || export.Type != "export" {
+		return nil, models.NewError(400, "RouteImport", "document is not a valid Insomnia export")
+	}
+
+	folders := make(map[string]insomniaResource)
+	baseVariables := make(map[string]string)
+	subVariables := make(map[string]string)
+	for _, resource := range export.Resources {
+		switch resource.Type {
+		case "request_group":
+			folders[resource.ID] = resource
+		case "environment":
+			// The base environment belongs to the workspace, the sub environments only fill the missing variables.
+			variables := subVariables
+			if !strings.HasPrefix(resource.ParentID, "env_") {
+				variables = baseVariables
+			}
+			for name, val := range resource.Data {
+				variables[sanitizeRouteImportVariable(name)] = stringifyRouteImportValue(val)
+			}
+		}
+	}
+	for name, val := range subVariables {
+		if _, ok := baseVariables[name]; !ok {
+			baseVariables[name] = val
+		}
+	}
+
+	importer := newRouteFlowImporter()
+	for _, resource := range export.Resources {
+		if resource.Type != "request" {
+			continue
+		}
+
+		env := make(map[string]string)
+		baseURL, route := importInsomniaRequest(resource, env)
+		fillRouteImportEnv(env, baseVariables)
+
+		name := resource.Name
+		for parentID, depth := resource.ParentID, 0; depth < openAPIMaxRefs; depth++ {
+			folder, ok := folders[parentID]
+			if !ok {
+				break
+			}
+			name = folder.Name + " / " + name
+			parentID = folder.ParentID
+		}
+		if resource.Name == "" {
+			name = route.Method + " " + route.Path
+		}
+		importer.add(name, env, baseURL, route)
+	}
+
+	return importer.imports, nil
+}
+
+func importInsomniaRequest(resource insomniaResource, env map[string]string) (string, DTO.CreateRoute) {
+	method := resource.Method
+	if method == "" {
+		method = "GET"
+	}
+	route := newRouteImportStep(method)
+
+	baseURL, path, query := splitRouteImportURL(convertRouteImportTemplates(resource.URL, env))
+	for key, val := range parseRouteImportQuery(query) {
+		route.RequestQuery[key] = val
+	}
+	for _, param := range resource.Parameters {
+		if !param.Disabled && param.Name != "" {
+			route.RequestQuery[param.Name] = convertRouteImportTemplates(param.Value, env)
+		}
+	}
+	route.Path, route.RequestParams = importRoutePath(path, nil)
+
+	for _, header := range resource.Headers {
+		if !header.Disabled && header.Name != "" {
+			route.RequestHeaders[header.Name] = convertRouteImportTemplates(header.Value, env)
+		}
+	}
+
+	switch resource.Body.MimeType {
+	case "":
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		formBody := make(map[string]any)
+		for _, param := range resource.Body.Params {
+			if !param.Disabled && param.Type != "file" {
+				formBody[param.Name] = convertRouteImportTemplates(param.Value, env)
+			}
+		}
+		if len(formBody) > 0 {
+			route.BodyType = models.RouteBodyTypeForm
+			route.RequestBody = formBody
+		}
+	default:
+		setRouteImportBody(&route, resource.Body.Text, env)
+		if route.BodyType == models.RouteBodyTypeRaw {
+			if _, ok := route.RequestHeaders["Content-Type"]; !ok {
+				route.RequestHeaders["Content-Type"] = resource.Body.MimeType
+			}
+		}
+	}
+
+	if !resource.Authentication.Disabled {
+		importInsomniaAuth(&route, resource.Authentication, env)
+	}
+
+	return baseURL, route
+}
+
+// importInsomniaAuth sets the auth of the step, an API key sent in the query is added to the query params and the
+// one sent in a cookie uses the cookie auth.
+func importInsomniaAuth(route *DTO.CreateRoute, auth insomniaAuth, env map[string]string) {
+	switch auth.Type {
+	case "bearer":
+		route.Auth = models.RouteAuth{
+			Type:  models.RouteAuthTypeBearer,
+			Value: convertRouteImportTemplates(auth.Token, env),
+		}
+	case "basic":
+		route.Auth = models.RouteAuth{
+			Type:     models.RouteAuthTypeBasic,
+			Username: convertRouteImportTemplates(auth.Username, env),
+			Password: convertRouteImportTemplates(auth.Password, env),
+		}
+	case "apikey":
+		value := convertRouteImportTemplates(auth.Value, env)
+		switch auth.AddTo {
+		case "queryParams":
+			route.RequestQuery[auth.Key] = value
+			return
+		case "cookie":
+			route.Auth = models.RouteAuth{Type: models.RouteAuthTypeCookie, Name: auth.Key, Value: value}
+			return
+		}
+		route.Auth = models.RouteAuth{Type: models.RouteAuthTypeAPIKey, Name: auth.Key, Value: value}
+	}
+}
@@ -0,0 +1,401 @@
+package servicesApp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+const (
+	// openAPIMaxRefs stops following the references which point at each other.
+	openAPIMaxRefs = 16
+	// openAPIMaxExampleDepth stops generating the examples of the recursive schemas.
+	openAPIMaxExampleDepth = 8
+)
+
+// openAPIMethods are the operations which are imported, the others are not used to check the routes.
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete"}
+
+// openAPIImporter reads the OpenAPI document without a model of the whole spec, only the parts needed to send the
+// request are used.
+type openAPIImporter struct {
+	document map[string]any
+	// schemaRefs are the schemas which are being generated, a schema which refers to itself is left out of its
+	// example.
+	schemaRefs map[string]bool
+}
+
+func importOpenAPIRouteFlows(document map[string]any) ([]DTO.RouteFlowImport, error) {
+	version, _ := document["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, models.NewError(400, "RouteImport", fmt.Sprintf("OpenAPI version %q is not supported, use OpenAPI 3", version))
+	}
+
+	oi := &openAPIImporter{document: document, schemaRefs: make(map[string]bool)}
+	baseURL, basePath := oi.server()
+	paths, _ := document["paths"].(map[string]any)
+
+	importer := newRouteFlowImporter()
+	for _, path := range sortedRouteImportKeys(paths) {
+		pathItem := oi.resolve(paths[path])
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range openAPIMethods {
+			operation, ok := pathItem[method].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			route := newRouteImportStep(method)
+			params := make(map[string]string)
+			for _, rawParameter := range append(oi.list(pathItem["parameters"]), oi.list(operation["parameters"])...) {
+				parameter := oi.resolve(rawParameter)
+				if parameter == nil {
+					continue
+				}
+				name, _ := parameter["name"].(string)
+				in, _ := parameter["in"].(string)
+				required, _ := parameter["required"].(bool)
+
+				switch {
+				case in == "path":
+					params[name] = oi.parameterExample(parameter)
+				case in == "query" && required:
+					route.RequestQuery[name] = oi.parameterExample(parameter)
+				case in == "header" && required && !isOpenAPIReservedHeader(name):
+					route.RequestHeaders[name] = oi.parameterExample(parameter)
+				}
+			}
+
+			route.Path, route.RequestParams = importRoutePath(basePath+path, params)
+			oi.setRequestBody(&route, operation["requestBody"])
+			route.ResponseStatusCode = oi.expectedStatusCode(operation)
+
+			importer.add(strings.ToUpper(method)+" "+path, map[string]string{}, baseURL, route)
+		}
+	}
+
+	return importer.imports, nil
+}
+
+// isOpenAPIReservedHeader tells which headers are described by other parts of the spec, they are set from the body
+// and the auth of the step.
+func isOpenAPIReservedHeader(name string) bool {
+	switch strings.ToLower(name) {
+	case "accept", "content-type", "authorization":
+		return true
+	default:
+		return false
+	}
+}
+
+// server returns the base URL of the first server, the path of a relative server is added to the paths instead.
+func (oi *openAPIImporter) server() (baseURL, basePath string) {
+	servers := oi.list(oi.document["servers"])
+	if len(servers) == 0 {
+		return "", ""
+	}
+	server := oi.resolve(servers[0])
+	if server == nil {
+		return "", ""
+	}
+
+	serverURL, _ := server["url"].(string)
+	variables, _ := server["variables"].(map[string]any)
+	for name, rawVariable := range variables {
+		variable, _ := rawVariable.(map[string]any)
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", stringifyRouteImportValue(variable["default"]))
+	}
+
+	parsedURL, err := url.Parse(serverURL)
+	if err != nil {
+		return "", ""
+	}
+	basePath = strings.TrimSuffix(parsedURL.Path, "/")
+	if parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return "", basePath
+	}
+
+	return parsedURL.Scheme + "://" + parsedURL.Host, basePath
+}
+
+// resolve follows the local references, the references to other documents cannot be read and are skipped.
+func (oi *openAPIImporter) resolve(value any) map[string]any {
+	for range openAPIMaxRefs {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		ref, ok := object["$ref"].(string)
+		if !ok {
+			return object
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil
+		}
+
+		value = any(oi.document)
+		for token := range strings.SplitSeq(strings.TrimPrefix(ref, "#/"), "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			parent, ok := value.(map[string]any)
+			if !ok {
+				return nil
+			}
+			value = parent[token]
+		}
+	}
+
+	return nil
+}
+
+func (oi *openAPIImporter) list(value any) []any {
+	list, _ := value.([]any)
+	return list
+}
+
+func (oi *openAPIImporter) parameterExample(parameter map[string]any) string {
+	example := oi.example(parameter)
+	if example == nil {
+		example = oi.schemaExample(parameter["schema"], 0)
+	}
+	if example == nil {
+		return routeImportDefaultParam
+	}
+
+	return stringifyRouteImportValue(example)
+}
+
+// example returns the example of the parameter or the media type, the first of the named examples is used.
+func (oi *openAPIImporter) example(object map[string]any) any {
+	if example, ok := object["example"]; ok {
+		return example
+	}
+	examples, _ := object["examples"].(map[string]any)
+	for _, name := range sortedRouteImportKeys(examples) {
+		if example := oi.resolve(examples[name]); example != nil {
+			if val, ok := example["value"]; ok {
+				return val
+			}
+		}
+	}
+
+	return nil
+}
+
+// schemaExample builds the example from the schema, the examples written in the schema are preferred.
+func (oi *openAPIImporter) schemaExample(rawSchema any, depth int) any {
+	if rawObject, ok := rawSchema.(map[string]any); ok {
+		if ref, ok := rawObject["$ref"].(string); ok {
+			if oi.schemaRefs[ref] {
+				return nil
+			}
+			oi.schemaRefs[ref] = true
+			defer delete(oi.schemaRefs, ref)
+		}
+	}
+
+	schema := oi.resolve(rawSchema)
+	if schema == nil || depth > openAPIMaxExampleDepth {
+		return nil
+	}
+
+	if example, ok := schema["example"]; ok {
+		return example
+	}
+	if examples := oi.list(schema["examples"]); len(examples) > 0 {
+		return examples[0]
+	}
+	if defaultValue, ok := schema["default"]; ok {
+		return defaultValue
+	}
+	if enum := oi.list(schema["enum"]); len(enum) > 0 {
+		return enum[0]
+	}
+
+	if allOf := oi.list(schema["allOf"]); len(allOf) > 0 {
+		merged := make(map[string]any)
+		for _, subSchema := range allOf {
+			if object, ok := oi.schemaExample(subSchema, depth+1).(map[string]any); ok {
+				for key, val := range object {
+					merged[key] = val
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if subSchemas := oi.list(schema[key]); len(subSchemas) > 0 {
+			return oi.schemaExample(subSchemas[0], depth+1)
+		}
+	}
+
+	_, hasProperties := schema["properties"]
+	switch schemaType := openAPISchemaType(schema); {
+	case schemaType == "object" || (schemaType == "" && hasProperties):
+		object := make(map[string]any)
+		properties, _ := schema["properties"].(map[string]any)
+		for _, name := range sortedRouteImportKeys(properties) {
+			if property := oi.resolve(properties[name]); property != nil {
+				if readOnly, _ := property["readOnly"].(bool); readOnly {
+					continue
+				}
+			}
+			if example := oi.schemaExample(properties[name], depth+1); example != nil {
+				object[name] = example
+			}
+		}
+		return object
+	case schemaType == "array":
+		if item := oi.schemaExample(schema["items"], depth+1); item != nil {
+			return []any{item}
+		}
+		return []any{}
+	case schemaType == "integer" || schemaType == "number":
+		if minimum, ok := schema["minimum"].(float64); ok {
+			return minimum
+		}
+		return float64(1)
+	case schemaType == "boolean":
+		return true
+	case schemaType == "string":
+		return openAPIStringExample(schema)
+	default:
+		return nil
+	}
+}
+
+// openAPISchemaType returns the type of the schema, OpenAPI 3.1 can list the types together with null.
+func openAPISchemaType(schema map[string]any) string {
+	switch schemaType := schema["type"].(type) {
+	case string:
+		return schemaType
+	case []any:
+		for _, rawType := range schemaType {
+			if name, ok := rawType.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func openAPIStringExample(schema map[string]any) string {
+	format, _ := schema["format"].(string)
+	switch format {
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000001"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	default:
+		return "string"
+	}
+}
+
+// setRequestBody uses the JSON content of the body, the forms are sent as the form body and other content as the
+// raw body.
+func (oi *openAPIImporter) setRequestBody(route *DTO.CreateRoute, rawRequestBody any) {
+	requestBody := oi.resolve(rawRequestBody)
+	if requestBody == nil {
+		return
+	}
+	content, _ := requestBody["content"].(map[string]any)
+	if len(content) == 0 {
+		return
+	}
+
+	contentTypes := sortedRouteImportKeys(content)
+	contentType := contentTypes[0]
+	for _, preferredType := range []string{"application/json", "application/x-www-form-urlencoded", "multipart/form-data"} {
+		if slices.Contains(contentTypes, preferredType) {
+			contentType = preferredType
+			break
+		}
+	}
+	if !slices.Contains(contentTypes, "application/json") {
+		for _, name := range contentTypes {
+			if strings.Contains(name, "json") {
+				contentType = name
+				break
+			}
+		}
+	}
+
+	media := oi.resolve(content[contentType])
+	if media == nil {
+		return
+	}
+	example := oi.example(media)
+	if example == nil {
+		example = oi.schemaExample(media["schema"], 0)
+	}
+
+	switch {
+	case contentType == "application/x-www-form-urlencoded" || contentType == "multipart/form-data":
+		if object, ok := example.(map[string]any); ok {
+			route.BodyType = models.RouteBodyTypeForm
+			route.RequestBody = object
+		}
+	case strings.Contains(contentType, "json"):
+		if object, ok := example.(map[string]any); ok {
+			route.BodyType = models.RouteBodyTypeJSON
+			route.RequestBody = object
+			if contentType != "application/json" {
+				route.RequestHeaders["Content-Type"] = contentType
+			}
+			return
+		}
+		if example == nil {
+			return
+		}
+		encodedExample, err := json.Marshal(example)
+		if err != nil {
+			return
+		}
+		route.BodyType = models.RouteBodyTypeRaw
+		route.RawBody = string(encodedExample)
+		route.RequestHeaders["Content-Type"] = contentType
+	default:
+		if example == nil {
+			return
+		}
+		route.BodyType = models.RouteBodyTypeRaw
+		route.RawBody = stringifyRouteImportValue(example)
+		route.RequestHeaders["Content-Type"] = contentType
+	}
+}
+
+// expectedStatusCode returns the lowest success status of the operation, 200 is expected when the spec lists only
+// a range like 2XX or no success at all.
+func (oi *openAPIImporter) expectedStatusCode(operation map[string]any) int {
+	responses, _ := operation["responses"].(map[string]any)
+	statusCode := 0
+	for code := range responses {
+		parsedCode, err := strconv.Atoi(code)
+		if err != nil || parsedCode < 200 || parsedCode > 299 {
+			continue
+		}
+		if statusCode == 0 || parsedCode < statusCode {
+			statusCode = parsedCode
+		}
+	}
+
+	if statusCode == 0 {
+		return 200
+	}
+	return statusCode
+}
@@ -0,0 +1,263 @@
+package servicesApp
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanKeyValue `json:"variable"`
+	Auth     *postmanAuth      `json:"auth"`
+}
+
+// postmanItem is either a folder with its own items or a request.
+type postmanItem struct {
+	Name     string          `json:"name"`
+	Item     []postmanItem   `json:"item"`
+	Request  *postmanRequest `json:"request"`
+	Response []struct {
+		Code int `json:"code"`
+	} `json:"response"`
+	Auth *postmanAuth `json:"auth"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	URL    postmanURL        `json:"url"`
+	Header []postmanKeyValue `json:"header"`
+	Body   *postmanBody      `json:"body"`
+	Auth   *postmanAuth      `json:"auth"`
+}
+
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Query    []postmanKeyValue `json:"query"`
+	Variable []postmanKeyValue `json:"variable"`
+}
+
+type postmanBody struct {
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw"`
+	URLEncoded []postmanKeyValue `json:"urlencoded"`
+	FormData   []postmanKeyValue `json:"formdata"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []postmanKeyValue `json:"bearer"`
+	Basic  []postmanKeyValue `json:"basic"`
+	APIKey []postmanKeyValue `json:"apikey"`
+}
+
+type postmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+}
+
+// UnmarshalJSON reads the request written only as its URL.
+func (pr *postmanRequest) UnmarshalJSON(data []byte) error {
+	var rawURL string
+	if err := json.Unmarshal(data, &rawURL); err == nil {
+		*pr = postmanRequest{Method: "GET", URL: postmanURL{Raw: rawURL}}
+		return nil
+	}
+
+	type request postmanRequest
+	return json.Unmarshal(data, (*request)(pr))
+}
+
+// UnmarshalJSON reads the URL written as a string.
+func (pu *postmanURL) UnmarshalJSON(data []byte) error {
+	var rawURL string
+	if err := json.Unmarshal(data, &rawURL); err == nil {
+		*pu = postmanURL{Raw: rawURL}
+		return nil
+	}
+
+	type requestURL postmanURL
+	return json.Unmarshal(data, (*requestURL)(pu))
+}
+
+func postmanValue(keyValues []postmanKeyValue, key string) string {
+	for _, keyValue := range keyValues {
+		if keyValue.Key == key {
+			return stringifyRouteImportValue(keyValue.Value)
+		}
+	}
+	return ""
+}
+
+func importPostmanRouteFlows(documentJSON []byte) ([]DTO.RouteFlowImport, error) {
+	var collection postmanCollection
+	if err := json.Unmarshal(documentJSON, &collection); err != nil {
+		return nil, models.NewError(400, "RouteImport", "document is not a valid Postman collection")
+	}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.1") {
+		return nil, models.NewError(400, "RouteImport", "only Postman v2.1 collections are supported")
+	}
+
+	variables := make(map[string]string, len(collection.Variable))
+	for _, variable := range collection.Variable {
+		if !variable.Disabled {
+			variables[sanitizeRouteImportVariable(variable.Key)] = stringifyRouteImportValue(variable.Value)
+		}
+	}
+
+	importer := newRouteFlowImporter()
+	importPostmanItems(importer, collection.Item, "", collection.Auth, variables)
+	return importer.imports, nil
+}
+
+// importPostmanItems adds the requests of the folders, the name of the folder is a part of the flow name and its
+// auth is used by the requests which do not have their own.
+func importPostmanItems(importer *routeFlowImporter, items []postmanItem, folder string, auth *postmanAuth,
+	variables map[string]string,
+) {
+	for _, item := range items {
+		name := item.Name
+		if folder != "" {
+			name = folder + " / " + item.Name
+		}
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+
+		if item.Request == nil {
+			importPostmanItems(importer, item.Item, name, itemAuth, variables)
+			continue
+		}
+		if item.Request.Auth != nil {
+			itemAuth = item.Request.Auth
+		}
+
+		env := make(map[string]string)
+		baseURL, route := importPostmanRequest(*item.Request, itemAuth, env)
+		for _, response := range item.Response {
+			if response.Code >= 200 && response.Code <= 299 {
+				route.ResponseStatusCode = response.Code
+				break
+			}
+		}
+		fillRouteImportEnv(env, variables)
+
+		if name == "" {
+			name = route.Method + " " + route.Path
+		}
+		importer.add(name, env, baseURL, route)
+	}
+}
+
+func importPostmanRequest(postmanRequest postmanRequest, auth *postmanAuth, env map[string]string) (string, DTO.CreateRoute) {
+	method := postmanRequest.Method
+	if method == "" {
+		method = "GET"
+	}
+	route := newRouteImportStep(method)
+
+	baseURL, path, query := splitRouteImportURL(convertRouteImportTemplates(postmanRequest.URL.Raw, env))
+	if postmanRequest.URL.Query != nil {
+		for _, param := range postmanRequest.URL.Query {
+			if !param.Disabled {
+				route.RequestQuery[param.Key] = convertRouteImportTemplates(stringifyRouteImportValue(param.Value), env)
+			}
+		}
+	} else {
+		for key, val := range parseRouteImportQuery(query) {
+			route.RequestQuery[key] = val
+		}
+	}
+
+	params := make(map[string]string, len(postmanRequest.URL.Variable))
+	for _, variable := range postmanRequest.URL.Variable {
+		params[variable.Key] = convertRouteImportTemplates(stringifyRouteImportValue(variable.Value), env)
+	}
+	route.Path, route.RequestParams = importRoutePath(path, params)
+
+	for _, header := range postmanRequest.Header {
+		if !header.Disabled && header.Key != "" {
+			route.RequestHeaders[header.Key] = convertRouteImportTemplates(stringifyRouteImportValue(header.Value), env)
+		}
+	}
+
+	if postmanRequest.Body != nil {
+		importPostmanBody(&route, *postmanRequest.Body, env)
+	}
+	if auth != nil {
+		importPostmanAuth(&route, *auth, env)
+	}
+
+	return baseURL, route
+}
+
+func importPostmanBody(route *DTO.CreateRoute, body postmanBody, env map[string]string) {
+	switch body.Mode {
+	case "raw":
+		setRouteImportBody(route, body.Raw, env)
+	case "urlencoded", "formdata":
+		fields := body.URLEncoded
+		if body.Mode == "formdata" {
+			fields = body.FormData
+		}
+		formBody := make(map[string]any)
+		for _, field := range fields {
+			if !field.Disabled && field.Type != "file" {
+				formBody[field.Key] = convertRouteImportTemplates(stringifyRouteImportValue(field.Value), env)
+			}
+		}
+		if len(formBody) > 0 {
+			route.BodyType = models.RouteBodyTypeForm
+			route.RequestBody = formBody
+		}
+	case "graphql":
+		if body.GraphQL == nil {
+			return
+		}
+		graphQLBody := map[string]any{"query": convertRouteImportTemplates(body.GraphQL.Query, env)}
+		var graphQLVariables map[string]any
+		if err := json.Unmarshal([]byte(body.GraphQL.Variables), &graphQLVariables); err == nil {
+			graphQLBody["variables"] = convertRouteImportBody(graphQLVariables, env)
+		}
+		route.BodyType = models.RouteBodyTypeJSON
+		route.RequestBody = graphQLBody
+	}
+}
+
+// importPostmanAuth sets the auth of the step, an API key sent in the query is added to the query params.
+func importPostmanAuth(route *DTO.CreateRoute, auth postmanAuth, env map[string]string) {
+	switch auth.Type {
+	case "bearer":
+		route.Auth = models.RouteAuth{
+			Type:  models.RouteAuthTypeBearer,
+			Value: convertRouteImportTemplates(postmanValue(auth.Bearer, "token"), env),
+		}
+	case "basic":
+		route.Auth = models.RouteAuth{
+			Type:     models.RouteAuthTypeBasic,
+			Username: convertRouteImportTemplates(postmanValue(auth.Basic, "username"), env),
+			Password: convertRouteImportTemplates(postmanValue(auth.Basic, "password"), env),
+		}
+	case "apikey":
+		name := postmanValue(auth.APIKey, "key")
+		value := convertRouteImportTemplates(postmanValue(auth.APIKey, "value"), env)
+		if postmanValue(auth.APIKey, "in") == "query" {
+			route.RequestQuery[name] = value
+			return
+		}
+		route.Auth = models.RouteAuth{Type: models.RouteAuthTypeAPIKey, Name: name, Value: value}
+	}
+}
@@ -0,0 +1,332 @@
+package servicesApp
+
+import (
+	"testing"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+const openAPIImportDocument = `
+openapi: 3.0.3
+info:
+  title: Users
+  version: "1.0"
+servers:
+  - url: https://{host}/v1
+    variables:
+      host:
+        default: api.example.com
+paths:
+  /users:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/User'
+      responses:
+        "201":
+          description: created
+        "400":
+          description: invalid
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+          example: 7
+    get:
+      parameters:
+        - name: fields
+          in: query
+          required: true
+          schema:
+            type: string
+            enum: [name, email]
+        - name: page
+          in: query
+          schema:
+            type: integer
+      responses:
+        2XX:
+          description: found
+  /users/{id}/avatar.{format}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+      - name: format
+        in: path
+        required: true
+        example: png
+    options:
+      responses:
+        "204":
+          description: options
+    delete:
+      responses:
+        "204":
+          description: removed
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        email:
+          type: string
+          format: email
+        manager:
+          $ref: '#/components/schemas/User'
+`
+
+const postmanImportDocument = `{
+  "info": {
+    "name": "Users",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [
+    {"key": "baseUrl", "value": "https://api.example.com"},
+    {"key": "token", "value": "abc"}
+  ],
+  "item": [
+    {
+      "name": "Users",
+      "item": [
+        {
+          "name": "Get user",
+          "request": {
+            "method": "GET",
+            "header": [{"key": "X-Trace", "value": "1"}, {"key": "X-Old", "value": "1", "disabled": true}],
+            "url": {
+              "raw": "{{baseUrl}}/users/:id?expand=true",
+              "query": [{"key": "expand", "value": "true"}],
+              "variable": [{"key": "id", "value": "7"}]
+            }
+          },
+          "response": [{"code": 404}, {"code": 200}]
+        },
+        {
+          "name": "Create user",
+          "request": {
+            "method": "POST",
+            "auth": {"type": "basic", "basic": [{"key": "username", "value": "admin"}, {"key": "password", "value": "{{password}}"}]},
+            "body": {"mode": "raw", "raw": "{\"email\": \"{{email}}\"}"},
+            "url": "{{baseUrl}}/users"
+          }
+        }
+      ]
+    }
+  ]
+}`
+
+const insomniaImportDocument = `{
+  "_type": "export",
+  "__export_format": 4,
+  "resources": [
+    {"_id": "wrk_1", "_type": "workspace", "name": "Users"},
+    {"_id": "env_1", "_type": "environment", "parentId": "wrk_1", "data": {"base_url": "https://api.example.com"}},
+    {"_id": "env_2", "_type": "environment", "parentId": "env_1", "data": {"api-key": "k3y"}},
+    {"_id": "fld_1", "_type": "request_group", "parentId": "wrk_1", "name": "Users"},
+    {
+      "_id": "req_1",
+      "_type": "request",
+      "parentId": "fld_1",
+      "name": "Search users",
+      "method": "POST",
+      "url": "{{ _.base_url }}/users/search?limit=5",
+      "body": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "q", "value": "adrian"}]},
+      "authentication": {"type": "apikey", "key": "X-Api-Key", "value": "{{ _['api-key'] }}", "addTo": "header"}
+    },
+    {
+      "_id": "req_2",
+      "_type": "request",
+      "parentId": "wrk_1",
+      "name": "Export",
+      "method": "POST",
+      "url": "{{ _.base_url }}/export",
+      "body": {"mimeType": "text/csv", "text": "id,name"}
+    }
+  ]
+}`
+
+// checkImportedRouteFlow runs the checks which the route controller runs before the flow is saved.
+func checkImportedRouteFlow(t *testing.T, flow DTO.CreateRouteData) {
+	t.Helper()
+	for _, route := range flow.Routes {
+		assert.True(t, request.CheckRouteParams(route), route.Path)
+		assert.NoError(t, validation.CheckRouteRequest(route))
+	}
+	assert.NoError(t, validation.CheckRouteFlowHTTPOptions(flow.HTTPOptions))
+	assert.NoError(t, validation.CheckRouteTemplates(flow))
+}
+
+func TestParseRouteFlowsImport(t *testing.T) {
+	type args struct {
+		name            string
+		format          string
+		document        string
+		expectedImports []DTO.RouteFlowImport
+		expectedError   error
+	}
+
+	importedFlow := func(name, baseURL string, env map[string]string, route DTO.CreateRoute) DTO.RouteFlowImport {
+		if route.RequestQuery == nil {
+			route.RequestQuery = map[string]string{}
+		}
+		if route.RequestParams == nil {
+			route.RequestParams = map[string]string{}
+		}
+		if route.RequestHeaders == nil {
+			route.RequestHeaders = map[string]string{}
+		}
+		return DTO.RouteFlowImport{
+			Name:   name,
+			Method: route.Method,
+			Path:   route.Path,
+			Flow: DTO.CreateRouteData{
+				Name:        name,
+				Env:         env,
+				HTTPOptions: models.RouteFlowHTTPOptions{BaseURL: baseURL},
+				Routes:      []DTO.CreateRoute{route},
+			},
+		}
+	}
+
+	testsScenarios := []args{
+		{
+			name:     "OpenAPI document in YAML",
+			document: openAPIImportDocument,
+			expectedImports: []DTO.RouteFlowImport{
+				importedFlow("POST /users", "https://api.example.com", map[string]string{}, DTO.CreateRoute{
+					Path:               "/v1/users",
+					Method:             "POST",
+					BodyType:           models.RouteBodyTypeJSON,
+					RequestBody:        map[string]any{"email": "user@example.com"},
+					ResponseStatusCode: 201,
+				}),
+				importedFlow("GET /users/{id}", "https://api.example.com", map[string]string{}, DTO.CreateRoute{
+					Path:               "/v1/users/{id}",
+					Method:             "GET",
+					RequestQuery:       map[string]string{"fields": "name"},
+					RequestParams:      map[string]string{"id": "7"},
+					ResponseStatusCode: 200,
+				}),
+				importedFlow("DELETE /users/{id}/avatar.{format}", "https://api.example.com", map[string]string{},
+					DTO.CreateRoute{
+						Path:               "/v1/users/{id}/avatar.png",
+						Method:             "DELETE",
+						RequestParams:      map[string]string{"id": "1"},
+						ResponseStatusCode: 204,
+					}),
+			},
+		},
+		{
+			name:     "Postman collection",
+			document: postmanImportDocument,
+			expectedImports: []DTO.RouteFlowImport{
+				importedFlow("Users / Get user", "{{ .env.baseUrl }}",
+					map[string]string{"baseUrl": "https://api.example.com", "token": "abc"}, DTO.CreateRoute{
+						Path:               "/users/{id}",
+						Method:             "GET",
+						RequestQuery:       map[string]string{"expand": "true"},
+						RequestParams:      map[string]string{"id": "7"},
+						RequestHeaders:     map[string]string{"X-Trace": "1"},
+						Auth:               models.RouteAuth{Type: models.RouteAuthTypeBearer, Value: "{{ .env.token }}"},
+						ResponseStatusCode: 200,
+					}),
+				importedFlow("Users / Create user", "{{ .env.baseUrl }}",
+					map[string]string{"baseUrl": "https://api.example.com", "email": "", "password": ""},
+					DTO.CreateRoute{
+						Path:        "/users",
+						Method:      "POST",
+						BodyType:    models.RouteBodyTypeJSON,
+						RequestBody: map[string]any{"email": "{{ .env.email }}"},
+						Auth: models.RouteAuth{
+							Type:     models.RouteAuthTypeBasic,
+							Username: "admin",
+							Password: "{{ .env.password }}",
+						},
+						ResponseStatusCode: 200,
+					}),
+			},
+		},
+		{
+			name:     "Insomnia export",
+			format:   models.RouteImportFormatInsomnia,
+			document: insomniaImportDocument,
+			expectedImports: []DTO.RouteFlowImport{
+				importedFlow("Users / Search users", "{{ .env.base_url }}",
+					map[string]string{"base_url": "https://api.example.com", "api_key": "k3y"}, DTO.CreateRoute{
+						Path:               "/users/search",
+						Method:             "POST",
+						RequestQuery:       map[string]string{"limit": "5"},
+						BodyType:           models.RouteBodyTypeForm,
+						RequestBody:        map[string]any{"q": "adrian"},
+						Auth:               models.RouteAuth{Type: models.RouteAuthTypeAPIKey, Name: "X-Api-Key", Value: "{{ .env.api_key }}"},
+						ResponseStatusCode: 200,
+					}),
+				importedFlow("Export", "{{ .env.base_url }}",
+					map[string]string{"base_url": "https://api.example.com"}, DTO.CreateRoute{
+						Path:               "/export",
+						Method:             "POST",
+						RequestHeaders:     map[string]string{"Content-Type": "text/csv"},
+						BodyType:           models.RouteBodyTypeRaw,
+						RawBody:            "id,name",
+						ResponseStatusCode: 200,
+					}),
+			},
+		},
+		{
+			name:          "Swagger 2.0 document",
+			document:      `{"swagger": "2.0", "paths": {}}`,
+			expectedError: models.NewError(400, "RouteImport", "Swagger 2.0 documents are not supported, convert them to OpenAPI 3"),
+		},
+		{
+			name:          "Unknown document",
+			document:      `{"name": "users"}`,
+			expectedError: models.NewError(400, "RouteImport", "document is not an OpenAPI 3 document, a Postman v2.1 collection or an Insomnia export"),
+		},
+		{
+			name:          "Document without operations",
+			document:      `{"openapi": "3.1.0", "paths": {}}`,
+			expectedError: models.NewError(400, "RouteImport", "document does not have any requests to import"),
+		},
+		{
+			name:          "Document which is not an object",
+			document:      `- users`,
+			expectedError: models.NewError(400, "RouteImport", "document has to be an object"),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			imports, err := parseRouteFlowsImport(testScenario.format, testScenario.document)
+			if testScenario.expectedError != nil {
+				assert.EqualError(t, err, testScenario.expectedError.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testScenario.expectedImports, imports)
+		})
+	}
+}
+
+func TestParseRouteFlowsImport_FlowsPassValidation(t *testing.T) {
+	for _, document := range []string{openAPIImportDocument, postmanImportDocument, insomniaImportDocument} {
+		imports, err := parseRouteFlowsImport("", document)
+		assert.NoError(t, err)
+		for _, routeFlowImport := range imports {
+			checkImportedRouteFlow(t, routeFlowImport.Flow)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package servicesApp
+
+import (
+	"context"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+// routeFlowNotification tells whether the run changed the status the flow was last notified about. A failure is
+// reported once the streak reaches the threshold and waits for the cooldown of the previous notification. A recovery
+// is reported right after a reported failure, so the failure is never the last word about the flow.
+func routeFlowNotification(state models.RouteFlowNotificationState, routeFlowRun DTO.RouteFlowRun,
+	now time.Time,
+) (DTO.RouteFlowNotification, bool) {
+	notifications := state.Notifications
+	if notifications.Muted {
+		return DTO.RouteFlowNotification{}, false
+	}
+
+	routeFlowNotification := DTO.RouteFlowNotification{
+		FlowID:        state.FlowID,
+		AppID:         state.AppID,
+		Name:          state.Name,
+		FailureStreak: state.FailureStreak,
+	}
+	if routeFlowRun.Status == "success" {
+		if state.NotifiedStatus != models.RouteFlowNotifiedStatusFailing {
+			return DTO.RouteFlowNotification{}, false
+		}
+		routeFlowNotification.Recovered = true
+		return routeFlowNotification, true
+	}
+
+	failureThreshold := max(notifications.FailureThreshold, 1)
+	if state.NotifiedStatus == models.RouteFlowNotifiedStatusFailing || state.FailureStreak < failureThreshold {
+		return DTO.RouteFlowNotification{}, false
+	}
+	if state.NotifiedAt != nil &&
+		now.Sub(*state.NotifiedAt) < time.Duration(notifications.CooldownSeconds)*time.Second {
+		return DTO.RouteFlowNotification{}, false
+	}
+	if len(routeFlowRun.Steps) > 0 {
+		failedStep := routeFlowRun.Steps[len(routeFlowRun.Steps)-1]
+		routeFlowNotification.FailedStep = &failedStep
+	}
+
+	return routeFlowNotification, true
+}
+
+// notifyRouteFlowRuns counts the finished runs of the flows and notifies about the flows which started failing or
+// recovered. The errors are only logged, so they do not fail the check of the routes.
+func (rs *RouteStatusService) notifyRouteFlowRuns(ctx context.Context, routeFlowRuns []DTO.RouteFlowRun) {
+	now := time.Now().UTC()
+	routeFlowNotifications := make([]DTO.RouteFlowNotification, 0)
+	for _, routeFlowRun := range routeFlowRuns {
+		if routeFlowRun.DryRun || routeFlowRun.Status == routeFlowRunRunningStatus {
+			continue
+		}
+
+		state, err := rs.routeRepository.UpdateRouteFlowFailureStreak(ctx, routeFlowRun.FlowID,
+			routeFlowRun.Status != "success")
+		if err != nil {
+			rs.loggerService.Warn("failed to update route flow failure streak", map[string]any{
+				"flowID": routeFlowRun.FlowID,
+				"error":  err.Error(),
+			})
+			continue
+		}
+
+		routeFlowNotification, notify := routeFlowNotification(*state, routeFlowRun, now)
+		if !notify {
+			continue
+		}
+		notifiedStatus := models.RouteFlowNotifiedStatusFailing
+		if routeFlowNotification.Recovered {
+			notifiedStatus = models.RouteFlowNotifiedStatusPassing
+		}
+		err = rs.routeRepository.UpdateRouteFlowNotifiedStatus(ctx, routeFlowRun.FlowID, notifiedStatus, now)
+		if err != nil {
+			rs.loggerService.Warn("failed to update route flow notified status", map[string]any{
+				"flowID": routeFlowRun.FlowID,
+				"error":  err.Error(),
+			})
+			continue
+		}
+		routeFlowNotifications = append(routeFlowNotifications, routeFlowNotification)
+	}
+
+	if len(routeFlowNotifications) == 0 {
+		return
+	}
+	err := rs.appNotificationsService.SendRouteFlowsNotifications(ctx, routeFlowNotifications)
+	if err != nil {
+		rs.loggerService.Warn("failed to send route flows notifications", map[string]any{
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package servicesApp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteFlowNotification(t *testing.T) {
+	type args struct {
+		name                 string
+		state                models.RouteFlowNotificationState
+		routeFlowRun         DTO.RouteFlowRun
+		expectedNotification DTO.RouteFlowNotification
+		expectedNotify       bool
+	}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	minuteAgo := now.Add(-time.Minute)
+	failedStep := DTO.RouteFlowRunStep{WorkingRouteID: 2, Position: 1, Status: "Failed;1 assertions failed"}
+	failedRun := DTO.RouteFlowRun{
+		FlowID: 5,
+		Status: failedStep.Status,
+		Steps:  []DTO.RouteFlowRunStep{{WorkingRouteID: 1, Status: "success"}, failedStep},
+	}
+	successRun := DTO.RouteFlowRun{FlowID: 5, Status: "success"}
+	state := func(failureStreak int, notifiedStatus string, notifications models.RouteFlowNotifications,
+		notifiedAt *time.Time,
+	) models.RouteFlowNotificationState {
+		return models.RouteFlowNotificationState{
+			FlowID:         5,
+			AppID:          "app",
+			Name:           "login",
+			Notifications:  notifications,
+			FailureStreak:  failureStreak,
+			NotifiedStatus: notifiedStatus,
+			NotifiedAt:     notifiedAt,
+		}
+	}
+
+	testsScenarios := []args{
+		{
+			name:         "First failure without the threshold",
+			state:        state(1, models.RouteFlowNotifiedStatusPassing, models.RouteFlowNotifications{}, nil),
+			routeFlowRun: failedRun,
+			expectedNotification: DTO.RouteFlowNotification{
+				FlowID:        5,
+				AppID:         "app",
+				Name:          "login",
+				FailureStreak: 1,
+				FailedStep:    &failedStep,
+			},
+			expectedNotify: true,
+		},
+		{
+			name: "Failure below the threshold",
+			state: state(2, models.RouteFlowNotifiedStatusPassing,
+				models.RouteFlowNotifications{FailureThreshold: 3}, nil),
+			routeFlowRun: failedRun,
+		},
+		{
+			name: "Failure which reached the threshold",
+			state: state(3, models.RouteFlowNotifiedStatusPassing,
+				models.RouteFlowNotifications{FailureThreshold: 3}, nil),
+			routeFlowRun: failedRun,
+			expectedNotification: DTO.RouteFlowNotification{
+				FlowID:        5,
+				AppID:         "app",
+				Name:          "login",
+				FailureStreak: 3,
+				FailedStep:    &failedStep,
+			},
+			expectedNotify: true,
+		},
+		{
+			name:         "Failure which was already notified",
+			state:        state(4, models.RouteFlowNotifiedStatusFailing, models.RouteFlowNotifications{}, &minuteAgo),
+			routeFlowRun: failedRun,
+		},
+		{
+			name:         "Recovery after the notified failure",
+			state:        state(0, models.RouteFlowNotifiedStatusFailing, models.RouteFlowNotifications{}, &minuteAgo),
+			routeFlowRun: successRun,
+			expectedNotification: DTO.RouteFlowNotification{
+				FlowID:    5,
+				AppID:     "app",
+				Name:      "login",
+				Recovered: true,
+			},
+			expectedNotify: true,
+		},
+		{
+			name:         "Success of the passing flow",
+			state:        state(0, models.RouteFlowNotifiedStatusPassing, models.RouteFlowNotifications{}, nil),
+			routeFlowRun: successRun,
+		},
+		{
+			name: "Fail, recover inside cooldown",
+			state: state(0, models.RouteFlowNotifiedStatusFailing,
+				models.RouteFlowNotifications{CooldownSeconds: 600}, &minuteAgo),
+			routeFlowRun: successRun,
+			expectedNotification: DTO.RouteFlowNotification{
+				FlowID:    5,
+				AppID:     "app",
+				Name:      "login",
+				Recovered: true,
+			},
+			expectedNotify: true,
+		},
+		{
+			name: "Failure during the cooldown of the recovery",
+			state: state(1, models.RouteFlowNotifiedStatusPassing,
+				models.RouteFlowNotifications{CooldownSeconds: 600}, &minuteAgo),
+			routeFlowRun: failedRun,
+		},
+		{
+			name: "Failure of the muted flow",
+			state: state(1, models.RouteFlowNotifiedStatusPassing, models.RouteFlowNotifications{Muted: true},
+				nil),
+			routeFlowRun: failedRun,
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			routeFlowNotification, notify := routeFlowNotification(testScenario.state, testScenario.routeFlowRun,
+				now)
+			assert.Equal(t, testScenario.expectedNotify, notify)
+			assert.Equal(t, testScenario.expectedNotification, routeFlowNotification)
+		})
+	}
+}
+
+func TestDescribeRouteFlowNotification(t *testing.T) {
+	type args struct {
+		name                  string
+		routeFlowNotification DTO.RouteFlowNotification
+		expectedDescription   string
+	}
+
+	testsScenarios := []args{
+		{
+			name: "Failed assertion",
+			routeFlowNotification: DTO.RouteFlowNotification{
+				Name:          "login",
+				FailureStreak: 3,
+				FailedStep: &DTO.RouteFlowRunStep{
+					Position: 1,
+					Status:   "Failed;1 assertions failed",
+					Request: models.RouteRunRequest{
+						Method: "GET",
+						URL:    "http://192.168.0.100:3040/me?",
+					},
+					ResponseStatusCode: 200,
+					ResponseBody:       "{\n  \"id\": 7\n}",
+					FailedAssertions: []models.FailedAssertion{{
+						Source:   "body",
+						Selector: "id",
+						Operator: "eq",
+						Expected: float64(8),
+						Actual:   float64(7),
+						Message:  "value is not equal",
+					}},
+				},
+			},
+			expectedDescription: `route flow "login" failed 3 times in a row at step 2 (GET ` +
+				`http://192.168.0.100:3040/me?): Failed;1 assertions failed; assertion body id eq 8, got 7 ` +
+				`(value is not equal); response 200: { "id": 7 }`,
+		},
+		{
+			name: "Request which was not sent",
+			routeFlowNotification: DTO.RouteFlowNotification{
+				Name:          "login",
+				FailureStreak: 1,
+				FailedStep: &DTO.RouteFlowRunStep{
+					Status:  "Failed;To check route",
+					Request: models.RouteRunRequest{Method: "POST", URL: "http://192.168.0.100:3040/login?"},
+					Error:   "connection refused",
+				},
+			},
+			expectedDescription: `route flow "login" failed 1 times in a row at step 1 (POST ` +
+				`http://192.168.0.100:3040/login?): Failed;To check route; error: connection refused`,
+		},
+		{
+			name:                  "Recovery",
+			routeFlowNotification: DTO.RouteFlowNotification{Name: "login", Recovered: true},
+			expectedDescription:   `route flow "login" recovered`,
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			assert.Equal(t, testScenario.expectedDescription,
+				describeRouteFlowNotification(testScenario.routeFlowNotification))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package servicesApp
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+const (
+	// routeRunResponseBodyMaxLength limits the beginning of the response body kept in the run history.
+	routeRunResponseBodyMaxLength = 1024
+	routeRunRedacted              = "[REDACTED]"
+)
+
+// routeRunSensitiveHeaders are redacted in the run history whatever their values are.
+var routeRunSensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key"}
+
+func newRouteFlowRunStep(route models.RouteToTest, status, stepError string) DTO.RouteFlowRunStep {
+	return DTO.RouteFlowRunStep{
+		WorkingRouteID:   route.ID,
+		Position:         route.Position,
+		Status:           status,
+		Request:          models.RouteRunRequest{Method: route.Method, Headers: map[string]string{}},
+		FailedAssertions: []models.FailedAssertion{},
+		Error:            stepError,
+	}
+}
+
+// redactRouteSecrets replaces the values of the secrets of the flow, so they are not kept in the run history.
+func redactRouteSecrets(text string, secrets map[string]string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			text = strings.ReplaceAll(text, secret, routeRunRedacted)
+		}
+	}
+	return text
+}
+
+func redactRouteValue(value any, secrets map[string]string) any {
+	switch typedValue := value.(type) {
+	case string:
+		return redactRouteSecrets(typedValue, secrets)
+	case map[string]any:
+		redactedValue := make(map[string]any, len(typedValue))
+		for key, val := range typedValue {
+			redactedValue[key] = redactRouteValue(val, secrets)
+		}
+		return redactedValue
+	case []any:
+		redactedValue := make([]any, len(typedValue))
+		for i, val := range typedValue {
+			redactedValue[i] = redactRouteValue(val, secrets)
+		}
+		return redactedValue
+	default:
+		return value
+	}
+}
+
+// newRouteRunRequest describes the request sent by the step. The credential headers are always redacted, because
+// they usually hold a token received from one of the previous steps.
+func newRouteRunRequest(route models.RouteToTest, url string, secrets map[string]string) models.RouteRunRequest {
+	headers := make(map[string]string, len(route.RequestHeaders)+1)
+	for key, val := range route.RequestHeaders {
+		if slices.Contains(routeRunSensitiveHeaders, http.CanonicalHeaderKey(key)) {
+			headers[key] = routeRunRedacted
+			continue
+		}
+		headers[key] = redactRouteSecrets(val, secrets)
+	}
+	if name, _ := routeAuthHeader(route); name != "" {
+		headers[name] = routeRunRedacted
+	}
+
+	var body any = map[string]any(route.RequestBody)
+	if route.BodyType == models.RouteBodyTypeRaw {
+		body = route.RawBody
+	}
+
+	return models.RouteRunRequest{
+		Method:  route.Method,
+		URL:     redactRouteSecrets(url, secrets),
+		Headers: headers,
+		Body:    redactRouteValue(body, secrets),
+	}
+}
+
+func routeResponseBodySnippet(body []byte) string {
+	if len(body) <= routeRunResponseBodyMaxLength {
+		return strings.ToValidUTF8(string(body), "")
+	}
+	return strings.ToValidUTF8(string(body[:routeRunResponseBodyMaxLength]), "") + "..."
+}
@@ -0,0 +1,411 @@
+package servicesApp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRouteStatusService_runRouteFlow(t *testing.T) {
+	type args struct {
+		name           string
+		routesToTest   []models.RouteToTest
+		expectedStatus string
+		expectedSteps  []DTO.RouteFlowRunStep
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login":
+			_, _ = w.Write([]byte(`{"token":"abc","user":{"id":17}}`))
+		case "/users/17":
+			if r.Header.Get("X-Token") != "abc" {
+				w.WriteHeader(401)
+				_, _ = w.Write([]byte(`{"error":"unauthorized"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":17}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets, err := utils.Encrypt(`{"password":"qwerty"}`, "test-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loginRoute := models.RouteToTest{
+		ID:                 1,
+		IPAddress:          host,
+		Port:               port,
+		Path:               "/login",
+		Method:             "POST",
+		RequestBody:        models.JSONMapStringAny{"password": "{{ .secrets.password }}"},
+		ResponseStatusCode: 200,
+		Extract: models.RouteVariables{
+			{Name: "token", Selector: "token"},
+			{Name: "userID", Selector: "user.id"},
+		},
+		FlowID:  5,
+		Version: 2,
+		Secrets: secrets,
+	}
+	loginStep := DTO.RouteFlowRunStep{
+		WorkingRouteID: 1,
+		Status:         "success",
+		Request: models.RouteRunRequest{
+			Method:  "POST",
+			URL:     "http://" + host + ":" + port + "/login?",
+			Headers: map[string]string{},
+			Body:    map[string]any{"password": "[REDACTED]"},
+		},
+		ResponseStatusCode: 200,
+		ResponseBody:       `{"token":"abc","user":{"id":17}}`,
+		FailedAssertions:   []models.FailedAssertion{},
+	}
+
+	testsScenarios := []args{
+		{
+			name: "Variables are passed to the next step",
+			routesToTest: []models.RouteToTest{
+				loginRoute,
+				{
+					ID:                 2,
+					Position:           1,
+					IPAddress:          host,
+					Port:               port,
+					Path:               "/users/{{ .vars.userID }}",
+					Method:             "GET",
+					RequestHeaders:     models.JSONMapStringString{"X-Token": "{{ .vars.token }}"},
+					ResponseStatusCode: 200,
+				},
+			},
+			expectedStatus: "success",
+			expectedSteps: []DTO.RouteFlowRunStep{
+				loginStep,
+				{
+					WorkingRouteID: 2,
+					Position:       1,
+					Status:         "success",
+					Request: models.RouteRunRequest{
+						Method:  "GET",
+						URL:     "http://" + host + ":" + port + "/users/17?",
+						Headers: map[string]string{"X-Token": "abc"},
+						Body:    map[string]any{},
+					},
+					ResponseStatusCode: 200,
+					ResponseBody:       `{"id":17}`,
+					FailedAssertions:   []models.FailedAssertion{},
+				},
+			},
+		},
+		{
+			name: "Run stops at the failed step",
+			routesToTest: []models.RouteToTest{
+				loginRoute,
+				{
+					ID:                 2,
+					Position:           1,
+					IPAddress:          host,
+					Port:               port,
+					Path:               "/users/{{ .vars.userID }}",
+					Method:             "GET",
+					ResponseStatusCode: 200,
+				},
+				{
+					ID:       3,
+					Position: 2,
+					Path:     "/never",
+				},
+			},
+			expectedStatus: "Failed;1 assertions failed",
+			expectedSteps: []DTO.RouteFlowRunStep{
+				loginStep,
+				{
+					WorkingRouteID: 2,
+					Position:       1,
+					Status:         "Failed;1 assertions failed",
+					Request: models.RouteRunRequest{
+						Method:  "GET",
+						URL:     "http://" + host + ":" + port + "/users/17?",
+						Headers: map[string]string{},
+						Body:    map[string]any{},
+					},
+					ResponseStatusCode: 401,
+					ResponseBody:       `{"error":"unauthorized"}`,
+					FailedAssertions: []models.FailedAssertion{
+						{Source: "status", Operator: "eq", Expected: 200, Actual: 401,
+							Message: "status code is different"},
+					},
+				},
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			loggerService := tests.CreateLogger()
+			routeStatusService := NewRouteStatusService(new(mocks.MockRouteRepository), &fakeAppNotificationsService{}, "test-key", loggerService)
+			routeFlowRun, err := routeStatusService.runRouteFlow(context.Background(), testScenario.routesToTest)
+			assert.NoError(t, err)
+			assert.Equal(t, 5, routeFlowRun.FlowID)
+			assert.Equal(t, 2, routeFlowRun.Version)
+			assert.Equal(t, testScenario.expectedStatus, routeFlowRun.Status)
+			for i := range routeFlowRun.Steps {
+				routeFlowRun.Steps[i].LatencyMs = 0
+			}
+			assert.Equal(t, testScenario.expectedSteps, routeFlowRun.Steps)
+		})
+	}
+}
+
+func TestNewRouteRunRequest(t *testing.T) {
+	route := models.RouteToTest{
+		Method:               "POST",
+		RequestAuthorization: "abc",
+		RequestHeaders: models.JSONMapStringString{
+			"cookie":   "session=1",
+			"X-Region": "eu-qwerty",
+		},
+		RequestBody: models.JSONMapStringAny{
+			"user":  map[string]any{"password": "qwerty"},
+			"items": []any{"qwerty", float64(1)},
+		},
+	}
+
+	runRequest := newRouteRunRequest(route, "http://localhost/login?key=qwerty",
+		map[string]string{"password": "qwerty", "empty": ""})
+
+	assert.Equal(t, models.RouteRunRequest{
+		Method: "POST",
+		URL:    "http://localhost/login?key=[REDACTED]",
+		Headers: map[string]string{
+			"cookie":        "[REDACTED]",
+			"X-Region":      "eu-[REDACTED]",
+			"Authorization": "[REDACTED]",
+		},
+		Body: map[string]any{
+			"user":  map[string]any{"password": "[REDACTED]"},
+			"items": []any{"[REDACTED]", float64(1)},
+		},
+	}, runRequest)
+}
+
+func TestRouteStatusService_RunRouteFlow(t *testing.T) {
+	type args struct {
+		name          string
+		options       DTO.RunRouteFlowOptions
+		expectedRun   *DTO.RouteFlowRun
+		expectedError error
+		setupMocks    func() interfaces.RouteRepository
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"abc"}`))
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appID := "27cf4966c158762ceb9495fbdd044a73325efd3bd2a4f9646fc45662ef59490d"
+	routesToTest := []models.RouteToTest{
+		{
+			ID:                 1,
+			IPAddress:          host,
+			Port:               port,
+			Path:               "/login",
+			Method:             "POST",
+			ResponseStatusCode: 200,
+			Extract:            models.RouteVariables{{Name: "token", Selector: "token"}},
+			FlowID:             5,
+			Version:            2,
+		},
+		{
+			ID:                 2,
+			Position:           1,
+			IPAddress:          host,
+			Port:               port,
+			Path:               "/me",
+			Method:             "GET",
+			RequestHeaders:     models.JSONMapStringString{"X-Token": "{{ .vars.token }}"},
+			ResponseStatusCode: 200,
+			FlowID:             5,
+			Version:            2,
+		},
+	}
+	dryRunSteps := []DTO.RouteFlowRunStep{
+		{
+			WorkingRouteID: 1,
+			Status:         "success",
+			Request: models.RouteRunRequest{
+				Method:  "POST",
+				URL:     "http://" + host + ":" + port + "/login?",
+				Headers: map[string]string{},
+				Body:    map[string]any{},
+			},
+			FailedAssertions: []models.FailedAssertion{},
+		},
+		{
+			WorkingRouteID: 2,
+			Position:       1,
+			Status:         "success",
+			Request: models.RouteRunRequest{
+				Method:  "GET",
+				URL:     "http://" + host + ":" + port + "/me?",
+				Headers: map[string]string{"X-Token": "<token>"},
+				Body:    map[string]any{},
+			},
+			FailedAssertions: []models.FailedAssertion{},
+		},
+	}
+
+	testsScenarios := []args{
+		{
+			name:    "Dry run is not sent nor saved",
+			options: DTO.RunRouteFlowOptions{DryRun: true},
+			expectedRun: &DTO.RouteFlowRun{
+				FlowID:  5,
+				Version: 2,
+				Status:  "success",
+				DryRun:  true,
+				Steps:   dryRunSteps,
+			},
+			setupMocks: func() interfaces.RouteRepository {
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("GetRouteFlowRoutesToTest", mock.Anything, 5, appID, 1).Return(routesToTest,
+					nil)
+				return mRouteRepository
+			},
+		},
+		{
+			name:    "Run is saved with the statuses of the steps",
+			options: DTO.RunRouteFlowOptions{},
+			expectedRun: &DTO.RouteFlowRun{
+				ID:      7,
+				FlowID:  5,
+				Version: 2,
+				Status:  "success",
+			},
+			setupMocks: func() interfaces.RouteRepository {
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("GetRouteFlowRoutesToTest", mock.Anything, 5, appID, 1).Return(routesToTest,
+					nil)
+				mRouteRepository.On("UpdateWorkingRoutesStatuses", mock.Anything, map[int]models.RouteStepResult{
+					1: {Status: "success", FailedAssertions: []models.FailedAssertion{}},
+					2: {Status: "success", FailedAssertions: []models.FailedAssertion{}},
+				}).Return(nil)
+				mRouteRepository.On("InsertRouteFlowRuns", mock.Anything, mock.Anything).Return([]int{7}, nil)
+				mRouteRepository.On("UpdateRouteFlowFailureStreak", mock.Anything, 5, false).Return(
+					&models.RouteFlowNotificationState{
+						FlowID:         5,
+						AppID:          appID,
+						NotifiedStatus: models.RouteFlowNotifiedStatusPassing,
+					}, nil)
+				return mRouteRepository
+			},
+		},
+		{
+			name:          "Flow of another user",
+			options:       DTO.RunRouteFlowOptions{},
+			expectedError: models.NewError(404, "RouteFlow", "route flow not found"),
+			setupMocks: func() interfaces.RouteRepository {
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("GetRouteFlowRoutesToTest", mock.Anything, 5, appID, 1).Return(
+					[]models.RouteToTest{}, nil)
+				return mRouteRepository
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			loggerService := tests.CreateLogger()
+			routeRepository := testScenario.setupMocks()
+			routeStatusService := NewRouteStatusService(routeRepository, &fakeAppNotificationsService{}, "test-key", loggerService)
+			routeFlowRun, err := routeStatusService.RunRouteFlow(context.Background(), appID, 1, 5,
+				testScenario.options)
+			if testScenario.expectedError != nil {
+				assert.Equal(t, testScenario.expectedError, err)
+				assert.Nil(t, routeFlowRun)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testScenario.expectedRun.ID, routeFlowRun.ID)
+			assert.Equal(t, testScenario.expectedRun.Status, routeFlowRun.Status)
+			assert.Equal(t, testScenario.expectedRun.DryRun, routeFlowRun.DryRun)
+			if testScenario.expectedRun.Steps != nil {
+				assert.Equal(t, testScenario.expectedRun.Steps, routeFlowRun.Steps)
+			}
+			routeRepository.(*mocks.MockRouteRepository).AssertExpectations(t)
+		})
+	}
+}
+
+func TestRouteStatusService_Shutdown(t *testing.T) {
+	requestReceived := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requestReceived)
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appID := "27cf4966c158762ceb9495fbdd044a73325efd3bd2a4f9646fc45662ef59490d"
+	routesToTest := []models.RouteToTest{
+		{
+			ID:                 1,
+			IPAddress:          host,
+			Port:               port,
+			Path:               "/slow",
+			Method:             "GET",
+			ResponseStatusCode: 200,
+			FlowID:             5,
+			Version:            2,
+		},
+	}
+	routeRepository := new(mocks.MockRouteRepository)
+	routeRepository.On("GetRouteFlowRoutesToTest", mock.Anything, 5, appID, 1).Return(routesToTest, nil)
+	routeRepository.On("InsertRouteFlowRuns", mock.Anything, mock.Anything).Return([]int{7}, nil)
+	routeRepository.On("FinishRouteFlowRun", mock.Anything, mock.MatchedBy(func(routeFlowRun DTO.RouteFlowRun) bool {
+		return routeFlowRun.ID == 7 && routeFlowRun.Status == routeFlowRunInterruptedStatus
+	})).Return(nil).Once()
+	routeRepository.On("UpdateRouteFlowFailureStreak", mock.Anything, 5, true).Return(
+		&models.RouteFlowNotificationState{FlowID: 5, AppID: appID}, nil)
+	routeStatusService := NewRouteStatusService(routeRepository, &fakeAppNotificationsService{}, "test-key",
+		tests.CreateLogger())
+
+	routeFlowRun, err := routeStatusService.RunRouteFlow(context.Background(), appID, 1, 5,
+		DTO.RunRouteFlowOptions{Async: true})
+	assert.NoError(t, err)
+	assert.Equal(t, routeFlowRunRunningStatus, routeFlowRun.Status)
+	<-requestReceived
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, routeStatusService.Shutdown(ctx))
+	routeRepository.AssertExpectations(t)
+}
@@ -2,44 +2,137 @@ package servicesApp
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"runtime"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/models"
 	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
 	"github.com/slodkiadrianek/octopus/internal/utils"
 	"github.com/slodkiadrianek/octopus/internal/utils/request"
+	"github.com/slodkiadrianek/octopus/internal/utils/validation"
 )
 
+const (
+	routeFlowRunRunningStatus = "running"
+	// routeFlowRunFailedStatus is saved for the runs which could not be finished, e.g. because a request could not be
+	// prepared.
+	routeFlowRunFailedStatus = "Failed;To run route flow"
+	// routeFlowRunTimeout limits how long an async run can take.
+	routeFlowRunTimeout = 5 * time.Minute
+	// routeFlowRunSaveTimeout is how long an async run canceled by the shutdown has to save its result.
+	routeFlowRunSaveTimeout = 5 * time.Second
+	// routeFlowRunInterruptedStatus is saved for the async runs which were still running when the API stopped.
+	routeFlowRunInterruptedStatus = "Failed;Interrupted by shutdown"
+	// routeFlowsPerHostLimit is how many flows check the same host at once, so a single app is not flooded.
+	routeFlowsPerHostLimit = 2
+	// routeStepDefaultTimeout is used by the steps of the flows which do not set their own timeoutMs.
+	routeStepDefaultTimeout = 30 * time.Second
+)
+
+// routeHostLimiter limits how many flows run against the same host, the slots of every host are created when the
+// host is checked for the first time.
+type routeHostLimiter struct {
+	mu    sync.Mutex
+	limit int
+	slots map[string]chan struct{}
+}
+
+func newRouteHostLimiter(limit int) *routeHostLimiter {
+	return &routeHostLimiter{
+		limit: limit,
+		slots: make(map[string]chan struct{}),
+	}
+}
+
+func (rl *routeHostLimiter) hostSlots(host string) chan struct{} {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	slots, found := rl.slots[host]
+	if !found {
+		slots = make(chan struct{}, rl.limit)
+		rl.slots[host] = slots
+	}
+	return slots
+}
+
+// acquire waits for a free slot of the host, it gives up when the context is done.
+func (rl *routeHostLimiter) acquire(ctx context.Context, host string) error {
+	select {
+	case rl.hostSlots(host) <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (rl *routeHostLimiter) release(host string) {
+	<-rl.hostSlots(host)
+}
+
 type RouteStatusService struct {
-	routeRepository interfaces.RouteRepository
-	loggerService   utils.LoggerService
+	routeRepository         interfaces.RouteRepository
+	appNotificationsService interfaces.AppNotificationsService
+	encryptionKey           string
+	loggerService           utils.LoggerService
+	// asyncRunsCtx is canceled by Shutdown, the async runs are tracked by asyncRuns.
+	asyncRunsCtx    context.Context
+	cancelAsyncRuns context.CancelFunc
+	asyncRuns       sync.WaitGroup
 }
 
 func NewRouteStatusService(routeRepository interfaces.RouteRepository,
+	appNotificationsService interfaces.AppNotificationsService, encryptionKey string,
 	loggerService utils.LoggerService,
 ) *RouteStatusService {
+	asyncRunsCtx, cancelAsyncRuns := context.WithCancel(context.Background())
 	return &RouteStatusService{
-		routeRepository: routeRepository,
-		loggerService:   loggerService,
+		routeRepository:         routeRepository,
+		appNotificationsService: appNotificationsService,
+		encryptionKey:           encryptionKey,
+		loggerService:           loggerService,
+		asyncRunsCtx:            asyncRunsCtx,
+		cancelAsyncRuns:         cancelAsyncRuns,
 	}
 }
 
-func (rs *RouteStatusService) sortRoutesToTest(routesToTest []models.RouteToTest) map[string][]models.RouteToTest {
-	sortedRoutesToTests := make(map[string][]models.RouteToTest, len(routesToTest))
+func (rs *RouteStatusService) sortRoutesToTest(routesToTest []models.RouteToTest) map[int][]models.RouteToTest {
+	sortedRoutesToTests := make(map[int][]models.RouteToTest, len(routesToTest))
 	for _, routeToTest := range routesToTest {
-		key := routeToTest.Name + routeToTest.AppID
+		flowID := routeToTest.FlowID
 		if routeToTest.ParentID == 0 {
-			sortedRoutesToTests[key] = append([]models.RouteToTest{routeToTest},
-				sortedRoutesToTests[key]...)
+			sortedRoutesToTests[flowID] = append([]models.RouteToTest{routeToTest},
+				sortedRoutesToTests[flowID]...)
 		} else {
-			sortedRoutesToTests[key] = append(sortedRoutesToTests[key], routeToTest)
+			sortedRoutesToTests[flowID] = append(sortedRoutesToTests[flowID], routeToTest)
 		}
 	}
 
 	return sortedRoutesToTests
 }
 
+// mergeNextRouteData sets the data passed from the previous step on top of the data of the step.
+func mergeNextRouteData[T any](data map[string]T, nextRouteData map[string]T) map[string]T {
+	if len(nextRouteData) == 0 {
+		return data
+	}
+
+	mergedData := make(map[string]T, len(data)+len(nextRouteData))
+	maps.Copy(mergedData, data)
+	maps.Copy(mergedData, nextRouteData)
+
+	return mergedData
+}
+
 func (rs *RouteStatusService) addParamsToThePath(path string, params models.JSONMapStringString) string {
 	splittedPath := strings.Split(path, "/")
 	for i := 0; i < len(splittedPath); i++ {
@@ -57,23 +150,118 @@ func (rs *RouteStatusService) addParamsToThePath(path string, params models.JSON
 	return pathWithParamsIncluded
 }
 
-func (rs *RouteStatusService) prepareRouteDataForTestRequest(route models.RouteToTest) (authorizationHeader string, url string, preparedBody []byte, err error) {
-	authorizationHeader = "Bearer " + route.RequestAuthorization
+// routeAuthHeader returns the header with the credentials of the step, the name is empty for a step without them.
+func routeAuthHeader(route models.RouteToTest) (name, value string) {
+	switch route.Auth.Type {
+	case models.RouteAuthTypeBearer:
+		return "Authorization", "Bearer " + route.Auth.Value
+	case models.RouteAuthTypeBasic:
+		credentials := base64.StdEncoding.EncodeToString([]byte(route.Auth.Username + ":" + route.Auth.Password))
+		return "Authorization", "Basic " + credentials
+	case models.RouteAuthTypeAPIKey:
+		name = route.Auth.Name
+		if name == "" {
+			name = "X-Api-Key"
+		}
+		return name, route.Auth.Value
+	case models.RouteAuthTypeCookie:
+		return "Cookie", route.Auth.Name + "=" + route.Auth.Value
+	}
+
+	if route.RequestAuthorization != "" {
+		return "Authorization", "Bearer " + route.RequestAuthorization
+	}
+	return "", ""
+}
+
+// encodeRouteBody returns the body of the step and its content type. The values of a form are written the way they
+// look in JSON.
+func encodeRouteBody(route models.RouteToTest) (body []byte, contentType string, err error) {
+	switch route.BodyType {
+	case models.RouteBodyTypeForm:
+		form := make(url.Values, len(route.RequestBody))
+		for key, val := range route.RequestBody {
+			form.Set(key, stringifyJSONValue(val))
+		}
+		return []byte(form.Encode()), "application/x-www-form-urlencoded", nil
+	case models.RouteBodyTypeRaw:
+		return []byte(route.RawBody), "text/plain; charset=UTF-8", nil
+	default:
+		body, err = utils.MarshalData(route.RequestBody)
+		return body, "application/json; charset=UTF-8", err
+	}
+}
+
+// routeFlowHost returns the host checked by the flow. A base URL which uses the env is rendered, the one which cannot
+// be rendered without the secrets is used as it is.
+func routeFlowHost(route models.RouteToTest) string {
+	baseURL := route.HTTPOptions.BaseURL
+	if baseURL == "" {
+		return net.JoinHostPort(route.IPAddress, route.Port)
+	}
+	if renderedBaseURL, err := newRouteTemplateData(route.Env, nil).renderString(baseURL); err == nil {
+		baseURL = renderedBaseURL
+	}
+	if parsedURL, err := url.Parse(baseURL); err == nil && parsedURL.Host != "" {
+		return parsedURL.Host
+	}
+	return baseURL
+}
+
+// routeStepTimeout returns how long a single step of the flow can take.
+func routeStepTimeout(route models.RouteToTest) time.Duration {
+	if route.HTTPOptions.TimeoutMs > 0 {
+		return time.Duration(route.HTTPOptions.TimeoutMs) * time.Millisecond
+	}
+	return routeStepDefaultTimeout
+}
+
+// routeBaseURL returns the base URL of the flow or the address of the app when the flow does not have one.
+func routeBaseURL(route models.RouteToTest) string {
+	if route.HTTPOptions.BaseURL != "" {
+		return strings.TrimSuffix(route.HTTPOptions.BaseURL, "/")
+	}
+	return "http://" + route.IPAddress + ":" + route.Port
+}
+
+// prepareRouteDataForTestRequest builds the request of the step. The headers of the step are set last, so they can
+// replace the content type and the credentials.
+func (rs *RouteStatusService) prepareRouteDataForTestRequest(route models.RouteToTest) (request.HTTPRequest, error) {
 	query := make([]string, 0, len(route.RequestQuery))
 	for key, val := range route.RequestQuery {
 		query = append(query, key+"="+val)
 	}
 
 	path := rs.addParamsToThePath(route.Path, route.RequestParams)
-	url = "http://" + route.IPAddress + ":" + route.Port + path + "?" + strings.Join(query, "&")
+	requestURL := routeBaseURL(route) + path + "?" + strings.Join(query, "&")
 
-	preparedBody, err = utils.MarshalData(route.RequestBody)
+	body, contentType, err := encodeRouteBody(route)
 	if err != nil {
 		rs.loggerService.Error("failed to marshal webhook payload", err)
-		return "", "", nil, err
+		return request.HTTPRequest{}, err
 	}
 
-	return authorizationHeader, url, preparedBody, nil
+	headers := make(map[string]string, len(route.RequestHeaders)+2)
+	headers["Content-Type"] = contentType
+	if name, value := routeAuthHeader(route); name != "" {
+		headers[name] = value
+	}
+	maps.Copy(headers, route.RequestHeaders)
+
+	httpOptions := route.HTTPOptions
+	return request.HTTPRequest{
+		Method:          route.Method,
+		URL:             requestURL,
+		Headers:         headers,
+		Body:            body,
+		Timeout:         time.Duration(httpOptions.TimeoutMs) * time.Millisecond,
+		FollowRedirects: httpOptions.RedirectPolicy != models.RouteRedirectPolicyNone,
+		MaxRedirects:    httpOptions.MaxRedirects,
+		TLS: request.TLSOptions{
+			InsecureSkipVerify: httpOptions.InsecureSkipVerify,
+			CACertificate:      httpOptions.CACertificate,
+		},
+	}, nil
 }
 
 func (rs *RouteStatusService) prepareDataForTheNextRoute(route models.RouteToTest, key string,
@@ -118,6 +306,141 @@ func (rs *RouteStatusService) prepareDataForTheNextRoute(route models.RouteToTes
 	return nextRouteBody, nextRouteParams, nextRouteQuery, nextRouteAuthorizationHeader, routeStatus
 }
 
+// runRouteFlow sends the requests of the steps one after another and stops at the first failed step. The run has
+// the result of every step which was run.
+func (rs *RouteStatusService) runRouteFlow(ctx context.Context,
+	routesToTest []models.RouteToTest,
+) (routeFlowRun DTO.RouteFlowRun, err error) {
+	routeFlowRun = DTO.RouteFlowRun{
+		FlowID:    routesToTest[0].FlowID,
+		Version:   routesToTest[0].Version,
+		Status:    "success",
+		StartedAt: time.Now().UTC(),
+		Steps:     make([]DTO.RouteFlowRunStep, 0, len(routesToTest)),
+	}
+	addStep := func(step DTO.RouteFlowRunStep) {
+		routeFlowRun.Steps = append(routeFlowRun.Steps, step)
+		if step.Status != "success" {
+			routeFlowRun.Status = step.Status
+		}
+	}
+	defer func() {
+		routeFlowRun.DurationMs = time.Since(routeFlowRun.StartedAt).Milliseconds()
+	}()
+
+	secrets, err := decryptRouteFlowSecrets(routesToTest[0].Secrets, rs.encryptionKey)
+	if err != nil {
+		rs.loggerService.Error("failed to decrypt route flow secrets", map[string]any{
+			"flowID": routesToTest[0].FlowID,
+			"error":  err.Error(),
+		})
+		addStep(newRouteFlowRunStep(routesToTest[0], "Failed;To decrypt secrets", "failed to decrypt secrets"))
+		return routeFlowRun, nil
+	}
+	templateData := newRouteTemplateData(routesToTest[0].Env, secrets)
+
+	nextRouteBody := make(map[string]any)
+	nextRouteParams := make(map[string]string)
+	nextRouteQuery := make(map[string]string)
+	nextRouteAuthorizationHeader := ""
+
+	for _, route := range routesToTest {
+		if err := ctx.Err(); err != nil {
+			return routeFlowRun, err
+		}
+		route.RequestBody = mergeNextRouteData(route.RequestBody, nextRouteBody)
+		route.RequestParams = mergeNextRouteData(route.RequestParams, nextRouteParams)
+		route.RequestQuery = mergeNextRouteData(route.RequestQuery, nextRouteQuery)
+
+		if len(nextRouteAuthorizationHeader) > 0 {
+			route.RequestAuthorization = nextRouteAuthorizationHeader
+		}
+
+		route, err = renderRouteTemplates(route, templateData)
+		if err != nil {
+			rs.loggerService.Info("failed to render route templates", map[string]any{
+				"routeID": route.ID,
+				"error":   err.Error(),
+			})
+			addStep(newRouteFlowRunStep(route, "Failed;To render templates", redactRouteSecrets(err.Error(),
+				secrets)))
+			return routeFlowRun, nil
+		}
+
+		httpRequest, err := rs.prepareRouteDataForTestRequest(route)
+		if err != nil {
+			return routeFlowRun, err
+		}
+
+		step := newRouteFlowRunStep(route, "success", "")
+		step.Request = newRouteRunRequest(route, httpRequest.URL, secrets)
+
+		stepCtx, cancel := context.WithTimeout(ctx, routeStepTimeout(route))
+		httpResponse, err := request.SendHTTPRequest(stepCtx, httpRequest)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return routeFlowRun, ctx.Err()
+			}
+			rs.loggerService.Info("Failed to check route", map[string]any{
+				"url":    step.Request.URL,
+				"method": route.Method,
+			})
+			step.Status = "Failed;To check route"
+			step.Error = redactRouteSecrets(err.Error(), secrets)
+			addStep(step)
+			return routeFlowRun, nil
+		}
+		step.ResponseStatusCode = httpResponse.StatusCode
+		step.ResponseBody = redactRouteSecrets(routeResponseBodySnippet(httpResponse.RawBody), secrets)
+		step.LatencyMs = httpResponse.Latency.Milliseconds()
+
+		stepResponse := routeResponse{
+			statusCode: httpResponse.StatusCode,
+			header:     httpResponse.Header,
+			body:       httpResponse.Body,
+			latency:    httpResponse.Latency,
+		}
+		failedAssertions := evaluateRouteAssertions(route, stepResponse)
+		if len(failedAssertions) == 0 {
+			failedAssertions = extractRouteVariables(route, stepResponse, templateData)
+		}
+		if len(failedAssertions) > 0 {
+			step.Status = fmt.Sprintf("Failed;%d assertions failed", len(failedAssertions))
+			step.FailedAssertions = failedAssertions
+			addStep(step)
+			return routeFlowRun, nil
+		}
+
+		// The data of every field is gathered, so the next step gets all of them and not only the last one.
+		nextRouteBody = make(map[string]any)
+		nextRouteParams = make(map[string]string)
+		nextRouteQuery = make(map[string]string)
+		nextRouteAuthorizationHeader = ""
+		responseBody, _ := httpResponse.Body.(map[string]any)
+		for key, val := range responseBody {
+			body, params, query, authorizationHeader, status := rs.prepareDataForTheNextRoute(route, key, val)
+			if strings.HasPrefix(status, "Failed") {
+				step.Status = status
+				break
+			}
+			maps.Copy(nextRouteBody, body)
+			maps.Copy(nextRouteParams, params)
+			maps.Copy(nextRouteQuery, query)
+			if authorizationHeader != "" {
+				nextRouteAuthorizationHeader = authorizationHeader
+			}
+		}
+
+		addStep(step)
+		if step.Status != "success" {
+			return routeFlowRun, nil
+		}
+	}
+
+	return routeFlowRun, nil
+}
+
 func (rs *RouteStatusService) CheckRoutesStatus(ctx context.Context) error {
 	rs.loggerService.Info("started checking statuses of the routes")
 
@@ -132,80 +455,291 @@ func (rs *RouteStatusService) CheckRoutesStatus(ctx context.Context) error {
 
 	sortedRoutesToTests := rs.sortRoutesToTest(routesToTest)
 
-	routesStatuses := make(map[int]string)
-	for _, routesToTest := range sortedRoutesToTests {
-		nextRouteBody := make(map[string]any)
-		nextRouteParams := make(map[string]string)
-		nextRouteQuery := make(map[string]string)
-		nextRouteAuthorizationHeader := ""
+	routeFlowRuns, err := rs.runRouteFlows(ctx, sortedRoutesToTests)
+	if err != nil {
+		return err
+	}
+	routesStatuses := make(map[int]models.RouteStepResult)
+	for _, routeFlowRun := range routeFlowRuns {
+		addRouteStepResults(routesStatuses, routeFlowRun)
+	}
+	rs.loggerService.Info("the routes statuses have started inserting into database", routesStatuses)
 
-		for _, route := range routesToTest {
-			routeStatus := "unknown"
+	if len(routesStatuses) > 0 {
+		err = rs.routeRepository.UpdateWorkingRoutesStatuses(ctx, routesStatuses)
+		if err != nil {
+			return err
+		}
+	}
 
-			if len(nextRouteBody) > 0 {
-				route.RequestBody = nextRouteBody
-			}
+	_, err = rs.routeRepository.InsertRouteFlowRuns(ctx, routeFlowRuns)
+	if err != nil {
+		return err
+	}
+	rs.notifyRouteFlowRuns(ctx, routeFlowRuns)
 
-			if len(nextRouteParams) > 0 {
-				route.RequestParams = nextRouteParams
-			}
+	rs.loggerService.Info("the route statuses have finished inserting into the database.", routesStatuses)
 
-			if len(nextRouteQuery) > 0 {
-				route.RequestQuery = nextRouteQuery
-			}
+	return nil
+}
 
-			if len(nextRouteAuthorizationHeader) > 0 {
-				route.RequestAuthorization = nextRouteAuthorizationHeader
+// runRouteFlows runs the flows on a pool of workers, the flows of the same host wait for each other above
+// routeFlowsPerHostLimit. The runs are not saved when the context is done, so the flows stopped half-way are not
+// reported as failed.
+func (rs *RouteStatusService) runRouteFlows(ctx context.Context,
+	sortedRoutesToTests map[int][]models.RouteToTest,
+) ([]DTO.RouteFlowRun, error) {
+	jobs := make(chan []models.RouteToTest, len(sortedRoutesToTests))
+	routeFlowRunsChan := make(chan DTO.RouteFlowRun, len(sortedRoutesToTests))
+	errorsChan := make(chan error, len(sortedRoutesToTests))
+	hostLimiter := newRouteHostLimiter(routeFlowsPerHostLimit)
+
+	workerCount := min(runtime.NumCPU(), len(sortedRoutesToTests))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				host := routeFlowHost(job[0])
+				if err := hostLimiter.acquire(ctx, host); err != nil {
+					errorsChan <- err
+					continue
+				}
+
+				routeFlowRun, err := rs.runRouteFlow(ctx, job)
+				hostLimiter.release(host)
+				if err != nil && ctx.Err() != nil {
+					errorsChan <- err
+					continue
+				}
+				// A flow which could not be run fails on its own, the runs of the other flows are kept.
+				if err != nil {
+					routeFlowRun.Status = routeFlowRunFailedStatus
+					rs.loggerService.Error("failed to run route flow", map[string]any{
+						"flowID": job[0].FlowID,
+						"error":  err.Error(),
+					})
+				}
+				routeFlowRunsChan <- routeFlowRun
 			}
+		}()
+	}
 
-			authorizationHeader, url, body, err := rs.prepareRouteDataForTestRequest(route)
-			if err != nil {
-				return err
-			}
+	for _, routesToTest := range sortedRoutesToTests {
+		jobs <- routesToTest
+	}
+	close(jobs)
 
-			responseStatusCode, responseBody, err := request.SendHTTP(ctx, url, authorizationHeader, route.Method,
-				body, true)
-			if err != nil {
-				rs.loggerService.Info("Failed to check route", map[string]any{
-					"url":    url,
-					"method": route.Method,
-					"body":   body,
-				})
-				routeStatus = "Failed;To check route"
-				routesStatuses[route.ID] = routeStatus
-				break
-			}
+	wg.Wait()
+	close(routeFlowRunsChan)
+	close(errorsChan)
 
-			if len(responseBody) != len(route.ResponseBody) {
-				routeStatus = "Failed;Different body"
-				routesStatuses[route.ID] = routeStatus
-				break
-			}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err, found := <-errorsChan; found {
+		return nil, err
+	}
 
-			if responseStatusCode != route.ResponseStatusCode {
-				routeStatus = "Failed;Status Code"
-				routesStatuses[route.ID] = routeStatus
-				break
-			}
+	routeFlowRuns := make([]DTO.RouteFlowRun, 0, len(sortedRoutesToTests))
+	for routeFlowRun := range routeFlowRunsChan {
+		routeFlowRuns = append(routeFlowRuns, routeFlowRun)
+	}
+	return routeFlowRuns, nil
+}
 
-			for key, val := range responseBody {
-				nextRouteBody, nextRouteParams, nextRouteQuery, nextRouteAuthorizationHeader,
-					routeStatus = rs.prepareDataForTheNextRoute(route, key, val)
+func addRouteStepResults(routesStatuses map[int]models.RouteStepResult, routeFlowRun DTO.RouteFlowRun) {
+	for _, step := range routeFlowRun.Steps {
+		routesStatuses[step.WorkingRouteID] = models.RouteStepResult{
+			Status:           step.Status,
+			FailedAssertions: step.FailedAssertions,
+		}
+	}
+}
+
+// dryRunRouteFlow renders the requests of the steps and checks their assertions without sending anything. The
+// variables are not known before the requests are sent, so they are rendered as <name>.
+func (rs *RouteStatusService) dryRunRouteFlow(routesToTest []models.RouteToTest) (DTO.RouteFlowRun, error) {
+	routeFlowRun := DTO.RouteFlowRun{
+		FlowID:    routesToTest[0].FlowID,
+		Version:   routesToTest[0].Version,
+		Status:    "success",
+		DryRun:    true,
+		StartedAt: time.Now().UTC(),
+		Steps:     make([]DTO.RouteFlowRunStep, 0, len(routesToTest)),
+	}
+
+	secrets, err := decryptRouteFlowSecrets(routesToTest[0].Secrets, rs.encryptionKey)
+	if err != nil {
+		rs.loggerService.Error("failed to decrypt route flow secrets", map[string]any{
+			"flowID": routesToTest[0].FlowID,
+			"error":  err.Error(),
+		})
+		return routeFlowRun, models.NewError(500, "Encryption", "failed to decrypt route flow secrets")
+	}
+	templateData := newRouteTemplateData(routesToTest[0].Env, secrets)
+
+	for _, route := range routesToTest {
+		step := newRouteFlowRunStep(route, "success", "")
+
+		renderedRoute, err := renderRouteTemplates(route, templateData)
+		if err != nil {
+			step.Status = "Failed;To render templates"
+			step.Error = redactRouteSecrets(err.Error(), secrets)
+		} else if err := validation.CheckRouteAssertions(DTO.CreateRoute{
+			Assertions:     renderedRoute.Assertions,
+			ResponseSchema: renderedRoute.ResponseSchema,
+		}); err != nil {
+			step.Status = "Failed;Invalid assertions"
+			step.Error = err.Error()
+		} else {
+			httpRequest, err := rs.prepareRouteDataForTestRequest(renderedRoute)
+			if err != nil {
+				return routeFlowRun, err
 			}
+			step.Request = newRouteRunRequest(renderedRoute, httpRequest.URL, secrets)
+		}
 
-			routeStatus = "success"
-			routesStatuses[route.ID] = routeStatus
+		routeFlowRun.Steps = append(routeFlowRun.Steps, step)
+		if step.Status != "success" && routeFlowRun.Status == "success" {
+			routeFlowRun.Status = step.Status
+		}
+		for _, variable := range route.Extract {
+			templateData.vars[variable.Name] = "<" + variable.Name + ">"
 		}
+	}
+	routeFlowRun.DurationMs = time.Since(routeFlowRun.StartedAt).Milliseconds()
+
+	return routeFlowRun, nil
+}
 
+// saveRouteFlowRun updates the statuses of the steps and saves the run, an async run was added before it started.
+// The finished run is counted for the notifications of the flow.
+func (rs *RouteStatusService) saveRouteFlowRun(ctx context.Context, routeFlowRun *DTO.RouteFlowRun) error {
+	routesStatuses := make(map[int]models.RouteStepResult, len(routeFlowRun.Steps))
+	addRouteStepResults(routesStatuses, *routeFlowRun)
+	if len(routesStatuses) > 0 {
+		if err := rs.routeRepository.UpdateWorkingRoutesStatuses(ctx, routesStatuses); err != nil {
+			return err
+		}
 	}
-	rs.loggerService.Info("the routes statuses have started inserting into database", routesStatuses)
 
-	err = rs.routeRepository.UpdateWorkingRoutesStatuses(ctx, routesStatuses)
+	if routeFlowRun.ID != 0 {
+		if err := rs.routeRepository.FinishRouteFlowRun(ctx, *routeFlowRun); err != nil {
+			return err
+		}
+	} else {
+		runIDs, err := rs.routeRepository.InsertRouteFlowRuns(ctx, []DTO.RouteFlowRun{*routeFlowRun})
+		if err != nil {
+			return err
+		}
+		routeFlowRun.ID = runIDs[0]
+	}
+	rs.notifyRouteFlowRuns(ctx, []DTO.RouteFlowRun{*routeFlowRun})
+
+	return nil
+}
+
+// RunRouteFlow checks a single flow on demand. A dry run is returned without being saved, an async run is returned
+// with the running status and finishes in the background.
+func (rs *RouteStatusService) RunRouteFlow(ctx context.Context, appID string, ownerID, flowID int,
+	options DTO.RunRouteFlowOptions,
+) (*DTO.RouteFlowRun, error) {
+	routesToTest, err := rs.routeRepository.GetRouteFlowRoutesToTest(ctx, flowID, appID, ownerID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(routesToTest) == 0 {
+		return nil, models.NewError(404, "RouteFlow", "route flow not found")
 	}
 
-	rs.loggerService.Info("the route statuses have finished inserting into the database.", routesStatuses)
+	if options.DryRun {
+		routeFlowRun, err := rs.dryRunRouteFlow(routesToTest)
+		if err != nil {
+			return nil, err
+		}
+		return &routeFlowRun, nil
+	}
 
-	return nil
+	if !options.Async {
+		routeFlowRun, err := rs.runRouteFlow(ctx, routesToTest)
+		if err != nil {
+			return nil, err
+		}
+		if err := rs.saveRouteFlowRun(ctx, &routeFlowRun); err != nil {
+			return nil, err
+		}
+		return &routeFlowRun, nil
+	}
+
+	runningRouteFlowRun := DTO.RouteFlowRun{
+		FlowID:    flowID,
+		Version:   routesToTest[0].Version,
+		Status:    routeFlowRunRunningStatus,
+		StartedAt: time.Now().UTC(),
+		Steps:     []DTO.RouteFlowRunStep{},
+	}
+	if err := rs.saveRouteFlowRun(ctx, &runningRouteFlowRun); err != nil {
+		return nil, err
+	}
+
+	rs.asyncRuns.Add(1)
+	go func() {
+		defer rs.asyncRuns.Done()
+		// The run outlives the request, so it gets its own context, which is canceled when the API stops.
+		runCtx, cancel := context.WithTimeout(rs.asyncRunsCtx, routeFlowRunTimeout)
+		defer cancel()
+
+		routeFlowRun, err := rs.runRouteFlow(runCtx, routesToTest)
+		if err != nil {
+			routeFlowRun.Status = routeFlowRunFailedStatus
+			rs.loggerService.Error("failed to run route flow", map[string]any{
+				"flowID": flowID,
+				"error":  err.Error(),
+			})
+		}
+		if rs.asyncRunsCtx.Err() != nil {
+			routeFlowRun.Status = routeFlowRunInterruptedStatus
+		}
+		routeFlowRun.ID = runningRouteFlowRun.ID
+		// The result is saved even when the run was canceled.
+		saveCtx, saveCancel := context.WithTimeout(context.WithoutCancel(runCtx), routeFlowRunSaveTimeout)
+		defer saveCancel()
+		if err := rs.saveRouteFlowRun(saveCtx, &routeFlowRun); err != nil {
+			rs.loggerService.Error("failed to save route flow run", map[string]any{
+				"flowID": flowID,
+				"runID":  routeFlowRun.ID,
+				"error":  err.Error(),
+			})
+		}
+	}()
+
+	return &runningRouteFlowRun, nil
+}
+
+// Shutdown cancels the async runs and waits until they save their results or the context is done.
+func (rs *RouteStatusService) Shutdown(ctx context.Context) error {
+	rs.cancelAsyncRuns()
+
+	done := make(chan struct{})
+	go func() {
+		rs.asyncRuns.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FailInterruptedRouteFlowRuns fails the async runs left running by an API which was not stopped cleanly. It is
+// called when the API starts, before any new run.
+func (rs *RouteStatusService) FailInterruptedRouteFlowRuns(ctx context.Context) error {
+	return rs.routeRepository.FailRunningRouteFlowRuns(ctx, routeFlowRunRunningStatus, routeFlowRunInterruptedStatus)
 }
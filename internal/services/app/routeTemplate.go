@@ -0,0 +1,198 @@
+package servicesApp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+// routeVariableTemplateRegex matches a value which is a single variable, such a value of the body keeps the type of
+// the extracted one.
+var routeVariableTemplateRegex = regexp.MustCompile(`^\{\{\s*\.vars\.(\w+)\s*\}\}$`)
+
+// routeTemplateData is what the templates of the steps can use, the variables are extracted by the earlier steps.
+type routeTemplateData struct {
+	vars    map[string]any
+	env     map[string]string
+	secrets map[string]string
+}
+
+func newRouteTemplateData(env, secrets map[string]string) *routeTemplateData {
+	return &routeTemplateData{
+		vars:    make(map[string]any),
+		env:     env,
+		secrets: secrets,
+	}
+}
+
+// decryptRouteFlowSecrets returns the secrets of the flow, a flow without the secrets has an empty string saved.
+func decryptRouteFlowSecrets(encryptedSecrets, encryptionKey string) (map[string]string, error) {
+	if encryptedSecrets == "" {
+		return map[string]string{}, nil
+	}
+
+	decryptedSecrets, err := utils.Decrypt(encryptedSecrets, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := utils.UnmarshalData[map[string]string]([]byte(decryptedSecrets))
+	if err != nil {
+		return nil, err
+	}
+
+	return *secrets, nil
+}
+
+func (td *routeTemplateData) renderString(text string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("route").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	// The variables are printed the way they look in JSON, so the numbers are not written in the exponent form.
+	vars := make(map[string]string, len(td.vars))
+	for name, val := range td.vars {
+		vars[name] = stringifyJSONValue(val)
+	}
+
+	var renderedText strings.Builder
+	err = tmpl.Execute(&renderedText, map[string]any{
+		"vars":    vars,
+		"env":     td.env,
+		"secrets": td.secrets,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return renderedText.String(), nil
+}
+
+func (td *routeTemplateData) renderStrings(values map[string]string) (map[string]string, error) {
+	renderedValues := make(map[string]string, len(values))
+	for key, val := range values {
+		renderedValue, err := td.renderString(val)
+		if err != nil {
+			return nil, err
+		}
+		renderedValues[key] = renderedValue
+	}
+
+	return renderedValues, nil
+}
+
+func (td *routeTemplateData) renderBody(body any) (any, error) {
+	switch typedBody := body.(type) {
+	case string:
+		if match := routeVariableTemplateRegex.FindStringSubmatch(typedBody); match != nil {
+			val, ok := td.vars[match[1]]
+			if !ok {
+				return nil, fmt.Errorf("variable %q was not extracted", match[1])
+			}
+			return val, nil
+		}
+		return td.renderString(typedBody)
+	case map[string]any:
+		renderedBody := make(map[string]any, len(typedBody))
+		for key, val := range typedBody {
+			renderedValue, err := td.renderBody(val)
+			if err != nil {
+				return nil, err
+			}
+			renderedBody[key] = renderedValue
+		}
+		return renderedBody, nil
+	case []any:
+		renderedBody := make([]any, len(typedBody))
+		for i, val := range typedBody {
+			renderedValue, err := td.renderBody(val)
+			if err != nil {
+				return nil, err
+			}
+			renderedBody[i] = renderedValue
+		}
+		return renderedBody, nil
+	default:
+		return body, nil
+	}
+}
+
+// renderRouteTemplates fills the templates of the path, params, query, headers, credentials and body of the step and
+// the base URL of the flow, the step itself is left untouched.
+func renderRouteTemplates(route models.RouteToTest, templateData *routeTemplateData) (models.RouteToTest, error) {
+	var err error
+	if route.Path, err = templateData.renderString(route.Path); err != nil {
+		return route, err
+	}
+	if route.RequestAuthorization, err = templateData.renderString(route.RequestAuthorization); err != nil {
+		return route, err
+	}
+	if route.RequestParams, err = templateData.renderStrings(route.RequestParams); err != nil {
+		return route, err
+	}
+	if route.RequestQuery, err = templateData.renderStrings(route.RequestQuery); err != nil {
+		return route, err
+	}
+	if route.RequestHeaders, err = templateData.renderStrings(route.RequestHeaders); err != nil {
+		return route, err
+	}
+	for _, text := range []*string{&route.Auth.Username, &route.Auth.Password, &route.Auth.Value, &route.RawBody,
+		&route.HTTPOptions.BaseURL} {
+		if *text, err = templateData.renderString(*text); err != nil {
+			return route, err
+		}
+	}
+
+	renderedBody, err := templateData.renderBody(map[string]any(route.RequestBody))
+	if err != nil {
+		return route, err
+	}
+	route.RequestBody = renderedBody.(map[string]any)
+
+	return route, nil
+}
+
+// extractRouteVariables adds the variables of the step to the template data, every variable which is missing in the
+// response is reported.
+func extractRouteVariables(route models.RouteToTest, response routeResponse,
+	templateData *routeTemplateData,
+) []models.FailedAssertion {
+	failedAssertions := make([]models.FailedAssertion, 0)
+
+	for _, variable := range route.Extract {
+		var val any
+		var found bool
+		if variable.Source == models.RouteAssertionSourceHeader {
+			headerValues := response.header.Values(variable.Selector)
+			val, found = strings.Join(headerValues, ", "), len(headerValues) > 0
+		} else {
+			val, found = selectJSONValue(response.body, variable.Selector)
+		}
+
+		if !found {
+			source := variable.Source
+			if source == "" {
+				source = models.RouteAssertionSourceBody
+			}
+			failedAssertions = append(failedAssertions, models.FailedAssertion{
+				Source:   "extract",
+				Selector: variable.Selector,
+				Operator: models.RouteAssertionOperatorExists,
+				Expected: variable.Name,
+				Message:  fmt.Sprintf("value of the variable is missing in the response %s", source),
+			})
+			continue
+		}
+		templateData.vars[variable.Name] = val
+	}
+
+	return failedAssertions
+}
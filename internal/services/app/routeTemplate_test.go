@@ -0,0 +1,144 @@
+package servicesApp
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderRouteTemplates(t *testing.T) {
+	type args struct {
+		name          string
+		route         models.RouteToTest
+		expectedRoute models.RouteToTest
+		expectedError error
+	}
+
+	templateData := newRouteTemplateData(map[string]string{"region": "eu"}, map[string]string{"token": "s3cret"})
+	templateData.vars["userID"] = float64(1000000)
+	templateData.vars["user"] = map[string]any{"name": "adrian"}
+
+	testsScenarios := []args{
+		{
+			name: "Every part of the request is rendered",
+			route: models.RouteToTest{
+				Path:                 "/users/{{ .vars.userID }}",
+				RequestAuthorization: "{{ .secrets.token }}",
+				RequestQuery:         models.JSONMapStringString{"region": "{{ .env.region }}"},
+				RequestParams:        models.JSONMapStringString{"id": "{{ .vars.userID }}"},
+				RequestHeaders:       models.JSONMapStringString{"X-User": "user-{{ .vars.userID }}"},
+				RequestBody: models.JSONMapStringAny{
+					"id":     "{{ .vars.userID }}",
+					"owner":  "{{.vars.user}}",
+					"items":  []any{"{{ .env.region }}", float64(2)},
+					"static": true,
+				},
+			},
+			expectedRoute: models.RouteToTest{
+				Path:                 "/users/1000000",
+				RequestAuthorization: "s3cret",
+				RequestQuery:         models.JSONMapStringString{"region": "eu"},
+				RequestParams:        models.JSONMapStringString{"id": "1000000"},
+				RequestHeaders:       models.JSONMapStringString{"X-User": "user-1000000"},
+				RequestBody: models.JSONMapStringAny{
+					"id":     float64(1000000),
+					"owner":  map[string]any{"name": "adrian"},
+					"items":  []any{"eu", float64(2)},
+					"static": true,
+				},
+			},
+		},
+		{
+			name: "Missing variable",
+			route: models.RouteToTest{
+				Path:           "/users/{{ .vars.postID }}",
+				RequestQuery:   models.JSONMapStringString{},
+				RequestParams:  models.JSONMapStringString{},
+				RequestHeaders: models.JSONMapStringString{},
+			},
+			expectedError: errors.New(`template: route:1:15: executing "route" at <.vars.postID>: map has no entry for key "postID"`),
+		},
+		{
+			name: "Request without templates",
+			route: models.RouteToTest{
+				Path:        "/health",
+				RequestBody: models.JSONMapStringAny{"id": float64(1)},
+			},
+			expectedRoute: models.RouteToTest{
+				Path:           "/health",
+				RequestQuery:   models.JSONMapStringString{},
+				RequestParams:  models.JSONMapStringString{},
+				RequestHeaders: models.JSONMapStringString{},
+				RequestBody:    models.JSONMapStringAny{"id": float64(1)},
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			route, err := renderRouteTemplates(testScenario.route, templateData)
+			if testScenario.expectedError != nil {
+				assert.EqualError(t, err, testScenario.expectedError.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testScenario.expectedRoute, route)
+		})
+	}
+}
+
+func TestExtractRouteVariables(t *testing.T) {
+	type args struct {
+		name                     string
+		extract                  models.RouteVariables
+		expectedVars             map[string]any
+		expectedFailedAssertions []models.FailedAssertion
+	}
+
+	response := routeResponse{
+		header: http.Header{"X-Request-Id": []string{"abc"}},
+		body: map[string]any{
+			"data": map[string]any{"items": []any{map[string]any{"id": float64(7)}}},
+		},
+	}
+
+	testsScenarios := []args{
+		{
+			name: "Variables from the body and the headers",
+			extract: models.RouteVariables{
+				{Name: "itemID", Selector: "data.items.0.id"},
+				{Name: "itemsCount", Selector: "$.data.items.#"},
+				{Name: "requestID", Source: models.RouteAssertionSourceHeader, Selector: "x-request-id"},
+			},
+			expectedVars: map[string]any{
+				"itemID":     float64(7),
+				"itemsCount": float64(1),
+				"requestID":  "abc",
+			},
+			expectedFailedAssertions: []models.FailedAssertion{},
+		},
+		{
+			name: "Missing variable is reported",
+			extract: models.RouteVariables{
+				{Name: "itemID", Selector: "data.items.0.id"},
+				{Name: "token", Source: models.RouteAssertionSourceHeader, Selector: "X-Token"},
+			},
+			expectedVars: map[string]any{"itemID": float64(7)},
+			expectedFailedAssertions: []models.FailedAssertion{
+				{Source: "extract", Selector: "X-Token", Operator: "exists", Expected: "token",
+					Message: "value of the variable is missing in the response header"},
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			templateData := newRouteTemplateData(nil, nil)
+			failedAssertions := extractRouteVariables(models.RouteToTest{Extract: testScenario.extract}, response,
+				templateData)
+			assert.Equal(t, testScenario.expectedVars, templateData.vars)
+			assert.Equal(t, testScenario.expectedFailedAssertions, failedAssertions)
+		})
+	}
+}
@@ -2,16 +2,24 @@ package servicesApp
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
 	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
 	"github.com/slodkiadrianek/octopus/tests"
 	"github.com/slodkiadrianek/octopus/tests/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+const emptyHTTPOptions = `{"baseURL":"","timeoutMs":0,"redirectPolicy":"","maxRedirects":0,` +
+	`"insecureSkipVerify":false,"caCertificate":""}`
+
+const emptyNotifications = `{"muted":false,"failureThreshold":0,"cooldownSeconds":0}`
+
 func TestRouteService_prepareDataAboutRouteToInsertToDb(t *testing.T) {
 	type args struct {
 		name                  string
@@ -81,7 +89,7 @@ func TestRouteService_prepareDataAboutRouteToInsertToDb(t *testing.T) {
 		t.Run(testScenario.name, func(t *testing.T) {
 			loggerService := tests.CreateLogger()
 			routeRepository := new(mocks.MockRouteRepository)
-			routeService := NewRouteService(loggerService, routeRepository)
+			routeService := NewRouteService(loggerService, routeRepository, new(mocks.MockAppRepository), "test-key")
 			nextRoutes, requestRoutes, responseRoutes, routeInfo, err := routeService.prepareDataAboutRouteToInsertToDb(testScenario.routes)
 			assert.Equal(t, testScenario.expectedNextRoutes, nextRoutes)
 			assert.Equal(t, testScenario.expectedRouteRequest, requestRoutes)
@@ -156,7 +164,7 @@ func TestRouteService_saveRouteComponents(t *testing.T) {
 			ctx := context.Background()
 			loggerService := tests.CreateLogger()
 			routeRepository := testScenario.setupMocks()
-			routeService := NewRouteService(loggerService, routeRepository)
+			routeService := NewRouteService(loggerService, routeRepository, new(mocks.MockAppRepository), "test-key")
 			routesInfoIDs, routesRequestsIDs, routesResponsesIDs, nextRoutesDataIDs, err := routeService.saveRouteComponents(ctx, testScenario.nextRoutes, testScenario.routeRequest, testScenario.routeResponse, testScenario.routeInfo)
 			assert.Equal(t, testScenario.expectedNextRouteDataIDs, nextRoutesDataIDs)
 			assert.Equal(t, testScenario.expectedError, err)
@@ -167,7 +175,7 @@ func TestRouteService_saveRouteComponents(t *testing.T) {
 	}
 }
 
-func TestRouteService_saveWorkingRoutes(t *testing.T) {
+func TestRouteService_prepareWorkingRoutes(t *testing.T) {
 	type args struct {
 		name                  string
 		routes                *[]DTO.CreateRoute
@@ -178,37 +186,41 @@ func TestRouteService_saveWorkingRoutes(t *testing.T) {
 		routesResponsesIDs    []int
 		nextRouteDataIDs      []int
 		expectedError         error
-		setupMocks            func() interfaces.RouteRepository
+		expectedRouteIDs      []int
 	}
 
 	testsScenarios := []args{
 		{
-			name:                  "Properly saved working routes",
+			name:                  "Properly prepared working routes",
 			appID:                 "27cf4966c158762ceb9495fbdd044a73325efd3bd2a4f9646fc45662ef59490d",
 			nameOfTheWorkingRoute: "test",
-			routes: &[]DTO.CreateRoute{{
-				ParentID: 0,
-			}},
-			expectedError:      nil,
-			routeRequestsIDs:   []int{0},
-			routesResponsesIDs: []int{0},
-			routesInfoIDs:      []int{0},
-			nextRouteDataIDs:   []int{0},
-			setupMocks: func() interfaces.RouteRepository {
-				mRouteRepository := new(mocks.MockRouteRepository)
-				mRouteRepository.On("InsertWorkingRoute", mock.Anything, mock.Anything).Return(0, nil)
-				return mRouteRepository
-			},
+			routes:                &[]DTO.CreateRoute{{}, {ParentID: 1}},
+			expectedError:         nil,
+			routeRequestsIDs:      []int{3, 4},
+			routesResponsesIDs:    []int{5, 6},
+			routesInfoIDs:         []int{7, 8},
+			nextRouteDataIDs:      []int{9, 10},
+			expectedRouteIDs:      []int{7, 8},
 		},
 	}
 	for _, testScenario := range testsScenarios {
 		t.Run(testScenario.name, func(t *testing.T) {
-			ctx := context.Background()
 			loggerService := tests.CreateLogger()
-			routeRepository := testScenario.setupMocks()
-			routeService := NewRouteService(loggerService, routeRepository)
-			err := routeService.saveWorkingRoutes(ctx, testScenario.routes, testScenario.appID, testScenario.nameOfTheWorkingRoute, testScenario.nextRouteDataIDs, testScenario.routeRequestsIDs, testScenario.routesResponsesIDs, testScenario.routesInfoIDs)
+			routeService := NewRouteService(loggerService, new(mocks.MockRouteRepository), new(mocks.MockAppRepository),
+				"test-key")
+			workingRoutes, err := routeService.prepareWorkingRoutes(testScenario.routes, testScenario.appID,
+				testScenario.nameOfTheWorkingRoute, 1, 2, testScenario.nextRouteDataIDs, testScenario.routeRequestsIDs,
+				testScenario.routesResponsesIDs, testScenario.routesInfoIDs)
 			assert.Equal(t, testScenario.expectedError, err)
+			routeIDs := make([]int, 0, len(workingRoutes))
+			for i, workingRoute := range workingRoutes {
+				assert.Equal(t, 1, workingRoute.FlowID)
+				assert.Equal(t, 2, workingRoute.Version)
+				assert.Equal(t, i, workingRoute.Position)
+				assert.Equal(t, "unknown", workingRoute.Status)
+				routeIDs = append(routeIDs, workingRoute.RouteID)
+			}
+			assert.Equal(t, testScenario.expectedRouteIDs, routeIDs)
 		})
 	}
 }
@@ -219,40 +231,315 @@ func TestRouteService_AddWorkingRoutes(t *testing.T) {
 		routes                *[]DTO.CreateRoute
 		appID                 string
 		nameOfTheWorkingRoute string
+		expectedFlowID        int
 		expectedError         error
-		setupMocks            func() interfaces.RouteRepository
+		setupMocks            func() (interfaces.RouteRepository, interfaces.AppRepository)
+	}
+
+	appID := "27cf4966c158762ceb9495fbdd044a73325efd3bd2a4f9646fc45662ef59490d"
+	testsScenarios := []args{
+		{
+			name: "Properly added working routes to the database",
+			routes: &[]DTO.CreateRoute{
+				{
+					Path:               "/user",
+					Method:             "GET",
+					ResponseStatusCode: 200,
+				},
+			},
+			appID:                 appID,
+			nameOfTheWorkingRoute: "test",
+			expectedFlowID:        3,
+			expectedError:         nil,
+			setupMocks: func() (interfaces.RouteRepository, interfaces.AppRepository) {
+				mAppRepository := new(mocks.MockAppRepository)
+				mAppRepository.On("GetApp", mock.Anything, appID, 1).Return(&models.App{ID: appID}, nil)
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("InsertRouteFlow", mock.Anything, appID, "test", "{}", "",
+					emptyHTTPOptions, emptyNotifications).Return(3, nil)
+				mRouteRepository.On("InsertRoutesInfo", mock.Anything, mock.Anything).Return([]int{0}, nil)
+				mRouteRepository.On("InsertNextRoutesData", mock.Anything, mock.Anything).Return([]int{0}, nil)
+
+				mRouteRepository.On("InsertRoutesResponses", mock.Anything, mock.Anything).Return([]int{0}, nil)
+				mRouteRepository.On("InsertRoutesRequests", mock.Anything, mock.Anything).Return([]int{0}, nil)
+				mRouteRepository.On("InsertWorkingRoute", mock.Anything, mock.MatchedBy(func(workingRoute DTO.WorkingRoute) bool {
+					return workingRoute.FlowID == 3 && workingRoute.Version == 1
+				})).Return(0, nil)
+				return mRouteRepository, mAppRepository
+			},
+		},
+		{
+			name: "Flow with the same name already exists",
+			routes: &[]DTO.CreateRoute{
+				{
+					Path:               "/user",
+					Method:             "GET",
+					ResponseStatusCode: 200,
+				},
+			},
+			appID:                 appID,
+			nameOfTheWorkingRoute: "test",
+			expectedError:         models.NewError(409, "RouteFlow", "route flow with this name already exists"),
+			setupMocks: func() (interfaces.RouteRepository, interfaces.AppRepository) {
+				mAppRepository := new(mocks.MockAppRepository)
+				mAppRepository.On("GetApp", mock.Anything, appID, 1).Return(&models.App{ID: appID}, nil)
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("InsertRouteFlow", mock.Anything, appID, "test", "{}", "",
+					emptyHTTPOptions, emptyNotifications).Return(0,
+					models.NewError(409, "RouteFlow", "route flow with this name already exists"))
+				return mRouteRepository, mAppRepository
+			},
+		},
+		{
+			name: "Flow is removed when its steps fail to save",
+			routes: &[]DTO.CreateRoute{
+				{
+					Path:               "/user",
+					Method:             "GET",
+					ResponseStatusCode: 200,
+				},
+			},
+			appID:                 appID,
+			nameOfTheWorkingRoute: "test",
+			expectedError:         models.NewError(500, "Database", "failed to get data from database"),
+			setupMocks: func() (interfaces.RouteRepository, interfaces.AppRepository) {
+				mAppRepository := new(mocks.MockAppRepository)
+				mAppRepository.On("GetApp", mock.Anything, appID, 1).Return(&models.App{ID: appID}, nil)
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("InsertRouteFlow", mock.Anything, appID, "test", "{}", "",
+					emptyHTTPOptions, emptyNotifications).Return(3, nil)
+				mRouteRepository.On("InsertRoutesInfo", mock.Anything, mock.Anything).Return([]int{},
+					models.NewError(500, "Database", "failed to get data from database"))
+				mRouteRepository.On("InsertNextRoutesData", mock.Anything, mock.Anything).Return([]int{0}, nil)
+				mRouteRepository.On("InsertRoutesResponses", mock.Anything, mock.Anything).Return([]int{0}, nil)
+				mRouteRepository.On("InsertRoutesRequests", mock.Anything, mock.Anything).Return([]int{0}, nil)
+				mRouteRepository.On("DeleteRouteFlow", mock.Anything, 3, appID, 1).Return(nil).Once()
+				return mRouteRepository, mAppRepository
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			ctx := context.Background()
+			loggerService := tests.CreateLogger()
+			routeRepository, appRepository := testScenario.setupMocks()
+			routeService := NewRouteService(loggerService, routeRepository, appRepository, "test-key")
+			routeFlow := DTO.CreateRouteData{Name: testScenario.nameOfTheWorkingRoute, Routes: *testScenario.routes}
+			flowID, err := routeService.AddWorkingRoutes(ctx, routeFlow, testScenario.appID, 1)
+			assert.Equal(t, testScenario.expectedFlowID, flowID)
+			assert.Equal(t, testScenario.expectedError, err)
+			routeRepository.(*mocks.MockRouteRepository).AssertExpectations(t)
+		})
+	}
+}
+
+func TestRouteService_UpdateRouteFlow(t *testing.T) {
+	type args struct {
+		name            string
+		expectedVersion int
+		expectedError   error
+		setupMocks      func() interfaces.RouteRepository
 	}
 
-	testsScenarios := []args{{
-		name: "Properly added working routes to the database",
-		routes: &[]DTO.CreateRoute{
-			{
-				Path:               "/user",
-				Method:             "GET",
-				ResponseStatusCode: 200,
+	appID := "27cf4966c158762ceb9495fbdd044a73325efd3bd2a4f9646fc45662ef59490d"
+	routes := []DTO.CreateRoute{
+		{
+			Path:               "/user",
+			Method:             "GET",
+			ResponseStatusCode: 200,
+		},
+		{
+			Path:               "/posts",
+			Method:             "GET",
+			ResponseStatusCode: 200,
+			ParentID:           1,
+		},
+	}
+	testsScenarios := []args{
+		{
+			name:            "Steps are saved as the next version",
+			expectedVersion: 3,
+			expectedError:   nil,
+			setupMocks: func() interfaces.RouteRepository {
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("GetRouteFlow", mock.Anything, 5, appID, 1, 0).Return(&DTO.RouteFlow{
+					ID:      5,
+					AppID:   appID,
+					Name:    "test",
+					Version: 2,
+				}, nil)
+				mRouteRepository.On("InsertRoutesInfo", mock.Anything, mock.Anything).Return([]int{1, 2}, nil)
+				mRouteRepository.On("InsertNextRoutesData", mock.Anything, mock.Anything).Return([]int{1, 2}, nil)
+				mRouteRepository.On("InsertRoutesResponses", mock.Anything, mock.Anything).Return([]int{1, 2}, nil)
+				mRouteRepository.On("InsertRoutesRequests", mock.Anything, mock.Anything).Return([]int{1, 2}, nil)
+				mRouteRepository.On("UpdateRouteFlow", mock.Anything, 5, appID, 1, "renamed", 3, `{"region":"eu"}`,
+					(*string)(nil), emptyHTTPOptions, emptyNotifications,
+					mock.MatchedBy(func(workingRoutes []DTO.WorkingRoute) bool {
+						return len(workingRoutes) == 2 && workingRoutes[0].FlowID == 5 &&
+							workingRoutes[0].Version == 3 && workingRoutes[0].Position == 0 &&
+							workingRoutes[1].Version == 3 && workingRoutes[1].Position == 1 &&
+							workingRoutes[1].RouteID == 2
+					})).Return(nil).Once()
+				return mRouteRepository
+			},
+		},
+		{
+			name:          "Flow not found",
+			expectedError: models.NewError(404, "RouteFlow", "route flow not found"),
+			setupMocks: func() interfaces.RouteRepository {
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("GetRouteFlow", mock.Anything, 5, appID, 1, 0).Return(nil,
+					models.NewError(404, "RouteFlow", "route flow not found"))
+				return mRouteRepository
+			},
+		},
+		{
+			name:          "Flow changed by another edit",
+			expectedError: models.NewError(409, "RouteFlow", "route flow was changed in the meantime"),
+			setupMocks: func() interfaces.RouteRepository {
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("GetRouteFlow", mock.Anything, 5, appID, 1, 0).Return(&DTO.RouteFlow{
+					ID:      5,
+					AppID:   appID,
+					Version: 1,
+				}, nil)
+				mRouteRepository.On("InsertRoutesInfo", mock.Anything, mock.Anything).Return([]int{1, 2}, nil)
+				mRouteRepository.On("InsertNextRoutesData", mock.Anything, mock.Anything).Return([]int{1, 2}, nil)
+				mRouteRepository.On("InsertRoutesResponses", mock.Anything, mock.Anything).Return([]int{1, 2}, nil)
+				mRouteRepository.On("InsertRoutesRequests", mock.Anything, mock.Anything).Return([]int{1, 2}, nil)
+				mRouteRepository.On("UpdateRouteFlow", mock.Anything, 5, appID, 1, "renamed", 2, `{"region":"eu"}`,
+					(*string)(nil), emptyHTTPOptions, emptyNotifications, mock.Anything).Return(
+					models.NewError(409, "RouteFlow", "route flow was changed in the meantime"))
+				return mRouteRepository
 			},
 		},
-		appID:                 "27cf4966c158762ceb9495fbdd044a73325efd3bd2a4f9646fc45662ef59490d",
-		nameOfTheWorkingRoute: "test",
-		expectedError:         nil,
-		setupMocks: func() interfaces.RouteRepository {
-			mRouteRepository := new(mocks.MockRouteRepository)
-			mRouteRepository.On("InsertRoutesInfo", mock.Anything, mock.Anything).Return([]int{0}, nil)
-			mRouteRepository.On("InsertNextRoutesData", mock.Anything, mock.Anything).Return([]int{0}, nil)
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			ctx := context.Background()
+			loggerService := tests.CreateLogger()
+			routeRepository := testScenario.setupMocks()
+			routeService := NewRouteService(loggerService, routeRepository, new(mocks.MockAppRepository), "test-key")
+			routeFlow := DTO.CreateRouteData{
+				Name:   "renamed",
+				Env:    map[string]string{"region": "eu"},
+				Routes: slices.Clone(routes),
+			}
+			version, err := routeService.UpdateRouteFlow(ctx, routeFlow, appID, 1, 5)
+			assert.Equal(t, testScenario.expectedVersion, version)
+			assert.Equal(t, testScenario.expectedError, err)
+			routeRepository.(*mocks.MockRouteRepository).AssertExpectations(t)
+		})
+	}
+}
+
+func TestRouteService_GetRouteFlow(t *testing.T) {
+	type args struct {
+		name              string
+		encryptedSecrets  func(t *testing.T) string
+		expectedSecrets   []string
+		expectedErrorCode int
+	}
 
-			mRouteRepository.On("InsertRoutesResponses", mock.Anything, mock.Anything).Return([]int{0}, nil)
-			mRouteRepository.On("InsertRoutesRequests", mock.Anything, mock.Anything).Return([]int{0}, nil)
-			mRouteRepository.On("InsertWorkingRoute", mock.Anything, mock.Anything).Return(0, nil)
-			return mRouteRepository
+	appID := "27cf4966c158762ceb9495fbdd044a73325efd3bd2a4f9646fc45662ef59490d"
+	testsScenarios := []args{
+		{
+			name: "Only the names of the secrets are returned",
+			encryptedSecrets: func(t *testing.T) string {
+				encryptedSecrets, err := utils.Encrypt(`{"token":"abc","password":"qwerty"}`, "test-key")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return encryptedSecrets
+			},
+			expectedSecrets: []string{"password", "token"},
+		},
+		{
+			name: "Flow without secrets",
+			encryptedSecrets: func(t *testing.T) string {
+				return ""
+			},
+			expectedSecrets: []string{},
+		},
+		{
+			name: "Secrets encrypted with another key",
+			encryptedSecrets: func(t *testing.T) string {
+				encryptedSecrets, err := utils.Encrypt(`{"token":"abc"}`, "another-key")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return encryptedSecrets
+			},
+			expectedErrorCode: 500,
 		},
-	}}
+	}
 	for _, testScenario := range testsScenarios {
 		t.Run(testScenario.name, func(t *testing.T) {
 			ctx := context.Background()
+			loggerService := tests.CreateLogger()
+			routeRepository := new(mocks.MockRouteRepository)
+			routeRepository.On("GetRouteFlow", mock.Anything, 5, appID, 1, 0).Return(&DTO.RouteFlow{
+				ID:               5,
+				AppID:            appID,
+				EncryptedSecrets: testScenario.encryptedSecrets(t),
+			}, nil)
+			routeService := NewRouteService(loggerService, routeRepository, new(mocks.MockAppRepository), "test-key")
+			routeFlow, err := routeService.GetRouteFlow(ctx, appID, 1, 5, 0)
+			if testScenario.expectedErrorCode != 0 {
+				var appError *models.Error
+				assert.ErrorAs(t, err, &appError)
+				assert.Equal(t, testScenario.expectedErrorCode, appError.StatusCode)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testScenario.expectedSecrets, routeFlow.SecretNames)
+			assert.Empty(t, routeFlow.EncryptedSecrets)
+		})
+	}
+}
+
+func TestRouteService_GetRouteFlowRuns(t *testing.T) {
+	type args struct {
+		name          string
+		expectedRuns  []DTO.RouteFlowRun
+		expectedError error
+		setupMocks    func() interfaces.RouteRepository
+	}
+
+	appID := "27cf4966c158762ceb9495fbdd044a73325efd3bd2a4f9646fc45662ef59490d"
+	routeFlowRuns := []DTO.RouteFlowRun{
+		{ID: 2, FlowID: 5, Version: 1, Status: "success", Steps: []DTO.RouteFlowRunStep{}},
+	}
+	testsScenarios := []args{
+		{
+			name:         "Runs of the flow",
+			expectedRuns: routeFlowRuns,
+			setupMocks: func() interfaces.RouteRepository {
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("GetRouteFlow", mock.Anything, 5, appID, 1, 0).Return(&DTO.RouteFlow{ID: 5},
+					nil)
+				mRouteRepository.On("GetRouteFlowRuns", mock.Anything, 5, appID, 1).Return(routeFlowRuns, nil)
+				return mRouteRepository
+			},
+		},
+		{
+			name:          "Flow of another user",
+			expectedError: models.NewError(404, "RouteFlow", "route flow not found"),
+			setupMocks: func() interfaces.RouteRepository {
+				mRouteRepository := new(mocks.MockRouteRepository)
+				mRouteRepository.On("GetRouteFlow", mock.Anything, 5, appID, 1, 0).Return(nil,
+					models.NewError(404, "RouteFlow", "route flow not found"))
+				return mRouteRepository
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
 			loggerService := tests.CreateLogger()
 			routeRepository := testScenario.setupMocks()
-			routeService := NewRouteService(loggerService, routeRepository)
-			err := routeService.AddWorkingRoutes(ctx, testScenario.routes, testScenario.appID, testScenario.nameOfTheWorkingRoute)
+			routeService := NewRouteService(loggerService, routeRepository, new(mocks.MockAppRepository), "test-key")
+			runs, err := routeService.GetRouteFlowRuns(context.Background(), appID, 1, 5)
+			assert.Equal(t, testScenario.expectedRuns, runs)
 			assert.Equal(t, testScenario.expectedError, err)
 		})
 	}
@@ -0,0 +1,222 @@
+package servicesApp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPm2AppStatus(t *testing.T) {
+	type args struct {
+		name                 string
+		process              DTO.Pm2Process
+		found                bool
+		expectedStatus       string
+		expectedRestartCount int
+	}
+	startedAt := time.Now().Add(-time.Hour)
+	testsScenarios := []args{
+		{
+			name: "Online process",
+			process: DTO.Pm2Process{Name: "api", Status: "online", RestartCount: 3, MemoryBytes: 1024,
+				StartedAt: startedAt},
+			found:                true,
+			expectedStatus:       "running",
+			expectedRestartCount: 3,
+		},
+		{
+			name:           "Stopped process",
+			process:        DTO.Pm2Process{Name: "api", Status: "stopped"},
+			found:          true,
+			expectedStatus: "stopped",
+		},
+		{
+			name:                 "Errored process",
+			process:              DTO.Pm2Process{Name: "api", Status: "errored", RestartCount: 15},
+			found:                true,
+			expectedStatus:       "errored",
+			expectedRestartCount: 15,
+		},
+		{
+			name:           "Process removed from pm2",
+			expectedStatus: "stopped",
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appStatus := pm2AppStatus("pm2-api", testScenario.process, testScenario.found)
+			assert.Equal(t, "pm2-api", appStatus.AppID)
+			assert.Equal(t, testScenario.expectedStatus, appStatus.Status)
+			assert.Equal(t, testScenario.expectedRestartCount, appStatus.RestartCount)
+			if !testScenario.process.StartedAt.IsZero() {
+				assert.Equal(t, startedAt, appStatus.ChangedAt)
+				assert.GreaterOrEqual(t, appStatus.Duration, time.Hour)
+			}
+		})
+	}
+}
+
+func TestDockerAppStatus(t *testing.T) {
+	type args struct {
+		name                 string
+		containerInfo        container.InspectResponse
+		expectedStatus       string
+		expectedHealth       string
+		expectedHealthOutput string
+		expectedExitCode     int
+		expectedOOMKilled    bool
+		expectedError        error
+	}
+	startedAt := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+	inspectResponse := func(state *container.State, restartCount int) container.InspectResponse {
+		return container.InspectResponse{
+			ContainerJSONBase: &container.ContainerJSONBase{State: state, RestartCount: restartCount},
+		}
+	}
+	testsScenarios := []args{
+		{
+			name:           "Running container without health check",
+			containerInfo:  inspectResponse(&container.State{Status: "running", StartedAt: startedAt}, 0),
+			expectedStatus: "running",
+		},
+		{
+			name: "Running container with failing health check",
+			containerInfo: inspectResponse(&container.State{
+				Status:    "running",
+				StartedAt: startedAt,
+				Health: &container.Health{
+					Status: "unhealthy",
+					Log: []*container.HealthcheckResult{
+						{ExitCode: 0, Output: "ok"},
+						{ExitCode: 1, Output: "  curl: (7) Failed to connect\n"},
+					},
+				},
+			}, 0),
+			expectedStatus:       "unhealthy",
+			expectedHealth:       "unhealthy",
+			expectedHealthOutput: "curl: (7) Failed to connect",
+		},
+		{
+			name: "Running container with passing health check",
+			containerInfo: inspectResponse(&container.State{
+				Status:    "running",
+				StartedAt: startedAt,
+				Health:    &container.Health{Status: "healthy"},
+			}, 0),
+			expectedStatus: "running",
+			expectedHealth: "healthy",
+		},
+		{
+			name: "Container killed by OOM",
+			containerInfo: inspectResponse(&container.State{
+				Status:    "exited",
+				StartedAt: startedAt,
+				ExitCode:  137,
+				OOMKilled: true,
+			}, 3),
+			expectedStatus:    "exited",
+			expectedExitCode:  137,
+			expectedOOMKilled: true,
+		},
+		{
+			name:          "Invalid start time",
+			containerInfo: inspectResponse(&container.State{Status: "running", StartedAt: "yesterday"}, 0),
+			expectedError: errors.New("cannot parse"),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appStatus, err := dockerAppStatus("container-id", testScenario.containerInfo)
+			if testScenario.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "container-id", appStatus.AppID)
+			assert.Equal(t, testScenario.expectedStatus, appStatus.Status)
+			assert.Equal(t, testScenario.expectedHealth, appStatus.Health)
+			assert.Equal(t, testScenario.expectedHealthOutput, appStatus.HealthOutput)
+			assert.Equal(t, testScenario.expectedExitCode, appStatus.ExitCode)
+			assert.Equal(t, testScenario.expectedOOMKilled, appStatus.OOMKilled)
+			assert.Equal(t, testScenario.containerInfo.RestartCount, appStatus.RestartCount)
+			assert.GreaterOrEqual(t, appStatus.Duration, time.Hour)
+		})
+	}
+}
+
+func TestSystemdAppStatus(t *testing.T) {
+	type args struct {
+		name           string
+		unit           DTO.SystemdUnit
+		expectedStatus string
+	}
+	testsScenarios := []args{
+		{
+			name:           "Active unit",
+			unit:           DTO.SystemdUnit{ActiveState: "active", RestartCount: 1, StartedAt: time.Now()},
+			expectedStatus: "running",
+		},
+		{
+			name:           "Inactive unit",
+			unit:           DTO.SystemdUnit{ActiveState: "inactive"},
+			expectedStatus: "stopped",
+		},
+		{
+			name:           "Failed unit",
+			unit:           DTO.SystemdUnit{ActiveState: "failed"},
+			expectedStatus: "failed",
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appStatus := systemdAppStatus("systemd-1-api.service", testScenario.unit)
+			assert.Equal(t, testScenario.expectedStatus, appStatus.Status)
+			assert.Equal(t, testScenario.unit.RestartCount, appStatus.RestartCount)
+		})
+	}
+}
+
+func TestKubernetesAppStatus(t *testing.T) {
+	type args struct {
+		name           string
+		workload       DTO.KubernetesWorkload
+		expectedStatus string
+	}
+	changedAt := time.Now().Add(-time.Hour)
+	testsScenarios := []args{
+		{
+			name:           "All replicas ready",
+			workload:       DTO.KubernetesWorkload{Replicas: 3, ReadyReplicas: 3, ChangedAt: changedAt},
+			expectedStatus: "running",
+		},
+		{
+			name:           "Part of replicas ready",
+			workload:       DTO.KubernetesWorkload{Replicas: 3, ReadyReplicas: 1, ChangedAt: changedAt},
+			expectedStatus: "degraded",
+		},
+		{
+			name:           "No replica ready",
+			workload:       DTO.KubernetesWorkload{Replicas: 3, ChangedAt: changedAt},
+			expectedStatus: "unavailable",
+		},
+		{
+			name:           "Scaled to zero",
+			workload:       DTO.KubernetesWorkload{ChangedAt: changedAt},
+			expectedStatus: "stopped",
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appStatus := kubernetesAppStatus("k8s-api", testScenario.workload)
+			assert.Equal(t, "k8s-api", appStatus.AppID)
+			assert.Equal(t, testScenario.expectedStatus, appStatus.Status)
+			assert.Equal(t, changedAt, appStatus.ChangedAt)
+			assert.GreaterOrEqual(t, appStatus.Duration, time.Hour)
+		})
+	}
+}
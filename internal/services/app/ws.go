@@ -2,95 +2,347 @@ package servicesApp
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
-	"unicode/utf8"
 
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
 	"github.com/slodkiadrianek/octopus/internal/utils"
 )
 
+const (
+	logsStreamAll    = "all"
+	logsStreamStdout = "stdout"
+	logsStreamStderr = "stderr"
+)
+
+const defaultLogsTail = "100"
+
 type WsService struct {
-	dockerHost string
-	logger     utils.LoggerService
+	appRepository        interfaces.AppRepository
+	dockerClientManager  interfaces.DockerClientManager
+	dockerHost           string
+	systemdClient        interfaces.SystemdClient
+	kubernetesRepository interfaces.KubernetesRepository
+	kubernetesClient     interfaces.KubernetesClient
+	logger               utils.LoggerService
 }
 
-func NewWsService(logger utils.LoggerService, dockerHost string) *WsService {
+func NewWsService(appRepository interfaces.AppRepository, logger utils.LoggerService, dockerHost string,
+	dockerClientManager interfaces.DockerClientManager, systemdClient interfaces.SystemdClient,
+	kubernetesRepository interfaces.KubernetesRepository, kubernetesClient interfaces.KubernetesClient,
+) *WsService {
 	return &WsService{
-		dockerHost: dockerHost,
-		logger:     logger,
+		appRepository:        appRepository,
+		dockerClientManager:  dockerClientManager,
+		dockerHost:           dockerHost,
+		systemdClient:        systemdClient,
+		kubernetesRepository: kubernetesRepository,
+		kubernetesClient:     kubernetesClient,
+		logger:               logger,
 	}
 }
 
-func (ws *WsService) Logs(ctx context.Context, appId string, conn *websocket.Conn) {
-	cli, err := client.NewClientWithOpts(client.WithHost(ws.dockerHost), client.WithAPIVersionNegotiation())
-	if err != nil {
-		ws.logger.Error("Error creating Docker client", err)
-		return
+// logLineWriter splits the logs of a single stream into lines. Docker prefixes every line with its timestamp,
+// lines which do not match the grep pattern are dropped.
+type logLineWriter struct {
+	stream string
+	grep   *regexp.Regexp
+	buffer []byte
+	send   func(DTO.LogLine) error
+}
+
+func (lw *logLineWriter) Write(p []byte) (int, error) {
+	lw.buffer = append(lw.buffer, p...)
+	for {
+		newLineIndex := bytes.IndexByte(lw.buffer, '\n')
+		if newLineIndex < 0 {
+			return len(p), nil
+		}
+		line := string(lw.buffer[:newLineIndex])
+		lw.buffer = lw.buffer[newLineIndex+1:]
+		if err := lw.sendLine(line); err != nil {
+			return 0, err
+		}
 	}
-	defer cli.Close()
+}
 
-	conn.SetPongHandler(func(string) error {
+// Flush sends the last line when the logs do not end with a new line.
+func (lw *logLineWriter) Flush() error {
+	if len(lw.buffer) == 0 {
 		return nil
-	})
+	}
+	line := string(lw.buffer)
+	lw.buffer = nil
 
+	return lw.sendLine(line)
+}
+
+func (lw *logLineWriter) sendLine(line string) error {
+	line = strings.ToValidUTF8(strings.TrimSuffix(line, "\r"), "")
+	logLine := DTO.LogLine{
+		Stream: lw.stream,
+		Line:   line,
+	}
+	if timestamp, text, found := strings.Cut(line, " "); found {
+		if parsedTimestamp, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+			logLine.Timestamp = parsedTimestamp
+			logLine.Line = text
+		}
+	}
+	if lw.grep != nil && !lw.grep.MatchString(logLine.Line) {
+		return nil
+	}
+
+	return lw.send(logLine)
+}
+
+func prepareLogsOptions(logsOptions DTO.LogsOptions) container.LogsOptions {
 	options := container.LogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
+		ShowStdout: logsOptions.Stream != logsStreamStderr,
+		ShowStderr: logsOptions.Stream != logsStreamStdout,
+		Since:      logsOptions.Since,
+		Until:      logsOptions.Until,
 		Follow:     true,
 		Timestamps: true,
-		Tail:       "100",
+		Tail:       logsOptions.Tail,
+	}
+	if options.Tail == "" {
+		options.Tail = defaultLogsTail
+	}
+	// The stream ends on its own when the logs are read up to a point in time.
+	if logsOptions.Until != "" {
+		options.Follow = false
 	}
 
-	reader, err := cli.ContainerLogs(ctx, appId, options)
+	return options
+}
+
+// journalEntry holds the fields of a journalctl JSON line used by the logs. The message is an array of bytes when
+// it is not valid UTF-8.
+type journalEntry struct {
+	Message           json.RawMessage `json:"MESSAGE"`
+	Priority          string          `json:"PRIORITY"`
+	RealtimeTimestamp string          `json:"__REALTIME_TIMESTAMP"`
+}
+
+// parseJournalEntry converts a journal entry to a log line. Entries with the error or a more severe priority are
+// reported as stderr.
+func parseJournalEntry(entryJSON []byte) (DTO.LogLine, error) {
+	var entry journalEntry
+	if err := json.Unmarshal(entryJSON, &entry); err != nil {
+		return DTO.LogLine{}, err
+	}
+
+	logLine := DTO.LogLine{Stream: logsStreamStdout}
+	if priority, err := strconv.Atoi(entry.Priority); err == nil && priority <= 3 {
+		logLine.Stream = logsStreamStderr
+	}
+	if microseconds, err := strconv.ParseInt(entry.RealtimeTimestamp, 10, 64); err == nil {
+		logLine.Timestamp = time.UnixMicro(microseconds).UTC()
+	}
+	var message string
+	if err := json.Unmarshal(entry.Message, &message); err != nil {
+		var messageBytes []byte
+		var messageInts []int
+		if err := json.Unmarshal(entry.Message, &messageInts); err == nil {
+			for _, messageInt := range messageInts {
+				messageBytes = append(messageBytes, byte(messageInt))
+			}
+		}
+		message = string(messageBytes)
+	}
+	logLine.Line = strings.ToValidUTF8(message, "")
+
+	return logLine, nil
+}
+
+// Logs streams the logs of the docker container, the journal of the systemd unit or the logs of the kubernetes
+// workload pods to the websocket as DTO.LogLine JSON frames.
+func (ws *WsService) Logs(ctx context.Context, appID string, ownerID int, logsOptions DTO.LogsOptions,
+	conn *websocket.Conn,
+) {
+	var grep *regexp.Regexp
+	if logsOptions.Grep != "" {
+		var err error
+		grep, err = regexp.Compile(logsOptions.Grep)
+		if err != nil {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte("grep is not a valid regular expression"))
+			return
+		}
+	}
+
+	app, err := ws.appRepository.GetApp(ctx, appID, ownerID)
 	if err != nil {
-		ws.logger.Error("Failed to connect to container", err)
-		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("Failed to connect to container: %v", err)))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
 		return
 	}
-	defer reader.Close()
-
-	pingTicker := time.NewTicker(30 * time.Second)
-	defer pingTicker.Stop()
 
-	scanner := bufio.NewScanner(reader)
+	switch app.Runtime {
+	case models.RuntimeDocker:
+		ws.dockerLogs(ctx, appID, logsOptions, grep, conn)
+	case models.RuntimeSystemd:
+		ws.journalLogs(ctx, appID, logsOptions, grep, conn)
+	case models.RuntimeKubernetes:
+		ws.podLogs(ctx, appID, logsOptions, grep, conn)
+	default:
+		err := models.NewError(400, "Logs", "logs are available only for docker, systemd and kubernetes apps")
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+	}
+}
 
-	for {
-		select {
-		case <-pingTicker.C:
-			if err := conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(10*time.Second)); err != nil {
-				ws.logger.Info("Client disconnected during ping")
+// keepAlive cancels the context when the client disconnects.
+func (ws *WsService) keepAlive(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn) {
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
 				return
 			}
-		case <-ctx.Done():
-			ws.logger.Info("Status check stopped by context")
-			return
-		default:
-			if scanner.Scan() {
-				line := scanner.Text()
-				filteredRunes := make([]rune, 0, len(line))
-				for _, character := range line {
-					if character == utf8.RuneError {
-						continue
-					}
-					filteredRunes = append(filteredRunes, character)
-				}
-				utf8Bytes := []byte(string(filteredRunes))
-				if err := conn.WriteMessage(websocket.TextMessage, utf8Bytes); err != nil {
-					if !websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
-						ws.logger.Info("Client disconnected")
-					}
+		}
+	}()
+
+	go func() {
+		pingTicker := time.NewTicker(30 * time.Second)
+		defer pingTicker.Stop()
+		for {
+			select {
+			case <-pingTicker.C:
+				if err := conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(10*time.Second)); err != nil {
+					ws.logger.Info("Client disconnected during ping")
+					cancel()
 					return
 				}
-			} else {
-				if err := scanner.Err(); err != nil {
-					ws.logger.Error("Scanner error", err)
-				}
+			case <-ctx.Done():
 				return
 			}
 		}
+	}()
+}
+
+func (ws *WsService) journalLogs(ctx context.Context, appID string, logsOptions DTO.LogsOptions,
+	grep *regexp.Regexp, conn *websocket.Conn,
+) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ws.keepAlive(ctx, cancel, conn)
+
+	reader, err := ws.systemdClient.FollowJournal(ctx, DTO.SystemdUnitName(appID), logsOptions)
+	if err != nil {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		logLine, err := parseJournalEntry(scanner.Bytes())
+		if err != nil {
+			ws.logger.Warn("failed to parse journal entry", err)
+			continue
+		}
+		if logsOptions.Stream == logsStreamStdout && logLine.Stream != logsStreamStdout ||
+			logsOptions.Stream == logsStreamStderr && logLine.Stream != logsStreamStderr {
+			continue
+		}
+		if grep != nil && !grep.MatchString(logLine.Line) {
+			continue
+		}
+		if err := conn.WriteJSON(logLine); err != nil {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		ws.logger.Info("Logs stream stopped", err.Error())
+	}
+}
+
+func (ws *WsService) podLogs(ctx context.Context, appID string, logsOptions DTO.LogsOptions,
+	grep *regexp.Regexp, conn *websocket.Conn,
+) {
+	workload, err := ws.kubernetesRepository.GetKubernetesWorkload(ctx, appID)
+	if err != nil {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ws.keepAlive(ctx, cancel, conn)
+
+	err = ws.kubernetesClient.StreamPodLogs(ctx, *workload, logsOptions, func(logLine DTO.LogLine) error {
+		if logsOptions.Stream == logsStreamStderr {
+			return nil
+		}
+		if grep != nil && !grep.MatchString(logLine.Line) {
+			return nil
+		}
+		return conn.WriteJSON(logLine)
+	})
+	if err != nil && ctx.Err() == nil {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+	}
+}
+
+func (ws *WsService) dockerLogs(ctx context.Context, appID string, logsOptions DTO.LogsOptions,
+	grep *regexp.Regexp, conn *websocket.Conn,
+) {
+	cli, err := ws.dockerClientManager.GetClient(ctx, ws.dockerHost)
+	if err != nil {
+		ws.logger.Error("Error creating Docker client", err)
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+
+	containerInfo, err := cli.ContainerInspect(ctx, appID)
+	if err != nil {
+		ws.logger.Error("Failed to connect to container", err)
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("Failed to connect to container: %v", err)))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ws.keepAlive(ctx, cancel, conn)
+
+	reader, err := cli.ContainerLogs(ctx, appID, prepareLogsOptions(logsOptions))
+	if err != nil {
+		ws.logger.Error("Failed to connect to container", err)
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("Failed to connect to container: %v", err)))
+		return
+	}
+	defer reader.Close()
+
+	send := func(logLine DTO.LogLine) error {
+		return conn.WriteJSON(logLine)
+	}
+	stdoutWriter := &logLineWriter{stream: logsStreamStdout, grep: grep, send: send}
+	stderrWriter := &logLineWriter{stream: logsStreamStderr, grep: grep, send: send}
+	// Containers with a TTY do not multiplex the logs, everything is written to stdout.
+	if containerInfo.Config != nil && containerInfo.Config.Tty {
+		_, err = io.Copy(stdoutWriter, reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdoutWriter, stderrWriter, reader)
+	}
+	if err == nil {
+		err = stdoutWriter.Flush()
+	}
+	if err == nil {
+		err = stderrWriter.Flush()
+	}
+	if err != nil && ctx.Err() == nil {
+		ws.logger.Info("Logs stream stopped", err.Error())
 	}
 }
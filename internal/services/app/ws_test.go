@@ -0,0 +1,371 @@
+package servicesApp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLogLineWriter_Write(t *testing.T) {
+	type args struct {
+		name          string
+		grep          string
+		writes        []string
+		expectedLines []DTO.LogLine
+	}
+	timestamp := time.Date(2025, 1, 1, 10, 0, 0, 123, time.UTC)
+	testsScenarios := []args{
+		{
+			name:   "Line split between writes",
+			writes: []string{"2025-01-01T10:00:00.000000123Z server ", "started\n"},
+			expectedLines: []DTO.LogLine{
+				{Stream: "stdout", Timestamp: timestamp, Line: "server started"},
+			},
+		},
+		{
+			name:   "Many lines in one write",
+			writes: []string{"2025-01-01T10:00:00.000000123Z first\r\n2025-01-01T10:00:00.000000123Z second\n"},
+			expectedLines: []DTO.LogLine{
+				{Stream: "stdout", Timestamp: timestamp, Line: "first"},
+				{Stream: "stdout", Timestamp: timestamp, Line: "second"},
+			},
+		},
+		{
+			name:   "Line without timestamp",
+			writes: []string{"no timestamp here\n"},
+			expectedLines: []DTO.LogLine{
+				{Stream: "stdout", Line: "no timestamp here"},
+			},
+		},
+		{
+			name: "Grep filters lines",
+			grep: "ERROR|panic",
+			writes: []string{"2025-01-01T10:00:00.000000123Z INFO ok\n",
+				"2025-01-01T10:00:00.000000123Z ERROR failed\n"},
+			expectedLines: []DTO.LogLine{
+				{Stream: "stdout", Timestamp: timestamp, Line: "ERROR failed"},
+			},
+		},
+		{
+			name:   "Last line without new line is flushed",
+			writes: []string{"2025-01-01T10:00:00.000000123Z last"},
+			expectedLines: []DTO.LogLine{
+				{Stream: "stdout", Timestamp: timestamp, Line: "last"},
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			lines := make([]DTO.LogLine, 0)
+			lineWriter := &logLineWriter{
+				stream: "stdout",
+				send: func(logLine DTO.LogLine) error {
+					lines = append(lines, logLine)
+					return nil
+				},
+			}
+			if testScenario.grep != "" {
+				lineWriter.grep = regexp.MustCompile(testScenario.grep)
+			}
+			for _, write := range testScenario.writes {
+				_, err := lineWriter.Write([]byte(write))
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, lineWriter.Flush())
+			assert.Equal(t, testScenario.expectedLines, lines)
+		})
+	}
+}
+
+func TestWsService_Logs(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name             string
+		logsOptions      DTO.LogsOptions
+		expectedMessages []string
+		setupMock        func() *mocks.MockAppRepository
+	}
+	testsScenarios := []args{
+		{
+			name:        "Demultiplexed stdout and stderr",
+			logsOptions: DTO.LogsOptions{Tail: "10"},
+			expectedMessages: []string{
+				`{"stream":"stdout","ts":"2025-01-01T10:00:00Z","line":"started"}`,
+				`{"stream":"stderr","ts":"2025-01-01T10:00:01Z","line":"ERROR failed"}`,
+			},
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "app", 1).Return(&models.App{ID: "app", Runtime: models.RuntimeDocker}, nil)
+				return m
+			},
+		},
+		{
+			name:        "Grep",
+			logsOptions: DTO.LogsOptions{Grep: "ERROR"},
+			expectedMessages: []string{
+				`{"stream":"stderr","ts":"2025-01-01T10:00:01Z","line":"ERROR failed"}`,
+			},
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "app", 1).Return(&models.App{ID: "app", Runtime: models.RuntimeDocker}, nil)
+				return m
+			},
+		},
+		{
+			name:             "Invalid grep",
+			logsOptions:      DTO.LogsOptions{Grep: "("},
+			expectedMessages: []string{"grep is not a valid regular expression"},
+			setupMock: func() *mocks.MockAppRepository {
+				return new(mocks.MockAppRepository)
+			},
+		},
+		{
+			name:             "App of another user",
+			logsOptions:      DTO.LogsOptions{},
+			expectedMessages: []string{"app not found"},
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "app", 1).Return((*models.App)(nil),
+					models.NewError(404, "App", "app not found"))
+				return m
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			dockerServer := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+				"GET /containers/app/json": func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"Id":"app","Config":{"Tty":false}}`))
+				},
+				"GET /containers/app/logs": func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Query().Get("timestamps") != "1" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					stdout := stdcopy.NewStdWriter(w, stdcopy.Stdout)
+					stderr := stdcopy.NewStdWriter(w, stdcopy.Stderr)
+					_, _ = stdout.Write([]byte("2025-01-01T10:00:00Z started\n"))
+					_, _ = stderr.Write([]byte("2025-01-01T10:00:01Z ERROR failed\n"))
+				},
+			})
+			defer dockerServer.Close()
+			dockerClientManager := tests.CreateDockerClientManager(loggerService)
+			defer dockerClientManager.Close()
+			wsService := NewWsService(testScenario.setupMock(), loggerService, tests.FakeDockerHost(dockerServer),
+				dockerClientManager, new(mocks.MockSystemdClient), new(mocks.MockKubernetesRepository),
+				new(mocks.MockKubernetesClient))
+
+			upgrader := websocket.Upgrader{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				wsService.Logs(context.Background(), "app", 1, testScenario.logsOptions, conn)
+			}))
+			defer server.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			assert.NoError(t, err)
+			defer conn.Close()
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			for _, expectedMessage := range testScenario.expectedMessages {
+				_, message, err := conn.ReadMessage()
+				assert.NoError(t, err)
+				assert.Contains(t, string(message), expectedMessage)
+			}
+		})
+	}
+}
+
+func TestParseJournalEntry(t *testing.T) {
+	type args struct {
+		name            string
+		entry           string
+		expectedLogLine DTO.LogLine
+		expectedError   bool
+	}
+	testsScenarios := []args{
+		{
+			name:  "Info entry",
+			entry: `{"MESSAGE":"server started","PRIORITY":"6","__REALTIME_TIMESTAMP":"1735725600000000"}`,
+			expectedLogLine: DTO.LogLine{Stream: "stdout", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+				Line: "server started"},
+		},
+		{
+			name:  "Error entry",
+			entry: `{"MESSAGE":"failed to bind","PRIORITY":"3","__REALTIME_TIMESTAMP":"1735725600000000"}`,
+			expectedLogLine: DTO.LogLine{Stream: "stderr", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+				Line: "failed to bind"},
+		},
+		{
+			name:            "Message as bytes",
+			entry:           `{"MESSAGE":[104,105],"PRIORITY":"6"}`,
+			expectedLogLine: DTO.LogLine{Stream: "stdout", Line: "hi"},
+		},
+		{
+			name:          "Not a journal entry",
+			entry:         `-- No entries --`,
+			expectedError: true,
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			logLine, err := parseJournalEntry([]byte(testScenario.entry))
+			if testScenario.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testScenario.expectedLogLine, logLine)
+		})
+	}
+}
+
+func TestWsService_Logs_Journal(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name             string
+		logsOptions      DTO.LogsOptions
+		expectedMessages []string
+	}
+	journal := `{"MESSAGE":"started","PRIORITY":"6","__REALTIME_TIMESTAMP":"1735725600000000"}
+{"MESSAGE":"ERROR failed","PRIORITY":"3","__REALTIME_TIMESTAMP":"1735725601000000"}
+`
+	testsScenarios := []args{
+		{
+			name:        "Journal of the unit",
+			logsOptions: DTO.LogsOptions{},
+			expectedMessages: []string{
+				`{"stream":"stdout","ts":"2025-01-01T10:00:00Z","line":"started"}`,
+				`{"stream":"stderr","ts":"2025-01-01T10:00:01Z","line":"ERROR failed"}`,
+			},
+		},
+		{
+			name:        "Only stderr",
+			logsOptions: DTO.LogsOptions{Stream: "stderr"},
+			expectedMessages: []string{
+				`{"stream":"stderr","ts":"2025-01-01T10:00:01Z","line":"ERROR failed"}`,
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appRepository := new(mocks.MockAppRepository)
+			appRepository.On("GetApp", mock.Anything, "systemd-1-api.service", 1).Return(&models.App{
+				ID: "systemd-1-api.service", Runtime: models.RuntimeSystemd,
+			}, nil)
+			systemdClient := new(mocks.MockSystemdClient)
+			systemdClient.On("FollowJournal", mock.Anything, "api.service", testScenario.logsOptions).Return(
+				io.NopCloser(strings.NewReader(journal)), nil)
+			wsService := NewWsService(appRepository, loggerService, "", nil, systemdClient, nil, nil)
+
+			upgrader := websocket.Upgrader{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				wsService.Logs(context.Background(), "systemd-1-api.service", 1, testScenario.logsOptions, conn)
+			}))
+			defer server.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			assert.NoError(t, err)
+			defer conn.Close()
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			for _, expectedMessage := range testScenario.expectedMessages {
+				_, message, err := conn.ReadMessage()
+				assert.NoError(t, err)
+				assert.Contains(t, string(message), expectedMessage)
+			}
+		})
+	}
+}
+
+func TestWsService_Logs_Kubernetes(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name             string
+		logsOptions      DTO.LogsOptions
+		expectedMessages []string
+	}
+	logLines := []DTO.LogLine{
+		{Stream: "stdout", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), Line: "started",
+			Source: "api-0/api"},
+		{Stream: "stdout", Timestamp: time.Date(2025, 1, 1, 10, 0, 1, 0, time.UTC), Line: "ERROR failed",
+			Source: "api-1/api"},
+	}
+	testsScenarios := []args{
+		{
+			name:        "Logs of the pods",
+			logsOptions: DTO.LogsOptions{},
+			expectedMessages: []string{
+				`{"stream":"stdout","ts":"2025-01-01T10:00:00Z","line":"started","source":"api-0/api"}`,
+				`{"stream":"stdout","ts":"2025-01-01T10:00:01Z","line":"ERROR failed","source":"api-1/api"}`,
+			},
+		},
+		{
+			name:        "Grep",
+			logsOptions: DTO.LogsOptions{Grep: "ERROR"},
+			expectedMessages: []string{
+				`{"stream":"stdout","ts":"2025-01-01T10:00:01Z","line":"ERROR failed","source":"api-1/api"}`,
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			workload := models.KubernetesWorkload{AppID: "k8s-api", Cluster: "k3s", Namespace: "default",
+				Kind: models.KubernetesKindStatefulSet, Name: "api"}
+			appRepository := new(mocks.MockAppRepository)
+			appRepository.On("GetApp", mock.Anything, "k8s-api", 1).Return(&models.App{
+				ID: "k8s-api", Runtime: models.RuntimeKubernetes,
+			}, nil)
+			kubernetesRepository := new(mocks.MockKubernetesRepository)
+			kubernetesRepository.On("GetKubernetesWorkload", mock.Anything, "k8s-api").Return(&workload, nil)
+			kubernetesClient := new(mocks.MockKubernetesClient)
+			kubernetesClient.On("StreamPodLogs", mock.Anything, workload, testScenario.logsOptions).Return(logLines,
+				nil)
+			wsService := NewWsService(appRepository, loggerService, "", nil, nil, kubernetesRepository,
+				kubernetesClient)
+
+			upgrader := websocket.Upgrader{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				wsService.Logs(context.Background(), "k8s-api", 1, testScenario.logsOptions, conn)
+			}))
+			defer server.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			assert.NoError(t, err)
+			defer conn.Close()
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			for _, expectedMessage := range testScenario.expectedMessages {
+				_, message, err := conn.ReadMessage()
+				assert.NoError(t, err)
+				assert.Contains(t, string(message), expectedMessage)
+			}
+		})
+	}
+}
@@ -15,14 +15,22 @@ type AppRepository interface {
 	GetAppStatus(ctx context.Context, id string, ownerID int) (DTO.AppStatus, error)
 	GetAppsToCheck(ctx context.Context) ([]*models.AppToCheck, error)
 	UpdateApp(ctx context.Context, appID string, app DTO.UpdateApp, ownerID int) error
+	UpdateAppID(ctx context.Context, appID, newAppID string, ownerID int) error
+	UpdateAppStoppedByUser(ctx context.Context, appID string, stoppedByUser bool) error
 	InsertAppStatuses(ctx context.Context, appsStatuses []DTO.AppStatus) error
 	GetUsersToSendNotifications(ctx context.Context, appsStatuses []DTO.AppStatus) ([]models.NotificationInfo, error)
 }
 
 type AppNotificationsService interface {
 	SendNotifications(ctx context.Context, appsStatuses []DTO.AppStatus) error
+	SendLogAlertsNotifications(ctx context.Context, triggeredLogAlerts []DTO.TriggeredLogAlert) error
+	SendRemediationsFailedNotifications(ctx context.Context, failedRemediations []DTO.FailedRemediation) error
+	SendRouteFlowsNotifications(ctx context.Context, routeFlowNotifications []DTO.RouteFlowNotification) error
 }
 type AppStatusService interface {
 	GetAppStatus(ctx context.Context, appID string, ownerID int) (DTO.AppStatus, error)
 	CheckAppsStatus(ctx context.Context) ([]DTO.AppStatus, error)
+	CheckDockerAppStatus(ctx context.Context, containerID string) ([]DTO.AppStatus, error)
+	SetDockerEventsSubscribed(subscribed bool)
+	SetDockerAppStoppedByUser(ctx context.Context, containerID string, stoppedByUser bool) error
 }
@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+)
+
+type ConsoleAuditRepository interface {
+	InsertConsoleAuditLog(ctx context.Context, consoleAuditLog DTO.ConsoleAuditLog) error
+}
@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+)
+
+type ContainerStatsRepository interface {
+	InsertContainersStats(ctx context.Context, containersStats []DTO.ContainerStats) error
+	GetContainerStatsHistory(ctx context.Context, appID string, ownerID int, since time.Time) ([]DTO.ContainerStats,
+		error)
+	DeleteContainersStatsOlderThan(ctx context.Context, olderThan time.Time) error
+}
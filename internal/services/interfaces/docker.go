@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/docker/docker/client"
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+type DockerClientManager interface {
+	GetClient(ctx context.Context, dockerHost string) (client.APIClient, error)
+	ResetClient(dockerHost string)
+}
+
+type DockerRepository interface {
+	GetDockerHost(ctx context.Context, host string) (*models.DockerHost, error)
+	UpsertDockerHost(ctx context.Context, dockerHost models.DockerHost) error
+}
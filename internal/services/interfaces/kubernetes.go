@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+type KubernetesRepository interface {
+	GetKubernetesCluster(ctx context.Context, ownerID int, name string) (*models.KubernetesCluster, error)
+	UpsertKubernetesCluster(ctx context.Context, cluster models.KubernetesCluster) error
+	ImportKubernetesWorkloads(ctx context.Context, apps []DTO.App, workloads []models.KubernetesWorkload) error
+	GetKubernetesWorkload(ctx context.Context, appID string) (*models.KubernetesWorkload, error)
+	GetKubernetesWorkloads(ctx context.Context) ([]models.KubernetesWorkload, error)
+}
+
+type KubernetesClient interface {
+	ListWorkloads(ctx context.Context, ownerID int, cluster, namespace string) ([]DTO.KubernetesWorkload, error)
+	GetWorkload(ctx context.Context, workload models.KubernetesWorkload) (DTO.KubernetesWorkload, error)
+	RestartWorkload(ctx context.Context, workload models.KubernetesWorkload) error
+	StreamPodLogs(ctx context.Context, workload models.KubernetesWorkload, logsOptions DTO.LogsOptions,
+		send func(DTO.LogLine) error) error
+	ResetClient(ownerID int, cluster string)
+}
@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+)
+
+type LogAlertRepository interface {
+	GetLogAlertRules(ctx context.Context, appID string, ownerID int) ([]DTO.LogAlertRule, error)
+	InsertLogAlertRule(ctx context.Context, appID string, logAlertRule DTO.CreateLogAlertRule) (int, error)
+	UpdateLogAlertRule(ctx context.Context, appID string, ruleID, ownerID int, logAlertRule DTO.CreateLogAlertRule) error
+	DeleteLogAlertRule(ctx context.Context, appID string, ruleID, ownerID int) error
+	ValidateLogAlertPattern(ctx context.Context, pattern string) error
+	GetDueLogAlertRules(ctx context.Context, now time.Time) ([]DTO.LogAlertRule, error)
+	CountMatchingLogLines(ctx context.Context, appID, pattern string, since time.Time) (int, error)
+	UpdateLogAlertsLastTriggered(ctx context.Context, rulesIDs []int, triggeredAt time.Time) error
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+)
+
+type LogRepository interface {
+	InsertLogs(ctx context.Context, logs []DTO.AppLog) error
+	GetLastLogTime(ctx context.Context, appID string) (time.Time, error)
+	DeleteExpiredLogs(ctx context.Context) error
+	SearchLogs(ctx context.Context, ownerID int, logsSearch DTO.LogsSearch) (DTO.LogsSearchResult, error)
+	UpdateLogsRetention(ctx context.Context, appID string, ownerID, days int) error
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+)
+
+type Pm2Client interface {
+	ListProcesses(ctx context.Context) ([]DTO.Pm2Process, error)
+	RunAction(ctx context.Context, action, processName string) error
+}
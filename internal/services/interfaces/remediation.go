@@ -0,0 +1,38 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+type RemediationRepository interface {
+	GetRemediationPolicy(ctx context.Context, appID string, ownerID int) (*DTO.RemediationPolicy, error)
+	UpsertRemediationPolicy(ctx context.Context, appID string, policy DTO.UpsertRemediationPolicy) error
+	DeleteRemediationPolicy(ctx context.Context, appID string, ownerID int) error
+	UpdateRemediationMaintenance(ctx context.Context, appID string, ownerID int, maintenanceUntil *time.Time) error
+	GetAppsToRemediate(ctx context.Context, now time.Time) ([]models.AppToRemediate, error)
+	UpdateRemediationState(ctx context.Context, appID string, attempts int, lastAttemptAt *time.Time,
+		escalated bool) error
+	InsertRemediationAttempts(ctx context.Context, remediationAttempts []DTO.RemediationAttempt) error
+	GetRemediationHistory(ctx context.Context, appID string, ownerID int) ([]DTO.RemediationAttempt, error)
+}
+
+// The restarts of the runtimes used by the remediation policies.
+type DockerRestarter interface {
+	RestartContainer(ctx context.Context, appID string) error
+}
+
+type Pm2Restarter interface {
+	RestartApp(ctx context.Context, appID string, ownerID int) error
+}
+
+type SystemdRestarter interface {
+	RestartUnit(ctx context.Context, appID string, ownerID int) error
+}
+
+type KubernetesRestarter interface {
+	RestartWorkload(ctx context.Context, appID string, ownerID int) error
+}
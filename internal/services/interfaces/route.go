@@ -2,15 +2,30 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/models"
 )
 
 type RouteRepository interface {
-	CheckRouteStatus(ctx context.Context, routeID int) (string, error)
-	UpdateWorkingRoutesStatuses(ctx context.Context, routesStatuses map[int]string) error
+	InsertRouteFlow(ctx context.Context, appID, name, env, secrets, httpOptions, notifications string) (int, error)
+	UpdateRouteFlow(ctx context.Context, flowID int, appID string, ownerID int, name string, version int, env string,
+		secrets *string, httpOptions, notifications string, workingRoutes []DTO.WorkingRoute) error
+	DeleteRouteFlow(ctx context.Context, flowID int, appID string, ownerID int) error
+	GetRouteFlows(ctx context.Context, appID string, ownerID int) ([]DTO.RouteFlow, error)
+	GetRouteFlow(ctx context.Context, flowID int, appID string, ownerID, version int) (*DTO.RouteFlow, error)
+	UpdateWorkingRoutesStatuses(ctx context.Context, routesStatuses map[int]models.RouteStepResult) error
 	GetWorkingRoutesToTest(ctx context.Context) ([]models.RouteToTest, error)
+	GetRouteFlowRoutesToTest(ctx context.Context, flowID int, appID string, ownerID int) ([]models.RouteToTest, error)
+	InsertRouteFlowRuns(ctx context.Context, routeFlowRuns []DTO.RouteFlowRun) ([]int, error)
+	FinishRouteFlowRun(ctx context.Context, routeFlowRun DTO.RouteFlowRun) error
+	FailRunningRouteFlowRuns(ctx context.Context, runningStatus, failedStatus string) error
+	GetRouteFlowRuns(ctx context.Context, flowID int, appID string, ownerID int) ([]DTO.RouteFlowRun, error)
+	GetRouteFlowRun(ctx context.Context, runID, flowID int, appID string, ownerID int) (*DTO.RouteFlowRun, error)
+	UpdateRouteFlowFailureStreak(ctx context.Context, flowID int, failed bool) (*models.RouteFlowNotificationState,
+		error)
+	UpdateRouteFlowNotifiedStatus(ctx context.Context, flowID int, notifiedStatus string, notifiedAt time.Time) error
 	InsertRoutesInfo(ctx context.Context, routesInfo []*DTO.RouteInfo) ([]int, error)
 	InsertRoutesRequests(ctx context.Context,
 		routesRequests []*DTO.RouteRequest) ([]int, error)
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+type StackRepository interface {
+	UpsertStack(ctx context.Context, name string, ownerID int) (int, error)
+	GetStack(ctx context.Context, stackID, ownerID int) (*models.Stack, error)
+	GetStacks(ctx context.Context, ownerID int) ([]models.Stack, error)
+}
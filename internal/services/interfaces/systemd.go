@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+)
+
+type SystemdClient interface {
+	ListUnits(ctx context.Context, pattern string) ([]DTO.SystemdUnit, error)
+	GetUnit(ctx context.Context, unitName string) (DTO.SystemdUnit, error)
+	RunAction(ctx context.Context, action, unitName string) error
+	FollowJournal(ctx context.Context, unitName string, logsOptions DTO.LogsOptions) (io.ReadCloser, error)
+}
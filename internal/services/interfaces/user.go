@@ -15,4 +15,5 @@ type UserRepository interface {
 	DeleteUser(ctx context.Context, password string, userID int) error
 	FindUserByID(ctx context.Context, userID int) (models.User, error)
 	ChangeUserPassword(ctx context.Context, userID int, newPassword string) error
+	HasPermission(ctx context.Context, userID int, permission string) (bool, error)
 }
@@ -3,12 +3,16 @@ package thirdPartyServices
 import (
 	"context"
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
 	"sync"
 
 	containerTypes "github.com/docker/docker/api/types/container"
+	imageTypes "github.com/docker/docker/api/types/image"
+	networkTypes "github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
 	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/models"
 	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
@@ -16,39 +20,41 @@ import (
 )
 
 type DockerService struct {
-	appRepository interfaces.AppRepository
-	dockerHost    string
-	logger        utils.LoggerService
+	appRepository       interfaces.AppRepository
+	stackRepository     interfaces.StackRepository
+	dockerClientManager interfaces.DockerClientManager
+	dockerHost          string
+	logger              utils.LoggerService
 }
 
-func NewDockerService(appRepository interfaces.AppRepository, logger utils.LoggerService,
-	dockerHost string,
+func NewDockerService(appRepository interfaces.AppRepository, stackRepository interfaces.StackRepository,
+	logger utils.LoggerService, dockerHost string, dockerClientManager interfaces.DockerClientManager,
 ) *DockerService {
 	return &DockerService{
-		appRepository: appRepository,
-		dockerHost:    dockerHost,
-		logger:        logger,
+		appRepository:       appRepository,
+		stackRepository:     stackRepository,
+		dockerClientManager: dockerClientManager,
+		dockerHost:          dockerHost,
+		logger:              logger,
 	}
 }
 
 func (dc *DockerService) PauseContainer(ctx context.Context, appID string) error {
-	cli, err := client.NewClientWithOpts(client.WithHost(dc.dockerHost), client.WithAPIVersionNegotiation())
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
 	if err != nil {
 		return err
 	}
 
-	defer cli.Close()
 	err = cli.ContainerPause(ctx, appID)
 
 	return err
 }
 
 func (dc *DockerService) RestartContainer(ctx context.Context, appID string) error {
-	cli, err := client.NewClientWithOpts(client.WithHost(dc.dockerHost), client.WithAPIVersionNegotiation())
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
 	if err != nil {
 		return err
 	}
-	defer cli.Close()
 
 	err = cli.ContainerStop(ctx, appID, containerTypes.StopOptions{})
 	if err != nil {
@@ -62,11 +68,10 @@ func (dc *DockerService) RestartContainer(ctx context.Context, appID string) err
 }
 
 func (dc *DockerService) StartContainer(ctx context.Context, appID string) error {
-	cli, err := client.NewClientWithOpts(client.WithHost(dc.dockerHost), client.WithAPIVersionNegotiation())
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
 	if err != nil {
 		return err
 	}
-	defer cli.Close()
 
 	err = cli.ContainerStart(ctx, appID, containerTypes.StartOptions{})
 
@@ -74,11 +79,10 @@ func (dc *DockerService) StartContainer(ctx context.Context, appID string) error
 }
 
 func (dc *DockerService) UnpauseContainer(ctx context.Context, appID string) error {
-	cli, err := client.NewClientWithOpts(client.WithHost(dc.dockerHost), client.WithAPIVersionNegotiation())
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
 	if err != nil {
 		return err
 	}
-	defer cli.Close()
 
 	err = cli.ContainerUnpause(ctx, appID)
 
@@ -86,18 +90,19 @@ func (dc *DockerService) UnpauseContainer(ctx context.Context, appID string) err
 }
 
 func (dc *DockerService) StopContainer(ctx context.Context, appID string) error {
-	cli, err := client.NewClientWithOpts(client.WithHost(dc.dockerHost), client.WithAPIVersionNegotiation())
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
 	if err != nil {
 		return err
 	}
-	defer cli.Close()
 
 	err = cli.ContainerStop(ctx, appID, containerTypes.StopOptions{})
 
 	return err
 }
 
-func (dc *DockerService) prepareContainersDataToInsert(containers []containerTypes.Summary, ownerID int, importedApps []models.App) []DTO.App {
+func (dc *DockerService) prepareContainersDataToInsert(containers []containerTypes.Summary, ownerID int,
+	importedApps []models.App, stackIDs map[string]int,
+) []DTO.App {
 	workerCount := runtime.NumCPU()
 	jobs := make(chan containerTypes.Summary, len(containers))
 	appsChan := make(chan DTO.App, len(containers))
@@ -115,11 +120,16 @@ func (dc *DockerService) prepareContainersDataToInsert(containers []containerTyp
 					}
 				}
 				preparedAppName := job.Names[0][1:]
+				project := job.Labels[composeProjectLabel]
+				if service := job.Labels[composeServiceLabel]; project != "" && service != "" {
+					preparedAppName = project + "/" + service
+				}
 				if len(job.Ports) > 0 {
 					preparedPort := fmt.Sprintf("%d", job.Ports[0].PrivatePort)
-					splittedDockerHost := strings.Split(dc.dockerHost, "//")[1]
-					preparedIPAddress := strings.Split(splittedDockerHost, ":")[0]
-					appsChan <- *DTO.NewApp(job.ID, preparedAppName, "", true, ownerID, preparedIPAddress, preparedPort)
+					app := DTO.NewApp(job.ID, preparedAppName, "", models.RuntimeDocker, ownerID, dc.dockerHostIPAddress(),
+						preparedPort)
+					app.StackID = stackIDs[project]
+					appsChan <- *app
 				}
 			}
 		}()
@@ -138,17 +148,41 @@ func (dc *DockerService) prepareContainersDataToInsert(containers []containerTyp
 	return appsToInsert
 }
 
+// saveComposeStacks creates a stack for every compose project found in the container labels and returns their ids
+// by the project name.
+func (dc *DockerService) saveComposeStacks(ctx context.Context, containers []containerTypes.Summary,
+	ownerID int,
+) (map[string]int, error) {
+	stackIDs := make(map[string]int)
+	for _, container := range containers {
+		project := container.Labels[composeProjectLabel]
+		if project == "" {
+			continue
+		}
+		if _, exists := stackIDs[project]; exists {
+			continue
+		}
+
+		stackID, err := dc.stackRepository.UpsertStack(ctx, project, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		stackIDs[project] = stackID
+	}
+
+	return stackIDs, nil
+}
+
 func (dc *DockerService) ImportContainers(ctx context.Context, ownerID int) error {
 	importedApps, err := dc.appRepository.GetApps(ctx, ownerID)
 	if err != nil {
 		return err
 	}
 
-	cli, err := client.NewClientWithOpts(client.WithHost(dc.dockerHost), client.WithAPIVersionNegotiation())
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
 	if err != nil {
 		return err
 	}
-	defer cli.Close()
 
 	containers, err := cli.ContainerList(ctx, containerTypes.ListOptions{})
 	if err != nil {
@@ -156,7 +190,12 @@ func (dc *DockerService) ImportContainers(ctx context.Context, ownerID int) erro
 		return err
 	}
 
-	appsToInsert := dc.prepareContainersDataToInsert(containers, ownerID, importedApps)
+	stackIDs, err := dc.saveComposeStacks(ctx, containers, ownerID)
+	if err != nil {
+		return err
+	}
+
+	appsToInsert := dc.prepareContainersDataToInsert(containers, ownerID, importedApps, stackIDs)
 	if len(appsToInsert) == 0 {
 		return nil
 	}
@@ -167,3 +206,319 @@ func (dc *DockerService) ImportContainers(ctx context.Context, ownerID int) erro
 
 	return nil
 }
+
+func (dc *DockerService) dockerHostIPAddress() string {
+	splittedDockerHost := strings.Split(dc.dockerHost, "//")[1]
+	return strings.Split(splittedDockerHost, ":")[0]
+}
+
+func (dc *DockerService) pullImage(ctx context.Context, cli client.APIClient, image string) error {
+	pullProgress, err := cli.ImagePull(ctx, image, imageTypes.PullOptions{})
+	if err != nil {
+		dc.logger.Error("failed to pull image", map[string]any{
+			"image": image,
+			"err":   err.Error(),
+		})
+		return err
+	}
+	defer func() {
+		if closeErr := pullProgress.Close(); closeErr != nil {
+			dc.logger.Warn("failed to close image pull progress", closeErr)
+		}
+	}()
+
+	// The pull is finished only when the progress stream is fully read.
+	_, err = io.Copy(io.Discard, pullProgress)
+
+	return err
+}
+
+func (dc *DockerService) getDockerApp(ctx context.Context, appID string, ownerID int) (*models.App, error) {
+	app, err := dc.appRepository.GetApp(ctx, appID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if app.Runtime != models.RuntimeDocker {
+		return nil, models.NewError(400, "Docker", "app is not a docker container")
+	}
+
+	return app, nil
+}
+
+func prepareContainerConfigs(containerToCreate DTO.CreateContainer) (*containerTypes.Config,
+	*containerTypes.HostConfig, error,
+) {
+	exposedPorts := nat.PortSet{}
+	portBindings := nat.PortMap{}
+	for _, containerPort := range containerToCreate.Ports {
+		protocol := containerPort.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		port, err := nat.NewPort(protocol, containerPort.ContainerPort)
+		if err != nil {
+			return nil, nil, models.NewError(400, "Validation", fmt.Sprintf("invalid container port %s: %s",
+				containerPort.ContainerPort, err.Error()))
+		}
+		exposedPorts[port] = struct{}{}
+		portBindings[port] = append(portBindings[port], nat.PortBinding{HostPort: containerPort.HostPort})
+	}
+
+	config := &containerTypes.Config{
+		Image:        containerToCreate.Image,
+		Env:          containerToCreate.Env,
+		Labels:       containerToCreate.Labels,
+		ExposedPorts: exposedPorts,
+	}
+	hostConfig := &containerTypes.HostConfig{
+		Binds:        containerToCreate.Volumes,
+		PortBindings: portBindings,
+		RestartPolicy: containerTypes.RestartPolicy{
+			Name: containerTypes.RestartPolicyMode(containerToCreate.RestartPolicy),
+		},
+	}
+
+	return config, hostConfig, nil
+}
+
+func (dc *DockerService) CreateContainer(ctx context.Context, containerToCreate DTO.CreateContainer,
+	ownerID int,
+) (string, error) {
+	config, hostConfig, err := prepareContainerConfigs(containerToCreate)
+	if err != nil {
+		return "", err
+	}
+
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
+	if err != nil {
+		return "", err
+	}
+
+	err = dc.pullImage(ctx, cli, containerToCreate.Image)
+	if err != nil {
+		return "", err
+	}
+
+	createdContainer, err := cli.ContainerCreate(ctx, config, hostConfig, nil, nil, containerToCreate.Name)
+	if err != nil {
+		dc.logger.Error("failed to create container", err)
+		return "", err
+	}
+
+	err = cli.ContainerStart(ctx, createdContainer.ID, containerTypes.StartOptions{})
+	if err != nil {
+		dc.logger.Error("failed to start created container", err)
+		dc.removeContainer(ctx, cli, createdContainer.ID)
+		return "", err
+	}
+
+	port := ""
+	if len(containerToCreate.Ports) > 0 {
+		port = containerToCreate.Ports[0].HostPort
+	}
+	app := DTO.NewApp(createdContainer.ID, containerToCreate.Name, "", models.RuntimeDocker, ownerID, dc.dockerHostIPAddress(), port)
+	err = dc.appRepository.InsertApp(ctx, []DTO.App{*app})
+	if err != nil {
+		dc.removeContainer(ctx, cli, createdContainer.ID)
+		return "", err
+	}
+
+	return createdContainer.ID, nil
+}
+
+func (dc *DockerService) RemoveContainer(ctx context.Context, appID string, ownerID int, removeVolumes bool) error {
+	_, err := dc.getDockerApp(ctx, appID, ownerID)
+	if err != nil {
+		return err
+	}
+
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
+	if err != nil {
+		return err
+	}
+
+	err = cli.ContainerRemove(ctx, appID, containerTypes.RemoveOptions{
+		RemoveVolumes: removeVolumes,
+		Force:         true,
+	})
+	if err != nil {
+		dc.logger.Error("failed to remove container", err)
+		return err
+	}
+
+	return dc.appRepository.DeleteApp(ctx, appID, ownerID)
+}
+
+func (dc *DockerService) RenameContainer(ctx context.Context, appID string, ownerID int, name string) error {
+	app, err := dc.getDockerApp(ctx, appID, ownerID)
+	if err != nil {
+		return err
+	}
+
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
+	if err != nil {
+		return err
+	}
+
+	err = cli.ContainerRename(ctx, appID, name)
+	if err != nil {
+		dc.logger.Error("failed to rename container", err)
+		return err
+	}
+
+	err = dc.appRepository.UpdateApp(ctx, appID, DTO.UpdateApp{
+		Name:              name,
+		Description:       app.Description,
+		IPAddress:         app.IPAddress,
+		Port:              app.Port,
+		DiscordWebhookURL: app.DiscordWebhookURL,
+		SlackWebhookURL:   app.SlackWebhookURL,
+	}, ownerID)
+	if err != nil {
+		// The container keeps the name which is saved in the db.
+		renameErr := cli.ContainerRename(ctx, appID, app.Name)
+		if renameErr != nil {
+			dc.logger.Error("failed to restore name of the container", map[string]any{
+				"appID": appID,
+				"err":   renameErr.Error(),
+			})
+		}
+		return err
+	}
+
+	return nil
+}
+
+// removeContainer removes a container which was created by a failed operation, the error of the operation is
+// returned to the user so the failure of the cleanup is only logged.
+func (dc *DockerService) removeContainer(ctx context.Context, cli client.APIClient, appID string) {
+	err := cli.ContainerRemove(ctx, appID, containerTypes.RemoveOptions{Force: true})
+	if err != nil {
+		dc.logger.Error("failed to remove container of the failed operation", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+	}
+}
+
+func (dc *DockerService) restoreContainer(ctx context.Context, cli client.APIClient, appID, name string,
+	wasRunning bool,
+) {
+	err := cli.ContainerRename(ctx, appID, name)
+	if err != nil {
+		dc.logger.Error("failed to restore name of the replaced container", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+	}
+	if !wasRunning {
+		return
+	}
+
+	err = cli.ContainerStart(ctx, appID, containerTypes.StartOptions{})
+	if err != nil {
+		dc.logger.Error("failed to start the replaced container", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+	}
+}
+
+// RecreateContainer pulls the image and replaces the container with a new one which keeps the name and the
+// configuration of the old one. The old container is restored when the new one can not be created or the app can
+// not be moved to it.
+func (dc *DockerService) RecreateContainer(ctx context.Context, appID string, ownerID int, image string) (string,
+	error,
+) {
+	_, err := dc.getDockerApp(ctx, appID, ownerID)
+	if err != nil {
+		return "", err
+	}
+
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
+	if err != nil {
+		return "", err
+	}
+
+	oldContainer, err := cli.ContainerInspect(ctx, appID)
+	if err != nil {
+		dc.logger.Error("failed to inspect container", err)
+		return "", err
+	}
+	if image == "" {
+		image = oldContainer.Config.Image
+	}
+
+	err = dc.pullImage(ctx, cli, image)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimPrefix(oldContainer.Name, "/")
+	wasRunning := oldContainer.State != nil && oldContainer.State.Running
+	err = cli.ContainerStop(ctx, oldContainer.ID, containerTypes.StopOptions{})
+	if err != nil {
+		dc.logger.Error("failed to stop container", err)
+		return "", err
+	}
+	err = cli.ContainerRename(ctx, oldContainer.ID, name+"-octopus-old")
+	if err != nil {
+		dc.logger.Error("failed to rename container", err)
+		dc.restoreContainer(ctx, cli, oldContainer.ID, name, wasRunning)
+		return "", err
+	}
+
+	config := oldContainer.Config
+	config.Image = image
+	// The default hostname is the short id of the old container, docker generates a new one.
+	if strings.HasPrefix(oldContainer.ID, config.Hostname) {
+		config.Hostname = ""
+	}
+	networkingConfig := &networkTypes.NetworkingConfig{
+		EndpointsConfig: make(map[string]*networkTypes.EndpointSettings),
+	}
+	if oldContainer.NetworkSettings != nil {
+		for networkName, endpoint := range oldContainer.NetworkSettings.Networks {
+			networkingConfig.EndpointsConfig[networkName] = &networkTypes.EndpointSettings{
+				IPAMConfig: endpoint.IPAMConfig,
+				Aliases:    endpoint.Aliases,
+			}
+		}
+	}
+
+	newContainer, err := cli.ContainerCreate(ctx, config, oldContainer.HostConfig, networkingConfig, nil, name)
+	if err != nil {
+		dc.logger.Error("failed to create container", err)
+		dc.restoreContainer(ctx, cli, oldContainer.ID, name, wasRunning)
+		return "", err
+	}
+
+	if wasRunning {
+		err = cli.ContainerStart(ctx, newContainer.ID, containerTypes.StartOptions{})
+		if err != nil {
+			dc.logger.Error("failed to start recreated container", err)
+			dc.removeContainer(ctx, cli, newContainer.ID)
+			dc.restoreContainer(ctx, cli, oldContainer.ID, name, wasRunning)
+			return "", err
+		}
+	}
+
+	// The app is moved to the new container before the old one is removed, so it never points at a removed
+	// container.
+	err = dc.appRepository.UpdateAppID(ctx, appID, newContainer.ID, ownerID)
+	if err != nil {
+		dc.removeContainer(ctx, cli, newContainer.ID)
+		dc.restoreContainer(ctx, cli, oldContainer.ID, name, wasRunning)
+		return "", err
+	}
+
+	err = cli.ContainerRemove(ctx, oldContainer.ID, containerTypes.RemoveOptions{Force: true})
+	if err != nil {
+		dc.logger.Warn("failed to remove replaced container", map[string]any{
+			"appID": oldContainer.ID,
+			"err":   err.Error(),
+		})
+	}
+
+	return newContainer.ID, nil
+}
@@ -0,0 +1,242 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+const defaultConsoleShell = "/bin/sh"
+
+var consoleShells = []string{"/bin/sh", "/bin/bash", "/bin/ash", "/bin/zsh"}
+
+const (
+	consoleMessageInput  = "input"
+	consoleMessageResize = "resize"
+)
+
+type DockerConsoleService struct {
+	appRepository          interfaces.AppRepository
+	consoleAuditRepository interfaces.ConsoleAuditRepository
+	dockerClientManager    interfaces.DockerClientManager
+	dockerHost             string
+	logger                 utils.LoggerService
+}
+
+func NewDockerConsoleService(appRepository interfaces.AppRepository,
+	consoleAuditRepository interfaces.ConsoleAuditRepository, logger utils.LoggerService, dockerHost string,
+	dockerClientManager interfaces.DockerClientManager,
+) *DockerConsoleService {
+	return &DockerConsoleService{
+		appRepository:          appRepository,
+		consoleAuditRepository: consoleAuditRepository,
+		dockerClientManager:    dockerClientManager,
+		dockerHost:             dockerHost,
+		logger:                 logger,
+	}
+}
+
+// consoleCommandRecorder rebuilds the command lines typed into the terminal from the raw keystrokes, so they can
+// be written to the audit log. Escape sequences like arrow keys are skipped, so the recorded line is the typed
+// one, not the one completed or picked from the history by the shell.
+type consoleCommandRecorder struct {
+	line []rune
+	// 0 - no escape sequence, 1 - after ESC, 2 - inside of a CSI or SS3 sequence
+	escapeState int
+}
+
+func (cr *consoleCommandRecorder) Write(input string) []string {
+	commands := make([]string, 0)
+	for _, character := range input {
+		switch cr.escapeState {
+		case 1:
+			cr.escapeState = 0
+			if character == '[' || character == 'O' {
+				cr.escapeState = 2
+			}
+			continue
+		case 2:
+			if character >= 0x40 && character <= 0x7e {
+				cr.escapeState = 0
+			}
+			continue
+		}
+
+		switch character {
+		case '\x1b':
+			cr.escapeState = 1
+		case '\r', '\n':
+			command := strings.TrimSpace(string(cr.line))
+			cr.line = cr.line[:0]
+			if command != "" {
+				commands = append(commands, command)
+			}
+		case '\x7f', '\b':
+			if len(cr.line) > 0 {
+				cr.line = cr.line[:len(cr.line)-1]
+			}
+		case '\x03', '\x15':
+			cr.line = cr.line[:0]
+		default:
+			if unicode.IsPrint(character) || character == '\t' {
+				cr.line = append(cr.line, character)
+			}
+		}
+	}
+
+	return commands
+}
+
+func (dc *DockerConsoleService) auditCommand(sessionID, appID string, userID int, command string) {
+	consoleAuditLog := DTO.ConsoleAuditLog{
+		SessionID: sessionID,
+		AppID:     appID,
+		UserID:    userID,
+		Command:   command,
+		CreatedAt: time.Now().UTC(),
+	}
+	dc.logger.Info("console command", consoleAuditLog)
+	err := dc.consoleAuditRepository.InsertConsoleAuditLog(context.Background(), consoleAuditLog)
+	if err != nil {
+		dc.logger.Warn("failed to save console audit log", err)
+	}
+}
+
+// Console starts the shell in the container with a TTY and attaches it to the websocket. The output of the
+// shell is sent as binary messages, the client sends DTO.ConsoleMessage as JSON.
+func (dc *DockerConsoleService) Console(ctx context.Context, appID string, ownerID int, shell string,
+	conn *websocket.Conn,
+) {
+	if shell == "" {
+		shell = defaultConsoleShell
+	}
+	if !slices.Contains(consoleShells, shell) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("shell is not allowed, use one of: "+
+			strings.Join(consoleShells, ", ")))
+		return
+	}
+
+	app, err := dc.appRepository.GetApp(ctx, appID, ownerID)
+	if err != nil {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+	if app.Runtime != models.RuntimeDocker {
+		err := models.NewError(400, "Docker", "app is not a docker container")
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+
+	cli, err := dc.dockerClientManager.GetClient(ctx, dc.dockerHost)
+	if err != nil {
+		dc.logger.Error("Error creating Docker client", err)
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+
+	execCreated, err := cli.ContainerExecCreate(ctx, appID, containerTypes.ExecOptions{
+		Tty:          true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          []string{"TERM=xterm-256color"},
+		Cmd:          []string{shell},
+	})
+	if err != nil {
+		dc.logger.Error("failed to create exec instance", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+
+	hijackedConnection, err := cli.ContainerExecAttach(ctx, execCreated.ID, containerTypes.ExecAttachOptions{
+		Tty: true,
+	})
+	if err != nil {
+		dc.logger.Error("failed to attach to exec instance", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+	defer hijackedConnection.Close()
+
+	sessionID := execCreated.ID
+	dc.auditCommand(sessionID, appID, ownerID, shell)
+
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		buffer := make([]byte, 4096)
+		for {
+			n, err := hijackedConnection.Reader.Read(buffer)
+			if n > 0 {
+				if writeErr := conn.WriteMessage(websocket.BinaryMessage, buffer[:n]); writeErr != nil {
+					return
+				}
+			}
+			if err != nil {
+				// The shell has exited, closing the websocket stops reading the input.
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, "session ended"),
+					time.Now().Add(time.Second))
+				_ = conn.Close()
+				return
+			}
+		}
+	}()
+
+	commandRecorder := &consoleCommandRecorder{}
+	for {
+		_, payload, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+
+		var message DTO.ConsoleMessage
+		if err := json.Unmarshal(payload, &message); err != nil {
+			dc.logger.Warn("received malformed console message", err)
+			continue
+		}
+
+		switch message.Type {
+		case consoleMessageInput:
+			if _, err := hijackedConnection.Conn.Write([]byte(message.Data)); err != nil {
+				dc.logger.Warn("failed to write console input", err)
+				break
+			}
+			for _, command := range commandRecorder.Write(message.Data) {
+				dc.auditCommand(sessionID, appID, ownerID, command)
+			}
+		case consoleMessageResize:
+			err := cli.ContainerExecResize(ctx, execCreated.ID, containerTypes.ResizeOptions{
+				Height: message.Rows,
+				Width:  message.Cols,
+			})
+			if err != nil {
+				dc.logger.Warn("failed to resize console", err)
+			}
+		}
+	}
+
+	hijackedConnection.Close()
+	<-outputDone
+	dc.logger.Info("console session ended", map[string]any{
+		"sessionID": sessionID,
+		"appID":     appID,
+		"userID":    ownerID,
+	})
+}
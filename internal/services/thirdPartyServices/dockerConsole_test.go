@@ -0,0 +1,117 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConsoleCommandRecorder_Write(t *testing.T) {
+	type args struct {
+		name             string
+		inputs           []string
+		expectedCommands []string
+	}
+	testsScenarios := []args{
+		{
+			name:             "Command typed at once",
+			inputs:           []string{"ls -la\r"},
+			expectedCommands: []string{"ls -la"},
+		},
+		{
+			name:             "Command typed key by key with backspace",
+			inputs:           []string{"l", "x", "\x7f", "s", "\r"},
+			expectedCommands: []string{"ls"},
+		},
+		{
+			name:             "Arrow keys are skipped",
+			inputs:           []string{"cat\x1b[A\x1bOB file\r"},
+			expectedCommands: []string{"cat file"},
+		},
+		{
+			name:             "Ctrl+C discards the line",
+			inputs:           []string{"rm -rf /\x03", "whoami\r", "\r"},
+			expectedCommands: []string{"whoami"},
+		},
+		{
+			name:             "Many commands in one input",
+			inputs:           []string{"cd /app\rls\n"},
+			expectedCommands: []string{"cd /app", "ls"},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			commandRecorder := &consoleCommandRecorder{}
+			commands := make([]string, 0)
+			for _, input := range testScenario.inputs {
+				commands = append(commands, commandRecorder.Write(input)...)
+			}
+			assert.Equal(t, testScenario.expectedCommands, commands)
+		})
+	}
+}
+
+func TestDockerConsoleService_Console(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name            string
+		shell           string
+		expectedMessage string
+		setupMock       func() *mocks.MockAppRepository
+	}
+	testsScenarios := []args{
+		{
+			name:            "Shell is not allowed",
+			shell:           "/usr/bin/python3",
+			expectedMessage: "shell is not allowed",
+			setupMock: func() *mocks.MockAppRepository {
+				return new(mocks.MockAppRepository)
+			},
+		},
+		{
+			name:            "App is not a docker container",
+			shell:           "",
+			expectedMessage: "app is not a docker container",
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "app", 1).Return(&models.App{ID: "app"}, nil)
+				return m
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			dockerClientManager := tests.CreateDockerClientManager(loggerService)
+			defer dockerClientManager.Close()
+			dockerConsoleService := NewDockerConsoleService(testScenario.setupMock(),
+				new(mocks.MockConsoleAuditRepository), loggerService, "tcp://127.0.0.1:1", dockerClientManager)
+
+			upgrader := websocket.Upgrader{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				dockerConsoleService.Console(context.Background(), "app", 1, testScenario.shell, conn)
+			}))
+			defer server.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			assert.NoError(t, err)
+			defer conn.Close()
+			_, message, err := conn.ReadMessage()
+			assert.NoError(t, err)
+			assert.Contains(t, string(message), testScenario.expectedMessage)
+		})
+	}
+}
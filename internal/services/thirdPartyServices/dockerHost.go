@@ -0,0 +1,86 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type DockerHostService struct {
+	dockerRepository    interfaces.DockerRepository
+	dockerClientManager interfaces.DockerClientManager
+	encryptionKey       string
+	logger              utils.LoggerService
+}
+
+func NewDockerHostService(dockerRepository interfaces.DockerRepository,
+	dockerClientManager interfaces.DockerClientManager, encryptionKey string, logger utils.LoggerService,
+) *DockerHostService {
+	return &DockerHostService{
+		dockerRepository:    dockerRepository,
+		dockerClientManager: dockerClientManager,
+		encryptionKey:       encryptionKey,
+		logger:              logger,
+	}
+}
+
+func (dh *DockerHostService) validateTLSCredentials(dockerHost DTO.DockerHost) error {
+	if (dockerHost.TLSCert == "") != (dockerHost.TLSKey == "") {
+		return models.NewError(400, "Validation", "client certificate and client key have to be provided together")
+	}
+
+	if dockerHost.TLSCert != "" {
+		if _, err := tls.X509KeyPair([]byte(dockerHost.TLSCert), []byte(dockerHost.TLSKey)); err != nil {
+			return models.NewError(400, "Validation", "provided client certificate or key is invalid: "+err.Error())
+		}
+	}
+
+	if dockerHost.TLSCACert != "" {
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(dockerHost.TLSCACert)) {
+			return models.NewError(400, "Validation", "provided CA bundle does not contain any PEM certificate")
+		}
+	}
+
+	return nil
+}
+
+func (dh *DockerHostService) SaveDockerHost(ctx context.Context, dockerHost DTO.DockerHost) error {
+	err := dh.validateTLSCredentials(dockerHost)
+	if err != nil {
+		return err
+	}
+
+	encryptedDockerHost := models.DockerHost{
+		Host:            dockerHost.Host,
+		SSHIdentityFile: dockerHost.SSHIdentityFile,
+	}
+	fieldsToEncrypt := []struct {
+		data      string
+		encrypted *string
+	}{
+		{dockerHost.TLSCACert, &encryptedDockerHost.TLSCACert},
+		{dockerHost.TLSCert, &encryptedDockerHost.TLSCert},
+		{dockerHost.TLSKey, &encryptedDockerHost.TLSKey},
+	}
+	for _, field := range fieldsToEncrypt {
+		*field.encrypted, err = utils.Encrypt(field.data, dh.encryptionKey)
+		if err != nil {
+			dh.logger.Error("failed to encrypt docker host credentials", err)
+			return models.NewError(500, "Server", "internal server error")
+		}
+	}
+
+	err = dh.dockerRepository.UpsertDockerHost(ctx, encryptedDockerHost)
+	if err != nil {
+		return err
+	}
+
+	dh.dockerClientManager.ResetClient(dockerHost.Host)
+
+	return nil
+}
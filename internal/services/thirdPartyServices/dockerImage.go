@@ -0,0 +1,235 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	imageTypes "github.com/docker/docker/api/types/image"
+	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+type DockerImageService struct {
+	dockerClientManager interfaces.DockerClientManager
+	dockerHost          string
+	logger              utils.LoggerService
+}
+
+func NewDockerImageService(logger utils.LoggerService, dockerHost string,
+	dockerClientManager interfaces.DockerClientManager,
+) *DockerImageService {
+	return &DockerImageService{
+		dockerClientManager: dockerClientManager,
+		dockerHost:          dockerHost,
+		logger:              logger,
+	}
+}
+
+func (di *DockerImageService) ListImages(ctx context.Context) ([]DTO.DockerImage, error) {
+	cli, err := di.dockerClientManager.GetClient(ctx, di.dockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := cli.ImageList(ctx, imageTypes.ListOptions{})
+	if err != nil {
+		di.logger.Error("failed to list images", err)
+		return nil, err
+	}
+
+	containers, err := cli.ContainerList(ctx, containerTypes.ListOptions{All: true})
+	if err != nil {
+		di.logger.Error("failed to list containers", err)
+		return nil, err
+	}
+
+	containersByImage := make(map[string][]string, len(images))
+	for _, container := range containers {
+		if len(container.Names) == 0 {
+			continue
+		}
+		containersByImage[container.ImageID] = append(containersByImage[container.ImageID],
+			strings.TrimPrefix(container.Names[0], "/"))
+	}
+
+	dockerImages := make([]DTO.DockerImage, 0, len(images))
+	for _, image := range images {
+		usedBy := containersByImage[image.ID]
+		if usedBy == nil {
+			usedBy = []string{}
+		}
+		tags := image.RepoTags
+		if tags == nil {
+			tags = []string{}
+		}
+		dockerImages = append(dockerImages, DTO.DockerImage{
+			ID:         image.ID,
+			Tags:       tags,
+			Size:       image.Size,
+			Created:    time.Unix(image.Created, 0).UTC(),
+			Containers: usedBy,
+		})
+	}
+
+	return dockerImages, nil
+}
+
+// PullImage pulls the image and forwards every progress message of the daemon to the websocket as JSON.
+func (di *DockerImageService) PullImage(ctx context.Context, image string, conn *websocket.Conn) {
+	if !isValidImageReference(image) {
+		_ = conn.WriteJSON(DTO.ImagePullProgress{Error: "image reference is invalid"})
+		return
+	}
+
+	cli, err := di.dockerClientManager.GetClient(ctx, di.dockerHost)
+	if err != nil {
+		di.logger.Error("Error creating Docker client", err)
+		_ = conn.WriteJSON(DTO.ImagePullProgress{Error: err.Error()})
+		return
+	}
+
+	pullProgress, err := cli.ImagePull(ctx, image, imageTypes.PullOptions{})
+	if err != nil {
+		di.logger.Error("failed to pull image", map[string]any{
+			"image": image,
+			"err":   err.Error(),
+		})
+		_ = conn.WriteJSON(DTO.ImagePullProgress{Error: err.Error()})
+		return
+	}
+	defer func() {
+		if closeErr := pullProgress.Close(); closeErr != nil {
+			di.logger.Warn("failed to close image pull progress", closeErr)
+		}
+	}()
+
+	decoder := json.NewDecoder(pullProgress)
+	for {
+		var message struct {
+			DTO.ImagePullProgress
+			ErrorDetail *struct {
+				Message string `json:"message"`
+			} `json:"errorDetail"`
+		}
+		err := decoder.Decode(&message)
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			di.logger.Error("failed to read image pull progress", err)
+			_ = conn.WriteJSON(DTO.ImagePullProgress{Error: err.Error()})
+			return
+		}
+		if message.ErrorDetail != nil && message.Error == "" {
+			message.Error = message.ErrorDetail.Message
+		}
+
+		if err := conn.WriteJSON(message.ImagePullProgress); err != nil {
+			if !websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				di.logger.Info("Client disconnected")
+			}
+			return
+		}
+	}
+}
+
+func (di *DockerImageService) RemoveImage(ctx context.Context, imageID string, force bool) error {
+	cli, err := di.dockerClientManager.GetClient(ctx, di.dockerHost)
+	if err != nil {
+		return err
+	}
+
+	_, err = cli.ImageRemove(ctx, imageID, imageTypes.RemoveOptions{
+		Force:         force,
+		PruneChildren: true,
+	})
+	if err != nil {
+		di.logger.Error("failed to remove image", map[string]any{
+			"imageID": imageID,
+			"err":     err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (di *DockerImageService) PruneImages(ctx context.Context) (DTO.ImagesPruneReport, error) {
+	cli, err := di.dockerClientManager.GetClient(ctx, di.dockerHost)
+	if err != nil {
+		return DTO.ImagesPruneReport{}, err
+	}
+
+	pruneReport, err := cli.ImagesPrune(ctx, filters.NewArgs(filters.Arg("dangling", "true")))
+	if err != nil {
+		di.logger.Error("failed to prune images", err)
+		return DTO.ImagesPruneReport{}, err
+	}
+
+	imagesDeleted := make([]string, 0, len(pruneReport.ImagesDeleted))
+	for _, deletedImage := range pruneReport.ImagesDeleted {
+		if deletedImage.Deleted != "" {
+			imagesDeleted = append(imagesDeleted, deletedImage.Deleted)
+		}
+	}
+
+	return DTO.ImagesPruneReport{
+		ImagesDeleted:  imagesDeleted,
+		SpaceReclaimed: pruneReport.SpaceReclaimed,
+	}, nil
+}
+
+func (di *DockerImageService) GetImageHistory(ctx context.Context, imageID string) (DTO.ImageHistory, error) {
+	cli, err := di.dockerClientManager.GetClient(ctx, di.dockerHost)
+	if err != nil {
+		return DTO.ImageHistory{}, err
+	}
+
+	imageInspect, err := cli.ImageInspect(ctx, imageID)
+	if err != nil {
+		di.logger.Error("failed to inspect image", err)
+		return DTO.ImageHistory{}, err
+	}
+
+	history, err := cli.ImageHistory(ctx, imageID)
+	if err != nil {
+		di.logger.Error("failed to read image history", err)
+		return DTO.ImageHistory{}, err
+	}
+
+	imageHistory := DTO.ImageHistory{
+		Layers:  []string{},
+		History: make([]DTO.ImageHistoryEntry, 0, len(history)),
+	}
+	if imageInspect.RootFS.Layers != nil {
+		imageHistory.Layers = imageInspect.RootFS.Layers
+	}
+	for _, historyItem := range history {
+		tags := historyItem.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		imageHistory.History = append(imageHistory.History, DTO.ImageHistoryEntry{
+			ID:        historyItem.ID,
+			Created:   time.Unix(historyItem.Created, 0).UTC(),
+			CreatedBy: historyItem.CreatedBy,
+			Size:      historyItem.Size,
+			Tags:      tags,
+			Comment:   historyItem.Comment,
+		})
+	}
+
+	return imageHistory, nil
+}
+
+func isValidImageReference(image string) bool {
+	return image != "" && len(image) <= 256 && !strings.ContainsAny(image, " \t\n")
+}
@@ -0,0 +1,201 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerImageService_ListImages(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name           string
+		handlers       map[string]http.HandlerFunc
+		expectedImages []DTO.DockerImage
+		expectedError  error
+	}
+	testsScenarios := []args{
+		{
+			name: "Test with proper data",
+			handlers: map[string]http.HandlerFunc{
+				"GET /images/json": func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`[{"Id":"sha256:1","RepoTags":["nginx:1.27"],"Size":100,"Created":0},
+						{"Id":"sha256:2","RepoTags":null,"Size":50,"Created":0}]`))
+				},
+				"GET /containers/json": func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`[{"Id":"c1","Names":["/web"],"ImageID":"sha256:1"}]`))
+				},
+			},
+			expectedImages: []DTO.DockerImage{
+				{
+					ID:         "sha256:1",
+					Tags:       []string{"nginx:1.27"},
+					Size:       100,
+					Created:    time.Unix(0, 0).UTC(),
+					Containers: []string{"web"},
+				},
+				{
+					ID:         "sha256:2",
+					Tags:       []string{},
+					Size:       50,
+					Created:    time.Unix(0, 0).UTC(),
+					Containers: []string{},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name:           "Daemon error",
+			handlers:       map[string]http.HandlerFunc{},
+			expectedImages: nil,
+			expectedError:  errors.New("No such container"),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			server := tests.NewFakeDockerAPIServer(testScenario.handlers)
+			defer server.Close()
+			dockerClientManager := tests.CreateDockerClientManager(loggerService)
+			defer dockerClientManager.Close()
+
+			dockerImageService := NewDockerImageService(loggerService, tests.FakeDockerHost(server),
+				dockerClientManager)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+			images, err := dockerImageService.ListImages(ctx)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			assert.Equal(t, testScenario.expectedImages, images)
+		})
+	}
+}
+
+func TestDockerImageService_PullImage(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name             string
+		image            string
+		expectedMessages []DTO.ImagePullProgress
+	}
+	testsScenarios := []args{
+		{
+			name:  "Test with proper data",
+			image: "nginx:1.27",
+			expectedMessages: []DTO.ImagePullProgress{
+				{Status: "Pulling from library/nginx", ID: "1.27"},
+				{Status: "Downloading", ID: "a2318d6c47ec", Progress: "[=>   ] 1MB/10MB"},
+				{Status: "Status: Downloaded newer image for nginx:1.27"},
+			},
+		},
+		{
+			name:  "Pull error reported by daemon",
+			image: "nginx:missing",
+			expectedMessages: []DTO.ImagePullProgress{
+				{Error: "manifest for nginx:missing not found"},
+			},
+		},
+		{
+			name:  "Invalid image reference",
+			image: "",
+			expectedMessages: []DTO.ImagePullProgress{
+				{Error: "image reference is invalid"},
+			},
+		},
+	}
+	dockerServer := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+		"POST /images/create": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("tag") == "missing" {
+				_, _ = w.Write([]byte(`{"errorDetail":{"message":"manifest for nginx:missing not found"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"Pulling from library/nginx","id":"1.27"}
+{"status":"Downloading","id":"a2318d6c47ec","progress":"[=>   ] 1MB/10MB","progressDetail":{"current":1}}
+{"status":"Status: Downloaded newer image for nginx:1.27"}`))
+		},
+	})
+	defer dockerServer.Close()
+	dockerClientManager := tests.CreateDockerClientManager(loggerService)
+	defer dockerClientManager.Close()
+	dockerImageService := NewDockerImageService(loggerService, tests.FakeDockerHost(dockerServer), dockerClientManager)
+
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				upgrader := websocket.Upgrader{}
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				dockerImageService.PullImage(r.Context(), testScenario.image, conn)
+			}))
+			defer wsServer.Close()
+
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(wsServer.URL, "http"), nil)
+			assert.NoError(t, err)
+			defer conn.Close()
+
+			messages := make([]DTO.ImagePullProgress, 0, len(testScenario.expectedMessages))
+			for {
+				var message DTO.ImagePullProgress
+				if err := conn.ReadJSON(&message); err != nil {
+					break
+				}
+				messages = append(messages, message)
+			}
+			assert.Equal(t, testScenario.expectedMessages, messages)
+		})
+	}
+}
+
+func TestDockerImageService_GetImageHistory(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	server := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+		"GET /images/sha256:1/json": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"Id":"sha256:1","RootFS":{"Type":"layers","Layers":["sha256:l1","sha256:l2"]}}`))
+		},
+		"GET /images/sha256:1/history": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"Id":"sha256:1","Created":0,"CreatedBy":"CMD [\"nginx\"]","Size":0,
+				"Tags":["nginx:1.27"],"Comment":""}]`))
+		},
+	})
+	defer server.Close()
+	dockerClientManager := tests.CreateDockerClientManager(loggerService)
+	defer dockerClientManager.Close()
+
+	dockerImageService := NewDockerImageService(loggerService, tests.FakeDockerHost(server), dockerClientManager)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	imageHistory, err := dockerImageService.GetImageHistory(ctx, "sha256:1")
+	assert.NoError(t, err)
+	assert.Equal(t, DTO.ImageHistory{
+		Layers: []string{"sha256:l1", "sha256:l2"},
+		History: []DTO.ImageHistoryEntry{
+			{
+				ID:        "sha256:1",
+				Created:   time.Unix(0, 0).UTC(),
+				CreatedBy: `CMD ["nginx"]`,
+				Size:      0,
+				Tags:      []string{"nginx:1.27"},
+				Comment:   "",
+			},
+		},
+	}, imageHistory)
+}
@@ -0,0 +1,296 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+const (
+	composeProjectLabel   = "com.docker.compose.project"
+	composeServiceLabel   = "com.docker.compose.service"
+	composeDependsOnLabel = "com.docker.compose.depends_on"
+	// Services are required by default, the label set to "false" marks a service which may be down.
+	stackRequiredLabel = "com.octopus.required"
+)
+
+const (
+	stackStatusRunning = "running"
+	stackStatusDown    = "down"
+)
+
+type DockerStackService struct {
+	stackRepository     interfaces.StackRepository
+	dockerClientManager interfaces.DockerClientManager
+	dockerHost          string
+	logger              utils.LoggerService
+}
+
+func NewDockerStackService(stackRepository interfaces.StackRepository, logger utils.LoggerService,
+	dockerHost string, dockerClientManager interfaces.DockerClientManager,
+) *DockerStackService {
+	return &DockerStackService{
+		stackRepository:     stackRepository,
+		dockerClientManager: dockerClientManager,
+		dockerHost:          dockerHost,
+		logger:              logger,
+	}
+}
+
+func (ds *DockerStackService) listStackContainers(ctx context.Context, cli client.APIClient,
+	label string,
+) ([]containerTypes.Summary, error) {
+	containers, err := cli.ContainerList(ctx, containerTypes.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", label)),
+	})
+	if err != nil {
+		ds.logger.Error("failed to list stack containers", map[string]any{
+			"label": label,
+			"err":   err.Error(),
+		})
+		return nil, err
+	}
+
+	return containers, nil
+}
+
+func prepareStack(stack models.Stack, containers []containerTypes.Summary) DTO.Stack {
+	preparedStack := DTO.Stack{
+		ID:       stack.ID,
+		Name:     stack.Name,
+		Status:   stackStatusRunning,
+		Services: make([]DTO.StackService, 0, len(containers)),
+	}
+	for _, container := range containers {
+		required := container.Labels[stackRequiredLabel] != "false"
+		preparedStack.Services = append(preparedStack.Services, DTO.StackService{
+			AppID:    container.ID,
+			Service:  container.Labels[composeServiceLabel],
+			State:    string(container.State),
+			Required: required,
+		})
+		if required && container.State != containerTypes.StateRunning {
+			preparedStack.Status = stackStatusDown
+		}
+	}
+	if len(containers) == 0 {
+		preparedStack.Status = stackStatusDown
+	}
+	sort.Slice(preparedStack.Services, func(i, j int) bool {
+		return preparedStack.Services[i].Service < preparedStack.Services[j].Service
+	})
+
+	return preparedStack
+}
+
+func (ds *DockerStackService) GetStacks(ctx context.Context, ownerID int) ([]DTO.Stack, error) {
+	stacks, err := ds.stackRepository.GetStacks(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := ds.dockerClientManager.GetClient(ctx, ds.dockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := ds.listStackContainers(ctx, cli, composeProjectLabel)
+	if err != nil {
+		return nil, err
+	}
+	containersByProject := make(map[string][]containerTypes.Summary)
+	for _, container := range containers {
+		project := container.Labels[composeProjectLabel]
+		containersByProject[project] = append(containersByProject[project], container)
+	}
+
+	preparedStacks := make([]DTO.Stack, 0, len(stacks))
+	for _, stack := range stacks {
+		preparedStacks = append(preparedStacks, prepareStack(stack, containersByProject[stack.Name]))
+	}
+
+	return preparedStacks, nil
+}
+
+func (ds *DockerStackService) GetStack(ctx context.Context, stackID, ownerID int) (DTO.Stack, error) {
+	stack, err := ds.stackRepository.GetStack(ctx, stackID, ownerID)
+	if err != nil {
+		return DTO.Stack{}, err
+	}
+
+	cli, err := ds.dockerClientManager.GetClient(ctx, ds.dockerHost)
+	if err != nil {
+		return DTO.Stack{}, err
+	}
+
+	containers, err := ds.listStackContainers(ctx, cli, composeProjectLabel+"="+stack.Name)
+	if err != nil {
+		return DTO.Stack{}, err
+	}
+
+	return prepareStack(*stack, containers), nil
+}
+
+// orderByDependencies sorts the containers so every service comes after the services from its compose
+// depends_on label. Services in a dependency cycle keep their original order at the end.
+func orderByDependencies(containers []containerTypes.Summary) []containerTypes.Summary {
+	dependencies := make(map[string][]string, len(containers))
+	services := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		services[container.Labels[composeServiceLabel]] = true
+	}
+	for _, container := range containers {
+		service := container.Labels[composeServiceLabel]
+		for _, dependency := range strings.Split(container.Labels[composeDependsOnLabel], ",") {
+			dependencyService := strings.Split(dependency, ":")[0]
+			if dependencyService != "" && services[dependencyService] {
+				dependencies[service] = append(dependencies[service], dependencyService)
+			}
+		}
+	}
+
+	orderedContainers := make([]containerTypes.Summary, 0, len(containers))
+	started := make(map[string]bool, len(containers))
+	for len(orderedContainers) < len(containers) {
+		added := false
+		for _, container := range containers {
+			service := container.Labels[composeServiceLabel]
+			if started[container.ID] {
+				continue
+			}
+
+			ready := true
+			for _, dependency := range dependencies[service] {
+				if !startedService(containers, started, dependency) {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				orderedContainers = append(orderedContainers, container)
+				started[container.ID] = true
+				added = true
+			}
+		}
+
+		if !added {
+			for _, container := range containers {
+				if !started[container.ID] {
+					orderedContainers = append(orderedContainers, container)
+					started[container.ID] = true
+				}
+			}
+		}
+	}
+
+	return orderedContainers
+}
+
+func startedService(containers []containerTypes.Summary, started map[string]bool, service string) bool {
+	for _, container := range containers {
+		if container.Labels[composeServiceLabel] == service && !started[container.ID] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (ds *DockerStackService) getStackContainers(ctx context.Context, stackID, ownerID int) (client.APIClient,
+	[]containerTypes.Summary, error,
+) {
+	stack, err := ds.stackRepository.GetStack(ctx, stackID, ownerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cli, err := ds.dockerClientManager.GetClient(ctx, ds.dockerHost)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	containers, err := ds.listStackContainers(ctx, cli, composeProjectLabel+"="+stack.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(containers) == 0 {
+		return nil, nil, models.NewError(404, "Stack", "stack has no containers")
+	}
+
+	return cli, orderByDependencies(containers), nil
+}
+
+func (ds *DockerStackService) startContainers(ctx context.Context, cli client.APIClient,
+	containers []containerTypes.Summary,
+) error {
+	for _, container := range containers {
+		err := cli.ContainerStart(ctx, container.ID, containerTypes.StartOptions{})
+		if err != nil {
+			ds.logger.Error("failed to start stack container", map[string]any{
+				"appID": container.ID,
+				"err":   err.Error(),
+			})
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ds *DockerStackService) stopContainers(ctx context.Context, cli client.APIClient,
+	containers []containerTypes.Summary,
+) error {
+	for i := len(containers) - 1; i >= 0; i-- {
+		err := cli.ContainerStop(ctx, containers[i].ID, containerTypes.StopOptions{})
+		if err != nil {
+			ds.logger.Error("failed to stop stack container", map[string]any{
+				"appID": containers[i].ID,
+				"err":   err.Error(),
+			})
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ds *DockerStackService) StartStack(ctx context.Context, stackID, ownerID int) error {
+	cli, containers, err := ds.getStackContainers(ctx, stackID, ownerID)
+	if err != nil {
+		return err
+	}
+
+	return ds.startContainers(ctx, cli, containers)
+}
+
+func (ds *DockerStackService) StopStack(ctx context.Context, stackID, ownerID int) error {
+	cli, containers, err := ds.getStackContainers(ctx, stackID, ownerID)
+	if err != nil {
+		return err
+	}
+
+	return ds.stopContainers(ctx, cli, containers)
+}
+
+func (ds *DockerStackService) RestartStack(ctx context.Context, stackID, ownerID int) error {
+	cli, containers, err := ds.getStackContainers(ctx, stackID, ownerID)
+	if err != nil {
+		return err
+	}
+
+	err = ds.stopContainers(ctx, cli, containers)
+	if err != nil {
+		return err
+	}
+
+	return ds.startContainers(ctx, cli, containers)
+}
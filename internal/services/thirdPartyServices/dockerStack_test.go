@@ -0,0 +1,235 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func composeContainer(id, service, dependsOn string) containerTypes.Summary {
+	return containerTypes.Summary{
+		ID: id,
+		Labels: map[string]string{
+			composeProjectLabel:   "shop",
+			composeServiceLabel:   service,
+			composeDependsOnLabel: dependsOn,
+		},
+	}
+}
+
+func TestOrderByDependencies(t *testing.T) {
+	type args struct {
+		name               string
+		containers         []containerTypes.Summary
+		expectedContainers []string
+	}
+	testsScenarios := []args{
+		{
+			name: "Dependencies are started first",
+			containers: []containerTypes.Summary{
+				composeContainer("web", "web", "api:service_started:false"),
+				composeContainer("api", "api", "db:service_healthy:false,cache:service_started:false"),
+				composeContainer("db", "db", ""),
+				composeContainer("cache", "cache", ""),
+			},
+			expectedContainers: []string{"db", "cache", "api", "web"},
+		},
+		{
+			name: "Unknown dependency is ignored",
+			containers: []containerTypes.Summary{
+				composeContainer("web", "web", "proxy:service_started:false"),
+			},
+			expectedContainers: []string{"web"},
+		},
+		{
+			name: "Dependency cycle keeps original order",
+			containers: []containerTypes.Summary{
+				composeContainer("a", "a", "b:service_started:false"),
+				composeContainer("b", "b", "a:service_started:false"),
+				composeContainer("c", "c", ""),
+			},
+			expectedContainers: []string{"c", "a", "b"},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			orderedContainers := orderByDependencies(testScenario.containers)
+			orderedIDs := make([]string, 0, len(orderedContainers))
+			for _, container := range orderedContainers {
+				orderedIDs = append(orderedIDs, container.ID)
+			}
+			assert.Equal(t, testScenario.expectedContainers, orderedIDs)
+		})
+	}
+}
+
+func TestDockerStackService_GetStack(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name           string
+		containers     string
+		expectedStatus string
+		expectedError  error
+		setupMock      func() *mocks.MockStackRepository
+	}
+	testsScenarios := []args{
+		{
+			name: "All required services are running",
+			containers: `[{"Id":"db","State":"running","Labels":{"com.docker.compose.service":"db"}},
+				{"Id":"worker","State":"exited","Labels":{"com.docker.compose.service":"worker",
+				"com.octopus.required":"false"}}]`,
+			expectedStatus: "running",
+			expectedError:  nil,
+			setupMock: func() *mocks.MockStackRepository {
+				m := new(mocks.MockStackRepository)
+				m.On("GetStack", mock.Anything, 1, 1).Return(&models.Stack{ID: 1, Name: "shop", OwnerID: 1}, nil)
+				return m
+			},
+		},
+		{
+			name: "Required service is down",
+			containers: `[{"Id":"db","State":"exited","Labels":{"com.docker.compose.service":"db"}},
+				{"Id":"web","State":"running","Labels":{"com.docker.compose.service":"web"}}]`,
+			expectedStatus: "down",
+			expectedError:  nil,
+			setupMock: func() *mocks.MockStackRepository {
+				m := new(mocks.MockStackRepository)
+				m.On("GetStack", mock.Anything, 1, 1).Return(&models.Stack{ID: 1, Name: "shop", OwnerID: 1}, nil)
+				return m
+			},
+		},
+		{
+			name:           "Stack not found",
+			containers:     `[]`,
+			expectedStatus: "",
+			expectedError:  errors.New("stack not found"),
+			setupMock: func() *mocks.MockStackRepository {
+				m := new(mocks.MockStackRepository)
+				m.On("GetStack", mock.Anything, 1, 1).Return((*models.Stack)(nil),
+					models.NewError(404, "Stack", "stack not found"))
+				return m
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			server := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+				"GET /containers/json": func(w http.ResponseWriter, r *http.Request) {
+					if !strings.Contains(r.URL.Query().Get("filters"), "com.docker.compose.project=shop") {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(testScenario.containers))
+				},
+			})
+			defer server.Close()
+			dockerClientManager := tests.CreateDockerClientManager(loggerService)
+			defer dockerClientManager.Close()
+
+			dockerStackService := NewDockerStackService(testScenario.setupMock(), loggerService,
+				tests.FakeDockerHost(server), dockerClientManager)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+			stack, err := dockerStackService.GetStack(ctx, 1, 1)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			assert.Equal(t, testScenario.expectedStatus, stack.Status)
+		})
+	}
+}
+
+func TestDockerStackService_RestartStack(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	var calls []string
+	server := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+		"GET /containers/json": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"Id":"web","State":"running","Labels":{"com.docker.compose.service":"web",
+				"com.docker.compose.depends_on":"db:service_started:false"}},
+				{"Id":"db","State":"running","Labels":{"com.docker.compose.service":"db"}}]`))
+		},
+		"POST /containers/web/stop": func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "stop web")
+			w.WriteHeader(http.StatusNoContent)
+		},
+		"POST /containers/db/stop": func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "stop db")
+			w.WriteHeader(http.StatusNoContent)
+		},
+		"POST /containers/web/start": func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "start web")
+			w.WriteHeader(http.StatusNoContent)
+		},
+		"POST /containers/db/start": func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "start db")
+			w.WriteHeader(http.StatusNoContent)
+		},
+	})
+	defer server.Close()
+	dockerClientManager := tests.CreateDockerClientManager(loggerService)
+	defer dockerClientManager.Close()
+
+	stackRepositoryMock := new(mocks.MockStackRepository)
+	stackRepositoryMock.On("GetStack", mock.Anything, 1, 1).Return(&models.Stack{ID: 1, Name: "shop", OwnerID: 1},
+		nil)
+	dockerStackService := NewDockerStackService(stackRepositoryMock, loggerService, tests.FakeDockerHost(server),
+		dockerClientManager)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err := dockerStackService.RestartStack(ctx, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"stop web", "stop db", "start db", "start web"}, calls)
+}
+
+func TestDockerService_ImportComposeContainers(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	server := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+		"GET /containers/json": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"Id":"db","Names":["/shop-db-1"],"Ports":[{"PrivatePort":5432}],
+				"Labels":{"com.docker.compose.project":"shop","com.docker.compose.service":"db"}},
+				{"Id":"proxy","Names":["/proxy"],"Ports":[{"PrivatePort":80}]}]`))
+		},
+	})
+	defer server.Close()
+	dockerClientManager := tests.CreateDockerClientManager(loggerService)
+	defer dockerClientManager.Close()
+
+	appRepositoryMock := new(mocks.MockAppRepository)
+	appRepositoryMock.On("GetApps", mock.Anything, 1).Return([]models.App{}, nil)
+	appRepositoryMock.On("InsertApp", mock.Anything, mock.MatchedBy(func(apps []DTO.App) bool {
+		appsByID := make(map[string]DTO.App, len(apps))
+		for _, app := range apps {
+			appsByID[app.ID] = app
+		}
+		return len(apps) == 2 && appsByID["db"].Name == "shop/db" && appsByID["db"].StackID == 7 &&
+			appsByID["proxy"].Name == "proxy" && appsByID["proxy"].StackID == 0
+	})).Return(nil)
+	stackRepositoryMock := new(mocks.MockStackRepository)
+	stackRepositoryMock.On("UpsertStack", mock.Anything, "shop", 1).Return(7, nil)
+
+	dockerService := NewDockerService(appRepositoryMock, stackRepositoryMock, loggerService,
+		tests.FakeDockerHost(server), dockerClientManager)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err := dockerService.ImportContainers(ctx, 1)
+	assert.NoError(t, err)
+	appRepositoryMock.AssertExpectations(t)
+	stackRepositoryMock.AssertExpectations(t)
+}
@@ -0,0 +1,301 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/gorilla/websocket"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+// Containers stats are kept for a week, older samples are removed by the worker.
+const containersStatsRetention = 7 * 24 * time.Hour
+
+type DockerStatsService struct {
+	appRepository            interfaces.AppRepository
+	containerStatsRepository interfaces.ContainerStatsRepository
+	dockerClientManager      interfaces.DockerClientManager
+	dockerHost               string
+	logger                   utils.LoggerService
+}
+
+func NewDockerStatsService(appRepository interfaces.AppRepository,
+	containerStatsRepository interfaces.ContainerStatsRepository, logger utils.LoggerService, dockerHost string,
+	dockerClientManager interfaces.DockerClientManager,
+) *DockerStatsService {
+	return &DockerStatsService{
+		appRepository:            appRepository,
+		containerStatsRepository: containerStatsRepository,
+		dockerClientManager:      dockerClientManager,
+		dockerHost:               dockerHost,
+		logger:                   logger,
+	}
+}
+
+// calculateCPUPercent uses the same formula as the docker CLI: the container cpu time delta divided by the
+// system cpu time delta, scaled by the number of cpus available to the container.
+func calculateCPUPercent(stats containerTypes.StatsResponse) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	return cpuDelta / systemDelta * onlineCPUs * 100
+}
+
+// calculateMemoryUsage excludes the page cache from the usage, cgroup v2 reports it as inactive_file and
+// cgroup v1 as total_inactive_file.
+func calculateMemoryUsage(memoryStats containerTypes.MemoryStats) uint64 {
+	cache, ok := memoryStats.Stats["inactive_file"]
+	if !ok {
+		cache = memoryStats.Stats["total_inactive_file"]
+	}
+	if cache > memoryStats.Usage {
+		return memoryStats.Usage
+	}
+
+	return memoryStats.Usage - cache
+}
+
+func calculateBlockIO(blkioStats containerTypes.BlkioStats) (uint64, uint64) {
+	var blockRead, blockWrite uint64
+	for _, entry := range blkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			blockRead += entry.Value
+		case "write":
+			blockWrite += entry.Value
+		}
+	}
+
+	return blockRead, blockWrite
+}
+
+func prepareContainerStats(appID string, stats containerTypes.StatsResponse) DTO.ContainerStats {
+	containerStats := DTO.ContainerStats{
+		AppID:       appID,
+		CPUPercent:  calculateCPUPercent(stats),
+		MemoryUsage: calculateMemoryUsage(stats.MemoryStats),
+		MemoryLimit: stats.MemoryStats.Limit,
+		PIDs:        stats.PidsStats.Current,
+		CreatedAt:   stats.Read.UTC(),
+	}
+	if containerStats.MemoryLimit > 0 {
+		containerStats.MemoryPercent = float64(containerStats.MemoryUsage) / float64(containerStats.MemoryLimit) * 100
+	}
+	for _, network := range stats.Networks {
+		containerStats.NetworkRx += network.RxBytes
+		containerStats.NetworkTx += network.TxBytes
+	}
+	containerStats.BlockRead, containerStats.BlockWrite = calculateBlockIO(stats.BlkioStats)
+	if stats.Read.IsZero() {
+		containerStats.CreatedAt = time.Now().UTC()
+	}
+
+	return containerStats
+}
+
+func (ds *DockerStatsService) getDockerApp(ctx context.Context, appID string, ownerID int) error {
+	app, err := ds.appRepository.GetApp(ctx, appID, ownerID)
+	if err != nil {
+		return err
+	}
+	if app.Runtime != models.RuntimeDocker {
+		return models.NewError(400, "Docker", "app is not a docker container")
+	}
+
+	return nil
+}
+
+// readContainerStats reads a single sample. The stats are not streamed, so the daemon waits for a second sample
+// and the precpu stats required to calculate the cpu usage are filled in.
+func (ds *DockerStatsService) readContainerStats(ctx context.Context, cli client.APIClient,
+	appID string,
+) (DTO.ContainerStats, error) {
+	statsReader, err := cli.ContainerStats(ctx, appID, false)
+	if err != nil {
+		ds.logger.Error("failed to read container stats", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+		return DTO.ContainerStats{}, err
+	}
+	defer func() {
+		if closeErr := statsReader.Body.Close(); closeErr != nil {
+			ds.logger.Warn("failed to close container stats", closeErr)
+		}
+	}()
+
+	var stats containerTypes.StatsResponse
+	err = json.NewDecoder(statsReader.Body).Decode(&stats)
+	if err != nil {
+		ds.logger.Error("failed to decode container stats", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+		return DTO.ContainerStats{}, err
+	}
+
+	return prepareContainerStats(appID, stats), nil
+}
+
+func (ds *DockerStatsService) GetContainerStats(ctx context.Context, appID string,
+	ownerID int,
+) (DTO.ContainerStats, error) {
+	err := ds.getDockerApp(ctx, appID, ownerID)
+	if err != nil {
+		return DTO.ContainerStats{}, err
+	}
+
+	cli, err := ds.dockerClientManager.GetClient(ctx, ds.dockerHost)
+	if err != nil {
+		return DTO.ContainerStats{}, err
+	}
+
+	return ds.readContainerStats(ctx, cli, appID)
+}
+
+func (ds *DockerStatsService) GetContainerStatsHistory(ctx context.Context, appID string, ownerID int,
+	since time.Time,
+) ([]DTO.ContainerStats, error) {
+	err := ds.getDockerApp(ctx, appID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.containerStatsRepository.GetContainerStatsHistory(ctx, appID, ownerID, since)
+}
+
+// StreamContainerStats forwards every sample of the docker stats stream to the websocket as JSON until the
+// client disconnects or the container stops.
+func (ds *DockerStatsService) StreamContainerStats(ctx context.Context, appID string, ownerID int,
+	conn *websocket.Conn,
+) {
+	err := ds.getDockerApp(ctx, appID, ownerID)
+	if err != nil {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+
+	cli, err := ds.dockerClientManager.GetClient(ctx, ds.dockerHost)
+	if err != nil {
+		ds.logger.Error("Error creating Docker client", err)
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	statsReader, err := cli.ContainerStats(ctx, appID, true)
+	if err != nil {
+		ds.logger.Error("failed to stream container stats", map[string]any{
+			"appID": appID,
+			"err":   err.Error(),
+		})
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+	defer func() {
+		if closeErr := statsReader.Body.Close(); closeErr != nil {
+			ds.logger.Warn("failed to close container stats", closeErr)
+		}
+	}()
+
+	decoder := json.NewDecoder(statsReader.Body)
+	for {
+		var stats containerTypes.StatsResponse
+		err := decoder.Decode(&stats)
+		if errors.Is(err, io.EOF) || errors.Is(ctx.Err(), context.Canceled) {
+			return
+		}
+		if err != nil {
+			ds.logger.Error("failed to decode container stats", map[string]any{
+				"appID": appID,
+				"err":   err.Error(),
+			})
+			return
+		}
+
+		if err := conn.WriteJSON(prepareContainerStats(appID, stats)); err != nil {
+			if !websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				ds.logger.Info("Client disconnected")
+			}
+			return
+		}
+	}
+}
+
+// InsertContainersStats samples the stats of every docker app and removes samples older than the
+// retention period.
+func (ds *DockerStatsService) InsertContainersStats(ctx context.Context) error {
+	apps, err := ds.appRepository.GetAppsToCheck(ctx)
+	if err != nil {
+		return err
+	}
+
+	cli, err := ds.dockerClientManager.GetClient(ctx, ds.dockerHost)
+	if err != nil {
+		return err
+	}
+
+	appsIDs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	containersStats := make([]DTO.ContainerStats, 0, len(apps))
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for appID := range appsIDs {
+				containerStats, err := ds.readContainerStats(ctx, cli, appID)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				containersStats = append(containersStats, containerStats)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, app := range apps {
+		if app.Runtime == models.RuntimeDocker {
+			appsIDs <- app.ID
+		}
+	}
+	close(appsIDs)
+	wg.Wait()
+
+	if len(containersStats) > 0 {
+		err = ds.containerStatsRepository.InsertContainersStats(ctx, containersStats)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ds.containerStatsRepository.DeleteContainersStatsOlderThan(ctx, time.Now().Add(-containersStatsRetention))
+}
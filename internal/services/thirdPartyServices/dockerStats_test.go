@@ -0,0 +1,199 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPrepareContainerStats(t *testing.T) {
+	type args struct {
+		name          string
+		stats         containerTypes.StatsResponse
+		expectedStats DTO.ContainerStats
+	}
+	read := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	testsScenarios := []args{
+		{
+			name: "Cgroup v2 stats",
+			stats: containerTypes.StatsResponse{
+				Read: read,
+				CPUStats: containerTypes.CPUStats{
+					CPUUsage:    containerTypes.CPUUsage{TotalUsage: 300},
+					SystemUsage: 2000,
+					OnlineCPUs:  2,
+				},
+				PreCPUStats: containerTypes.CPUStats{
+					CPUUsage:    containerTypes.CPUUsage{TotalUsage: 100},
+					SystemUsage: 1000,
+				},
+				MemoryStats: containerTypes.MemoryStats{
+					Usage: 300,
+					Limit: 1000,
+					Stats: map[string]uint64{"inactive_file": 100},
+				},
+				Networks: map[string]containerTypes.NetworkStats{
+					"eth0": {RxBytes: 10, TxBytes: 20},
+					"eth1": {RxBytes: 1, TxBytes: 2},
+				},
+				BlkioStats: containerTypes.BlkioStats{
+					IoServiceBytesRecursive: []containerTypes.BlkioStatEntry{
+						{Op: "read", Value: 5},
+						{Op: "write", Value: 7},
+						{Op: "Read", Value: 1},
+					},
+				},
+				PidsStats: containerTypes.PidsStats{Current: 4},
+			},
+			expectedStats: DTO.ContainerStats{
+				AppID:         "app",
+				CPUPercent:    40,
+				MemoryUsage:   200,
+				MemoryLimit:   1000,
+				MemoryPercent: 20,
+				NetworkRx:     11,
+				NetworkTx:     22,
+				BlockRead:     6,
+				BlockWrite:    7,
+				PIDs:          4,
+				CreatedAt:     read,
+			},
+		},
+		{
+			name: "Cgroup v1 stats without previous sample",
+			stats: containerTypes.StatsResponse{
+				Read: read,
+				CPUStats: containerTypes.CPUStats{
+					CPUUsage: containerTypes.CPUUsage{
+						TotalUsage:  300,
+						PercpuUsage: []uint64{150, 150},
+					},
+					SystemUsage: 2000,
+				},
+				MemoryStats: containerTypes.MemoryStats{
+					Usage: 300,
+					Stats: map[string]uint64{"total_inactive_file": 50},
+				},
+			},
+			expectedStats: DTO.ContainerStats{
+				AppID:       "app",
+				CPUPercent:  30,
+				MemoryUsage: 250,
+				CreatedAt:   read,
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			containerStats := prepareContainerStats("app", testScenario.stats)
+			assert.Equal(t, testScenario.expectedStats, containerStats)
+		})
+	}
+}
+
+func TestDockerStatsService_GetContainerStats(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name                string
+		expectedMemoryUsage uint64
+		expectedError       error
+		setupMock           func() *mocks.MockAppRepository
+	}
+	testsScenarios := []args{
+		{
+			name:                "Docker app",
+			expectedMemoryUsage: 200,
+			expectedError:       nil,
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "app", 1).Return(&models.App{ID: "app", Runtime: models.RuntimeDocker}, nil)
+				return m
+			},
+		},
+		{
+			name:                "App is not a docker container",
+			expectedMemoryUsage: 0,
+			expectedError:       errors.New("app is not a docker container"),
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "app", 1).Return(&models.App{ID: "app"}, nil)
+				return m
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			server := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+				"GET /containers/app/stats": func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Query().Get("stream") != "0" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"memory_stats":{"usage":300,"limit":1000,
+						"stats":{"inactive_file":100}}}`))
+				},
+			})
+			defer server.Close()
+			dockerClientManager := tests.CreateDockerClientManager(loggerService)
+			defer dockerClientManager.Close()
+
+			dockerStatsService := NewDockerStatsService(testScenario.setupMock(),
+				new(mocks.MockContainerStatsRepository), loggerService, tests.FakeDockerHost(server),
+				dockerClientManager)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+			containerStats, err := dockerStatsService.GetContainerStats(ctx, "app", 1)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			assert.Equal(t, testScenario.expectedMemoryUsage, containerStats.MemoryUsage)
+		})
+	}
+}
+
+func TestDockerStatsService_InsertContainersStats(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	server := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+		"GET /containers/app/stats": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"pids_stats":{"current":3}}`))
+		},
+	})
+	defer server.Close()
+	dockerClientManager := tests.CreateDockerClientManager(loggerService)
+	defer dockerClientManager.Close()
+
+	appRepository := new(mocks.MockAppRepository)
+	appRepository.On("GetAppsToCheck", mock.Anything).Return([]*models.AppToCheck{
+		{ID: "app", Runtime: models.RuntimeDocker},
+		{ID: "website", Runtime: models.RuntimeTCP},
+	}, nil)
+	containerStatsRepository := new(mocks.MockContainerStatsRepository)
+	containerStatsRepository.On("InsertContainersStats", mock.Anything, mock.MatchedBy(
+		func(containersStats []DTO.ContainerStats) bool {
+			return len(containersStats) == 1 && containersStats[0].AppID == "app" && containersStats[0].PIDs == 3
+		})).Return(nil)
+	containerStatsRepository.On("DeleteContainersStatsOlderThan", mock.Anything, mock.Anything).Return(nil)
+
+	dockerStatsService := NewDockerStatsService(appRepository, containerStatsRepository, loggerService,
+		tests.FakeDockerHost(server), dockerClientManager)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err := dockerStatsService.InsertContainersStats(ctx)
+	assert.NoError(t, err)
+	containerStatsRepository.AssertExpectations(t)
+}
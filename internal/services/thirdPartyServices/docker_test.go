@@ -3,9 +3,11 @@ package thirdPartyServices
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 	"time"
 
+	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/models"
 	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
 	"github.com/slodkiadrianek/octopus/tests"
@@ -60,7 +62,9 @@ func TestDockerService_ImportContainers(t *testing.T) {
 	for _, testScenario := range testsScenarios {
 		t.Run(testScenario.name, func(t *testing.T) {
 			appRepositoryMock := testScenario.setupMock()
-			dockerService := NewDockerService(appRepositoryMock, loggerService, testScenario.dockerHost)
+			dockerService := NewDockerService(appRepositoryMock, new(mocks.MockStackRepository),
+				loggerService, testScenario.dockerHost,
+				tests.CreateDockerClientManager(loggerService))
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
 			err := dockerService.ImportContainers(ctx, 1)
 			defer cancel()
@@ -105,7 +109,9 @@ func TestDockerService_PauseContainer(t *testing.T) {
 	for _, testScenario := range testsScenarios {
 		t.Run(testScenario.name, func(t *testing.T) {
 			appRepositoryMock := new(mocks.MockAppRepository)
-			dockerService := NewDockerService(appRepositoryMock, loggerService, testScenario.dockerHost)
+			dockerService := NewDockerService(appRepositoryMock, new(mocks.MockStackRepository),
+				loggerService, testScenario.dockerHost,
+				tests.CreateDockerClientManager(loggerService))
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
 			defer cancel()
 			var appID string
@@ -165,7 +171,9 @@ func TestDockerService_RestartContainer(t *testing.T) {
 	for _, testScenario := range testsScenarios {
 		t.Run(testScenario.name, func(t *testing.T) {
 			appRepositoryMock := new(mocks.MockAppRepository)
-			dockerService := NewDockerService(appRepositoryMock, loggerService, testScenario.dockerHost)
+			dockerService := NewDockerService(appRepositoryMock, new(mocks.MockStackRepository),
+				loggerService, testScenario.dockerHost,
+				tests.CreateDockerClientManager(loggerService))
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
 			defer cancel()
 			var appID string
@@ -225,7 +233,9 @@ func TestDockerService_UnpauseContainer(t *testing.T) {
 	for _, testScenario := range testsScenarios {
 		t.Run(testScenario.name, func(t *testing.T) {
 			appRepositoryMock := new(mocks.MockAppRepository)
-			dockerService := NewDockerService(appRepositoryMock, loggerService, testScenario.dockerHost)
+			dockerService := NewDockerService(appRepositoryMock, new(mocks.MockStackRepository),
+				loggerService, testScenario.dockerHost,
+				tests.CreateDockerClientManager(loggerService))
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
 			defer cancel()
 			var appID string
@@ -287,7 +297,9 @@ func TestDockerService_StartContainer(t *testing.T) {
 	for _, testScenario := range testsScenarios {
 		t.Run(testScenario.name, func(t *testing.T) {
 			appRepositoryMock := new(mocks.MockAppRepository)
-			dockerService := NewDockerService(appRepositoryMock, loggerService, testScenario.dockerHost)
+			dockerService := NewDockerService(appRepositoryMock, new(mocks.MockStackRepository),
+				loggerService, testScenario.dockerHost,
+				tests.CreateDockerClientManager(loggerService))
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
 			defer cancel()
 			var appID string
@@ -316,3 +328,351 @@ func TestDockerService_StartContainer(t *testing.T) {
 		})
 	}
 }
+
+func TestDockerService_StopContainer(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name          string
+		appID         string
+		expectedError error
+	}
+	testsScenarios := []args{
+		{
+			name:          "Test with proper data",
+			appID:         "e9530eae6aa7",
+			expectedError: nil,
+		},
+		{
+			name:          "Invalid app ID",
+			appID:         "unknown",
+			expectedError: errors.New("No such container"),
+		},
+	}
+	server := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+		"POST /containers/e9530eae6aa7/stop": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		},
+	})
+	defer server.Close()
+	dockerClientManager := tests.CreateDockerClientManager(loggerService)
+	defer dockerClientManager.Close()
+
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appRepositoryMock := new(mocks.MockAppRepository)
+			dockerService := NewDockerService(appRepositoryMock, new(mocks.MockStackRepository),
+				loggerService, tests.FakeDockerHost(server),
+				dockerClientManager)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+			err := dockerService.StopContainer(ctx, testScenario.appID)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+		})
+	}
+}
+
+func TestDockerService_CreateContainer(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name              string
+		containerToCreate DTO.CreateContainer
+		expectedAppID     string
+		expectedError     error
+		expectedRemoved   bool
+		setupMock         func() *mocks.MockAppRepository
+	}
+	testsScenarios := []args{
+		{
+			name: "Test with proper data",
+			containerToCreate: DTO.CreateContainer{
+				Name:          "nginx",
+				Image:         "nginx:1.27",
+				Ports:         []DTO.ContainerPort{{HostPort: "8080", ContainerPort: "80"}},
+				RestartPolicy: "unless-stopped",
+			},
+			expectedAppID: "c0ffee",
+			expectedError: nil,
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("InsertApp", mock.Anything, mock.MatchedBy(func(apps []DTO.App) bool {
+					return len(apps) == 1 && apps[0].ID == "c0ffee" && apps[0].Port == "8080" && apps[0].Runtime == models.RuntimeDocker
+				})).Return(nil)
+				return m
+			},
+		},
+		{
+			name: "Invalid container port",
+			containerToCreate: DTO.CreateContainer{
+				Name:  "nginx",
+				Image: "nginx:1.27",
+				Ports: []DTO.ContainerPort{{HostPort: "8080", ContainerPort: "http"}},
+			},
+			expectedAppID: "",
+			expectedError: errors.New("invalid container port http"),
+			setupMock: func() *mocks.MockAppRepository {
+				return new(mocks.MockAppRepository)
+			},
+		},
+		{
+			name: "Insert to db error",
+			containerToCreate: DTO.CreateContainer{
+				Name:  "nginx",
+				Image: "nginx:1.27",
+			},
+			expectedAppID:   "",
+			expectedError:   errors.New("failed to insert data to db"),
+			expectedRemoved: true,
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("InsertApp", mock.Anything, mock.Anything).Return(errors.New("failed to insert data to db"))
+				return m
+			},
+		},
+	}
+	removed := false
+	server := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+		"POST /images/create": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"Downloaded newer image for nginx:1.27"}`))
+		},
+		"POST /containers/create": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"Id":"c0ffee","Warnings":[]}`))
+		},
+		"POST /containers/c0ffee/start": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		},
+		"DELETE /containers/c0ffee": func(w http.ResponseWriter, r *http.Request) {
+			removed = true
+			w.WriteHeader(http.StatusNoContent)
+		},
+	})
+	defer server.Close()
+	dockerClientManager := tests.CreateDockerClientManager(loggerService)
+	defer dockerClientManager.Close()
+
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			removed = false
+			appRepositoryMock := testScenario.setupMock()
+			dockerService := NewDockerService(appRepositoryMock, new(mocks.MockStackRepository),
+				loggerService, tests.FakeDockerHost(server),
+				dockerClientManager)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+			appID, err := dockerService.CreateContainer(ctx, testScenario.containerToCreate, 1)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			assert.Equal(t, testScenario.expectedAppID, appID)
+			assert.Equal(t, testScenario.expectedRemoved, removed)
+			appRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDockerService_RemoveContainer(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name          string
+		appID         string
+		expectedError error
+		setupMock     func() *mocks.MockAppRepository
+	}
+	testsScenarios := []args{
+		{
+			name:          "Test with proper data",
+			appID:         "e9530eae6aa7",
+			expectedError: nil,
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "e9530eae6aa7", 1).Return(&models.App{ID: "e9530eae6aa7",
+					Runtime: models.RuntimeDocker}, nil)
+				m.On("DeleteApp", mock.Anything, "e9530eae6aa7", 1).Return(nil)
+				return m
+			},
+		},
+		{
+			name:          "App is not a docker container",
+			appID:         "e9530eae6aa7",
+			expectedError: errors.New("app is not a docker container"),
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "e9530eae6aa7", 1).Return(&models.App{ID: "e9530eae6aa7"}, nil)
+				return m
+			},
+		},
+		{
+			name:          "Container does not exist",
+			appID:         "unknown",
+			expectedError: errors.New("No such container"),
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "unknown", 1).Return(&models.App{ID: "unknown", Runtime: models.RuntimeDocker}, nil)
+				return m
+			},
+		},
+	}
+	server := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+		"DELETE /containers/e9530eae6aa7": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("v") != "1" || r.URL.Query().Get("force") != "1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	})
+	defer server.Close()
+	dockerClientManager := tests.CreateDockerClientManager(loggerService)
+	defer dockerClientManager.Close()
+
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appRepositoryMock := testScenario.setupMock()
+			dockerService := NewDockerService(appRepositoryMock, new(mocks.MockStackRepository),
+				loggerService, tests.FakeDockerHost(server),
+				dockerClientManager)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+			err := dockerService.RemoveContainer(ctx, testScenario.appID, 1, true)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			appRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDockerService_RecreateContainer(t *testing.T) {
+	loggerService := tests.CreateLogger()
+	type args struct {
+		name            string
+		createStatus    int
+		expectedAppID   string
+		expectedError   error
+		expectedNames   []string
+		expectedRemoved []string
+		setupMock       func() *mocks.MockAppRepository
+	}
+	testsScenarios := []args{
+		{
+			name:            "Test with proper data",
+			createStatus:    http.StatusCreated,
+			expectedAppID:   "new1d",
+			expectedError:   nil,
+			expectedNames:   []string{"nginx-octopus-old"},
+			expectedRemoved: []string{"0ld1d"},
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "0ld1d", 1).Return(&models.App{ID: "0ld1d", Runtime: models.RuntimeDocker}, nil)
+				m.On("UpdateAppID", mock.Anything, "0ld1d", "new1d", 1).Return(nil)
+				return m
+			},
+		},
+		{
+			name:          "Old container is restored when create fails",
+			createStatus:  http.StatusConflict,
+			expectedAppID: "",
+			expectedError: errors.New("name is already in use"),
+			expectedNames: []string{"nginx-octopus-old", "nginx"},
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "0ld1d", 1).Return(&models.App{ID: "0ld1d", Runtime: models.RuntimeDocker}, nil)
+				return m
+			},
+		},
+		{
+			name:            "Old container is restored when the app can not be moved to the new one",
+			createStatus:    http.StatusCreated,
+			expectedAppID:   "",
+			expectedError:   errors.New("failed to update data in db"),
+			expectedNames:   []string{"nginx-octopus-old", "nginx"},
+			expectedRemoved: []string{"new1d"},
+			setupMock: func() *mocks.MockAppRepository {
+				m := new(mocks.MockAppRepository)
+				m.On("GetApp", mock.Anything, "0ld1d", 1).Return(&models.App{ID: "0ld1d", Runtime: models.RuntimeDocker}, nil)
+				m.On("UpdateAppID", mock.Anything, "0ld1d", "new1d", 1).Return(errors.New("failed to update data in db"))
+				return m
+			},
+		},
+	}
+
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			var renamedTo []string
+			var removed []string
+			server := tests.NewFakeDockerAPIServer(map[string]http.HandlerFunc{
+				"GET /containers/0ld1d/json": func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"Id":"0ld1d","Name":"/nginx","State":{"Running":true},
+						"Config":{"Image":"nginx:1.27","Hostname":"0ld1d"},"HostConfig":{}}`))
+				},
+				"POST /images/create": func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(`{"status":"Downloaded newer image for nginx:1.28"}`))
+				},
+				"POST /containers/0ld1d/stop": func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				},
+				"POST /containers/0ld1d/rename": func(w http.ResponseWriter, r *http.Request) {
+					renamedTo = append(renamedTo, r.URL.Query().Get("name"))
+					w.WriteHeader(http.StatusNoContent)
+				},
+				"POST /containers/0ld1d/start": func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				},
+				"POST /containers/create": func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(testScenario.createStatus)
+					if testScenario.createStatus != http.StatusCreated {
+						_, _ = w.Write([]byte(`{"message":"name is already in use"}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"Id":"new1d","Warnings":[]}`))
+				},
+				"POST /containers/new1d/start": func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				},
+				"DELETE /containers/0ld1d": func(w http.ResponseWriter, r *http.Request) {
+					removed = append(removed, "0ld1d")
+					w.WriteHeader(http.StatusNoContent)
+				},
+				"DELETE /containers/new1d": func(w http.ResponseWriter, r *http.Request) {
+					removed = append(removed, "new1d")
+					w.WriteHeader(http.StatusNoContent)
+				},
+			})
+			defer server.Close()
+			dockerClientManager := tests.CreateDockerClientManager(loggerService)
+			defer dockerClientManager.Close()
+
+			appRepositoryMock := testScenario.setupMock()
+			dockerService := NewDockerService(appRepositoryMock, new(mocks.MockStackRepository),
+				loggerService, tests.FakeDockerHost(server),
+				dockerClientManager)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+			appID, err := dockerService.RecreateContainer(ctx, "0ld1d", 1, "nginx:1.28")
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			assert.Equal(t, testScenario.expectedAppID, appID)
+			assert.Equal(t, testScenario.expectedNames, renamedTo)
+			assert.Equal(t, testScenario.expectedRemoved, removed)
+			appRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// kubernetesAppNameMaxLength is the length of the name column of the apps, longer workload names are cut.
+const kubernetesAppNameMaxLength = 64
+
+type KubernetesService struct {
+	appRepository        interfaces.AppRepository
+	kubernetesRepository interfaces.KubernetesRepository
+	kubernetesClient     interfaces.KubernetesClient
+	encryptionKey        string
+	loggerService        utils.LoggerService
+}
+
+func NewKubernetesService(appRepository interfaces.AppRepository,
+	kubernetesRepository interfaces.KubernetesRepository, kubernetesClient interfaces.KubernetesClient,
+	encryptionKey string, loggerService utils.LoggerService,
+) *KubernetesService {
+	return &KubernetesService{
+		appRepository:        appRepository,
+		kubernetesRepository: kubernetesRepository,
+		kubernetesClient:     kubernetesClient,
+		encryptionKey:        encryptionKey,
+		loggerService:        loggerService,
+	}
+}
+
+// validateKubeconfigCredentials rejects the credentials which make the client run commands or read files of the
+// machine running OCTOPUS, only the credentials embedded in the kubeconfig are allowed.
+func validateKubeconfigCredentials(kubeconfig string) error {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return models.NewError(400, "Validation", "provided kubeconfig is invalid: "+err.Error())
+	}
+
+	for name, authInfo := range config.AuthInfos {
+		switch {
+		case authInfo.Exec != nil:
+			return models.NewError(400, "Validation", fmt.Sprintf("user %s of the kubeconfig uses exec", name))
+		case authInfo.AuthProvider != nil:
+			return models.NewError(400, "Validation", fmt.Sprintf("user %s of the kubeconfig uses auth-provider",
+				name))
+		case authInfo.TokenFile != "", authInfo.ClientCertificate != "", authInfo.ClientKey != "":
+			return models.NewError(400, "Validation", fmt.Sprintf(
+				"user %s of the kubeconfig reads credentials from files, embed them with token, "+
+					"client-certificate-data and client-key-data", name))
+		}
+	}
+	for name, cluster := range config.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return models.NewError(400, "Validation", fmt.Sprintf(
+				"cluster %s of the kubeconfig reads certificate-authority from a file, embed it with "+
+					"certificate-authority-data", name))
+		}
+	}
+
+	return nil
+}
+
+// SaveCluster registers the kubeconfig under the cluster name. The kubeconfig is stored encrypted, has to point
+// to a cluster with its current context and can use only embedded credentials.
+func (ks *KubernetesService) SaveCluster(ctx context.Context, ownerID int, cluster DTO.KubernetesCluster) error {
+	if err := validateKubeconfigCredentials(cluster.Kubeconfig); err != nil {
+		return err
+	}
+	if _, err := clientcmd.RESTConfigFromKubeConfig([]byte(cluster.Kubeconfig)); err != nil {
+		return models.NewError(400, "Validation", "provided kubeconfig is invalid: "+err.Error())
+	}
+
+	encryptedKubeconfig, err := utils.Encrypt(cluster.Kubeconfig, ks.encryptionKey)
+	if err != nil {
+		ks.loggerService.Error("failed to encrypt kubeconfig", err)
+		return models.NewError(500, "Server", "internal server error")
+	}
+
+	err = ks.kubernetesRepository.UpsertKubernetesCluster(ctx, models.KubernetesCluster{
+		OwnerID:    ownerID,
+		Name:       cluster.Name,
+		Kubeconfig: encryptedKubeconfig,
+	})
+	if err != nil {
+		return err
+	}
+
+	ks.kubernetesClient.ResetClient(ownerID, cluster.Name)
+
+	return nil
+}
+
+// ImportWorkloads imports the deployments and statefulsets of the namespace, all namespaces are imported when
+// it is empty.
+func (ks *KubernetesService) ImportWorkloads(ctx context.Context, ownerID int, cluster, namespace string) error {
+	importedApps, err := ks.appRepository.GetApps(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	importedAppsIDs := make(map[string]bool, len(importedApps))
+	for _, importedApp := range importedApps {
+		importedAppsIDs[importedApp.ID] = true
+	}
+
+	workloads, err := ks.kubernetesClient.ListWorkloads(ctx, ownerID, cluster, namespace)
+	if err != nil {
+		return err
+	}
+
+	appsToInsert := make([]DTO.App, 0, len(workloads))
+	workloadsToInsert := make([]models.KubernetesWorkload, 0, len(workloads))
+	for _, workload := range workloads {
+		appID := DTO.KubernetesAppID(ownerID, cluster, workload.Namespace, workload.Kind, workload.Name)
+		if importedAppsIDs[appID] {
+			continue
+		}
+
+		name := workload.Name
+		if len(name) > kubernetesAppNameMaxLength {
+			name = name[:kubernetesAppNameMaxLength]
+		}
+		description := fmt.Sprintf("%s %s/%s in cluster %s", workload.Kind, workload.Namespace, workload.Name,
+			cluster)
+		app := DTO.NewApp(appID, name, description, models.RuntimeKubernetes, ownerID, "", "")
+		appsToInsert = append(appsToInsert, *app)
+		workloadsToInsert = append(workloadsToInsert, models.KubernetesWorkload{
+			AppID:     appID,
+			OwnerID:   ownerID,
+			Cluster:   cluster,
+			Namespace: workload.Namespace,
+			Kind:      workload.Kind,
+			Name:      workload.Name,
+		})
+	}
+	if len(appsToInsert) == 0 {
+		return nil
+	}
+
+	return ks.kubernetesRepository.ImportKubernetesWorkloads(ctx, appsToInsert, workloadsToInsert)
+}
+
+func (ks *KubernetesService) getKubernetesWorkload(ctx context.Context, appID string,
+	ownerID int,
+) (*models.KubernetesWorkload, error) {
+	app, err := ks.appRepository.GetApp(ctx, appID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if app.Runtime != models.RuntimeKubernetes {
+		return nil, models.NewError(400, "Kubernetes", "app is not a kubernetes workload")
+	}
+
+	return ks.kubernetesRepository.GetKubernetesWorkload(ctx, app.ID)
+}
+
+func (ks *KubernetesService) RestartWorkload(ctx context.Context, appID string, ownerID int) error {
+	workload, err := ks.getKubernetesWorkload(ctx, appID, ownerID)
+	if err != nil {
+		return err
+	}
+
+	return ks.kubernetesClient.RestartWorkload(ctx, *workload)
+}
+
+func (ks *KubernetesService) GetWorkload(ctx context.Context, appID string,
+	ownerID int,
+) (DTO.KubernetesWorkload, error) {
+	workload, err := ks.getKubernetesWorkload(ctx, appID, ownerID)
+	if err != nil {
+		return DTO.KubernetesWorkload{}, err
+	}
+
+	return ks.kubernetesClient.GetWorkload(ctx, *workload)
+}
@@ -0,0 +1,193 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: k3s
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: k3s
+  context:
+    cluster: k3s
+    user: admin
+current-context: k3s
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+func TestKubernetesService_SaveCluster(t *testing.T) {
+	type args struct {
+		name          string
+		cluster       DTO.KubernetesCluster
+		expectedError error
+		setupMock     func() (*mocks.MockKubernetesRepository, *mocks.MockKubernetesClient)
+	}
+	testsScenarios := []args{
+		{
+			name:    "Save kubeconfig",
+			cluster: DTO.KubernetesCluster{Name: "k3s", Kubeconfig: testKubeconfig},
+			setupMock: func() (*mocks.MockKubernetesRepository, *mocks.MockKubernetesClient) {
+				mKubernetes := new(mocks.MockKubernetesRepository)
+				mKubernetes.On("UpsertKubernetesCluster", mock.Anything, mock.MatchedBy(
+					func(cluster models.KubernetesCluster) bool {
+						kubeconfig, err := utils.Decrypt(cluster.Kubeconfig, "test-key")
+						return cluster.OwnerID == 1 && cluster.Name == "k3s" && err == nil &&
+							kubeconfig == testKubeconfig
+					})).Return(nil)
+				mClient := new(mocks.MockKubernetesClient)
+				mClient.On("ResetClient", 1, "k3s").Return()
+				return mKubernetes, mClient
+			},
+		},
+		{
+			name: "Kubeconfig with exec",
+			cluster: DTO.KubernetesCluster{Name: "k3s", Kubeconfig: strings.Replace(testKubeconfig, "token: secret",
+				"exec:\n      apiVersion: client.authentication.k8s.io/v1\n      command: sh", 1)},
+			expectedError: errors.New("user admin of the kubeconfig uses exec"),
+			setupMock: func() (*mocks.MockKubernetesRepository, *mocks.MockKubernetesClient) {
+				return new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient)
+			},
+		},
+		{
+			name: "Kubeconfig with a client key file",
+			cluster: DTO.KubernetesCluster{Name: "k3s", Kubeconfig: strings.Replace(testKubeconfig, "token: secret",
+				"client-certificate: /etc/kubernetes/admin.crt\n    client-key: /etc/kubernetes/admin.key", 1)},
+			expectedError: errors.New("user admin of the kubeconfig reads credentials from files"),
+			setupMock: func() (*mocks.MockKubernetesRepository, *mocks.MockKubernetesClient) {
+				return new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient)
+			},
+		},
+		{
+			name: "Kubeconfig with a certificate authority file",
+			cluster: DTO.KubernetesCluster{Name: "k3s", Kubeconfig: strings.Replace(testKubeconfig,
+				"server: https://127.0.0.1:6443", "server: https://127.0.0.1:6443\n    certificate-authority: /root/ca.crt",
+				1)},
+			expectedError: errors.New("cluster k3s of the kubeconfig reads certificate-authority from a file"),
+			setupMock: func() (*mocks.MockKubernetesRepository, *mocks.MockKubernetesClient) {
+				return new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient)
+			},
+		},
+		{
+			name:          "Invalid kubeconfig",
+			cluster:       DTO.KubernetesCluster{Name: "k3s", Kubeconfig: "apiVersion: v1\nkind: Config\n"},
+			expectedError: errors.New("provided kubeconfig is invalid"),
+			setupMock: func() (*mocks.MockKubernetesRepository, *mocks.MockKubernetesClient) {
+				return new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient)
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			kubernetesRepository, kubernetesClient := testScenario.setupMock()
+			kubernetesService := NewKubernetesService(new(mocks.MockAppRepository), kubernetesRepository,
+				kubernetesClient, "test-key", tests.CreateLogger())
+			err := kubernetesService.SaveCluster(context.Background(), 1, testScenario.cluster)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			kubernetesRepository.AssertExpectations(t)
+			kubernetesClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestKubernetesService_ImportWorkloads(t *testing.T) {
+	apiID := DTO.KubernetesAppID(1, "k3s", "default", models.KubernetesKindDeployment, "api")
+	dbID := DTO.KubernetesAppID(1, "k3s", "default", models.KubernetesKindStatefulSet, "db")
+	mApp := new(mocks.MockAppRepository)
+	mApp.On("GetApps", mock.Anything, 1).Return([]models.App{{ID: dbID}}, nil)
+	mKubernetes := new(mocks.MockKubernetesRepository)
+	mKubernetes.On("ImportKubernetesWorkloads", mock.Anything, []DTO.App{
+		{ID: apiID, Name: "api", Description: "Deployment default/api in cluster k3s",
+			Runtime: models.RuntimeKubernetes, OwnerID: 1},
+	}, []models.KubernetesWorkload{
+		{AppID: apiID, OwnerID: 1, Cluster: "k3s", Namespace: "default", Kind: models.KubernetesKindDeployment,
+			Name: "api"},
+	}).Return(nil)
+	mClient := new(mocks.MockKubernetesClient)
+	mClient.On("ListWorkloads", mock.Anything, 1, "k3s", "default").Return([]DTO.KubernetesWorkload{
+		{Cluster: "k3s", Namespace: "default", Kind: models.KubernetesKindDeployment, Name: "api"},
+		{Cluster: "k3s", Namespace: "default", Kind: models.KubernetesKindStatefulSet, Name: "db"},
+	}, nil)
+
+	kubernetesService := NewKubernetesService(mApp, mKubernetes, mClient, "test-key", tests.CreateLogger())
+	err := kubernetesService.ImportWorkloads(context.Background(), 1, "k3s", "default")
+	assert.NoError(t, err)
+	mApp.AssertExpectations(t)
+	mKubernetes.AssertExpectations(t)
+}
+
+func TestKubernetesService_RestartWorkload(t *testing.T) {
+	workload := models.KubernetesWorkload{AppID: "k8s-api", Cluster: "k3s", Namespace: "default",
+		Kind: models.KubernetesKindDeployment, Name: "api"}
+	type args struct {
+		name          string
+		expectedError error
+		setupMock     func() (*mocks.MockAppRepository, *mocks.MockKubernetesRepository, *mocks.MockKubernetesClient)
+	}
+	testsScenarios := []args{
+		{
+			name: "Rollout restart",
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockKubernetesRepository,
+				*mocks.MockKubernetesClient,
+			) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApp", mock.Anything, "k8s-api", 1).Return(&models.App{
+					ID: "k8s-api", Runtime: models.RuntimeKubernetes,
+				}, nil)
+				mKubernetes := new(mocks.MockKubernetesRepository)
+				mKubernetes.On("GetKubernetesWorkload", mock.Anything, "k8s-api").Return(&workload, nil)
+				mClient := new(mocks.MockKubernetesClient)
+				mClient.On("RestartWorkload", mock.Anything, workload).Return(nil)
+				return mApp, mKubernetes, mClient
+			},
+		},
+		{
+			name:          "Not a kubernetes app",
+			expectedError: errors.New("app is not a kubernetes workload"),
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockKubernetesRepository,
+				*mocks.MockKubernetesClient,
+			) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApp", mock.Anything, "k8s-api", 1).Return(&models.App{
+					ID: "k8s-api", Runtime: models.RuntimeDocker,
+				}, nil)
+				return mApp, new(mocks.MockKubernetesRepository), new(mocks.MockKubernetesClient)
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appRepository, kubernetesRepository, kubernetesClient := testScenario.setupMock()
+			kubernetesService := NewKubernetesService(appRepository, kubernetesRepository, kubernetesClient,
+				"test-key", tests.CreateLogger())
+			err := kubernetesService.RestartWorkload(context.Background(), "k8s-api", 1)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			kubernetesClient.AssertExpectations(t)
+		})
+	}
+}
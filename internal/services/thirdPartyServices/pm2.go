@@ -1,22 +1,126 @@
 package thirdPartyServices
 
 import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
 	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
 	"github.com/slodkiadrianek/octopus/internal/utils"
 )
 
+// pm2LocalIPAddress is saved for imported apps, PM2 manages processes only on the machine it runs on.
+const pm2LocalIPAddress = "127.0.0.1"
+
 type Pm2Service struct {
 	appRepository interfaces.AppRepository
+	pm2Client     interfaces.Pm2Client
 	loggerService utils.LoggerService
 }
 
-func NewPm2Service(appRepository interfaces.AppRepository, loggerService utils.LoggerService) *Pm2Service {
+func NewPm2Service(appRepository interfaces.AppRepository, pm2Client interfaces.Pm2Client,
+	loggerService utils.LoggerService,
+) *Pm2Service {
 	return &Pm2Service{
 		appRepository: appRepository,
+		pm2Client:     pm2Client,
 		loggerService: loggerService,
 	}
 }
 
-// func (pm *Pm2Service) ImportApps() error {
-//
-// }
+func (pm *Pm2Service) ImportApps(ctx context.Context, ownerID int) error {
+	importedApps, err := pm.appRepository.GetApps(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	importedAppsIDs := make(map[string]bool, len(importedApps))
+	for _, importedApp := range importedApps {
+		importedAppsIDs[importedApp.ID] = true
+	}
+
+	processes, err := pm.pm2Client.ListProcesses(ctx)
+	if err != nil {
+		return err
+	}
+
+	appsToInsert := make([]DTO.App, 0, len(processes))
+	for _, process := range processes {
+		appID := DTO.Pm2AppID(ownerID, process.Name)
+		if importedAppsIDs[appID] {
+			continue
+		}
+		// PM2 lists every instance of a cluster, but they share the name and are controlled together.
+		importedAppsIDs[appID] = true
+
+		app := DTO.NewApp(appID, process.Name, "", models.RuntimePm2, ownerID, pm2LocalIPAddress, process.Port)
+		appsToInsert = append(appsToInsert, *app)
+	}
+	if len(appsToInsert) == 0 {
+		return nil
+	}
+
+	return pm.appRepository.InsertApp(ctx, appsToInsert)
+}
+
+func (pm *Pm2Service) getPm2App(ctx context.Context, appID string, ownerID int) (*models.App, error) {
+	app, err := pm.appRepository.GetApp(ctx, appID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if app.Runtime != models.RuntimePm2 {
+		return nil, models.NewError(400, "Pm2", "app is not a pm2 process")
+	}
+
+	return app, nil
+}
+
+func (pm *Pm2Service) runAction(ctx context.Context, action, appID string, ownerID int) error {
+	app, err := pm.getPm2App(ctx, appID, ownerID)
+	if err != nil {
+		return err
+	}
+
+	return pm.pm2Client.RunAction(ctx, action, DTO.Pm2ProcessName(app.ID))
+}
+
+func (pm *Pm2Service) StartApp(ctx context.Context, appID string, ownerID int) error {
+	return pm.runAction(ctx, "start", appID, ownerID)
+}
+
+func (pm *Pm2Service) StopApp(ctx context.Context, appID string, ownerID int) error {
+	return pm.runAction(ctx, "stop", appID, ownerID)
+}
+
+func (pm *Pm2Service) RestartApp(ctx context.Context, appID string, ownerID int) error {
+	return pm.runAction(ctx, "restart", appID, ownerID)
+}
+
+// ReloadApp restarts the instances one by one, so apps running in the cluster mode have no downtime.
+func (pm *Pm2Service) ReloadApp(ctx context.Context, appID string, ownerID int) error {
+	return pm.runAction(ctx, "reload", appID, ownerID)
+}
+
+// GetProcesses returns every instance of the app, a cluster has one process per instance.
+func (pm *Pm2Service) GetProcesses(ctx context.Context, appID string, ownerID int) ([]DTO.Pm2Process, error) {
+	app, err := pm.getPm2App(ctx, appID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	processes, err := pm.pm2Client.ListProcesses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	appProcesses := make([]DTO.Pm2Process, 0, 1)
+	for _, process := range processes {
+		if process.Name == DTO.Pm2ProcessName(app.ID) {
+			appProcesses = append(appProcesses, process)
+		}
+	}
+	if len(appProcesses) == 0 {
+		return nil, models.NewError(404, "Pm2", "pm2 process not found")
+	}
+
+	return appProcesses, nil
+}
@@ -0,0 +1,119 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPm2Service_ImportApps(t *testing.T) {
+	type args struct {
+		name          string
+		expectedError error
+		setupMock     func() (*mocks.MockAppRepository, *mocks.MockPm2Client)
+	}
+	testsScenarios := []args{
+		{
+			name: "Import new processes once per cluster",
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockPm2Client) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApps", mock.Anything, 1).Return([]models.App{{ID: "pm2-1-worker"}}, nil)
+				mApp.On("InsertApp", mock.Anything, []DTO.App{
+					{ID: "pm2-1-my-api", Name: "my-api", Runtime: models.RuntimePm2, OwnerID: 1, IPAddress: "127.0.0.1", Port: "3000"},
+				}).Return(nil)
+				mPm2 := new(mocks.MockPm2Client)
+				mPm2.On("ListProcesses", mock.Anything).Return([]DTO.Pm2Process{
+					{Name: "my-api", PmID: 0, Port: "3000"},
+					{Name: "my-api", PmID: 1, Port: "3000"},
+					{Name: "worker", PmID: 2},
+				}, nil)
+				return mApp, mPm2
+			},
+		},
+		{
+			name: "Nothing to import",
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockPm2Client) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApps", mock.Anything, 1).Return([]models.App{{ID: "pm2-1-worker"}}, nil)
+				mPm2 := new(mocks.MockPm2Client)
+				mPm2.On("ListProcesses", mock.Anything).Return([]DTO.Pm2Process{{Name: "worker"}}, nil)
+				return mApp, mPm2
+			},
+		},
+		{
+			name:          "PM2 is not available",
+			expectedError: errors.New("failed to list pm2 processes"),
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockPm2Client) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApps", mock.Anything, 1).Return([]models.App{}, nil)
+				mPm2 := new(mocks.MockPm2Client)
+				mPm2.On("ListProcesses", mock.Anything).Return([]DTO.Pm2Process{},
+					models.NewError(500, "Pm2", "failed to list pm2 processes"))
+				return mApp, mPm2
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appRepository, pm2Client := testScenario.setupMock()
+			pm2Service := NewPm2Service(appRepository, pm2Client, tests.CreateLogger())
+			err := pm2Service.ImportApps(context.Background(), 1)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			appRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPm2Service_ReloadApp(t *testing.T) {
+	type args struct {
+		name          string
+		expectedError error
+		setupMock     func() (*mocks.MockAppRepository, *mocks.MockPm2Client)
+	}
+	testsScenarios := []args{
+		{
+			name: "Reload pm2 app",
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockPm2Client) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApp", mock.Anything, "pm2-1-my-api", 1).Return(&models.App{ID: "pm2-1-my-api", Runtime: models.RuntimePm2}, nil)
+				mPm2 := new(mocks.MockPm2Client)
+				mPm2.On("RunAction", mock.Anything, "reload", "my-api").Return(nil)
+				return mApp, mPm2
+			},
+		},
+		{
+			name:          "Not a pm2 app",
+			expectedError: errors.New("app is not a pm2 process"),
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockPm2Client) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApp", mock.Anything, "pm2-1-my-api", 1).Return(&models.App{ID: "pm2-1-my-api", Runtime: models.RuntimeDocker},
+					nil)
+				return mApp, new(mocks.MockPm2Client)
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appRepository, pm2Client := testScenario.setupMock()
+			pm2Service := NewPm2Service(appRepository, pm2Client, tests.CreateLogger())
+			err := pm2Service.ReloadApp(context.Background(), "pm2-1-my-api", 1)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			pm2Client.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"strings"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/services/interfaces"
+	"github.com/slodkiadrianek/octopus/internal/utils"
+)
+
+// systemdLocalIPAddress is saved for imported apps, systemd manages units only on the machine it runs on.
+const systemdLocalIPAddress = "127.0.0.1"
+
+type SystemdService struct {
+	appRepository interfaces.AppRepository
+	systemdClient interfaces.SystemdClient
+	unitsPrefixes []string
+	loggerService utils.LoggerService
+}
+
+func NewSystemdService(appRepository interfaces.AppRepository, systemdClient interfaces.SystemdClient,
+	unitsPrefixes []string, loggerService utils.LoggerService,
+) *SystemdService {
+	return &SystemdService{
+		appRepository: appRepository,
+		systemdClient: systemdClient,
+		unitsPrefixes: unitsPrefixes,
+		loggerService: loggerService,
+	}
+}
+
+func hasAllowedPrefix(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ImportUnits imports the units matching the glob pattern, e.g. octopus-*.service. The pattern has to start with
+// one of the configured prefixes. Units which are not found are skipped.
+func (sd *SystemdService) ImportUnits(ctx context.Context, ownerID int, pattern string) error {
+	if !hasAllowedPrefix(pattern, sd.unitsPrefixes) {
+		return models.NewError(403, "Systemd", "pattern has to start with one of the prefixes: "+
+			strings.Join(sd.unitsPrefixes, ", "))
+	}
+
+	importedApps, err := sd.appRepository.GetApps(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	importedAppsIDs := make(map[string]bool, len(importedApps))
+	for _, importedApp := range importedApps {
+		importedAppsIDs[importedApp.ID] = true
+	}
+
+	units, err := sd.systemdClient.ListUnits(ctx, pattern)
+	if err != nil {
+		return err
+	}
+
+	appsToInsert := make([]DTO.App, 0, len(units))
+	for _, unit := range units {
+		appID := DTO.SystemdAppID(ownerID, unit.Name)
+		if importedAppsIDs[appID] || unit.LoadState == "not-found" || !hasAllowedPrefix(unit.Name, sd.unitsPrefixes) {
+			continue
+		}
+		app := DTO.NewApp(appID, unit.Name, unit.Description, models.RuntimeSystemd, ownerID, systemdLocalIPAddress,
+			"")
+		appsToInsert = append(appsToInsert, *app)
+	}
+	if len(appsToInsert) == 0 {
+		return nil
+	}
+
+	return sd.appRepository.InsertApp(ctx, appsToInsert)
+}
+
+func (sd *SystemdService) getSystemdApp(ctx context.Context, appID string, ownerID int) (*models.App, error) {
+	app, err := sd.appRepository.GetApp(ctx, appID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if app.Runtime != models.RuntimeSystemd {
+		return nil, models.NewError(400, "Systemd", "app is not a systemd unit")
+	}
+
+	return app, nil
+}
+
+func (sd *SystemdService) runAction(ctx context.Context, action, appID string, ownerID int) error {
+	app, err := sd.getSystemdApp(ctx, appID, ownerID)
+	if err != nil {
+		return err
+	}
+
+	return sd.systemdClient.RunAction(ctx, action, DTO.SystemdUnitName(app.ID))
+}
+
+func (sd *SystemdService) StartUnit(ctx context.Context, appID string, ownerID int) error {
+	return sd.runAction(ctx, "start", appID, ownerID)
+}
+
+func (sd *SystemdService) StopUnit(ctx context.Context, appID string, ownerID int) error {
+	return sd.runAction(ctx, "stop", appID, ownerID)
+}
+
+func (sd *SystemdService) RestartUnit(ctx context.Context, appID string, ownerID int) error {
+	return sd.runAction(ctx, "restart", appID, ownerID)
+}
+
+func (sd *SystemdService) GetUnit(ctx context.Context, appID string, ownerID int) (DTO.SystemdUnit, error) {
+	app, err := sd.getSystemdApp(ctx, appID, ownerID)
+	if err != nil {
+		return DTO.SystemdUnit{}, err
+	}
+
+	return sd.systemdClient.GetUnit(ctx, DTO.SystemdUnitName(app.ID))
+}
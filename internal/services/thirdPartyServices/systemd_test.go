@@ -0,0 +1,114 @@
+package thirdPartyServices
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/slodkiadrianek/octopus/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSystemdService_ImportUnits(t *testing.T) {
+	type args struct {
+		name          string
+		pattern       string
+		expectedError error
+		setupMock     func() (*mocks.MockAppRepository, *mocks.MockSystemdClient)
+	}
+	testsScenarios := []args{
+		{
+			name:    "Units with the allowed prefix are imported",
+			pattern: "octopus-*.service",
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockSystemdClient) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApps", mock.Anything, 1).Return([]models.App{{ID: "systemd-1-octopus-worker.service"}}, nil)
+				mApp.On("InsertApp", mock.Anything, []DTO.App{
+					{ID: "systemd-1-octopus-api.service", Name: "octopus-api.service", Description: "Octopus API",
+						Runtime: models.RuntimeSystemd, OwnerID: 1, IPAddress: "127.0.0.1"},
+				}).Return(nil)
+				mSystemd := new(mocks.MockSystemdClient)
+				mSystemd.On("ListUnits", mock.Anything, "octopus-*.service").Return([]DTO.SystemdUnit{
+					{Name: "octopus-api.service", Description: "Octopus API", LoadState: "loaded"},
+					{Name: "octopus-worker.service", LoadState: "loaded"},
+					{Name: "octopus-old.service", LoadState: "not-found"},
+				}, nil)
+				return mApp, mSystemd
+			},
+		},
+		{
+			name:          "Pattern without the allowed prefix",
+			pattern:       "*.service",
+			expectedError: errors.New("pattern has to start with one of the prefixes: octopus-"),
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockSystemdClient) {
+				return new(mocks.MockAppRepository), new(mocks.MockSystemdClient)
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appRepository, systemdClient := testScenario.setupMock()
+			systemdService := NewSystemdService(appRepository, systemdClient, []string{"octopus-"},
+				tests.CreateLogger())
+			err := systemdService.ImportUnits(context.Background(), 1, testScenario.pattern)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			appRepository.AssertExpectations(t)
+			systemdClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSystemdService_RestartUnit(t *testing.T) {
+	type args struct {
+		name          string
+		expectedError error
+		setupMock     func() (*mocks.MockAppRepository, *mocks.MockSystemdClient)
+	}
+	testsScenarios := []args{
+		{
+			name: "Restart systemd app",
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockSystemdClient) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApp", mock.Anything, "systemd-1-api.service", 1).Return(&models.App{
+					ID: "systemd-1-api.service", Runtime: models.RuntimeSystemd,
+				}, nil)
+				mSystemd := new(mocks.MockSystemdClient)
+				mSystemd.On("RunAction", mock.Anything, "restart", "api.service").Return(nil)
+				return mApp, mSystemd
+			},
+		},
+		{
+			name:          "Not a systemd app",
+			expectedError: errors.New("app is not a systemd unit"),
+			setupMock: func() (*mocks.MockAppRepository, *mocks.MockSystemdClient) {
+				mApp := new(mocks.MockAppRepository)
+				mApp.On("GetApp", mock.Anything, "systemd-1-api.service", 1).Return(&models.App{
+					ID: "systemd-1-api.service", Runtime: models.RuntimePm2,
+				}, nil)
+				return mApp, new(mocks.MockSystemdClient)
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			appRepository, systemdClient := testScenario.setupMock()
+			systemdService := NewSystemdService(appRepository, systemdClient, []string{"octopus-"},
+				tests.CreateLogger())
+			err := systemdService.RestartUnit(context.Background(), "systemd-1-api.service", 1)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Contains(t, err.Error(), testScenario.expectedError.Error())
+			}
+			systemdClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+func newGCM(encryptionKey string) (cipher.AEAD, error) {
+	if encryptionKey == "" {
+		return nil, errors.New("encryption key is empty")
+	}
+
+	key := sha256.Sum256([]byte(encryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Encrypt seals the data with AES-256-GCM and returns the nonce and ciphertext as base64.
+func Encrypt(data, encryptionKey string) (string, error) {
+	if data == "" {
+		return "", nil
+	}
+
+	gcm, err := newGCM(encryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealedData := gcm.Seal(nonce, nonce, []byte(data), nil)
+	return base64.StdEncoding.EncodeToString(sealedData), nil
+}
+
+func Decrypt(encryptedData, encryptionKey string) (string, error) {
+	if encryptedData == "" {
+		return "", nil
+	}
+
+	gcm, err := newGCM(encryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	sealedData, err := base64.StdEncoding.DecodeString(encryptedData)
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealedData) < gcm.NonceSize() {
+		return "", errors.New("encrypted data is too short")
+	}
+
+	nonce, cipherText := sealedData[:gcm.NonceSize()], sealedData[gcm.NonceSize():]
+	data, err := gcm.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/slodkiadrianek/octopus/internal/DTO"
 )
@@ -15,7 +17,7 @@ import (
 func SendHTTP(ctx context.Context, URL, authorizationHeader, method string, body []byte, readBody bool) (int,
 	map[string]any, error,
 ) {
-	httpClient := &http.Client{}
+	httpClient := &http.Client{Transport: defaultTransport, Timeout: defaultRequestTimeout}
 	req, err := http.NewRequestWithContext(ctx, method, URL, bytes.NewBuffer(body))
 	if err != nil {
 		return 0, map[string]any{}, err
@@ -33,7 +35,6 @@ func SendHTTP(ctx context.Context, URL, authorizationHeader, method string, body
 	var bodyFromResponse map[string]any
 	if readBody {
 		err = json.NewDecoder(response.Body).Decode(&bodyFromResponse)
-		fmt.Println(err)
 		if err != nil {
 			return 0, map[string]any{}, err
 		}
@@ -41,6 +42,108 @@ func SendHTTP(ctx context.Context, URL, authorizationHeader, method string, body
 	return response.StatusCode, bodyFromResponse, nil
 }
 
+// responseBodyMaxSize limits the response body read by SendHTTPRequest.
+const responseBodyMaxSize = 10 << 20
+
+// HTTPResponse is the response read by SendHTTPRequest. The body is the decoded JSON, it is nil when the response
+// is empty or is not JSON. RawBody is the body as it was received.
+type HTTPResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       any
+	RawBody    []byte
+	Latency    time.Duration
+}
+
+// HTTPRequest is the request sent by SendHTTPRequest. A zero Timeout stands for defaultRequestTimeout and a zero
+// MaxRedirects for defaultMaxRedirects, the redirect response itself is returned when FollowRedirects is false.
+type HTTPRequest struct {
+	Method          string
+	URL             string
+	Headers         map[string]string
+	Body            []byte
+	Timeout         time.Duration
+	FollowRedirects bool
+	MaxRedirects    int
+	TLS             TLSOptions
+}
+
+func newHTTPClient(httpRequest HTTPRequest) (*http.Client, error) {
+	transport, err := transportFor(httpRequest.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := httpRequest.Timeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	maxRedirects := httpRequest.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !httpRequest.FollowRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}, nil
+}
+
+// SendHTTPRequest sends the request with its headers, keeps the headers of the response and measures how long it
+// took to receive it. The connections are reused between the requests with the same TLS options.
+func SendHTTPRequest(ctx context.Context, httpRequest HTTPRequest) (*HTTPResponse, error) {
+	httpClient, err := newHTTPClient(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, httpRequest.Method, httpRequest.URL,
+		bytes.NewBuffer(httpRequest.Body))
+	if err != nil {
+		return nil, err
+	}
+	for key, val := range httpRequest.Headers {
+		req.Header.Set(key, val)
+	}
+
+	start := time.Now()
+	response, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, responseBodyMaxSize))
+	if err != nil {
+		return nil, err
+	}
+	latency := time.Since(start)
+
+	var bodyFromResponse any
+	if len(bytes.TrimSpace(responseBody)) > 0 {
+		if err := json.Unmarshal(responseBody, &bodyFromResponse); err != nil {
+			bodyFromResponse = nil
+		}
+	}
+
+	return &HTTPResponse{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       bodyFromResponse,
+		RawBody:    responseBody,
+		Latency:    latency,
+	}, nil
+}
+
 func ReadUserIDFromToken(r *http.Request) (int, error) {
 	userID, ok := r.Context().Value("id").(int)
 	if !ok || userID == 0 {
@@ -137,6 +240,9 @@ func ReadAllParams(r *http.Request) (map[string]string, error) {
 func CheckRouteParams(actualRoute DTO.CreateRoute) bool {
 	countParamsFromPath := 0
 	for seq := range strings.SplitSeq(actualRoute.Path, "/") {
+		if strings.Contains(seq, "{{") {
+			continue
+		}
 		leftBrace := strings.Contains(seq, "{")
 		rightBrace := strings.Contains(seq, "}")
 		if leftBrace && rightBrace {
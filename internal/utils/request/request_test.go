@@ -3,12 +3,15 @@ package request
 import (
 	"bytes"
 	"context"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/slodkiadrianek/octopus/internal/utils"
 	"github.com/slodkiadrianek/octopus/tests"
@@ -259,6 +262,14 @@ func TestCheckRouteParams(t *testing.T) {
 			},
 			expectedResult: false,
 		},
+		{
+			name: "Template in the path is not a param",
+			actualRoute: DTO.CreateRoute{
+				RequestParams: map[string]string{"appID": "1232131"},
+				Path:          "/{appID}/{{ .vars.userID }}",
+			},
+			expectedResult: true,
+		},
 	}
 	for _, testScenario := range testsScenarios {
 		t.Run(testScenario.name, func(t *testing.T) {
@@ -399,6 +410,102 @@ func TestSendHttp(t *testing.T) {
 	}
 }
 
+func TestSendHTTPRequest(t *testing.T) {
+	type args struct {
+		name               string
+		httpRequest        HTTPRequest
+		expectedStatusCode int
+		expectedBody       any
+		expectedError      string
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/echo", http.StatusFound)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"apiKey":%q,"contentType":%q}`, r.Header.Get("X-Api-Key"),
+				r.Header.Get("Content-Type"))
+		}
+	}))
+	defer server.Close()
+	caCertificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	testsScenarios := []args{
+		{
+			name: "Server trusted with the CA certificate",
+			httpRequest: HTTPRequest{
+				Method:  "POST",
+				URL:     server.URL + "/echo",
+				Headers: map[string]string{"X-Api-Key": "abc", "Content-Type": "text/plain"},
+				TLS:     TLSOptions{CACertificate: caCertificate},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       map[string]any{"apiKey": "abc", "contentType": "text/plain"},
+		},
+		{
+			name:          "Server with an unknown certificate",
+			httpRequest:   HTTPRequest{Method: "GET", URL: server.URL + "/echo"},
+			expectedError: "certificate signed by unknown authority",
+		},
+		{
+			name: "Redirect followed",
+			httpRequest: HTTPRequest{
+				Method:          "GET",
+				URL:             server.URL + "/redirect",
+				FollowRedirects: true,
+				TLS:             TLSOptions{InsecureSkipVerify: true},
+			},
+			expectedStatusCode: 200,
+			expectedBody:       map[string]any{"apiKey": "", "contentType": ""},
+		},
+		{
+			name: "Redirect returned",
+			httpRequest: HTTPRequest{
+				Method: "GET",
+				URL:    server.URL + "/redirect",
+				TLS:    TLSOptions{InsecureSkipVerify: true},
+			},
+			expectedStatusCode: 302,
+		},
+		{
+			name: "Timeout",
+			httpRequest: HTTPRequest{
+				Method:  "GET",
+				URL:     server.URL + "/slow",
+				Timeout: 50 * time.Millisecond,
+				TLS:     TLSOptions{InsecureSkipVerify: true},
+			},
+			expectedError: "Client.Timeout exceeded",
+		},
+		{
+			name: "CA certificate which is not PEM",
+			httpRequest: HTTPRequest{
+				Method: "GET",
+				URL:    server.URL + "/echo",
+				TLS:    TLSOptions{CACertificate: "certificate"},
+			},
+			expectedError: "CA certificate is not a valid PEM certificate",
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			httpResponse, err := SendHTTPRequest(context.Background(), testScenario.httpRequest)
+			if testScenario.expectedError != "" {
+				assert.ErrorContains(t, err, testScenario.expectedError)
+				assert.Nil(t, httpResponse)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testScenario.expectedStatusCode, httpResponse.StatusCode)
+			assert.Equal(t, testScenario.expectedBody, httpResponse.Body)
+		})
+	}
+}
+
 func TestReadUserIDFromToken(t *testing.T) {
 	type args struct {
 		name          string
@@ -439,3 +546,62 @@ func TestReadUserIDFromToken(t *testing.T) {
 		})
 	}
 }
+
+func TestTransportCache(t *testing.T) {
+	type args struct {
+		name          string
+		added         []TLSOptions
+		used          []TLSOptions
+		addedAfterUse []TLSOptions
+		expectedKept  []TLSOptions
+		expectedGone  []TLSOptions
+	}
+	first := TLSOptions{CACertificate: "first"}
+	second := TLSOptions{CACertificate: "second"}
+	third := TLSOptions{InsecureSkipVerify: true}
+	testsScenarios := []args{
+		{
+			name:         "Transports under the limit are kept",
+			added:        []TLSOptions{first, second},
+			expectedKept: []TLSOptions{first, second},
+		},
+		{
+			name:         "Least recently added transport is evicted",
+			added:        []TLSOptions{first, second, third},
+			expectedKept: []TLSOptions{second, third},
+			expectedGone: []TLSOptions{first},
+		},
+		{
+			name:          "Used transport is kept over the older one",
+			added:         []TLSOptions{first, second},
+			used:          []TLSOptions{first},
+			addedAfterUse: []TLSOptions{third},
+			expectedKept:  []TLSOptions{first, third},
+			expectedGone:  []TLSOptions{second},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			transportCache := newTransportCache(2)
+			for _, tlsOptions := range testScenario.added {
+				transportCache.add(newTransportKey(tlsOptions), newTransport(nil))
+			}
+			for _, tlsOptions := range testScenario.used {
+				_, ok := transportCache.get(newTransportKey(tlsOptions))
+				assert.True(t, ok)
+			}
+			for _, tlsOptions := range testScenario.addedAfterUse {
+				transportCache.add(newTransportKey(tlsOptions), newTransport(nil))
+			}
+
+			for _, tlsOptions := range testScenario.expectedKept {
+				_, ok := transportCache.get(newTransportKey(tlsOptions))
+				assert.True(t, ok)
+			}
+			for _, tlsOptions := range testScenario.expectedGone {
+				_, ok := transportCache.get(newTransportKey(tlsOptions))
+				assert.False(t, ok)
+			}
+		})
+	}
+}
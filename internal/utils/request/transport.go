@@ -0,0 +1,157 @@
+package request
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRequestTimeout is used by the requests which do not set their own timeout.
+	defaultRequestTimeout = 30 * time.Second
+	defaultMaxRedirects   = 10
+	// transportsLimit is how many transports with TLS options are kept, the least recently used one is closed first.
+	transportsLimit = 64
+)
+
+// TLSOptions changes how the certificate of the server is verified. CACertificate is a PEM encoded certificate
+// trusted on top of the system ones.
+type TLSOptions struct {
+	InsecureSkipVerify bool
+	CACertificate      string
+}
+
+// transports are shared by the requests, so the connections are reused between the checks. Every TLS configuration
+// gets its own transport, the default one is used by the requests without the TLS options.
+var (
+	defaultTransport = newTransport(nil)
+	transports       = newTransportCache(transportsLimit)
+)
+
+// transportKey identifies the TLS options by the hash of the CA certificate, so the certificates are not kept twice.
+type transportKey struct {
+	insecureSkipVerify bool
+	caCertificateHash  [sha256.Size]byte
+}
+
+func newTransportKey(tlsOptions TLSOptions) transportKey {
+	return transportKey{
+		insecureSkipVerify: tlsOptions.InsecureSkipVerify,
+		caCertificateHash:  sha256.Sum256([]byte(tlsOptions.CACertificate)),
+	}
+}
+
+type transportCacheEntry struct {
+	key       transportKey
+	transport *http.Transport
+}
+
+// transportCache keeps the recently used transports, the idle connections of the evicted ones are closed.
+type transportCache struct {
+	mu      sync.Mutex
+	limit   int
+	order   *list.List
+	entries map[transportKey]*list.Element
+}
+
+func newTransportCache(limit int) *transportCache {
+	return &transportCache{
+		limit:   limit,
+		order:   list.New(),
+		entries: make(map[transportKey]*list.Element),
+	}
+}
+
+func (tc *transportCache) get(key transportKey) (*http.Transport, bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	element, ok := tc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	tc.order.MoveToFront(element)
+	return element.Value.(*transportCacheEntry).transport, true
+}
+
+// add returns the transport which was saved in the meantime, so the concurrent requests share one transport.
+func (tc *transportCache) add(key transportKey, transport *http.Transport) *http.Transport {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if element, ok := tc.entries[key]; ok {
+		tc.order.MoveToFront(element)
+		return element.Value.(*transportCacheEntry).transport
+	}
+	tc.entries[key] = tc.order.PushFront(&transportCacheEntry{key: key, transport: transport})
+
+	for tc.order.Len() > tc.limit {
+		oldest := tc.order.Back()
+		tc.order.Remove(oldest)
+		evicted := oldest.Value.(*transportCacheEntry)
+		delete(tc.entries, evicted.key)
+		evicted.transport.CloseIdleConnections()
+	}
+
+	return transport
+}
+
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// NewCertPool returns the system certificates together with the PEM encoded one.
+func NewCertPool(caCertificate string) (*x509.CertPool, error) {
+	certPool, err := x509.SystemCertPool()
+	if err != nil {
+		certPool = x509.NewCertPool()
+	}
+	if !certPool.AppendCertsFromPEM([]byte(caCertificate)) {
+		return nil, errors.New("CA certificate is not a valid PEM certificate")
+	}
+
+	return certPool, nil
+}
+
+func transportFor(tlsOptions TLSOptions) (*http.Transport, error) {
+	if tlsOptions == (TLSOptions{}) {
+		return defaultTransport, nil
+	}
+	key := newTransportKey(tlsOptions)
+	if transport, ok := transports.get(key); ok {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: tlsOptions.InsecureSkipVerify,
+	}
+	if tlsOptions.CACertificate != "" {
+		certPool, err := NewCertPool(tlsOptions.CACertificate)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	return transports.add(key, newTransport(tlsConfig)), nil
+}
@@ -0,0 +1,262 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+// JSONSchemaError is a single violation of the schema, the path points at the value like "$.items[0].id".
+type JSONSchemaError struct {
+	Path    string
+	Message string
+}
+
+var supportedJSONSchemaTypes = []string{"null", "boolean", "object", "array", "number", "integer", "string"}
+
+var supportedJSONSchemaKeywords = []string{
+	"$schema", "$id", "title", "description", "type", "enum", "const", "properties", "required",
+	"additionalProperties", "items", "minItems", "maxItems", "minLength", "maxLength", "pattern", "minimum", "maximum",
+	"exclusiveMinimum", "exclusiveMaximum",
+}
+
+// JSONType returns the JSON type of a decoded value, a number without a fraction is an integer.
+func JSONType(value any) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if typedValue == math.Trunc(typedValue) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return reflect.TypeOf(value).String()
+	}
+}
+
+func schemaTypes(schema map[string]any) []string {
+	switch schemaType := schema["type"].(type) {
+	case string:
+		return []string{schemaType}
+	case []any:
+		types := make([]string, 0, len(schemaType))
+		for _, val := range schemaType {
+			if typeName, ok := val.(string); ok {
+				types = append(types, typeName)
+			}
+		}
+		return types
+	default:
+		return nil
+	}
+}
+
+func schemaNumber(schema map[string]any, keyword string) (float64, bool) {
+	number, ok := schema[keyword].(float64)
+	return number, ok
+}
+
+// CheckJSONSchema makes sure the schema uses only the keywords ValidateJSONSchema understands, so a response is not
+// reported as valid because a part of its schema was skipped.
+func CheckJSONSchema(schema map[string]any) error {
+	for keyword, val := range schema {
+		if !slices.Contains(supportedJSONSchemaKeywords, keyword) {
+			return fmt.Errorf("schema keyword %q is not supported", keyword)
+		}
+		switch keyword {
+		case "type":
+			types := schemaTypes(schema)
+			if len(types) == 0 {
+				return fmt.Errorf("schema type has to be a string or an array of strings")
+			}
+			for _, typeName := range types {
+				if !slices.Contains(supportedJSONSchemaTypes, typeName) {
+					return fmt.Errorf("schema type %q is not supported", typeName)
+				}
+			}
+		case "properties":
+			properties, ok := val.(map[string]any)
+			if !ok {
+				return fmt.Errorf("schema properties have to be an object")
+			}
+			for name, property := range properties {
+				propertySchema, ok := property.(map[string]any)
+				if !ok {
+					return fmt.Errorf("schema of the property %q has to be an object", name)
+				}
+				if err := CheckJSONSchema(propertySchema); err != nil {
+					return err
+				}
+			}
+		case "items":
+			itemsSchema, ok := val.(map[string]any)
+			if !ok {
+				return fmt.Errorf("schema items have to be an object")
+			}
+			if err := CheckJSONSchema(itemsSchema); err != nil {
+				return err
+			}
+		case "additionalProperties":
+			switch additionalProperties := val.(type) {
+			case bool:
+			case map[string]any:
+				if err := CheckJSONSchema(additionalProperties); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("schema additionalProperties has to be a boolean or an object")
+			}
+		case "required":
+			required, ok := val.([]any)
+			if !ok {
+				return fmt.Errorf("schema required has to be an array of strings")
+			}
+			for _, name := range required {
+				if _, ok := name.(string); !ok {
+					return fmt.Errorf("schema required has to be an array of strings")
+				}
+			}
+		case "enum":
+			if _, ok := val.([]any); !ok {
+				return fmt.Errorf("schema enum has to be an array")
+			}
+		case "pattern":
+			pattern, ok := val.(string)
+			if !ok {
+				return fmt.Errorf("schema pattern has to be a string")
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("schema pattern is not a valid regular expression")
+			}
+		case "minItems", "maxItems", "minLength", "maxLength", "minimum", "maximum", "exclusiveMinimum",
+			"exclusiveMaximum":
+			if _, ok := val.(float64); !ok {
+				return fmt.Errorf("schema %s has to be a number", keyword)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateJSONSchema validates the value against a subset of the JSON Schema draft 2020-12, every violation is
+// returned.
+func ValidateJSONSchema(schema map[string]any, value any, path string) []JSONSchemaError {
+	schemaErrors := make([]JSONSchemaError, 0)
+	addError := func(format string, args ...any) {
+		schemaErrors = append(schemaErrors, JSONSchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaTypes(schema); len(types) > 0 {
+		actualType := JSONType(value)
+		if !slices.Contains(types, actualType) && !(actualType == "integer" && slices.Contains(types, "number")) {
+			addError("expected %s, got %s", joinTypes(types), actualType)
+			return schemaErrors
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(val any) bool { return reflect.DeepEqual(val, value) }) {
+			addError("value is not one of the allowed values")
+		}
+	}
+	if constValue, ok := schema["const"]; ok && !reflect.DeepEqual(constValue, value) {
+		addError("value is not equal to the constant")
+	}
+
+	switch typedValue := value.(type) {
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				propertyName, _ := name.(string)
+				if _, ok := typedValue[propertyName]; !ok {
+					addError("property %q is required", propertyName)
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		keys := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			propertyPath := path + "." + key
+			if propertySchema, ok := properties[key].(map[string]any); ok {
+				schemaErrors = append(schemaErrors, ValidateJSONSchema(propertySchema, typedValue[key], propertyPath)...)
+				continue
+			}
+			switch additionalProperties := schema["additionalProperties"].(type) {
+			case bool:
+				if !additionalProperties {
+					addError("property %q is not allowed", key)
+				}
+			case map[string]any:
+				schemaErrors = append(schemaErrors, ValidateJSONSchema(additionalProperties, typedValue[key],
+					propertyPath)...)
+			}
+		}
+	case []any:
+		if minItems, ok := schemaNumber(schema, "minItems"); ok && float64(len(typedValue)) < minItems {
+			addError("expected at least %v items, got %d", minItems, len(typedValue))
+		}
+		if maxItems, ok := schemaNumber(schema, "maxItems"); ok && float64(len(typedValue)) > maxItems {
+			addError("expected at most %v items, got %d", maxItems, len(typedValue))
+		}
+		if itemsSchema, ok := schema["items"].(map[string]any); ok {
+			for i, item := range typedValue {
+				schemaErrors = append(schemaErrors, ValidateJSONSchema(itemsSchema, item,
+					path+"["+strconv.Itoa(i)+"]")...)
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(typedValue))
+		if minLength, ok := schemaNumber(schema, "minLength"); ok && length < minLength {
+			addError("expected at least %v characters", minLength)
+		}
+		if maxLength, ok := schemaNumber(schema, "maxLength"); ok && length > maxLength {
+			addError("expected at most %v characters", maxLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err == nil && !re.MatchString(typedValue) {
+				addError("value does not match the pattern %q", pattern)
+			}
+		}
+	case float64:
+		if minimum, ok := schemaNumber(schema, "minimum"); ok && typedValue < minimum {
+			addError("expected at least %v", minimum)
+		}
+		if maximum, ok := schemaNumber(schema, "maximum"); ok && typedValue > maximum {
+			addError("expected at most %v", maximum)
+		}
+		if exclusiveMinimum, ok := schemaNumber(schema, "exclusiveMinimum"); ok && typedValue <= exclusiveMinimum {
+			addError("expected more than %v", exclusiveMinimum)
+		}
+		if exclusiveMaximum, ok := schemaNumber(schema, "exclusiveMaximum"); ok && typedValue >= exclusiveMaximum {
+			addError("expected less than %v", exclusiveMaximum)
+		}
+	}
+
+	return schemaErrors
+}
+
+func joinTypes(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return fmt.Sprintf("one of %v", types)
+}
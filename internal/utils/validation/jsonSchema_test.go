@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeJSON(t *testing.T, data string) any {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	type args struct {
+		name           string
+		schema         string
+		value          string
+		expectedErrors []JSONSchemaError
+	}
+
+	userSchema := `{
+		"type": "object",
+		"required": ["id", "email", "roles"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+			"name": {"type": ["string", "null"], "maxLength": 5},
+			"roles": {"type": "array", "minItems": 1, "items": {"enum": ["admin", "user"]}}
+		}
+	}`
+
+	testsScenarios := []args{
+		{
+			name:           "Valid value",
+			schema:         userSchema,
+			value:          `{"id": 1, "email": "a@b.c", "name": null, "roles": ["admin"]}`,
+			expectedErrors: []JSONSchemaError{},
+		},
+		{
+			name:   "Every violation is reported",
+			schema: userSchema,
+			value:  `{"id": 0.5, "name": "too long", "roles": ["guest"], "extra": true}`,
+			expectedErrors: []JSONSchemaError{
+				{Path: "$", Message: `property "email" is required`},
+				{Path: "$", Message: `property "extra" is not allowed`},
+				{Path: "$.id", Message: "expected integer, got number"},
+				{Path: "$.name", Message: "expected at most 5 characters"},
+				{Path: "$.roles[0]", Message: "value is not one of the allowed values"},
+			},
+		},
+		{
+			name:   "Wrong type of the whole body",
+			schema: userSchema,
+			value:  `[1, 2]`,
+			expectedErrors: []JSONSchemaError{
+				{Path: "$", Message: "expected object, got array"},
+			},
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			schema := decodeJSON(t, testScenario.schema).(map[string]any)
+			schemaErrors := ValidateJSONSchema(schema, decodeJSON(t, testScenario.value), "$")
+			assert.Equal(t, testScenario.expectedErrors, schemaErrors)
+		})
+	}
+}
+
+func TestCheckRouteAssertions(t *testing.T) {
+	type args struct {
+		name          string
+		route         DTO.CreateRoute
+		expectedError error
+	}
+
+	testsScenarios := []args{
+		{
+			name: "Proper assertions",
+			route: DTO.CreateRoute{
+				Assertions: []models.RouteAssertion{
+					{Selector: "data.id", Operator: models.RouteAssertionOperatorType, Value: "integer"},
+					{Selector: "data.items.#", Operator: models.RouteAssertionOperatorRange, Min: tests.Ptr(1.0)},
+					{Source: models.RouteAssertionSourceHeader, Selector: "Content-Type",
+						Operator: models.RouteAssertionOperatorRegex, Value: "^application/json"},
+				},
+				ResponseSchema: map[string]any{"type": "object", "required": []any{"data"}},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Invalid regex",
+			route: DTO.CreateRoute{
+				Assertions: []models.RouteAssertion{
+					{Selector: "name", Operator: models.RouteAssertionOperatorRegex, Value: "("},
+				},
+			},
+			expectedError: errors.New(`regex assertion pattern "(" is not valid`),
+		},
+		{
+			name: "Range without bounds",
+			route: DTO.CreateRoute{
+				Assertions: []models.RouteAssertion{
+					{Selector: "count", Operator: models.RouteAssertionOperatorRange},
+				},
+			},
+			expectedError: errors.New("range assertion has to have a min or a max"),
+		},
+		{
+			name: "Header assertion without the header",
+			route: DTO.CreateRoute{
+				Assertions: []models.RouteAssertion{
+					{Source: models.RouteAssertionSourceHeader, Operator: models.RouteAssertionOperatorExists},
+				},
+			},
+			expectedError: errors.New("header assertion has to name the header"),
+		},
+		{
+			name: "Unsupported schema keyword",
+			route: DTO.CreateRoute{
+				ResponseSchema: map[string]any{"type": "object", "oneOf": []any{}},
+			},
+			expectedError: errors.New(`schema keyword "oneOf" is not supported`),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			err := CheckRouteAssertions(testScenario.route)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testScenario.expectedError.Error())
+		})
+	}
+}
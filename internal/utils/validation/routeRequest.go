@@ -0,0 +1,90 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/slodkiadrianek/octopus/internal/utils/request"
+)
+
+// headerNameRegex matches the token which a header name has to be.
+var headerNameRegex = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+// CheckRouteRequest makes sure the credentials and the body of the step can be sent.
+func CheckRouteRequest(route DTO.CreateRoute) error {
+	switch route.Auth.Type {
+	case "":
+	case models.RouteAuthTypeBearer:
+		if route.Auth.Value == "" {
+			return errors.New("bearer auth has to have the token as its value")
+		}
+	case models.RouteAuthTypeBasic:
+		if route.Auth.Username == "" {
+			return errors.New("basic auth has to have a username")
+		}
+	case models.RouteAuthTypeAPIKey:
+		if route.Auth.Value == "" {
+			return errors.New("API key auth has to have the key as its value")
+		}
+		if route.Auth.Name != "" && !headerNameRegex.MatchString(route.Auth.Name) {
+			return fmt.Errorf("API key header %q is not a valid header name", route.Auth.Name)
+		}
+	case models.RouteAuthTypeCookie:
+		if route.Auth.Name == "" || strings.ContainsAny(route.Auth.Name, "=; ") {
+			return errors.New("cookie auth has to have a cookie name without '=', ';' and spaces")
+		}
+	default:
+		return fmt.Errorf("auth type %q is not supported", route.Auth.Type)
+	}
+
+	if route.Auth.Type != "" && route.RequestAuthorization != "" {
+		return errors.New("route can have either auth or requestAuthorization")
+	}
+	if route.BodyType == models.RouteBodyTypeRaw && len(route.RequestBody) > 0 {
+		return errors.New("route with the raw body has to send it in rawBody instead of requestBody")
+	}
+	if route.BodyType != models.RouteBodyTypeRaw && route.RawBody != "" {
+		return errors.New("rawBody can be sent only with the raw body type")
+	}
+
+	for name := range route.RequestHeaders {
+		if !headerNameRegex.MatchString(name) {
+			return fmt.Errorf("header %q is not a valid header name", name)
+		}
+	}
+
+	return nil
+}
+
+// CheckRouteFlowHTTPOptions makes sure the base URL points at an HTTP server and the CA certificate can be read. A
+// base URL which uses the env is checked when the flow is run.
+func CheckRouteFlowHTTPOptions(httpOptions models.RouteFlowHTTPOptions) error {
+	if httpOptions.BaseURL != "" && !strings.Contains(httpOptions.BaseURL, "{{") {
+		baseURL, err := url.Parse(httpOptions.BaseURL)
+		if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+			return fmt.Errorf("base URL %q has to be an absolute http or https URL", httpOptions.BaseURL)
+		}
+		if baseURL.RawQuery != "" || baseURL.Fragment != "" {
+			return fmt.Errorf("base URL %q cannot have a query or a fragment", httpOptions.BaseURL)
+		}
+	}
+
+	if httpOptions.CACertificate != "" {
+		if _, err := request.NewCertPool(httpOptions.CACertificate); err != nil {
+			return err
+		}
+	}
+	if httpOptions.RedirectPolicy == models.RouteRedirectPolicyNone && httpOptions.MaxRedirects > 0 {
+		return errors.New("maxRedirects cannot be set when the redirects are not followed")
+	}
+	if httpOptions.InsecureSkipVerify && httpOptions.CACertificate != "" {
+		return errors.New("CA certificate is not used when the certificate is not verified")
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRouteRequest(t *testing.T) {
+	type args struct {
+		name          string
+		route         DTO.CreateRoute
+		expectedError error
+	}
+
+	testsScenarios := []args{
+		{
+			name: "Basic auth with the form body",
+			route: DTO.CreateRoute{
+				Auth:           models.RouteAuth{Type: models.RouteAuthTypeBasic, Username: "admin"},
+				BodyType:       models.RouteBodyTypeForm,
+				RequestBody:    map[string]any{"name": "adrian"},
+				RequestHeaders: map[string]string{"X-Request-Id": "1"},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "API key with the raw body",
+			route: DTO.CreateRoute{
+				Auth:     models.RouteAuth{Type: models.RouteAuthTypeAPIKey, Value: "{{ .secrets.key }}"},
+				BodyType: models.RouteBodyTypeRaw,
+				RawBody:  "<user/>",
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Unsupported auth",
+			route: DTO.CreateRoute{
+				Auth: models.RouteAuth{Type: "digest"},
+			},
+			expectedError: errors.New(`auth type "digest" is not supported`),
+		},
+		{
+			name: "Cookie without the name",
+			route: DTO.CreateRoute{
+				Auth: models.RouteAuth{Type: models.RouteAuthTypeCookie, Value: "abc"},
+			},
+			expectedError: errors.New("cookie auth has to have a cookie name without '=', ';' and spaces"),
+		},
+		{
+			name: "Auth together with the authorization",
+			route: DTO.CreateRoute{
+				Auth:                 models.RouteAuth{Type: models.RouteAuthTypeBearer, Value: "abc"},
+				RequestAuthorization: "abc",
+			},
+			expectedError: errors.New("route can have either auth or requestAuthorization"),
+		},
+		{
+			name: "Raw body in the request body",
+			route: DTO.CreateRoute{
+				BodyType:    models.RouteBodyTypeRaw,
+				RequestBody: map[string]any{"name": "adrian"},
+			},
+			expectedError: errors.New("route with the raw body has to send it in rawBody instead of requestBody"),
+		},
+		{
+			name: "Header name with a space",
+			route: DTO.CreateRoute{
+				RequestHeaders: map[string]string{"X Request": "1"},
+			},
+			expectedError: errors.New(`header "X Request" is not a valid header name`),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			err := CheckRouteRequest(testScenario.route)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testScenario.expectedError.Error())
+		})
+	}
+}
+
+func TestCheckRouteFlowHTTPOptions(t *testing.T) {
+	type args struct {
+		name          string
+		httpOptions   models.RouteFlowHTTPOptions
+		expectedError error
+	}
+
+	testsScenarios := []args{
+		{
+			name: "HTTPS base URL",
+			httpOptions: models.RouteFlowHTTPOptions{
+				BaseURL:        "https://api.example.com/v1",
+				TimeoutMs:      5000,
+				RedirectPolicy: models.RouteRedirectPolicyNone,
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Base URL from the env",
+			httpOptions:   models.RouteFlowHTTPOptions{BaseURL: "{{ .env.baseURL }}"},
+			expectedError: nil,
+		},
+		{
+			name:          "Base URL without the scheme",
+			httpOptions:   models.RouteFlowHTTPOptions{BaseURL: "api.example.com"},
+			expectedError: errors.New(`base URL "api.example.com" has to be an absolute http or https URL`),
+		},
+		{
+			name:          "Base URL with the query",
+			httpOptions:   models.RouteFlowHTTPOptions{BaseURL: "https://api.example.com?key=1"},
+			expectedError: errors.New(`base URL "https://api.example.com?key=1" cannot have a query or a fragment`),
+		},
+		{
+			name:          "CA certificate which is not PEM",
+			httpOptions:   models.RouteFlowHTTPOptions{CACertificate: "certificate"},
+			expectedError: errors.New("CA certificate is not a valid PEM certificate"),
+		},
+		{
+			name: "Max redirects without following them",
+			httpOptions: models.RouteFlowHTTPOptions{
+				RedirectPolicy: models.RouteRedirectPolicyNone,
+				MaxRedirects:   3,
+			},
+			expectedError: errors.New("maxRedirects cannot be set when the redirects are not followed"),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			err := CheckRouteFlowHTTPOptions(testScenario.httpOptions)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testScenario.expectedError.Error())
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+)
+
+// routeVariableNameRegex keeps the names of the variables, env and secrets usable as {{ .vars.<name> }}.
+var routeVariableNameRegex = regexp.MustCompile(`^\w+$`)
+
+// routeTemplateReferences parses the template and returns the fields it uses, e.g. ["vars", "userID"].
+func routeTemplateReferences(text string) ([][]string, error) {
+	if !strings.Contains(text, "{{") {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("route").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	references := make([][]string, 0)
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch typedNode := node.(type) {
+		case *parse.ListNode:
+			if typedNode == nil {
+				return
+			}
+			for _, child := range typedNode.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(typedNode.Pipe)
+		case *parse.PipeNode:
+			if typedNode == nil {
+				return
+			}
+			for _, command := range typedNode.Cmds {
+				walk(command)
+			}
+		case *parse.CommandNode:
+			for _, arg := range typedNode.Args {
+				walk(arg)
+			}
+		case *parse.FieldNode:
+			references = append(references, typedNode.Ident)
+		case *parse.IfNode:
+			walk(typedNode.Pipe)
+			walk(typedNode.List)
+			walk(typedNode.ElseList)
+		case *parse.RangeNode:
+			walk(typedNode.Pipe)
+			walk(typedNode.List)
+			walk(typedNode.ElseList)
+		case *parse.WithNode:
+			walk(typedNode.Pipe)
+			walk(typedNode.List)
+			walk(typedNode.ElseList)
+		}
+	}
+	walk(tmpl.Tree.Root)
+
+	return references, nil
+}
+
+// checkRouteTemplate makes sure the template parses and uses only the variables extracted by the earlier steps, the
+// env of the flow and its secrets. The secrets are not checked when they were left out, because the saved ones are
+// kept then.
+func checkRouteTemplate(text string, vars, env, secrets map[string]string) error {
+	references, err := routeTemplateReferences(text)
+	if err != nil {
+		return fmt.Errorf("template %q is not valid", text)
+	}
+
+	for _, reference := range references {
+		if len(reference) < 2 {
+			return fmt.Errorf("template %q has to use .vars, .env or .secrets", text)
+		}
+		name := reference[1]
+		switch reference[0] {
+		case "vars":
+			if _, ok := vars[name]; !ok {
+				return fmt.Errorf("variable %q is not extracted by any of the previous routes", name)
+			}
+		case "env":
+			if _, ok := env[name]; !ok {
+				return fmt.Errorf("env %q is not defined", name)
+			}
+		case "secrets":
+			if _, ok := secrets[name]; secrets != nil && !ok {
+				return fmt.Errorf("secret %q is not defined", name)
+			}
+		default:
+			return fmt.Errorf("template %q has to use .vars, .env or .secrets", text)
+		}
+	}
+
+	return nil
+}
+
+func checkRouteBodyTemplates(body any, vars, env, secrets map[string]string) error {
+	switch typedBody := body.(type) {
+	case string:
+		return checkRouteTemplate(typedBody, vars, env, secrets)
+	case map[string]any:
+		for _, val := range typedBody {
+			if err := checkRouteBodyTemplates(val, vars, env, secrets); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, val := range typedBody {
+			if err := checkRouteBodyTemplates(val, vars, env, secrets); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkRouteVariableNames[T any](kind string, names map[string]T) error {
+	for name := range names {
+		if !routeVariableNameRegex.MatchString(name) {
+			return fmt.Errorf("%s name %q can contain only letters, digits and underscores", kind, name)
+		}
+	}
+
+	return nil
+}
+
+// CheckRouteTemplates checks the variables extracted by the steps and the templates which use them, a step can only
+// use the variables extracted by the steps before it.
+func CheckRouteTemplates(routeFlow DTO.CreateRouteData) error {
+	if err := checkRouteVariableNames("env", routeFlow.Env); err != nil {
+		return err
+	}
+	if err := checkRouteVariableNames("secret", routeFlow.Secrets); err != nil {
+		return err
+	}
+
+	vars := make(map[string]string)
+	if err := checkRouteTemplate(routeFlow.HTTPOptions.BaseURL, vars, routeFlow.Env, routeFlow.Secrets); err != nil {
+		return err
+	}
+	for _, route := range routeFlow.Routes {
+		templates := []string{route.Path, route.RequestAuthorization, route.Auth.Username, route.Auth.Password,
+			route.Auth.Value, route.RawBody}
+		for _, val := range route.RequestParams {
+			templates = append(templates, val)
+		}
+		for _, val := range route.RequestQuery {
+			templates = append(templates, val)
+		}
+		for _, val := range route.RequestHeaders {
+			templates = append(templates, val)
+		}
+		for _, text := range templates {
+			if err := checkRouteTemplate(text, vars, routeFlow.Env, routeFlow.Secrets); err != nil {
+				return err
+			}
+		}
+		if err := checkRouteBodyTemplates(route.RequestBody, vars, routeFlow.Env, routeFlow.Secrets); err != nil {
+			return err
+		}
+
+		for _, variable := range route.Extract {
+			if !routeVariableNameRegex.MatchString(variable.Name) {
+				return fmt.Errorf("variable name %q can contain only letters, digits and underscores", variable.Name)
+			}
+			if variable.Source == models.RouteAssertionSourceHeader && variable.Selector == "" {
+				return fmt.Errorf("header variable %q has to name the header", variable.Name)
+			}
+			vars[variable.Name] = variable.Selector
+		}
+	}
+
+	return nil
+}
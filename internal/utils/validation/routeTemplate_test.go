@@ -0,0 +1,139 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRouteTemplates(t *testing.T) {
+	type args struct {
+		name          string
+		routeFlow     DTO.CreateRouteData
+		expectedError error
+	}
+
+	loginRoute := DTO.CreateRoute{
+		Path: "/login",
+		Extract: []models.RouteVariable{
+			{Name: "token", Selector: "data.token"},
+			{Name: "userID", Selector: "$.data.user.id"},
+		},
+	}
+
+	testsScenarios := []args{
+		{
+			name: "Variables extracted by the previous step",
+			routeFlow: DTO.CreateRouteData{
+				Env:     map[string]string{"region": "eu"},
+				Secrets: map[string]string{"password": "qwerty"},
+				Routes: []DTO.CreateRoute{
+					loginRoute,
+					{
+						Path:                 "/users/{{ .vars.userID }}",
+						RequestAuthorization: "{{ .vars.token }}",
+						RequestQuery:         map[string]string{"region": "{{ .env.region }}"},
+						RequestHeaders:       map[string]string{"X-Password": "{{ .secrets.password }}"},
+						RequestBody:          map[string]any{"ids": []any{"{{ .vars.userID }}"}},
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Secrets left out of the edited flow are not checked",
+			routeFlow: DTO.CreateRouteData{
+				Routes: []DTO.CreateRoute{
+					{Path: "/users", RequestHeaders: map[string]string{"X-Password": "{{ .secrets.password }}"}},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Variable used before it is extracted",
+			routeFlow: DTO.CreateRouteData{
+				Routes: []DTO.CreateRoute{
+					{Path: "/users/{{ .vars.userID }}"},
+					loginRoute,
+				},
+			},
+			expectedError: errors.New(`variable "userID" is not extracted by any of the previous routes`),
+		},
+		{
+			name: "Unknown env",
+			routeFlow: DTO.CreateRouteData{
+				Routes: []DTO.CreateRoute{
+					{Path: "/users", RequestBody: map[string]any{"region": "{{ .env.region }}"}},
+				},
+			},
+			expectedError: errors.New(`env "region" is not defined`),
+		},
+		{
+			name: "Base URL and credentials from the env and the secrets",
+			routeFlow: DTO.CreateRouteData{
+				Env:         map[string]string{"baseURL": "https://api.example.com"},
+				Secrets:     map[string]string{"password": "qwerty"},
+				HTTPOptions: models.RouteFlowHTTPOptions{BaseURL: "{{ .env.baseURL }}"},
+				Routes: []DTO.CreateRoute{
+					{
+						Path: "/login",
+						Auth: models.RouteAuth{Type: models.RouteAuthTypeBasic, Username: "admin",
+							Password: "{{ .secrets.password }}"},
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Raw body with the variable of the same step",
+			routeFlow: DTO.CreateRouteData{
+				Routes: []DTO.CreateRoute{
+					{Path: "/users", RawBody: "<id>{{ .vars.userID }}</id>", Extract: []models.RouteVariable{
+						{Name: "userID", Selector: "id"},
+					}},
+				},
+			},
+			expectedError: errors.New(`variable "userID" is not extracted by any of the previous routes`),
+		},
+		{
+			name: "Template which does not parse",
+			routeFlow: DTO.CreateRouteData{
+				Routes: []DTO.CreateRoute{
+					{Path: "/users/{{ .vars.userID"},
+				},
+			},
+			expectedError: errors.New(`template "/users/{{ .vars.userID" is not valid`),
+		},
+		{
+			name: "Template outside of the variables",
+			routeFlow: DTO.CreateRouteData{
+				Routes: []DTO.CreateRoute{
+					{Path: "/users/{{ .ID }}"},
+				},
+			},
+			expectedError: errors.New(`template "/users/{{ .ID }}" has to use .vars, .env or .secrets`),
+		},
+		{
+			name: "Variable name which cannot be used in the template",
+			routeFlow: DTO.CreateRouteData{
+				Routes: []DTO.CreateRoute{
+					{Path: "/login", Extract: []models.RouteVariable{{Name: "user-id", Selector: "id"}}},
+				},
+			},
+			expectedError: errors.New(`variable name "user-id" can contain only letters, digits and underscores`),
+		},
+	}
+	for _, testScenario := range testsScenarios {
+		t.Run(testScenario.name, func(t *testing.T) {
+			err := CheckRouteTemplates(testScenario.routeFlow)
+			if testScenario.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testScenario.expectedError.Error())
+		})
+	}
+}
@@ -2,9 +2,13 @@ package validation
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"slices"
 
 	z "github.com/Oudwins/zog"
 	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
 )
 
 func ValidateUsersIDs(userID, userIDFromToken int) error {
@@ -87,3 +91,45 @@ func IsDataInResponseOrRequest[T map[string]any | map[string]string](responseBod
 	}
 	return false
 }
+
+// CheckRouteAssertions makes sure the assertions of the route can be evaluated, so a mistake in them is reported when
+// the flow is saved instead of failing every check.
+func CheckRouteAssertions(route DTO.CreateRoute) error {
+	for _, assertion := range route.Assertions {
+		if assertion.Source == models.RouteAssertionSourceHeader && assertion.Selector == "" {
+			return errors.New("header assertion has to name the header")
+		}
+		switch assertion.Operator {
+		case models.RouteAssertionOperatorRegex:
+			pattern, ok := assertion.Value.(string)
+			if !ok {
+				return errors.New("regex assertion has to have a pattern as its value")
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("regex assertion pattern %q is not valid", pattern)
+			}
+		case models.RouteAssertionOperatorType:
+			expectedType, _ := assertion.Value.(string)
+			if !slices.Contains(supportedJSONSchemaTypes, expectedType) {
+				return fmt.Errorf("type assertion has to have one of %v as its value", supportedJSONSchemaTypes)
+			}
+		case models.RouteAssertionOperatorRange:
+			if assertion.Min == nil && assertion.Max == nil {
+				return errors.New("range assertion has to have a min or a max")
+			}
+			if assertion.Min != nil && assertion.Max != nil && *assertion.Min > *assertion.Max {
+				return errors.New("range assertion min has to be lower than its max")
+			}
+		case models.RouteAssertionOperatorEq:
+			if assertion.Value == nil {
+				return errors.New("eq assertion has to have a value")
+			}
+		}
+	}
+
+	if len(route.ResponseSchema) > 0 {
+		return CheckJSONSchema(route.ResponseSchema)
+	}
+
+	return nil
+}
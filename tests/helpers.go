@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
@@ -44,7 +47,7 @@ func CreateLogger() *utils.Logger {
 	return loggerService
 }
 
-func Ptr[T ~int | ~string](v T) *T {
+func Ptr[T ~int | ~string | ~float64](v T) *T {
 	return &v
 }
 
@@ -118,3 +121,38 @@ func KillAndRemoveContainer(ctx context.Context, containerID string, loggerServi
 
 	return nil
 }
+
+func CreateDockerClientManager(loggerService *utils.Logger) *config.DockerClientManager {
+	return config.NewDockerClientManager(loggerService, nil, "", 30*time.Second, 0, time.Millisecond)
+}
+
+// NewFakeDockerAPIServer starts an HTTP server that answers like the docker daemon. Handlers are keyed by
+// "METHOD /path" with the API version prefix stripped, for example "POST /containers/123/start".
+func NewFakeDockerAPIServer(handlers map[string]http.HandlerFunc) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if strings.HasPrefix(path, "/v1.") {
+			path = path[strings.Index(path[1:], "/")+1:]
+		}
+
+		if path == "/_ping" {
+			w.Header().Set("API-Version", "1.47")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("OK"))
+			return
+		}
+
+		handler, ok := handlers[r.Method+" "+path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"No such container"}`))
+			return
+		}
+		handler(w, r)
+	}))
+}
+
+func FakeDockerHost(server *httptest.Server) string {
+	return "tcp://" + strings.TrimPrefix(server.URL, "http://")
+}
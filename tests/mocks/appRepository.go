@@ -47,6 +47,16 @@ func (m *MockAppRepository) UpdateApp(ctx context.Context, appID string, app DTO
 	return args.Error(0)
 }
 
+func (m *MockAppRepository) UpdateAppID(ctx context.Context, appID, newAppID string, ownerID int) error {
+	args := m.Called(ctx, appID, newAppID, ownerID)
+	return args.Error(0)
+}
+
+func (m *MockAppRepository) UpdateAppStoppedByUser(ctx context.Context, appID string, stoppedByUser bool) error {
+	args := m.Called(ctx, appID, stoppedByUser)
+	return args.Error(0)
+}
+
 func (m *MockAppRepository) InsertAppStatuses(ctx context.Context, appsStatuses []DTO.AppStatus) error {
 	args := m.Called(ctx, appsStatuses)
 	return args.Error(0)
@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockConsoleAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockConsoleAuditRepository) InsertConsoleAuditLog(ctx context.Context,
+	consoleAuditLog DTO.ConsoleAuditLog,
+) error {
+	args := m.Called(ctx, consoleAuditLog)
+	return args.Error(0)
+}
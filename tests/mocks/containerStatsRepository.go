@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockContainerStatsRepository struct {
+	mock.Mock
+}
+
+func (m *MockContainerStatsRepository) InsertContainersStats(ctx context.Context,
+	containersStats []DTO.ContainerStats,
+) error {
+	args := m.Called(ctx, containersStats)
+	return args.Error(0)
+}
+
+func (m *MockContainerStatsRepository) GetContainerStatsHistory(ctx context.Context, appID string, ownerID int,
+	since time.Time,
+) ([]DTO.ContainerStats, error) {
+	args := m.Called(ctx, appID, ownerID, since)
+	return args.Get(0).([]DTO.ContainerStats), args.Error(1)
+}
+
+func (m *MockContainerStatsRepository) DeleteContainersStatsOlderThan(ctx context.Context, olderThan time.Time) error {
+	args := m.Called(ctx, olderThan)
+	return args.Error(0)
+}
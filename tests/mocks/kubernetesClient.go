@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockKubernetesClient struct {
+	mock.Mock
+}
+
+func (m *MockKubernetesClient) ListWorkloads(ctx context.Context, ownerID int, cluster,
+	namespace string,
+) ([]DTO.KubernetesWorkload, error) {
+	args := m.Called(ctx, ownerID, cluster, namespace)
+	return args.Get(0).([]DTO.KubernetesWorkload), args.Error(1)
+}
+
+func (m *MockKubernetesClient) GetWorkload(ctx context.Context,
+	workload models.KubernetesWorkload,
+) (DTO.KubernetesWorkload, error) {
+	args := m.Called(ctx, workload)
+	return args.Get(0).(DTO.KubernetesWorkload), args.Error(1)
+}
+
+func (m *MockKubernetesClient) RestartWorkload(ctx context.Context, workload models.KubernetesWorkload) error {
+	args := m.Called(ctx, workload)
+	return args.Error(0)
+}
+
+// StreamPodLogs sends the log lines returned by the mock.
+func (m *MockKubernetesClient) StreamPodLogs(ctx context.Context, workload models.KubernetesWorkload,
+	logsOptions DTO.LogsOptions, send func(DTO.LogLine) error,
+) error {
+	args := m.Called(ctx, workload, logsOptions)
+	for _, logLine := range args.Get(0).([]DTO.LogLine) {
+		if err := send(logLine); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockKubernetesClient) ResetClient(ownerID int, cluster string) {
+	m.Called(ownerID, cluster)
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockKubernetesRepository struct {
+	mock.Mock
+}
+
+func (m *MockKubernetesRepository) GetKubernetesCluster(ctx context.Context, ownerID int,
+	name string,
+) (*models.KubernetesCluster, error) {
+	args := m.Called(ctx, ownerID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.KubernetesCluster), args.Error(1)
+}
+
+func (m *MockKubernetesRepository) UpsertKubernetesCluster(ctx context.Context,
+	cluster models.KubernetesCluster,
+) error {
+	args := m.Called(ctx, cluster)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesRepository) ImportKubernetesWorkloads(ctx context.Context, apps []DTO.App,
+	workloads []models.KubernetesWorkload,
+) error {
+	args := m.Called(ctx, apps, workloads)
+	return args.Error(0)
+}
+
+func (m *MockKubernetesRepository) GetKubernetesWorkload(ctx context.Context,
+	appID string,
+) (*models.KubernetesWorkload, error) {
+	args := m.Called(ctx, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.KubernetesWorkload), args.Error(1)
+}
+
+func (m *MockKubernetesRepository) GetKubernetesWorkloads(ctx context.Context) ([]models.KubernetesWorkload, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.KubernetesWorkload), args.Error(1)
+}
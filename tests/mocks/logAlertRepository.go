@@ -0,0 +1,65 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLogAlertRepository struct {
+	mock.Mock
+}
+
+func (m *MockLogAlertRepository) GetLogAlertRules(ctx context.Context, appID string,
+	ownerID int,
+) ([]DTO.LogAlertRule, error) {
+	args := m.Called(ctx, appID, ownerID)
+	return args.Get(0).([]DTO.LogAlertRule), args.Error(1)
+}
+
+func (m *MockLogAlertRepository) InsertLogAlertRule(ctx context.Context, appID string,
+	logAlertRule DTO.CreateLogAlertRule,
+) (int, error) {
+	args := m.Called(ctx, appID, logAlertRule)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLogAlertRepository) UpdateLogAlertRule(ctx context.Context, appID string, ruleID, ownerID int,
+	logAlertRule DTO.CreateLogAlertRule,
+) error {
+	args := m.Called(ctx, appID, ruleID, ownerID, logAlertRule)
+	return args.Error(0)
+}
+
+func (m *MockLogAlertRepository) DeleteLogAlertRule(ctx context.Context, appID string, ruleID, ownerID int) error {
+	args := m.Called(ctx, appID, ruleID, ownerID)
+	return args.Error(0)
+}
+
+func (m *MockLogAlertRepository) ValidateLogAlertPattern(ctx context.Context, pattern string) error {
+	args := m.Called(ctx, pattern)
+	return args.Error(0)
+}
+
+func (m *MockLogAlertRepository) GetDueLogAlertRules(ctx context.Context,
+	now time.Time,
+) ([]DTO.LogAlertRule, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]DTO.LogAlertRule), args.Error(1)
+}
+
+func (m *MockLogAlertRepository) CountMatchingLogLines(ctx context.Context, appID, pattern string,
+	since time.Time,
+) (int, error) {
+	args := m.Called(ctx, appID, pattern, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLogAlertRepository) UpdateLogAlertsLastTriggered(ctx context.Context, rulesIDs []int,
+	triggeredAt time.Time,
+) error {
+	args := m.Called(ctx, rulesIDs, triggeredAt)
+	return args.Error(0)
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockLogRepository) InsertLogs(ctx context.Context, logs []DTO.AppLog) error {
+	args := m.Called(ctx, logs)
+	return args.Error(0)
+}
+
+func (m *MockLogRepository) GetLastLogTime(ctx context.Context, appID string) (time.Time, error) {
+	args := m.Called(ctx, appID)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockLogRepository) DeleteExpiredLogs(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockLogRepository) SearchLogs(ctx context.Context, ownerID int,
+	logsSearch DTO.LogsSearch,
+) (DTO.LogsSearchResult, error) {
+	args := m.Called(ctx, ownerID, logsSearch)
+	return args.Get(0).(DTO.LogsSearchResult), args.Error(1)
+}
+
+func (m *MockLogRepository) UpdateLogsRetention(ctx context.Context, appID string, ownerID, days int) error {
+	args := m.Called(ctx, appID, ownerID, days)
+	return args.Error(0)
+}
@@ -0,0 +1,22 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPm2Client struct {
+	mock.Mock
+}
+
+func (m *MockPm2Client) ListProcesses(ctx context.Context) ([]DTO.Pm2Process, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]DTO.Pm2Process), args.Error(1)
+}
+
+func (m *MockPm2Client) RunAction(ctx context.Context, action, processName string) error {
+	args := m.Called(ctx, action, processName)
+	return args.Error(0)
+}
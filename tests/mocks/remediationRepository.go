@@ -0,0 +1,68 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRemediationRepository struct {
+	mock.Mock
+}
+
+func (m *MockRemediationRepository) GetRemediationPolicy(ctx context.Context, appID string,
+	ownerID int,
+) (*DTO.RemediationPolicy, error) {
+	args := m.Called(ctx, appID, ownerID)
+	return args.Get(0).(*DTO.RemediationPolicy), args.Error(1)
+}
+
+func (m *MockRemediationRepository) UpsertRemediationPolicy(ctx context.Context, appID string,
+	policy DTO.UpsertRemediationPolicy,
+) error {
+	args := m.Called(ctx, appID, policy)
+	return args.Error(0)
+}
+
+func (m *MockRemediationRepository) DeleteRemediationPolicy(ctx context.Context, appID string, ownerID int) error {
+	args := m.Called(ctx, appID, ownerID)
+	return args.Error(0)
+}
+
+func (m *MockRemediationRepository) UpdateRemediationMaintenance(ctx context.Context, appID string, ownerID int,
+	maintenanceUntil *time.Time,
+) error {
+	args := m.Called(ctx, appID, ownerID, maintenanceUntil)
+	return args.Error(0)
+}
+
+func (m *MockRemediationRepository) GetAppsToRemediate(ctx context.Context,
+	now time.Time,
+) ([]models.AppToRemediate, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]models.AppToRemediate), args.Error(1)
+}
+
+func (m *MockRemediationRepository) UpdateRemediationState(ctx context.Context, appID string, attempts int,
+	lastAttemptAt *time.Time, escalated bool,
+) error {
+	args := m.Called(ctx, appID, attempts, lastAttemptAt, escalated)
+	return args.Error(0)
+}
+
+func (m *MockRemediationRepository) InsertRemediationAttempts(ctx context.Context,
+	remediationAttempts []DTO.RemediationAttempt,
+) error {
+	args := m.Called(ctx, remediationAttempts)
+	return args.Error(0)
+}
+
+func (m *MockRemediationRepository) GetRemediationHistory(ctx context.Context, appID string,
+	ownerID int,
+) ([]DTO.RemediationAttempt, error) {
+	args := m.Called(ctx, appID, ownerID)
+	return args.Get(0).([]DTO.RemediationAttempt), args.Error(1)
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/slodkiadrianek/octopus/internal/DTO"
 	"github.com/slodkiadrianek/octopus/internal/models"
@@ -12,7 +13,9 @@ type MockRouteRepository struct {
 	mock.Mock
 }
 
-func (m *MockRouteRepository) UpdateWorkingRoutesStatuses(ctx context.Context, routeStatuses map[int]string) error {
+func (m *MockRouteRepository) UpdateWorkingRoutesStatuses(ctx context.Context,
+	routeStatuses map[int]models.RouteStepResult,
+) error {
 	args := m.Called(ctx, routeStatuses)
 	return args.Error(0)
 }
@@ -21,6 +24,70 @@ func (m *MockRouteRepository) GetWorkingRoutesToTest(ctx context.Context) ([]mod
 	return args.Get(0).([]models.RouteToTest), args.Error(1)
 }
 
+func (m *MockRouteRepository) GetRouteFlowRoutesToTest(ctx context.Context, flowID int, appID string,
+	ownerID int,
+) ([]models.RouteToTest, error) {
+	args := m.Called(ctx, flowID, appID, ownerID)
+	return args.Get(0).([]models.RouteToTest), args.Error(1)
+}
+
+func (m *MockRouteRepository) InsertRouteFlowRuns(ctx context.Context, routeFlowRuns []DTO.RouteFlowRun) ([]int,
+	error,
+) {
+	args := m.Called(ctx, routeFlowRuns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockRouteRepository) FinishRouteFlowRun(ctx context.Context, routeFlowRun DTO.RouteFlowRun) error {
+	args := m.Called(ctx, routeFlowRun)
+	return args.Error(0)
+}
+
+func (m *MockRouteRepository) FailRunningRouteFlowRuns(ctx context.Context, runningStatus, failedStatus string) error {
+	args := m.Called(ctx, runningStatus, failedStatus)
+	return args.Error(0)
+}
+
+func (m *MockRouteRepository) UpdateRouteFlowFailureStreak(ctx context.Context, flowID int,
+	failed bool,
+) (*models.RouteFlowNotificationState, error) {
+	args := m.Called(ctx, flowID, failed)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RouteFlowNotificationState), args.Error(1)
+}
+
+func (m *MockRouteRepository) UpdateRouteFlowNotifiedStatus(ctx context.Context, flowID int, notifiedStatus string,
+	notifiedAt time.Time,
+) error {
+	args := m.Called(ctx, flowID, notifiedStatus, notifiedAt)
+	return args.Error(0)
+}
+
+func (m *MockRouteRepository) GetRouteFlowRun(ctx context.Context, runID, flowID int, appID string,
+	ownerID int,
+) (*DTO.RouteFlowRun, error) {
+	args := m.Called(ctx, runID, flowID, appID, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*DTO.RouteFlowRun), args.Error(1)
+}
+
+func (m *MockRouteRepository) GetRouteFlowRuns(ctx context.Context, flowID int, appID string,
+	ownerID int,
+) ([]DTO.RouteFlowRun, error) {
+	args := m.Called(ctx, flowID, appID, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]DTO.RouteFlowRun), args.Error(1)
+}
+
 func (m *MockRouteRepository) InsertRoutesInfo(ctx context.Context, routesInfo []*DTO.RouteInfo) ([]int, error) {
 	args := m.Called(ctx, routesInfo)
 	return args.Get(0).([]int), args.Error(1)
@@ -45,3 +112,38 @@ func (m *MockRouteRepository) InsertWorkingRoute(ctx context.Context, workingRou
 	args := m.Called(ctx, workingRoute)
 	return args.Get(0).(int), args.Error(1)
 }
+
+func (m *MockRouteRepository) InsertRouteFlow(ctx context.Context, appID, name, env, secrets, httpOptions,
+	notifications string,
+) (int, error) {
+	args := m.Called(ctx, appID, name, env, secrets, httpOptions, notifications)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRouteRepository) UpdateRouteFlow(ctx context.Context, flowID int, appID string, ownerID int, name string,
+	version int, env string, secrets *string, httpOptions, notifications string, workingRoutes []DTO.WorkingRoute,
+) error {
+	args := m.Called(ctx, flowID, appID, ownerID, name, version, env, secrets, httpOptions, notifications,
+		workingRoutes)
+	return args.Error(0)
+}
+
+func (m *MockRouteRepository) DeleteRouteFlow(ctx context.Context, flowID int, appID string, ownerID int) error {
+	args := m.Called(ctx, flowID, appID, ownerID)
+	return args.Error(0)
+}
+
+func (m *MockRouteRepository) GetRouteFlows(ctx context.Context, appID string, ownerID int) ([]DTO.RouteFlow, error) {
+	args := m.Called(ctx, appID, ownerID)
+	return args.Get(0).([]DTO.RouteFlow), args.Error(1)
+}
+
+func (m *MockRouteRepository) GetRouteFlow(ctx context.Context, flowID int, appID string, ownerID,
+	version int,
+) (*DTO.RouteFlow, error) {
+	args := m.Called(ctx, flowID, appID, ownerID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*DTO.RouteFlow), args.Error(1)
+}
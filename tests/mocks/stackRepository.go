@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/slodkiadrianek/octopus/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStackRepository struct {
+	mock.Mock
+}
+
+func (m *MockStackRepository) UpsertStack(ctx context.Context, name string, ownerID int) (int, error) {
+	args := m.Called(ctx, name, ownerID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStackRepository) GetStack(ctx context.Context, stackID, ownerID int) (*models.Stack, error) {
+	args := m.Called(ctx, stackID, ownerID)
+	return args.Get(0).(*models.Stack), args.Error(1)
+}
+
+func (m *MockStackRepository) GetStacks(ctx context.Context, ownerID int) ([]models.Stack, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).([]models.Stack), args.Error(1)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+	"io"
+
+	"github.com/slodkiadrianek/octopus/internal/DTO"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSystemdClient struct {
+	mock.Mock
+}
+
+func (m *MockSystemdClient) ListUnits(ctx context.Context, pattern string) ([]DTO.SystemdUnit, error) {
+	args := m.Called(ctx, pattern)
+	return args.Get(0).([]DTO.SystemdUnit), args.Error(1)
+}
+
+func (m *MockSystemdClient) GetUnit(ctx context.Context, unitName string) (DTO.SystemdUnit, error) {
+	args := m.Called(ctx, unitName)
+	return args.Get(0).(DTO.SystemdUnit), args.Error(1)
+}
+
+func (m *MockSystemdClient) RunAction(ctx context.Context, action, unitName string) error {
+	args := m.Called(ctx, action, unitName)
+	return args.Error(0)
+}
+
+func (m *MockSystemdClient) FollowJournal(ctx context.Context, unitName string,
+	logsOptions DTO.LogsOptions,
+) (io.ReadCloser, error) {
+	args := m.Called(ctx, unitName, logsOptions)
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
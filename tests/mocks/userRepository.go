@@ -46,3 +46,8 @@ func (m *MockUserRepository) ChangeUserPassword(ctx context.Context, userID int,
 	args := m.Called(ctx, userID, newPassword)
 	return args.Error(0)
 }
+
+func (m *MockUserRepository) HasPermission(ctx context.Context, userID int, permission string) (bool, error) {
+	args := m.Called(ctx, userID, permission)
+	return args.Bool(0), args.Error(1)
+}